@@ -114,6 +114,37 @@ func NewMultiSigAccount(m int, pubKeys []*crypto.PublicKey) (*Account, error) {
 	}, nil
 }
 
+// NewSchnorrAccount creates an account without private key of the Schnorr
+// address aggregated from the public keys.
+func NewSchnorrAccount(pubKeys [][]byte) (*Account, error) {
+	sumPublicKey, err := crypto.AggregatePublickeys(pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := crypto.DecodePoint(sumPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	schnorrContract, err := contract.CreateSchnorrContract(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	programHash := schnorrContract.ToProgramHash()
+	address, err := programHash.ToAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Account{
+		PrivateKey:   nil,
+		PublicKey:    nil,
+		ProgramHash:  *programHash,
+		RedeemScript: schnorrContract.Code,
+		Address:      address,
+	}, nil
+}
+
 func NewSchnorrAggregateAccount(accounts []*Account) *SchnorAccount {
 	var sa = new(SchnorAccount)
 	var Pxs, Pys []*big.Int
@@ -10,11 +10,9 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
-	"math/rand"
 	"os"
 	"sort"
 	"sync"
-	"time"
 
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/core/contract"
@@ -30,12 +28,18 @@ type Client struct {
 	mu sync.Mutex
 
 	path      string
+	version   string
 	iv        []byte
 	masterKey []byte
 
 	mainAccount common.Uint160
 	accounts    map[common.Uint160]*Account
 
+	// seed is the seed of HD wallet, nextIndex is the index of the next
+	// address to derive.
+	seed      []byte
+	nextIndex uint32
+
 	FileStore
 }
 
@@ -75,7 +79,12 @@ func Add(path string, password []byte) (*Client, error) {
 	if client == nil {
 		return nil, errors.New("add account failed")
 	}
-	_, err := client.CreateAccount()
+	var err error
+	if client.IsHD() {
+		_, err = client.DeriveAccount()
+	} else {
+		_, err = client.CreateAccount()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +102,16 @@ func AddMultiSig(path string, password []byte, m int, pubKeys []*crypto.PublicKe
 	return client.CreateMultiSigAccount(m, pubKeys)
 }
 
+func AddSchnorr(path string, password []byte, pubKeys [][]byte) (*Account, error) {
+	exist := utils.FileExisted(path)
+	client := NewClient(path, password, !exist)
+	if client == nil {
+		return nil, errors.New("add Schnorr account failed")
+	}
+
+	return client.CreateSchnorrAccount(pubKeys)
+}
+
 func Open(path string, password []byte) (*Client, error) {
 	client := NewClient(path, password, false)
 	if client == nil {
@@ -193,71 +212,23 @@ func NewClient(path string, password []byte, create bool) *Client {
 		FileStore: FileStore{path: path},
 	}
 
-	passwordKey := crypto.ToAesKey(password)
 	if create {
-		//create new client
-		client.iv = make([]byte, 16)
-		client.masterKey = make([]byte, 32)
-
-		//generate random number for iv/masterkey
-		r := rand.New(rand.NewSource(time.Now().UnixNano()))
-		for i := 0; i < 16; i++ {
-			client.iv[i] = byte(r.Intn(256))
-		}
-		for i := 0; i < 32; i++ {
-			client.masterKey[i] = byte(r.Intn(256))
-		}
-
 		//new client store (build DB)
 		client.BuildDatabase(path)
 
-		if err := client.SaveStoredData("Version", []byte(KeystoreVersion)); err != nil {
-			fmt.Println("error: failed to save Version")
-			return nil
-		}
-
-		pwdhash := sha256.Sum256(passwordKey)
-		if err := client.SaveStoredData("PasswordHash", pwdhash[:]); err != nil {
-			fmt.Println("error: failed to save PasswordHash")
-			return nil
-		}
-		if err := client.SaveStoredData("IV", client.iv[:]); err != nil {
-			fmt.Println("error: failed to save IV")
-			return nil
-		}
-
-		aesmk, err := crypto.AesEncrypt(client.masterKey[:], passwordKey, client.iv)
-		if err != nil {
-			fmt.Println("error: failed to encrypt the passoword", err.Error())
-			return nil
-		}
-		if err := client.SaveStoredData("MasterKey", aesmk); err != nil {
-			fmt.Println("error: failed to save MasterKey")
+		if err := client.createKeystore(password); err != nil {
+			fmt.Println("error: failed to create keystore,", err)
 			return nil
 		}
-
 	} else {
-		if ok := client.verifyPasswordKey(passwordKey); !ok {
-			return nil
-		}
-		var err error
-		client.iv, err = client.LoadStoredData("IV")
-		if err != nil {
-			fmt.Println("error: failed to load iv")
-			return nil
-		}
-		encryptedMasterKey, err := client.LoadStoredData("MasterKey")
-		if err != nil {
-			fmt.Println("error: failed to load master key")
+		if ok := client.openKeystore(password); !ok {
 			return nil
 		}
-		client.masterKey, err = crypto.AesDecrypt(encryptedMasterKey, passwordKey, client.iv)
-		if err != nil {
-			fmt.Println("error: failed to decrypt master key")
+		if err := client.loadHDSeed(); err != nil {
+			fmt.Println("error: failed to load HD seed")
 			return nil
 		}
 	}
-	common.ClearBytes(passwordKey)
 
 	return client
 }
@@ -287,8 +258,24 @@ func (cl *Client) CreateMultiSigAccount(m int, pubKeys []*crypto.PublicKey) (*Ac
 	return account, nil
 }
 
+func (cl *Client) CreateSchnorrAccount(pubKeys [][]byte) (*Account, error) {
+	account, err := NewSchnorrAccount(pubKeys)
+	if err != nil {
+		return nil, err
+	}
+	if err = cl.SaveAccountData(&account.ProgramHash, account.RedeemScript, nil); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
 // SaveAccount saves a Account to memory and db
 func (cl *Client) SaveAccount(ac *Account) error {
+	return cl.saveAccount(ac, "")
+}
+
+func (cl *Client) saveAccount(ac *Account, derivationPath string) error {
 	cl.mu.Lock()
 	defer cl.mu.Unlock()
 
@@ -313,7 +300,8 @@ func (cl *Client) SaveAccount(ac *Account) error {
 	common.ClearBytes(decryptedPrivateKey)
 
 	// save Account keys to db
-	err = cl.SaveAccountData(&ac.ProgramHash, ac.RedeemScript, encryptedPrivateKey)
+	err = cl.SaveDerivedAccountData(&ac.ProgramHash, ac.RedeemScript,
+		encryptedPrivateKey, derivationPath)
 	if err != nil {
 		return err
 	}
@@ -398,7 +386,7 @@ func (cl *Client) LoadAccounts() error {
 }
 
 func (cl *Client) EncryptPrivateKey(prikey []byte) ([]byte, error) {
-	enc, err := crypto.AesEncrypt(prikey, cl.masterKey, cl.iv)
+	enc, err := cl.encryptData(prikey)
 	if err != nil {
 		return nil, err
 	}
@@ -410,14 +398,17 @@ func (cl *Client) DecryptPrivateKey(prikey []byte) ([]byte, error) {
 	if prikey == nil {
 		return nil, errors.New("the private key is nil")
 	}
-	if len(prikey) != 96 {
+	if cl.version == KeystoreVersionV1 && len(prikey) != 96 {
 		return nil, errors.New("the len of private key is not 96bytes")
 	}
 
-	dec, err := crypto.AesDecrypt(prikey, cl.masterKey, cl.iv)
+	dec, err := cl.decryptData(prikey)
 	if err != nil {
 		return nil, err
 	}
+	if len(dec) != 96 {
+		return nil, errors.New("the len of private key is not 96bytes")
+	}
 
 	return dec, nil
 }
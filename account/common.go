@@ -17,7 +17,11 @@ const (
 	MAINACCOUNT      = "main-account"
 	SUBACCOUNT       = "sub-account"
 	KeystoreFileName = "keystore.dat"
-	KeystoreVersion  = "1.0.0"
+	KeystoreVersion  = "2.0.0"
+
+	// KeystoreVersionV1 is the version of keystore that the password is
+	// hashed by SHA256 and keys are encrypted by AES-CBC.
+	KeystoreVersionV1 = "1.0.0"
 
 	MaxSignalQueueLen = 5
 )
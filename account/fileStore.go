@@ -22,6 +22,18 @@ type AccountData struct {
 	RedeemScript        string
 	PrivateKeyEncrypted string
 	Type                string
+	DerivationPath      string `json:",omitempty"`
+}
+
+// KDFParams is the parameters of the function deriving the key, which
+// encrypts the master key, from the password.
+type KDFParams struct {
+	Name   string
+	Salt   string
+	N      int
+	R      int
+	P      int
+	KeyLen int
 }
 
 type FileData struct {
@@ -29,7 +41,14 @@ type FileData struct {
 	PasswordHash string
 	IV           string
 	MasterKey    string
+	KDF          *KDFParams `json:",omitempty"`
+	HDSeed       string     `json:",omitempty"`
+	Mnemonic     string     `json:",omitempty"`
 	Account      []AccountData
+
+	// SchnorrNonces is the encrypted secrets of the Schnorr nonces not used
+	// yet, indexed by the public nonces.
+	SchnorrNonces map[string]string `json:",omitempty"`
 }
 
 type FileStore struct {
@@ -105,6 +124,15 @@ func (cs *FileStore) BuildDatabase(path string) {
 
 func (cs *FileStore) SaveAccountData(programHash *common.Uint168, redeemScript []byte,
 	encryptedPrivateKey []byte) error {
+	return cs.SaveDerivedAccountData(programHash, redeemScript,
+		encryptedPrivateKey, "")
+}
+
+// SaveDerivedAccountData saves an account together with the derivation path
+// of its key, the path is empty if the key is not derived from the HD seed.
+func (cs *FileStore) SaveDerivedAccountData(programHash *common.Uint168,
+	redeemScript []byte, encryptedPrivateKey []byte,
+	derivationPath string) error {
 	JSONData, err := cs.readDB()
 	if err != nil {
 		return errors.New("error: reading db")
@@ -130,6 +158,7 @@ func (cs *FileStore) SaveAccountData(programHash *common.Uint168, redeemScript [
 		RedeemScript:        common.BytesToHexString(redeemScript),
 		PrivateKeyEncrypted: common.BytesToHexString(encryptedPrivateKey),
 		Type:                accountType,
+		DerivationPath:      derivationPath,
 	}
 
 	for _, v := range cs.data.Account {
@@ -205,7 +234,10 @@ func (cs *FileStore) SaveStoredData(name string, value []byte) error {
 		cs.data.MasterKey = hexValue
 	case "PasswordHash":
 		cs.data.PasswordHash = hexValue
-
+	case "HDSeed":
+		cs.data.HDSeed = hexValue
+	case "Mnemonic":
+		cs.data.Mnemonic = hexValue
 	}
 	JSONBlob, err := json.Marshal(cs.data)
 	if err != nil {
@@ -233,11 +265,107 @@ func (cs *FileStore) LoadStoredData(name string) ([]byte, error) {
 		return common.HexStringToBytes(cs.data.MasterKey)
 	case "PasswordHash":
 		return common.HexStringToBytes(cs.data.PasswordHash)
+	case "HDSeed":
+		return common.HexStringToBytes(cs.data.HDSeed)
+	case "Mnemonic":
+		return common.HexStringToBytes(cs.data.Mnemonic)
 	}
 
 	return nil, errors.New("can't find the key: " + name)
 }
 
+// SaveSchnorrNonce saves the encrypted secret of the public nonce.
+func (cs *FileStore) SaveSchnorrNonce(nonce string, secret []byte) error {
+	JSONData, err := cs.readDB()
+	if err != nil {
+		return errors.New("error: reading db")
+	}
+	cs.data.SchnorrNonces = nil
+	if err := json.Unmarshal(JSONData, &cs.data); err != nil {
+		return errors.New("error: unmarshal db")
+	}
+
+	if cs.data.SchnorrNonces == nil {
+		cs.data.SchnorrNonces = make(map[string]string)
+	}
+	cs.data.SchnorrNonces[nonce] = common.BytesToHexString(secret)
+	JSONBlob, err := json.Marshal(cs.data)
+	if err != nil {
+		return errors.New("error: marshal db")
+	}
+	return cs.writeDB(JSONBlob)
+}
+
+// LoadSchnorrNonce loads the encrypted secret of the public nonce.
+func (cs *FileStore) LoadSchnorrNonce(nonce string) ([]byte, error) {
+	JSONData, err := cs.readDB()
+	if err != nil {
+		return nil, errors.New("error: reading db")
+	}
+	cs.data.SchnorrNonces = nil
+	if err := json.Unmarshal(JSONData, &cs.data); err != nil {
+		return nil, errors.New("error: unmarshal db")
+	}
+
+	secret, ok := cs.data.SchnorrNonces[nonce]
+	if !ok {
+		return nil, errors.New("can't find the secret of nonce " + nonce)
+	}
+	return common.HexStringToBytes(secret)
+}
+
+// DeleteSchnorrNonce deletes the secret of the public nonce.
+func (cs *FileStore) DeleteSchnorrNonce(nonce string) error {
+	JSONData, err := cs.readDB()
+	if err != nil {
+		return errors.New("error: reading db")
+	}
+	cs.data.SchnorrNonces = nil
+	if err := json.Unmarshal(JSONData, &cs.data); err != nil {
+		return errors.New("error: unmarshal db")
+	}
+
+	delete(cs.data.SchnorrNonces, nonce)
+	JSONBlob, err := json.Marshal(cs.data)
+	if err != nil {
+		return errors.New("error: marshal db")
+	}
+	return cs.writeDB(JSONBlob)
+}
+
+func (cs *FileStore) SaveKDFParams(params *KDFParams) error {
+	JSONData, err := cs.readDB()
+	if err != nil {
+		return errors.New("error: reading db")
+	}
+	if err := json.Unmarshal(JSONData, &cs.data); err != nil {
+		return errors.New("error: unmarshal db")
+	}
+
+	cs.data.KDF = params
+	JSONBlob, err := json.Marshal(cs.data)
+	if err != nil {
+		return errors.New("error: marshal db")
+	}
+	cs.writeDB(JSONBlob)
+
+	return nil
+}
+
+func (cs *FileStore) LoadKDFParams() (*KDFParams, error) {
+	JSONData, err := cs.readDB()
+	if err != nil {
+		return nil, errors.New("error: reading db")
+	}
+	if err := json.Unmarshal(JSONData, &cs.data); err != nil {
+		return nil, errors.New("error: unmarshal db")
+	}
+	if cs.data.KDF == nil {
+		return nil, errors.New("can't find the key derivation params")
+	}
+	return cs.data.KDF, nil
+}
+
 func (cs *FileStore) SetPath(path string) {
 	cs.Lock()
 	defer cs.Unlock()
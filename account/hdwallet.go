@@ -0,0 +1,241 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package account
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+const (
+	// HDAccountPathFormat is the BIP44 path format of the external chain
+	// addresses of the HD wallet.
+	HDAccountPathFormat = crypto.DefaultAccountPath + "/0/%d"
+
+	// DefaultGapLimit is the count of consecutive unused addresses to stop
+	// discovering accounts while restoring a HD wallet.
+	DefaultGapLimit = 20
+
+	// encryptedEntropyLength is the length of the mnemonic entropy padded
+	// to be encrypted, the first byte is the length of entropy.
+	encryptedEntropyLength = 48
+)
+
+// CreateHD creates a HD wallet from the mnemonic, a new mnemonic is generated
+// if it is empty.  The main account is the first address of the wallet.
+func CreateHD(path string, password []byte, mnemonic,
+	passphrase string) (*Client, error) {
+	if mnemonic == "" {
+		entropy, err := crypto.NewEntropy(crypto.DefaultEntropyBits)
+		if err != nil {
+			return nil, err
+		}
+		if mnemonic, err = crypto.NewMnemonic(entropy); err != nil {
+			return nil, err
+		}
+	}
+	entropy, err := crypto.MnemonicToEntropy(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := crypto.NewSeed(mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return createClient(path, password, func(cl *Client) (*Account, error) {
+		if err := cl.saveHDSeed(entropy, seed); err != nil {
+			return nil, err
+		}
+		return cl.DeriveAccount()
+	})
+}
+
+// Restore restores a HD wallet from the mnemonic.  Addresses are derived one
+// by one and checked by isUsed, the discovery stops after gapLimit consecutive
+// unused addresses, all addresses before the last used one are added.
+func Restore(path string, password []byte, mnemonic, passphrase string,
+	gapLimit int, isUsed func(*Account) (bool, error)) (*Client, error) {
+	if gapLimit <= 0 {
+		return nil, errors.New("gap limit must be positive")
+	}
+	client, err := CreateHD(path, password, mnemonic, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.discoverAccounts(gapLimit, isUsed); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+	return client, nil
+}
+
+// discoverAccounts derives and saves the used accounts of the HD wallet.
+func (cl *Client) discoverAccounts(gapLimit int,
+	isUsed func(*Account) (bool, error)) error {
+	type derivedAccount struct {
+		account *Account
+		index   uint32
+	}
+	var derived []derivedAccount
+	lastUsed := -1
+	for index := cl.nextIndex; len(derived)-lastUsed <= gapLimit; index++ {
+		account, err := cl.deriveAccount(index)
+		if err == crypto.ErrInvalidChildKey {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		used, err := isUsed(account)
+		if err != nil {
+			return err
+		}
+		derived = append(derived, derivedAccount{account, index})
+		if used {
+			lastUsed = len(derived) - 1
+		}
+	}
+
+	for _, d := range derived[:lastUsed+1] {
+		if err := cl.saveDerivedAccount(d.account, d.index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsHD returns whether the wallet is a HD wallet.
+func (cl *Client) IsHD() bool {
+	return len(cl.seed) != 0
+}
+
+// GetMnemonic returns the mnemonic of the HD wallet.
+func (cl *Client) GetMnemonic() (string, error) {
+	if !cl.IsHD() {
+		return "", errors.New("not a HD wallet")
+	}
+	encrypted, err := cl.LoadStoredData("Mnemonic")
+	if err != nil {
+		return "", err
+	}
+	padded, err := cl.decryptData(encrypted)
+	if err != nil {
+		return "", err
+	}
+	defer common.ClearBytes(padded)
+	if len(padded) != encryptedEntropyLength ||
+		int(padded[0]) >= encryptedEntropyLength {
+		return "", errors.New("invalid mnemonic data")
+	}
+	return crypto.NewMnemonic(padded[1 : 1+padded[0]])
+}
+
+// DeriveAccount derives the next account of the HD wallet then save it.
+func (cl *Client) DeriveAccount() (*Account, error) {
+	if !cl.IsHD() {
+		return nil, errors.New("not a HD wallet")
+	}
+	for {
+		account, err := cl.deriveAccount(cl.nextIndex)
+		if err == crypto.ErrInvalidChildKey {
+			cl.nextIndex++
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := cl.saveDerivedAccount(account, cl.nextIndex); err != nil {
+			return nil, err
+		}
+		return account, nil
+	}
+}
+
+// deriveAccount derives the account of the given address index.
+func (cl *Client) deriveAccount(index uint32) (*Account, error) {
+	master, err := crypto.NewMasterKey(cl.seed)
+	if err != nil {
+		return nil, err
+	}
+	key, err := master.Derive(fmt.Sprintf(HDAccountPathFormat, index))
+	if err != nil {
+		return nil, err
+	}
+	return NewAccountWithPrivateKey(key.PrivateKey)
+}
+
+func (cl *Client) saveDerivedAccount(ac *Account, index uint32) error {
+	err := cl.saveAccount(ac, fmt.Sprintf(HDAccountPathFormat, index))
+	if err != nil {
+		return err
+	}
+	if index >= cl.nextIndex {
+		cl.nextIndex = index + 1
+	}
+	return nil
+}
+
+func (cl *Client) saveHDSeed(entropy, seed []byte) error {
+	padded := make([]byte, encryptedEntropyLength)
+	padded[0] = byte(len(entropy))
+	copy(padded[1:], entropy)
+	defer common.ClearBytes(padded)
+	encryptedEntropy, err := cl.encryptData(padded)
+	if err != nil {
+		return err
+	}
+	encryptedSeed, err := cl.encryptData(seed)
+	if err != nil {
+		return err
+	}
+	if err := cl.SaveStoredData("Mnemonic", encryptedEntropy); err != nil {
+		return err
+	}
+	if err := cl.SaveStoredData("HDSeed", encryptedSeed); err != nil {
+		return err
+	}
+	cl.seed = seed
+	return nil
+}
+
+// loadHDSeed loads the seed of HD wallet and the index of the next address
+// to derive, the seed is empty for a wallet of independent keys.
+func (cl *Client) loadHDSeed() error {
+	encryptedSeed, err := cl.LoadStoredData("HDSeed")
+	if err != nil {
+		return err
+	}
+	if len(encryptedSeed) == 0 {
+		return nil
+	}
+	cl.seed, err = cl.decryptData(encryptedSeed)
+	if err != nil {
+		return err
+	}
+
+	storeAccounts, err := cl.LoadAccountData()
+	if err != nil {
+		return err
+	}
+	for _, a := range storeAccounts {
+		if a.DerivationPath == "" {
+			continue
+		}
+		indexes, err := crypto.ParseDerivationPath(a.DerivationPath)
+		if err != nil || len(indexes) == 0 {
+			return errors.New("invalid derivation path " + a.DerivationPath)
+		}
+		if index := indexes[len(indexes)-1]; index >= cl.nextIndex {
+			cl.nextIndex = index + 1
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package account
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testMnemonic = "legal winner thank year wave sausage worth useful " +
+	"legal winner thank yellow"
+
+func TestCreateHD(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hdwallet")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	password := []byte("password")
+
+	// same mnemonic derives same accounts
+	path1 := filepath.Join(dir, "wallet1.dat")
+	client1, err := CreateHD(path1, password, testMnemonic, "")
+	assert.NoError(t, err)
+	assert.True(t, client1.IsHD())
+	path2 := filepath.Join(dir, "wallet2.dat")
+	client2, err := CreateHD(path2, password, testMnemonic, "")
+	assert.NoError(t, err)
+	assert.Equal(t, client1.GetMainAccount().Address,
+		client2.GetMainAccount().Address)
+
+	// passphrase derives different accounts
+	path3 := filepath.Join(dir, "wallet3.dat")
+	client3, err := CreateHD(path3, password, testMnemonic, "passphrase")
+	assert.NoError(t, err)
+	assert.NotEqual(t, client1.GetMainAccount().Address,
+		client3.GetMainAccount().Address)
+
+	mnemonic, err := client1.GetMnemonic()
+	assert.NoError(t, err)
+	assert.Equal(t, testMnemonic, mnemonic)
+
+	// added accounts are derived from the seed in order
+	_, err = Add(path1, password)
+	assert.NoError(t, err)
+	_, err = Add(path1, password)
+	assert.NoError(t, err)
+	reopened, err := Open(path1, password)
+	assert.NoError(t, err)
+	assert.True(t, reopened.IsHD())
+	assert.Equal(t, 3, len(reopened.GetAccounts()))
+	assert.Equal(t, uint32(3), reopened.nextIndex)
+	account2, err := reopened.deriveAccount(2)
+	assert.NoError(t, err)
+	assert.NotNil(t, reopened.GetAccountByCodeHash(
+		account2.ProgramHash.ToCodeHash()))
+
+	// random mnemonic
+	client4, err := CreateHD(filepath.Join(dir, "wallet4.dat"), password,
+		"", "")
+	assert.NoError(t, err)
+	_, err = client4.GetMnemonic()
+	assert.NoError(t, err)
+
+	_, err = CreateHD(filepath.Join(dir, "wallet5.dat"), password,
+		"zoo zoo zoo", "")
+	assert.Error(t, err)
+}
+
+func TestRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hdwallet")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	password := []byte("password")
+
+	created, err := CreateHD(filepath.Join(dir, "created.dat"), password,
+		testMnemonic, "")
+	assert.NoError(t, err)
+	used := make(map[string]bool)
+	for i := 0; i < 5; i++ {
+		account, err := created.deriveAccount(uint32(i))
+		assert.NoError(t, err)
+		// address 1, 3 and 4 have been used
+		if i == 1 || i == 3 || i == 4 {
+			used[account.Address] = true
+		}
+	}
+	isUsed := func(account *Account) (bool, error) {
+		return used[account.Address], nil
+	}
+
+	// gap between address 1 and 3 is less than the gap limit
+	restored, err := Restore(filepath.Join(dir, "restored1.dat"), password,
+		testMnemonic, "", 2, isUsed)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, len(restored.GetAccounts()))
+	assert.Equal(t, uint32(5), restored.nextIndex)
+	assert.Equal(t, created.GetMainAccount().Address,
+		restored.GetMainAccount().Address)
+
+	// address 3 is beyond the gap limit
+	restored, err = Restore(filepath.Join(dir, "restored2.dat"), password,
+		testMnemonic, "", 1, isUsed)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(restored.GetAccounts()))
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package account
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// createKeystore initializes a keystore of the latest version, the master key
+// is encrypted by AES-GCM with the key derived from password by scrypt.
+func (cl *Client) createKeystore(password []byte) error {
+	kdf, encryptedMasterKey, masterKey, err := newMasterKey(password)
+	if err != nil {
+		return err
+	}
+	if err := cl.SaveStoredData("Version", []byte(KeystoreVersion)); err != nil {
+		return err
+	}
+	if err := cl.SaveKDFParams(kdf); err != nil {
+		return err
+	}
+	if err := cl.SaveStoredData("MasterKey", encryptedMasterKey); err != nil {
+		return err
+	}
+	cl.version = KeystoreVersion
+	cl.masterKey = masterKey
+	return nil
+}
+
+// openKeystore decrypts the master key of keystore by the password, keystores
+// of old version are supported.
+func (cl *Client) openKeystore(password []byte) bool {
+	version, err := cl.LoadStoredData("Version")
+	if err != nil {
+		fmt.Println("error: failed to load version")
+		return false
+	}
+	switch string(version) {
+	case KeystoreVersion:
+		return cl.openKeystoreV2(password)
+	case KeystoreVersionV1, "":
+		return cl.openKeystoreV1(password)
+	default:
+		fmt.Println("error: unknown keystore version", string(version))
+		return false
+	}
+}
+
+func (cl *Client) openKeystoreV1(password []byte) bool {
+	passwordKey := crypto.ToAesKey(password)
+	defer common.ClearBytes(passwordKey)
+
+	if ok := cl.verifyPasswordKey(passwordKey); !ok {
+		return false
+	}
+	var err error
+	cl.iv, err = cl.LoadStoredData("IV")
+	if err != nil {
+		fmt.Println("error: failed to load iv")
+		return false
+	}
+	encryptedMasterKey, err := cl.LoadStoredData("MasterKey")
+	if err != nil {
+		fmt.Println("error: failed to load master key")
+		return false
+	}
+	cl.masterKey, err = crypto.AesDecrypt(encryptedMasterKey, passwordKey, cl.iv)
+	if err != nil {
+		fmt.Println("error: failed to decrypt master key")
+		return false
+	}
+	cl.version = KeystoreVersionV1
+	return true
+}
+
+func (cl *Client) openKeystoreV2(password []byte) bool {
+	kdf, err := cl.LoadKDFParams()
+	if err != nil {
+		fmt.Println("error: failed to load key derivation params")
+		return false
+	}
+	encryptedMasterKey, err := cl.LoadStoredData("MasterKey")
+	if err != nil {
+		fmt.Println("error: failed to load master key")
+		return false
+	}
+	passwordKey, err := derivePasswordKey(password, kdf)
+	if err != nil {
+		fmt.Println("error: failed to derive password key,", err)
+		return false
+	}
+	defer common.ClearBytes(passwordKey)
+
+	// The master key is authenticated, so a wrong password fails here.
+	cl.masterKey, err = crypto.AesGCMDecrypt(encryptedMasterKey, passwordKey)
+	if err != nil {
+		fmt.Println("error: password wrong")
+		return false
+	}
+	cl.version = KeystoreVersion
+	return true
+}
+
+// encryptData encrypts the data by master key in the way of keystore version.
+func (cl *Client) encryptData(data []byte) ([]byte, error) {
+	if cl.version == KeystoreVersionV1 {
+		return crypto.AesEncrypt(data, cl.masterKey, cl.iv)
+	}
+	return crypto.AesGCMEncrypt(data, cl.masterKey)
+}
+
+// decryptData decrypts the data by master key in the way of keystore version.
+func (cl *Client) decryptData(data []byte) ([]byte, error) {
+	if cl.version == KeystoreVersionV1 {
+		return crypto.AesDecrypt(data, cl.masterKey, cl.iv)
+	}
+	return crypto.AesGCMDecrypt(data, cl.masterKey)
+}
+
+// newMasterKey generates a random master key and encrypts it by the key
+// derived from password.
+func newMasterKey(password []byte) (*KDFParams, []byte, []byte, error) {
+	salt, err := crypto.NewSalt()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	params := crypto.DefaultScryptParams
+	kdf := &KDFParams{
+		Name:   crypto.KDFScrypt,
+		Salt:   common.BytesToHexString(salt),
+		N:      params.N,
+		R:      params.R,
+		P:      params.P,
+		KeyLen: params.KeyLen,
+	}
+	passwordKey, err := derivePasswordKey(password, kdf)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer common.ClearBytes(passwordKey)
+
+	masterKey := make([]byte, 32)
+	if _, err := rand.Read(masterKey); err != nil {
+		return nil, nil, nil, err
+	}
+	encryptedMasterKey, err := crypto.AesGCMEncrypt(masterKey, passwordKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return kdf, encryptedMasterKey, masterKey, nil
+}
+
+func derivePasswordKey(password []byte, kdf *KDFParams) ([]byte, error) {
+	if kdf.Name != crypto.KDFScrypt {
+		return nil, errors.New("unsupported key derivation function " +
+			kdf.Name)
+	}
+	salt, err := common.HexStringToBytes(kdf.Salt)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ScryptKey(password, salt, crypto.ScryptParams{
+		N:      kdf.N,
+		R:      kdf.R,
+		P:      kdf.P,
+		KeyLen: kdf.KeyLen,
+	})
+}
+
+// Upgrade re-encrypts the keystore of old version in the latest version in
+// place, the accounts and password are unchanged.
+func Upgrade(path string, password []byte) error {
+	old := NewClient(path, password, false)
+	if old == nil {
+		return errors.New("open wallet failed")
+	}
+	if old.version == KeystoreVersion {
+		return errors.New("keystore is already version " + KeystoreVersion)
+	}
+	storeAccounts, err := old.LoadAccountData()
+	if err != nil {
+		return err
+	}
+
+	kdf, encryptedMasterKey, masterKey, err := newMasterKey(password)
+	if err != nil {
+		return err
+	}
+	upgraded := &Client{version: KeystoreVersion, masterKey: masterKey}
+	reencrypt := func(hexData string) (string, error) {
+		if hexData == "" {
+			return "", nil
+		}
+		data, err := common.HexStringToBytes(hexData)
+		if err != nil {
+			return "", err
+		}
+		plain, err := old.decryptData(data)
+		if err != nil {
+			return "", err
+		}
+		defer common.ClearBytes(plain)
+		encrypted, err := upgraded.encryptData(plain)
+		if err != nil {
+			return "", err
+		}
+		return common.BytesToHexString(encrypted), nil
+	}
+
+	data := FileData{
+		Version:   KeystoreVersion,
+		MasterKey: common.BytesToHexString(encryptedMasterKey),
+		KDF:       kdf,
+		Account:   make([]AccountData, 0, len(storeAccounts)),
+	}
+	if data.HDSeed, err = reencrypt(old.data.HDSeed); err != nil {
+		return err
+	}
+	if data.Mnemonic, err = reencrypt(old.data.Mnemonic); err != nil {
+		return err
+	}
+	for nonce, secret := range old.data.SchnorrNonces {
+		if data.SchnorrNonces == nil {
+			data.SchnorrNonces = make(map[string]string)
+		}
+		if data.SchnorrNonces[nonce], err = reencrypt(secret); err != nil {
+			return err
+		}
+	}
+	for _, a := range storeAccounts {
+		if a.PrivateKeyEncrypted, err = reencrypt(a.PrivateKeyEncrypted); err != nil {
+			return err
+		}
+		data.Account = append(data.Account, a)
+	}
+
+	JSONBlob, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, so the keystore will not be broken
+	// if interrupted while writing.
+	tmpPath := path + ".new"
+	if err := ioutil.WriteFile(tmpPath, JSONBlob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
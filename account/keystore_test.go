@@ -0,0 +1,150 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package account
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeKeystoreV1 writes a keystore of version 1.0.0 with the accounts.
+func writeKeystoreV1(t *testing.T, path string, password []byte,
+	accounts ...*Account) {
+	iv := make([]byte, 16)
+	masterKey := make([]byte, 32)
+	for i := range masterKey {
+		masterKey[i] = byte(i)
+	}
+	passwordKey := crypto.ToAesKey(password)
+	passwordHash := sha256.Sum256(passwordKey)
+	encryptedMasterKey, err := crypto.AesEncrypt(masterKey, passwordKey, iv)
+	assert.NoError(t, err)
+
+	data := FileData{
+		Version:      KeystoreVersionV1,
+		PasswordHash: common.BytesToHexString(passwordHash[:]),
+		IV:           common.BytesToHexString(iv),
+		MasterKey:    common.BytesToHexString(encryptedMasterKey),
+	}
+	for i, ac := range accounts {
+		keyPair := make([]byte, 96)
+		publicKey, err := ac.PublicKey.EncodePoint(false)
+		assert.NoError(t, err)
+		copy(keyPair, publicKey[1:])
+		copy(keyPair[96-len(ac.PrivateKey):], ac.PrivateKey)
+		encrypted, err := crypto.AesEncrypt(keyPair, masterKey, iv)
+		assert.NoError(t, err)
+		accountType := SUBACCOUNT
+		if i == 0 {
+			accountType = MAINACCOUNT
+		}
+		data.Account = append(data.Account, AccountData{
+			Address:             ac.Address,
+			ProgramHash:         common.BytesToHexString(ac.ProgramHash.Bytes()),
+			RedeemScript:        common.BytesToHexString(ac.RedeemScript),
+			PrivateKeyEncrypted: common.BytesToHexString(encrypted),
+			Type:                accountType,
+		})
+	}
+	JSONBlob, err := json.Marshal(data)
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, JSONBlob, 0600))
+}
+
+func TestKeystore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, KeystoreFileName)
+	password := []byte("password")
+
+	client, err := Create(path, password)
+	assert.NoError(t, err)
+	_, err = client.CreateAccount()
+	assert.NoError(t, err)
+
+	var data FileData
+	JSONData, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(JSONData, &data))
+	assert.Equal(t, KeystoreVersion, data.Version)
+	assert.Equal(t, "", data.PasswordHash)
+	assert.Equal(t, crypto.KDFScrypt, data.KDF.Name)
+	assert.Equal(t, crypto.DefaultScryptParams.N, data.KDF.N)
+
+	opened, err := Open(path, password)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(opened.GetAccounts()))
+	assert.Equal(t, client.GetMainAccount(), opened.GetMainAccount())
+
+	_, err = Open(path, []byte("wrong"))
+	assert.Error(t, err)
+	assert.Error(t, Upgrade(path, password))
+
+	// secrets of Schnorr nonces can be taken only once
+	nonce, err := opened.NewSchnorrNonce()
+	assert.NoError(t, err)
+	secret, err := opened.TakeSchnorrNonce(nonce)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, len(secret))
+	_, err = opened.TakeSchnorrNonce(nonce)
+	assert.Error(t, err)
+}
+
+func TestKeystore_Upgrade(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, KeystoreFileName)
+	password := []byte("password")
+
+	account1, err := NewAccount()
+	assert.NoError(t, err)
+	account2, err := NewAccount()
+	assert.NoError(t, err)
+	writeKeystoreV1(t, path, password, account1, account2)
+
+	// old version is still readable
+	client, err := Open(path, password)
+	assert.NoError(t, err)
+	assert.Equal(t, KeystoreVersionV1, client.version)
+	assert.Equal(t, account1.PrivateKey, client.GetMainAccount().PrivateKey)
+	_, err = Open(path, []byte("wrong"))
+	assert.Error(t, err)
+
+	// accounts added to old version are encrypted in old way
+	account3, err := client.CreateAccount()
+	assert.NoError(t, err)
+	nonce, err := client.NewSchnorrNonce()
+	assert.NoError(t, err)
+
+	assert.Error(t, Upgrade(path, []byte("wrong")))
+	assert.NoError(t, Upgrade(path, password))
+
+	client, err = Open(path, password)
+	assert.NoError(t, err)
+	assert.Equal(t, KeystoreVersion, client.version)
+	assert.Equal(t, 3, len(client.GetAccounts()))
+	assert.Equal(t, account1.PrivateKey, client.GetMainAccount().PrivateKey)
+	for _, ac := range []*Account{account1, account2, account3} {
+		assert.Equal(t, ac.PrivateKey, client.GetAccountByCodeHash(
+			ac.ProgramHash.ToCodeHash()).PrivateKey)
+	}
+	secret, err := client.TakeSchnorrNonce(nonce)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, len(secret))
+	_, err = Open(path, []byte("wrong"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package account
+
+import (
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// NewSchnorrNonce generates a Schnorr nonce and saves its secret encrypted
+// in the keystore, returns the public nonce to share with other signers.
+func (cl *Client) NewSchnorrNonce() ([]byte, error) {
+	secret, nonce, err := crypto.SchnorrNonce()
+	if err != nil {
+		return nil, err
+	}
+	defer common.ClearBytes(secret)
+
+	encrypted, err := cl.encryptData(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := cl.SaveSchnorrNonce(common.BytesToHexString(nonce),
+		encrypted); err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// TakeSchnorrNonce returns the secret of the public nonce and deletes it
+// from the keystore, so the secret will never be used twice.
+func (cl *Client) TakeSchnorrNonce(nonce []byte) ([]byte, error) {
+	encrypted, err := cl.LoadSchnorrNonce(common.BytesToHexString(nonce))
+	if err != nil {
+		return nil, err
+	}
+	if err := cl.DeleteSchnorrNonce(common.BytesToHexString(nonce)); err != nil {
+		return nil, err
+	}
+	return cl.decryptData(encrypted)
+}
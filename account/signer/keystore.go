@@ -0,0 +1,50 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package signer
+
+import (
+	"github.com/elastos/Elastos.ELA/account"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// keystore is the Signer holding the private key of a keystore account in
+// the process memory.
+type keystore struct {
+	account   *account.Account
+	publicKey []byte
+}
+
+func (s *keystore) PublicKey() []byte {
+	return s.publicKey
+}
+
+func (s *keystore) Sign(request *Request) ([]byte, error) {
+	return crypto.Sign(s.account.PrivKey(), request.Data)
+}
+
+func (s *keystore) Decrypt(cipher []byte) ([]byte, error) {
+	return crypto.Decrypt(s.account.PrivKey(), cipher)
+}
+
+// NewKeystore creates a Signer with the private key of the account, which
+// signs anything asked unless guarded by a policy.
+func NewKeystore(acc *account.Account) (Signer, error) {
+	publicKey, err := acc.PublicKey.EncodePoint(true)
+	if err != nil {
+		return nil, err
+	}
+	return &keystore{account: acc, publicKey: publicKey}, nil
+}
+
+// OpenKeystore opens the keystore file and creates a Signer with the private
+// key of its main account.
+func OpenKeystore(path string, password []byte) (Signer, error) {
+	client, err := account.Open(path, password)
+	if err != nil {
+		return nil, err
+	}
+	return NewKeystore(client.GetMainAccount())
+}
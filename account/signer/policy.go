@@ -0,0 +1,365 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package signer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// policyHeightWindow is the number of heights below the highest signed one
+// of which the signed proposals and votes are remembered.
+const policyHeightWindow = 720
+
+// round locates a DPoS consensus round of a message type.
+type round struct {
+	Type       MessageType
+	Height     uint32
+	ViewOffset uint32
+}
+
+// Policy decides which requests a Signer may sign.  It only signs the allowed
+// message types, and refuses to sign two different proposals or two
+// different votes in the same height and view offset, which would be
+// treated as an illegal double signing by other arbiters.
+//
+// The type of a request is not trusted, the data of proposals and votes must
+// deserialize as the declared type, and the other data must not deserialize
+// as a proposal or vote, so a double signing can not be disguised as a
+// message or transaction.
+//
+// The hash of the signed proposal or vote in each round is recorded, which
+// is the same as the hash of the proposal or vote payload.  A policy opened
+// from a database persists the records before signing.
+type Policy struct {
+	// Log prints the refused requests if it is not nil.
+	Log func(format string, a ...interface{})
+
+	mtx       sync.Mutex
+	allowed   map[MessageType]struct{}
+	signed    map[round]common.Uint256
+	maxHeight uint32
+	refusal   bool
+	db        *protectionDB
+}
+
+// SetRefusal switches the refusal mode, in which all requests are logged and
+// refused.  It is used to run a producer on a new machine before the old one
+// is stopped.
+func (p *Policy) SetRefusal(refusal bool) {
+	p.mtx.Lock()
+	p.refusal = refusal
+	p.mtx.Unlock()
+}
+
+// Check returns an error if the request is refused, otherwise records the
+// request as signed.
+func (p *Policy) Check(request *Request) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if err := p.check(request); err != nil {
+		p.logf("refused to sign %s height %d view offset %d: %s",
+			request.Type, request.Height, request.ViewOffset, err)
+		return err
+	}
+	return nil
+}
+
+func (p *Policy) check(request *Request) error {
+	if p.refusal {
+		return errors.New("signer is in refusal mode")
+	}
+	if len(p.allowed) > 0 {
+		if _, ok := p.allowed[request.Type]; !ok {
+			return fmt.Errorf("signing %s is not allowed", request.Type)
+		}
+	}
+
+	dataType, viewOffset := parseDataType(request.Data)
+	switch request.Type {
+	case Proposal, Vote:
+		if dataType != request.Type {
+			return fmt.Errorf("data is not a %s", request.Type)
+		}
+		if dataType == Proposal && viewOffset != request.ViewOffset {
+			return fmt.Errorf("view offset of the proposal is %d",
+				viewOffset)
+		}
+	default:
+		if dataType != 0 {
+			return fmt.Errorf("%s data is a %s", request.Type, dataType)
+		}
+		return nil
+	}
+
+	if request.Height+policyHeightWindow < p.maxHeight {
+		return fmt.Errorf("height is too far below the signed height %d",
+			p.maxHeight)
+	}
+	key := round{request.Type, request.Height, request.ViewOffset}
+	hash := common.Hash(request.Data)
+	if signed, ok := p.signed[key]; ok {
+		if !signed.IsEqual(hash) {
+			return fmt.Errorf("another %s %s has been signed",
+				request.Type, signed)
+		}
+		return nil
+	}
+	return p.record(map[round]common.Uint256{key: hash})
+}
+
+// parseDataType returns Proposal and the view offset if the data is exactly
+// the unsigned data of a proposal, Vote if it is exactly the unsigned data of
+// a vote, otherwise zero.
+func parseDataType(data []byte) (MessageType, uint32) {
+	r := bytes.NewReader(data)
+	var proposal payload.DPOSProposal
+	if err := proposal.DeserializeUnSigned(r); err == nil && r.Len() == 0 &&
+		len(proposal.Sponsor) == crypto.COMPRESSEDLEN {
+		return Proposal, proposal.ViewOffset
+	}
+
+	r.Reset(data)
+	var vote payload.DPOSProposalVote
+	if err := vote.DeserializeUnsigned(r); err == nil && r.Len() == 0 &&
+		len(vote.Signer) == crypto.COMPRESSEDLEN {
+		return Vote, 0
+	}
+	return 0, 0
+}
+
+// record persists and remembers the records, the records out of the height
+// window are forgotten.
+func (p *Policy) record(records map[round]common.Uint256) error {
+	if p.db != nil {
+		if err := p.db.put(records); err != nil {
+			return err
+		}
+	}
+	maxHeight := p.maxHeight
+	for r, hash := range records {
+		p.signed[r] = hash
+		if r.Height > maxHeight {
+			maxHeight = r.Height
+		}
+	}
+	if maxHeight == p.maxHeight {
+		return nil
+	}
+
+	p.maxHeight = maxHeight
+	var expired []round
+	for r := range p.signed {
+		if r.Height+policyHeightWindow < p.maxHeight {
+			delete(p.signed, r)
+			expired = append(expired, r)
+		}
+	}
+	if p.db != nil && len(expired) > 0 {
+		return p.db.delete(expired)
+	}
+	return nil
+}
+
+// bind makes the records belong to the public key, it returns an error if
+// the database of the policy belongs to another one.
+func (p *Policy) bind(publicKey []byte) error {
+	if p.db == nil {
+		return nil
+	}
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	return p.db.bind(publicKey)
+}
+
+func (p *Policy) logf(format string, a ...interface{}) {
+	if p.Log != nil {
+		p.Log(format, a...)
+	}
+}
+
+// Close closes the database of the policy.
+func (p *Policy) Close() error {
+	if p.db == nil {
+		return nil
+	}
+	return p.db.close()
+}
+
+// InterchangeRecord is a proposal or vote signed in a round.
+type InterchangeRecord struct {
+	Type       string `json:"type"`
+	Height     uint32 `json:"height"`
+	ViewOffset uint32 `json:"viewoffset"`
+	Hash       string `json:"hash"`
+}
+
+// Interchange is the format of the exported records to migrate a producer
+// between machines.
+type Interchange struct {
+	PublicKey string              `json:"publickey"`
+	Records   []InterchangeRecord `json:"records"`
+}
+
+// Export writes the records in the interchange format.
+func (p *Policy) Export(w io.Writer) error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	var interchange Interchange
+	if p.db != nil {
+		publicKey, err := p.db.publicKey()
+		if err != nil {
+			return err
+		}
+		interchange.PublicKey = common.BytesToHexString(publicKey)
+	}
+	interchange.Records = make([]InterchangeRecord, 0, len(p.signed))
+	for r, hash := range p.signed {
+		interchange.Records = append(interchange.Records, InterchangeRecord{
+			Type:       r.Type.String(),
+			Height:     r.Height,
+			ViewOffset: r.ViewOffset,
+			Hash:       hash.String(),
+		})
+	}
+	sort.Slice(interchange.Records, func(i, j int) bool {
+		a, b := interchange.Records[i], interchange.Records[j]
+		if a.Height != b.Height {
+			return a.Height < b.Height
+		}
+		if a.ViewOffset != b.ViewOffset {
+			return a.ViewOffset < b.ViewOffset
+		}
+		return a.Type < b.Type
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&interchange)
+}
+
+// Import reads the records in the interchange format and merges them, it
+// returns the number of the new records.  Nothing is imported if a record
+// conflicts with a signed one, which means the key has signed two different
+// messages in the same round on different machines.
+func (p *Policy) Import(r io.Reader) (int, error) {
+	var interchange Interchange
+	if err := json.NewDecoder(r).Decode(&interchange); err != nil {
+		return 0, err
+	}
+	publicKey, err := common.HexStringToBytes(interchange.PublicKey)
+	if err != nil {
+		return 0, err
+	}
+
+	records := make(map[round]common.Uint256)
+	for _, record := range interchange.Records {
+		types, err := ParseMessageTypes(record.Type)
+		if err != nil {
+			return 0, err
+		}
+		if len(types) != 1 || (types[0] != Proposal && types[0] != Vote) {
+			return 0, fmt.Errorf("invalid record type %s", record.Type)
+		}
+		hash, err := common.Uint256FromHexString(record.Hash)
+		if err != nil {
+			return 0, err
+		}
+		records[round{types[0], record.Height, record.ViewOffset}] = *hash
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	for r, hash := range records {
+		if signed, ok := p.signed[r]; ok {
+			if !signed.IsEqual(hash) {
+				return 0, fmt.Errorf("%s %s at height %d view offset %d"+
+					" conflicts with the signed %s", r.Type, hash, r.Height,
+					r.ViewOffset, signed)
+			}
+			delete(records, r)
+		}
+	}
+	if p.db != nil && len(publicKey) > 0 {
+		if err := p.db.bind(publicKey); err != nil {
+			return 0, err
+		}
+	}
+	return len(records), p.record(records)
+}
+
+// NewPolicy creates a policy allowing to sign the given message types, all
+// message types are allowed if no type is given.  The records are only kept
+// in memory.
+func NewPolicy(types ...MessageType) *Policy {
+	allowed := make(map[MessageType]struct{}, len(types))
+	for _, t := range types {
+		allowed[t] = struct{}{}
+	}
+	return &Policy{
+		allowed: allowed,
+		signed:  make(map[round]common.Uint256),
+	}
+}
+
+// OpenPolicy creates a policy like NewPolicy, of which the records are
+// persisted in the database at the path.
+func OpenPolicy(path string, types ...MessageType) (*Policy, error) {
+	db, err := openProtectionDB(path)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := db.load()
+	if err != nil {
+		db.close()
+		return nil, err
+	}
+
+	p := NewPolicy(types...)
+	p.db = db
+	p.signed = signed
+	for r := range signed {
+		if r.Height > p.maxHeight {
+			p.maxHeight = r.Height
+		}
+	}
+	return p, nil
+}
+
+// guarded is the Signer checking the requests by a policy before signing
+// with another Signer.
+type guarded struct {
+	Signer
+	policy *Policy
+}
+
+func (s *guarded) Sign(request *Request) ([]byte, error) {
+	if err := s.policy.Check(request); err != nil {
+		return nil, err
+	}
+	return s.Signer.Sign(request)
+}
+
+// Guard returns a Signer checking the requests by the policy before signing
+// with the signer, the records of the policy are bound to the public key of
+// the signer.
+func Guard(signer Signer, policy *Policy) (Signer, error) {
+	if err := policy.bind(signer.PublicKey()); err != nil {
+		return nil, err
+	}
+	return &guarded{Signer: signer, policy: policy}, nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package signer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+var (
+	// publicKeyKey is the key of the public key the records belong to.
+	publicKeyKey = []byte("publickey")
+
+	// recordPrefix is the prefix of the keys of the signed records.
+	recordPrefix = []byte("r")
+
+	// syncWrite makes sure a record is on the disk before signing.
+	syncWrite = &opt.WriteOptions{Sync: true}
+)
+
+// roundKey returns the key of the round, the height is big endian encoded so
+// that the records are ordered by height.
+func roundKey(r round) []byte {
+	key := make([]byte, len(recordPrefix)+9)
+	n := copy(key, recordPrefix)
+	key[n] = byte(r.Type)
+	binary.BigEndian.PutUint32(key[n+1:], r.Height)
+	binary.BigEndian.PutUint32(key[n+5:], r.ViewOffset)
+	return key
+}
+
+func parseRoundKey(key []byte) (round, error) {
+	if len(key) != len(recordPrefix)+9 {
+		return round{}, fmt.Errorf("invalid record key %x", key)
+	}
+	key = key[len(recordPrefix):]
+	return round{
+		Type:       MessageType(key[0]),
+		Height:     binary.BigEndian.Uint32(key[1:]),
+		ViewOffset: binary.BigEndian.Uint32(key[5:]),
+	}, nil
+}
+
+// protectionDB persists the hashes of the proposals and votes signed by a
+// key, so they are still refused to be signed again with different data
+// after a restart.
+type protectionDB struct {
+	db *leveldb.DB
+}
+
+// publicKey returns the public key the records belong to, it is nil if no
+// record has been written.
+func (d *protectionDB) publicKey() ([]byte, error) {
+	publicKey, err := d.db.Get(publicKeyKey, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return publicKey, err
+}
+
+// bind makes the records belong to the public key, it returns an error if
+// they belong to another one.
+func (d *protectionDB) bind(publicKey []byte) error {
+	bound, err := d.publicKey()
+	if err != nil {
+		return err
+	}
+	if bound == nil {
+		return d.db.Put(publicKeyKey, publicKey, syncWrite)
+	}
+	if !bytes.Equal(bound, publicKey) {
+		return fmt.Errorf("protection records belong to public key %s",
+			common.BytesToHexString(bound))
+	}
+	return nil
+}
+
+func (d *protectionDB) load() (map[round]common.Uint256, error) {
+	records := make(map[round]common.Uint256)
+	iter := d.db.NewIterator(util.BytesPrefix(recordPrefix), nil)
+	defer iter.Release()
+	for iter.Next() {
+		r, err := parseRoundKey(iter.Key())
+		if err != nil {
+			return nil, err
+		}
+		hash, err := common.Uint256FromBytes(iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		records[r] = *hash
+	}
+	return records, iter.Error()
+}
+
+func (d *protectionDB) put(records map[round]common.Uint256) error {
+	batch := new(leveldb.Batch)
+	for r, hash := range records {
+		batch.Put(roundKey(r), hash.Bytes())
+	}
+	return d.db.Write(batch, syncWrite)
+}
+
+func (d *protectionDB) delete(rounds []round) error {
+	batch := new(leveldb.Batch)
+	for _, r := range rounds {
+		batch.Delete(roundKey(r))
+	}
+	return d.db.Write(batch, nil)
+}
+
+func (d *protectionDB) close() error {
+	return d.db.Close()
+}
+
+func openProtectionDB(path string) (*protectionDB, error) {
+	if path == "" {
+		return nil, errors.New("protection database path is empty")
+	}
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &protectionDB{db: db}, nil
+}
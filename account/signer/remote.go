@@ -0,0 +1,133 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package signer
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+const (
+	// remoteTimeout is the time to wait for the response of a remote
+	// signer, DPoS consensus can not wait longer than a few seconds.
+	remoteTimeout = 3 * time.Second
+
+	// maxResponseLength is the maximum length of a response.
+	maxResponseLength = 64 * 1024
+)
+
+// Commands of the remote signer protocol.  A request is a command byte
+// followed by the var bytes of its payload, a response is a status byte
+// followed by the var bytes of the result or of the error message.
+const (
+	cmdPublicKey byte = 0x01
+	cmdSign      byte = 0x02
+	cmdDecrypt   byte = 0x03
+)
+
+const (
+	statusOK    byte = 0x00
+	statusError byte = 0x01
+)
+
+func writeFrame(w io.Writer, flag byte, payload []byte) error {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(flag)
+	if err := common.WriteVarBytes(buf, payload); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func readFrame(r io.Reader, maxLength uint32) (byte, []byte, error) {
+	flag, err := common.ReadUint8(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	payload, err := common.ReadVarBytes(r, maxLength, "payload")
+	return flag, payload, err
+}
+
+// remote is the Signer sending the requests to a signer server listening on
+// a Unix socket, the private key never enters the process.
+type remote struct {
+	socket    string
+	publicKey []byte
+
+	mtx  sync.Mutex
+	conn net.Conn
+}
+
+func (s *remote) PublicKey() []byte {
+	return s.publicKey
+}
+
+func (s *remote) Sign(request *Request) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := request.Serialize(buf); err != nil {
+		return nil, err
+	}
+	return s.call(cmdSign, buf.Bytes())
+}
+
+func (s *remote) Decrypt(cipher []byte) ([]byte, error) {
+	return s.call(cmdDecrypt, cipher)
+}
+
+// call sends a request to the server and returns the result, the connection
+// is dropped on network errors and dialed again by the next call.
+func (s *remote) call(cmd byte, payload []byte) ([]byte, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout("unix", s.socket, remoteTimeout)
+		if err != nil {
+			return nil, err
+		}
+		s.conn = conn
+	}
+
+	status, result, err := s.roundTrip(cmd, payload)
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return nil, err
+	}
+	if status != statusOK {
+		return nil, errors.New("remote signer: " + string(result))
+	}
+	return result, nil
+}
+
+func (s *remote) roundTrip(cmd byte, payload []byte) (byte, []byte, error) {
+	if err := s.conn.SetDeadline(time.Now().Add(remoteTimeout)); err != nil {
+		return 0, nil, err
+	}
+	if err := writeFrame(s.conn, cmd, payload); err != nil {
+		return 0, nil, err
+	}
+	return readFrame(s.conn, maxResponseLength)
+}
+
+// Dial connects to the signer server listening on the Unix socket and
+// creates a Signer sending requests to it.
+func Dial(socket string) (Signer, error) {
+	s := &remote{socket: socket}
+	publicKey, err := s.call(cmdPublicKey, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.publicKey = publicKey
+	return s, nil
+}
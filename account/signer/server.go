@@ -0,0 +1,153 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package signer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// Server serves the requests of remote signers on a Unix socket with the
+// Signer it holds, usually a keystore signer with a policy.
+type Server struct {
+	// Log prints the served requests if it is not nil.
+	Log func(format string, a ...interface{})
+
+	signer   Signer
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mtx   sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// Listen listens on the Unix socket, which is only accessible by the owner
+// of the process.  A stale socket file left by a previous server is removed.
+func (s *Server) Listen(socket string) error {
+	if info, err := os.Stat(socket); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return fmt.Errorf("%s exists and is not a socket", socket)
+		}
+		if err := os.Remove(socket); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return err
+	}
+	s.listener = listener
+	return nil
+}
+
+// Serve accepts the connections until the server is closed.
+func (s *Server) Serve() error {
+	if s.listener == nil {
+		return errors.New("server is not listening")
+	}
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			s.wg.Wait()
+			return err
+		}
+		s.mtx.Lock()
+		s.conns[conn] = struct{}{}
+		s.mtx.Unlock()
+		s.wg.Add(1)
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops accepting connections, closes the connected ones and removes
+// the socket file.
+func (s *Server) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+	err := s.listener.Close()
+	s.mtx.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mtx.Unlock()
+	return err
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mtx.Lock()
+		delete(s.conns, conn)
+		s.mtx.Unlock()
+		conn.Close()
+	}()
+
+	for {
+		cmd, payload, err := readFrame(conn, maxRequestDataLength+16)
+		if err != nil {
+			return
+		}
+
+		result, err := s.handle(cmd, payload)
+		if err != nil {
+			err = writeFrame(conn, statusError, []byte(err.Error()))
+		} else {
+			err = writeFrame(conn, statusOK, result)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *Server) handle(cmd byte, payload []byte) ([]byte, error) {
+	switch cmd {
+	case cmdPublicKey:
+		return s.signer.PublicKey(), nil
+
+	case cmdSign:
+		var request Request
+		if err := request.Deserialize(bytes.NewReader(payload)); err != nil {
+			return nil, err
+		}
+		signature, err := s.signer.Sign(&request)
+		if err != nil {
+			s.logf("refused to sign %s height %d view offset %d: %s",
+				request.Type, request.Height, request.ViewOffset, err)
+			return nil, err
+		}
+		s.logf("signed %s height %d view offset %d", request.Type,
+			request.Height, request.ViewOffset)
+		return signature, nil
+
+	case cmdDecrypt:
+		return s.signer.Decrypt(payload)
+
+	default:
+		return nil, fmt.Errorf("unknown command %d", cmd)
+	}
+}
+
+func (s *Server) logf(format string, a ...interface{}) {
+	if s.Log != nil {
+		s.Log(format, a...)
+	}
+}
+
+// NewServer creates a server serving the requests with the signer.
+func NewServer(signer Signer) *Server {
+	return &Server{signer: signer, conns: make(map[net.Conn]struct{})}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package signer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/common"
+)
+
+// maxRequestDataLength is the maximum length of the data to be signed or
+// decrypted in one request.
+const maxRequestDataLength = 8 * 1024 * 1024
+
+// MessageType is the type of the message requested to be signed.
+type MessageType byte
+
+const (
+	// Proposal is the DPoS proposal of a block.
+	Proposal MessageType = 0x01
+
+	// Vote is the DPoS vote on a proposal.
+	Vote MessageType = 0x02
+
+	// Transaction is the unsigned data of a transaction.
+	Transaction MessageType = 0x03
+
+	// Message is any other data, such as the DPoS network messages.
+	Message MessageType = 0x04
+)
+
+var messageTypeStrings = map[MessageType]string{
+	Proposal:    "proposal",
+	Vote:        "vote",
+	Transaction: "transaction",
+	Message:     "message",
+}
+
+func (t MessageType) String() string {
+	if s, ok := messageTypeStrings[t]; ok {
+		return s
+	}
+	return fmt.Sprintf("MessageType%d", t)
+}
+
+// ParseMessageTypes parses the comma separated names of message types.
+func ParseMessageTypes(names string) ([]MessageType, error) {
+	var types []MessageType
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		var found bool
+		for t, s := range messageTypeStrings {
+			if s == name {
+				types = append(types, t)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown message type %s", name)
+		}
+	}
+	return types, nil
+}
+
+// Request is a request to sign the data of a message.  Height and ViewOffset
+// locate the DPoS consensus round of proposals and votes, they are zero for
+// the other message types.
+type Request struct {
+	Type       MessageType
+	Height     uint32
+	ViewOffset uint32
+	Data       []byte
+}
+
+func (r *Request) Serialize(w io.Writer) error {
+	if err := common.WriteUint8(w, uint8(r.Type)); err != nil {
+		return err
+	}
+	if err := common.WriteUint32(w, r.Height); err != nil {
+		return err
+	}
+	if err := common.WriteUint32(w, r.ViewOffset); err != nil {
+		return err
+	}
+	return common.WriteVarBytes(w, r.Data)
+}
+
+func (r *Request) Deserialize(reader io.Reader) error {
+	t, err := common.ReadUint8(reader)
+	if err != nil {
+		return err
+	}
+	r.Type = MessageType(t)
+	if r.Height, err = common.ReadUint32(reader); err != nil {
+		return err
+	}
+	if r.ViewOffset, err = common.ReadUint32(reader); err != nil {
+		return err
+	}
+	r.Data, err = common.ReadVarBytes(reader, maxRequestDataLength, "data")
+	return err
+}
+
+// Signer holds a private key and signs messages with it.  The private key
+// may live in the process memory or on a separate host, so callers should
+// never assume it can be read out.
+type Signer interface {
+	// PublicKey returns the compressed public key of the signer.
+	PublicKey() []byte
+
+	// Sign signs the data of the request, it returns an error if the
+	// request is refused by the policy of the signer.
+	Sign(request *Request) ([]byte, error)
+
+	// Decrypt decrypts the data encrypted by the public key.
+	Decrypt(cipher []byte) ([]byte, error)
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package signer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/account"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func proposalData(blockHash byte, viewOffset uint32) []byte {
+	proposal := &payload.DPOSProposal{
+		Sponsor:    make([]byte, crypto.COMPRESSEDLEN),
+		BlockHash:  common.Uint256{blockHash},
+		ViewOffset: viewOffset,
+	}
+	return proposal.Data()
+}
+
+func voteData(proposalHash byte) []byte {
+	vote := &payload.DPOSProposalVote{
+		ProposalHash: common.Uint256{proposalHash},
+		Signer:       make([]byte, crypto.COMPRESSEDLEN),
+		Accept:       true,
+	}
+	return vote.Data()
+}
+
+func TestPolicy(t *testing.T) {
+	policy := NewPolicy()
+
+	// Signing the same proposal again is allowed.
+	proposal := &Request{Type: Proposal, Height: 100, Data: proposalData(1, 0)}
+	assert.NoError(t, policy.Check(proposal))
+	assert.NoError(t, policy.Check(proposal))
+
+	// Another proposal in the same round is refused.
+	assert.Error(t, policy.Check(&Request{Type: Proposal, Height: 100,
+		Data: proposalData(2, 0)}))
+	assert.NoError(t, policy.Check(&Request{Type: Proposal, Height: 100,
+		ViewOffset: 1, Data: proposalData(2, 1)}))
+	assert.NoError(t, policy.Check(&Request{Type: Proposal, Height: 101,
+		Data: proposalData(2, 0)}))
+
+	// Votes are checked separately from proposals.
+	assert.NoError(t, policy.Check(&Request{Type: Vote, Height: 100,
+		Data: voteData(3)}))
+	assert.Error(t, policy.Check(&Request{Type: Vote, Height: 100,
+		Data: voteData(4)}))
+
+	// Other messages are not checked by rounds.
+	assert.NoError(t, policy.Check(&Request{Type: Message, Data: []byte{1}}))
+	assert.NoError(t, policy.Check(&Request{Type: Message, Data: []byte{2}}))
+
+	// The type and view offset of a request must match its data.
+	assert.Error(t, policy.Check(&Request{Type: Proposal, Height: 102,
+		Data: []byte{1}}))
+	assert.Error(t, policy.Check(&Request{Type: Proposal, Height: 102,
+		Data: voteData(1)}))
+	assert.Error(t, policy.Check(&Request{Type: Proposal, Height: 102,
+		ViewOffset: 1, Data: proposalData(1, 0)}))
+	assert.Error(t, policy.Check(&Request{Type: Vote, Height: 102,
+		Data: proposalData(1, 0)}))
+	assert.Error(t, policy.Check(&Request{Type: Message,
+		Data: proposalData(3, 0)}))
+	assert.Error(t, policy.Check(&Request{Type: Transaction,
+		Data: voteData(5)}))
+	assert.Equal(t, 4, len(policy.signed))
+
+	// Old rounds are forgotten and can not be signed any more.
+	assert.NoError(t, policy.Check(&Request{Type: Proposal,
+		Height: 101 + policyHeightWindow, Data: proposalData(1, 0)}))
+	assert.Equal(t, 2, len(policy.signed))
+	assert.Error(t, policy.Check(proposal))
+
+	// Only the allowed message types are signed.
+	policy = NewPolicy(Proposal, Vote)
+	assert.NoError(t, policy.Check(proposal))
+	assert.Error(t, policy.Check(&Request{Type: Transaction}))
+	assert.Error(t, policy.Check(&Request{Type: Message}))
+}
+
+func TestOpenPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "protection")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "protection")
+	publicKey := []byte{2, 1}
+
+	policy, err := OpenPolicy(path)
+	assert.NoError(t, err)
+	assert.NoError(t, policy.bind(publicKey))
+	proposal := &Request{Type: Proposal, Height: 100, Data: proposalData(1, 0)}
+	assert.NoError(t, policy.Check(proposal))
+	assert.NoError(t, policy.Check(&Request{Type: Vote, Height: 100,
+		Data: voteData(2)}))
+
+	// Nothing is signed in refusal mode.
+	policy.SetRefusal(true)
+	var logged int
+	policy.Log = func(format string, a ...interface{}) { logged++ }
+	assert.Error(t, policy.Check(proposal))
+	assert.Error(t, policy.Check(&Request{Type: Message}))
+	assert.Equal(t, 2, logged)
+	policy.SetRefusal(false)
+
+	// The records are still there after reopened.
+	assert.NoError(t, policy.Close())
+	policy, err = OpenPolicy(path)
+	assert.NoError(t, err)
+	assert.Error(t, policy.bind([]byte{2, 2}))
+	assert.NoError(t, policy.Check(proposal))
+	assert.Error(t, policy.Check(&Request{Type: Proposal, Height: 100,
+		Data: proposalData(3, 0)}))
+
+	exported := new(bytes.Buffer)
+	assert.NoError(t, policy.Export(exported))
+	assert.NoError(t, policy.Close())
+
+	// The records are imported to another machine.
+	other, err := OpenPolicy(filepath.Join(dir, "other"))
+	assert.NoError(t, err)
+	assert.NoError(t, other.Check(&Request{Type: Proposal, Height: 101,
+		Data: proposalData(4, 0)}))
+	count, err := other.Import(bytes.NewReader(exported.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	count, err = other.Import(bytes.NewReader(exported.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Error(t, other.Check(&Request{Type: Proposal, Height: 100,
+		Data: proposalData(3, 0)}))
+	assert.Error(t, other.bind([]byte{2, 2}))
+	assert.NoError(t, other.Close())
+
+	// Conflicting records are not imported.
+	conflict, err := OpenPolicy(filepath.Join(dir, "conflict"))
+	assert.NoError(t, err)
+	assert.NoError(t, conflict.Check(&Request{Type: Vote, Height: 100,
+		Data: voteData(5)}))
+	_, err = conflict.Import(bytes.NewReader(exported.Bytes()))
+	assert.Error(t, err)
+	assert.NoError(t, conflict.bind([]byte{2, 2}))
+	assert.NoError(t, conflict.Check(&Request{Type: Proposal, Height: 100,
+		Data: proposalData(3, 0)}))
+	assert.NoError(t, conflict.Close())
+}
+
+func TestParseMessageTypes(t *testing.T) {
+	types, err := ParseMessageTypes("proposal, vote,")
+	assert.NoError(t, err)
+	assert.Equal(t, []MessageType{Proposal, Vote}, types)
+
+	types, err = ParseMessageTypes("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(types))
+
+	_, err = ParseMessageTypes("proposal,block")
+	assert.Error(t, err)
+}
+
+func TestRemote(t *testing.T) {
+	acc, err := account.NewAccount()
+	assert.NoError(t, err)
+	s, err := NewKeystore(acc)
+	assert.NoError(t, err)
+	keystore, err := Guard(s, NewPolicy(Proposal, Vote, Message))
+	assert.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "signer")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "signer.sock")
+
+	server := NewServer(keystore)
+	assert.NoError(t, server.Listen(socket))
+	done := make(chan struct{})
+	go func() {
+		server.Serve()
+		close(done)
+	}()
+
+	remote, err := Dial(socket)
+	assert.NoError(t, err)
+	assert.Equal(t, keystore.PublicKey(), remote.PublicKey())
+
+	request := &Request{Type: Proposal, Height: 10, ViewOffset: 2,
+		Data: proposalData(1, 2)}
+	signature, err := remote.Sign(request)
+	assert.NoError(t, err)
+	assert.NoError(t, crypto.Verify(*acc.PublicKey, request.Data, signature))
+
+	// The requests refused by the policy of the server return errors.
+	_, err = remote.Sign(&Request{Type: Proposal, Height: 10, ViewOffset: 2,
+		Data: proposalData(2, 2)})
+	assert.Error(t, err)
+	_, err = remote.Sign(&Request{Type: Transaction, Data: []byte("tx")})
+	assert.Error(t, err)
+
+	cipher, err := crypto.Encrypt(acc.PublicKey, []byte("127.0.0.1:20339"))
+	assert.NoError(t, err)
+	plain, err := remote.Decrypt(cipher)
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:20339", string(plain))
+
+	// A stale socket file is replaced by the next server.
+	assert.NoError(t, server.Close())
+	<-done
+	_, err = remote.Sign(request)
+	assert.Error(t, err)
+	assert.NoError(t, ioutil.WriteFile(socket, nil, 0600))
+	assert.Error(t, NewServer(keystore).Listen(socket))
+}
@@ -211,7 +211,7 @@ func quickGenerateBlock(pow *pow.Service, prevHash *common.Uint256,
 		}
 		totalTxsSize = size
 
-		if !blockchain.IsFinalizedTransaction(tx, height) {
+		if !blockchain.IsFinalizedTransaction(tx, height, time.Time{}) {
 			continue
 		}
 		msgBlock.Transactions = append(msgBlock.Transactions, tx)
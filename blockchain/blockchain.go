@@ -205,7 +205,7 @@ func (b *BlockChain) MigrateOldDB(
 				break
 			}
 			var confirm *payload.Confirm
-			if start >= params.CRCOnlyDPOSHeight {
+			if params.IsActive(config.DeploymentCRCOnlyDPOS, start) {
 				confirm, err = b.db.GetConfirm(hash)
 				if err != nil {
 					done <- fmt.Errorf("get confirm err: %s", err)
@@ -297,6 +297,13 @@ func (b *BlockChain) InitCheckpoint(interrupt <-chan struct{},
 			startHeight = safeHeight + 1
 		}
 
+		// The checkpoints of a snapshot are taken at the snapshot height,
+		// and the blocks before it may not be available.
+		snapshotHeight, ok := b.SnapshotHeight()
+		if ok && startHeight <= snapshotHeight {
+			startHeight = snapshotHeight + 1
+		}
+
 		log.Info("[RecoverFromCheckPoints] recover start height: ", startHeight)
 		if barStart != nil && bestHeight >= startHeight {
 			barStart(bestHeight - startHeight)
@@ -314,7 +321,7 @@ func (b *BlockChain) InitCheckpoint(interrupt <-chan struct{},
 				break
 			}
 
-			if block.Height >= b.chainParams.DPoSV2StartHeight {
+			if b.chainParams.IsActive(config.DeploymentDPoSV2, block.Height) {
 				CalculateTxsFee(block.Block)
 			} else {
 				if block.Height >= bestHeight-uint32(
@@ -628,6 +635,29 @@ func (b *BlockChain) CreateCRAssetsRectifyTransaction() (interfaces.Transaction,
 	return tx, nil
 }
 
+// CreateTransferTransaction creates an unsigned transfer asset transaction
+// which pays the outputs with the spendable UTXOs of the given address, the
+// change is returned to the address.  UTXOs referenced by transactions in
+// the exclude map are not used.
+func (b *BlockChain) CreateTransferTransaction(fromAddress Uint168, fee Fixed64,
+	exclude map[common.OutPoint]struct{}, outputs ...*common.OutputInfo) (
+	interfaces.Transaction, error) {
+	utxos, _, err := b.getUTXOsFromAddress(fromAddress)
+	if err != nil {
+		return nil, err
+	}
+	available := make([]*common.UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		outPoint := common.OutPoint{TxID: utxo.TxID, Index: uint16(utxo.Index)}
+		if _, ok := exclude[outPoint]; !ok {
+			available = append(available, utxo)
+		}
+	}
+
+	return b.createTransaction(&payload.TransferAsset{}, common.TransferAsset,
+		fromAddress, fee, uint32(0), available, outputs...)
+}
+
 func CalculateTxsFee(block *Block) {
 	for _, tx := range block.Transactions {
 		if tx.IsCoinBaseTx() {
@@ -804,7 +834,8 @@ func (b *BlockChain) ContainsTransaction(hash Uint256) bool {
 	//TODO: implement error catch
 	_, _, err := b.db.GetTransaction(hash)
 	if err != nil {
-		return false
+		// The transaction still exists if only its block data is pruned.
+		return IsPrunedError(err)
 	}
 	return true
 }
@@ -1367,7 +1398,7 @@ func (b *BlockChain) reorganizeChain(detachNodes, attachNodes *list.List) error
 
 		// roll back state about the last block before disconnect
 		if !recoverFromDefault &&
-			block.Height-1 >= b.chainParams.VoteStartHeight {
+			b.chainParams.IsActive(config.DeploymentVoteStart, block.Height-1) {
 			err = b.CkpManager.OnRollbackTo(
 				block.Height-1, b.state.ConsensusAlgorithm == state.POW)
 			if err != nil {
@@ -1496,7 +1527,7 @@ func (b *BlockChain) connectBlock(node *BlockNode, block *Block, confirm *payloa
 		}
 	}
 
-	if block.Height >= b.chainParams.CRCOnlyDPOSHeight && !revertToPOW &&
+	if b.chainParams.IsActive(config.DeploymentCRCOnlyDPOS, block.Height) && !revertToPOW &&
 		b.state.ConsensusAlgorithm != state.POW && confirm != nil {
 		if err := checkBlockWithConfirmation(block, confirm,
 			b.CkpManager, b.state.ConsensusAlgorithm == state.POW); err != nil {
@@ -1644,6 +1675,7 @@ func (b *BlockChain) maybeAcceptBlock(block *Block, confirm *payload.Confirm) (b
 		}, nil, b.state.ConsensusAlgorithm == state.POW,
 			b.state.RevertToPOWBlockHeight, false)
 		DefaultLedger.Arbitrators.DumpInfo(block.Height)
+		b.maybePruneBlocks(block.Height)
 	}
 
 	events.Notify(events.ETBlockProcessed, block)
@@ -1651,7 +1683,7 @@ func (b *BlockChain) maybeAcceptBlock(block *Block, confirm *payload.Confirm) (b
 	// Notify the caller that the new block was accepted into the block
 	// chain.  The caller would typically want to react by relaying the
 	// inventory to other peers.
-	if block.Height >= b.chainParams.CRCOnlyDPOSHeight {
+	if b.chainParams.IsActive(config.DeploymentCRCOnlyDPOS, block.Height) {
 		if confirm != nil {
 			events.Notify(events.ETBlockConfirmAccepted, block)
 		} else {
@@ -2137,6 +2169,26 @@ func (b *BlockChain) LocateBlocks(locator []*Uint256, hashStop *Uint256, maxHash
 	return blocks
 }
 
+// LocateHeaders returns the headers of the blocks after the first known block
+// in the locator until the provided stop hash is reached, or up to the
+// provided max number of block headers.
+//
+// This function is safe for concurrent access.
+func (b *BlockChain) LocateHeaders(locator []*Uint256, hashStop *Uint256,
+	maxHeaders uint32) []*common.Header {
+	hashes := b.LocateBlocks(locator, hashStop, maxHeaders)
+	headers := make([]*common.Header, 0, len(hashes))
+	for _, hash := range hashes {
+		header, err := b.GetHeader(*hash)
+		if err != nil {
+			log.Errorf("LocateHeaders error %s", err)
+			break
+		}
+		headers = append(headers, header)
+	}
+	return headers
+}
+
 func (b *BlockChain) MedianAdjustedTime() time.Time {
 	newTimestamp := b.TimeSource.AdjustedTime()
 	minTimestamp := b.MedianTimePast.Add(time.Second)
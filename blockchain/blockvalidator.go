@@ -32,13 +32,14 @@ const (
 	MaxTimeOffsetSeconds = 2 * 60 * 60
 )
 
-func (b *BlockChain) CheckBlockSanity(block *Block) error {
-	header := block.Header
+// CheckHeaderSanity checks the aux pow, proof of work and timestamp of the
+// block header, it does not depend on the previous blocks.
+func (b *BlockChain) CheckHeaderSanity(header *common.Header) error {
 	hash := header.Hash()
 	if !header.AuxPow.Check(&hash, AuxPowChainID) {
 		return errors.New("[PowCheckBlockSanity] block check aux pow failed")
 	}
-	if CheckProofOfWork(&header, b.chainParams.PowConfiguration.PowLimit) != nil {
+	if CheckProofOfWork(header, b.chainParams.PowConfiguration.PowLimit) != nil {
 		return errors.New("[PowCheckBlockSanity] block check proof of work failed")
 	}
 
@@ -53,6 +54,13 @@ func (b *BlockChain) CheckBlockSanity(block *Block) error {
 	if tempTime.After(maxTimestamp) {
 		return errors.New("[PowCheckBlockSanity] block timestamp of is too far in the future")
 	}
+	return nil
+}
+
+func (b *BlockChain) CheckBlockSanity(block *Block) error {
+	if err := b.CheckHeaderSanity(&block.Header); err != nil {
+		return err
+	}
 
 	// A block must have at least one transaction.
 	numTx := len(block.Transactions)
@@ -129,7 +137,7 @@ func (b *BlockChain) CheckBlockSanity(block *Block) error {
 	if err != nil {
 		return errors.New("[PowCheckBlockSanity] merkleTree compute failed")
 	}
-	if !header.MerkleRoot.IsEqual(calcTransactionsRoot) {
+	if !block.Header.MerkleRoot.IsEqual(calcTransactionsRoot) {
 		return errors.New("[PowCheckBlockSanity] block merkle root is invalid")
 	}
 
@@ -280,7 +288,7 @@ func (b *BlockChain) checkTxsContext(block *Block) error {
 		block.Transactions[0], totalTxFee, dposReward)
 	if err != nil {
 		buf := new(bytes.Buffer)
-		if block.Height < b.chainParams.CheckRewardHeight {
+		if !b.chainParams.IsActive(config.DeploymentCheckReward, block.Height) {
 			if err = block.Serialize(buf); err != nil {
 				return err
 			}
@@ -299,13 +307,14 @@ func (b *BlockChain) checkTxsContext(block *Block) error {
 	return err
 }
 
-func (b *BlockChain) CheckBlockContext(block *Block, prevNode *BlockNode) error {
+// CheckHeaderContext checks the difficulty and timestamp of the block header
+// against the previous block node.
+func (b *BlockChain) CheckHeaderContext(header *common.Header, prevNode *BlockNode) error {
 	// The genesis block is valid by definition.
 	if prevNode == nil {
 		return nil
 	}
 
-	header := block.Header
 	expectedDifficulty, err := b.CalcNextRequiredDifficulty(prevNode,
 		time.Unix(int64(header.Timestamp), 0))
 	if err != nil {
@@ -324,10 +333,26 @@ func (b *BlockChain) CheckBlockContext(block *Block, prevNode *BlockNode) error
 	if !tempTime.After(medianTime) {
 		return errors.New("block timestamp is not after expected")
 	}
+	return nil
+}
 
+func (b *BlockChain) CheckBlockContext(block *Block, prevNode *BlockNode) error {
+	// The genesis block is valid by definition.
+	if prevNode == nil {
+		return nil
+	}
+
+	if err := b.CheckHeaderContext(&block.Header, prevNode); err != nil {
+		return err
+	}
+
+	var medianTime time.Time
+	if b.chainParams.IsActive(config.DeploymentTimeLock, block.Height) {
+		medianTime = CalcPastMedianTime(prevNode)
+	}
 	var recordSponsorExist bool
 	for _, tx := range block.Transactions[1:] {
-		if !IsFinalizedTransaction(tx, block.Height) {
+		if !IsFinalizedTransaction(tx, block.Height, medianTime) {
 			return errors.New("block contains unfinalized transaction")
 		}
 		if tx.IsRecordSponorTx() {
@@ -336,7 +361,7 @@ func (b *BlockChain) CheckBlockContext(block *Block, prevNode *BlockNode) error
 	}
 
 	// check if need to record sponsor
-	if block.Height >= b.chainParams.DPoSConfiguration.RecordSponsorStartHeight {
+	if b.chainParams.IsActive(config.DeploymentRecordSponsor, block.Height) {
 		lastBlock, err := b.GetDposBlockByHash(*prevNode.Hash)
 		if err != nil {
 			// try get block from cache
@@ -410,15 +435,23 @@ func CheckProofOfWork(header *common.Header, powLimit *big.Int) error {
 	return nil
 }
 
-func IsFinalizedTransaction(msgTx interfaces.Transaction, blockHeight uint32) bool {
+// IsFinalizedTransaction returns whether the transaction can be packed in a
+// block at the height.  A lock time not less than the lock time threshold is
+// compared with the median time past of the previous block, which is the
+// zero time before the time locks are active so that the lock never passes.
+func IsFinalizedTransaction(msgTx interfaces.Transaction, blockHeight uint32,
+	medianTime time.Time) bool {
 	// Lock time of zero means the transaction is finalized.
 	lockTime := msgTx.LockTime()
 	if lockTime == 0 {
 		return true
 	}
 
-	//FIXME only height
-	if lockTime < blockHeight {
+	if lockTime >= common.LockTimeThreshold {
+		if int64(lockTime) < medianTime.Unix() {
+			return true
+		}
+	} else if lockTime < blockHeight {
 		return true
 	}
 
@@ -530,7 +563,7 @@ func (b *BlockChain) checkCoinbaseTransactionContext(blockHeight uint32, coinbas
 	}
 
 	// main version >= H2
-	if blockHeight >= b.chainParams.PublicDPOSHeight {
+	if b.chainParams.IsActive(config.DeploymentPublicDPOS, blockHeight) {
 		totalReward := totalTxFee + b.chainParams.GetBlockReward(blockHeight)
 		rewardDPOSArbiter := Fixed64(math.Ceil(float64(totalReward) * 0.35))
 		if totalReward-rewardDPOSArbiter+DefaultLedger.Arbitrators.
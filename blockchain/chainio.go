@@ -64,6 +64,15 @@ var (
 	// chain state.
 	chainStateKeyName = []byte("chainstate")
 
+	// pruneRetainHeightKeyName is the name of the DB key used to store the
+	// height of the next block whose transactions need to be checked for
+	// retaining before the block data is pruned.
+	pruneRetainHeightKeyName = []byte("pruneretainheight")
+
+	// snapshotHeightKeyName is the name of the DB key used to store the
+	// height of the snapshot the database was bootstrapped from.
+	snapshotHeightKeyName = []byte("snapshotheight")
+
 	// spendJournalVersionKeyName is the name of the DB key used to store
 	// the version of the spend journal currently in the database.
 	spendJournalVersionKeyName = []byte("spendjournalversion")
@@ -356,40 +365,8 @@ func (b *BlockChain) createChainState() error {
 	// Create the initial the database chain state including creating the
 	// necessary index buckets and inserting the genesis block.
 	err := b.db.GetFFLDB().Update(func(dbTx database.Tx) error {
-		meta := dbTx.Metadata()
-
-		// Create the bucket that houses the block index data.
-		_, err := meta.CreateBucket(blockIndexBucketName)
-		if err != nil {
-			return err
-		}
-
-		// Create the bucket that houses the chain block hash to height
-		// index.
-		_, err = meta.CreateBucket(hashIndexBucketName)
-		if err != nil {
-			return err
-		}
-
-		// Create the bucket that houses the chain block height to hash
-		// index.
-		_, err = meta.CreateBucket(heightIndexBucketName)
-		if err != nil {
-			return err
-		}
-
-		// Create the bucket that houses the spend journal data and
-		// store its version.
-		_, err = meta.CreateBucket(spendJournalBucketName)
-		if err != nil {
-			return err
-		}
-
-		// Create the bucket that houses the utxo set and store its
-		// version.  Note that the genesis block coinbase transaction is
-		// intentionally not inserted here since it is not spendable by
-		// consensus rules.
-		_, err = meta.CreateBucket(utxoSetBucketName)
+		// Create the buckets that house the chain state.
+		err := dbCreateChainBuckets(dbTx)
 		if err != nil {
 			return err
 		}
@@ -419,6 +396,46 @@ func (b *BlockChain) createChainState() error {
 	return err
 }
 
+// dbCreateChainBuckets uses an existing database transaction to create the
+// buckets which house the block index and the chain state.
+func dbCreateChainBuckets(dbTx database.Tx) error {
+	meta := dbTx.Metadata()
+
+	// Create the bucket that houses the block index data.
+	_, err := meta.CreateBucket(blockIndexBucketName)
+	if err != nil {
+		return err
+	}
+
+	// Create the bucket that houses the chain block hash to height
+	// index.
+	_, err = meta.CreateBucket(hashIndexBucketName)
+	if err != nil {
+		return err
+	}
+
+	// Create the bucket that houses the chain block height to hash
+	// index.
+	_, err = meta.CreateBucket(heightIndexBucketName)
+	if err != nil {
+		return err
+	}
+
+	// Create the bucket that houses the spend journal data and
+	// store its version.
+	_, err = meta.CreateBucket(spendJournalBucketName)
+	if err != nil {
+		return err
+	}
+
+	// Create the bucket that houses the utxo set and store its
+	// version.  Note that the genesis block coinbase transaction is
+	// intentionally not inserted here since it is not spendable by
+	// consensus rules.
+	_, err = meta.CreateBucket(utxoSetBucketName)
+	return err
+}
+
 // initChainState attempts to load and initialize the chain state from the
 // database.  When the DB does not yet contain any chain state, both it and the
 // chain state are initialized to the genesis block.
@@ -606,6 +623,25 @@ func dbFetchHeightByHash(dbTx database.Tx, hash *common.Uint256) (uint32, error)
 	return uint32(byteOrder.Uint32(serializedHeight)), nil
 }
 
+// dbFetchIndexedHeader uses an existing database transaction to retrieve the
+// header of a main chain block from the block index, this is useful when the
+// block data has been pruned.
+func dbFetchIndexedHeader(dbTx database.Tx, hash *common.Uint256) (*common2.Header, error) {
+	height, err := dbFetchHeightByHash(dbTx, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	blockIndexBucket := dbTx.Metadata().Bucket(blockIndexBucketName)
+	blockRow := blockIndexBucket.Get(blockIndexKey(hash, height))
+	if blockRow == nil {
+		return nil, fmt.Errorf("block %s is not in the block index", hash)
+	}
+
+	header, _, err := DeserializeBlockRow(blockRow)
+	return header, err
+}
+
 // dbFetchBlockByNode uses an existing database transaction to retrieve the
 // raw block for the provided node, deserialize it, and return a btcutil.Block
 // with the height set.
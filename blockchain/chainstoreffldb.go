@@ -312,9 +312,15 @@ func (c *ChainStoreFFLDB) GetBlock(hash Uint256) (*DposBlock, error) {
 
 func (c *ChainStoreFFLDB) GetHeader(hash Uint256) (*common.Header, error) {
 	var headerBytes []byte
+	var prunedHeader *common.Header
 	err := c.db.View(func(tx database.Tx) error {
 		var e error
 		headerBytes, e = tx.FetchBlockHeader(&hash)
+		if IsPrunedError(e) {
+			// The block data is no longer on disk, fall back to the
+			// header stored in the block index.
+			prunedHeader, e = dbFetchIndexedHeader(tx, &hash)
+		}
 		if e != nil {
 			return e
 		}
@@ -323,6 +329,9 @@ func (c *ChainStoreFFLDB) GetHeader(hash Uint256) (*common.Header, error) {
 	if err != nil {
 		return nil, errors.New("[BlockChain], GetHeader failed")
 	}
+	if prunedHeader != nil {
+		return prunedHeader, nil
+	}
 
 	var header common.Header
 	err = header.DeserializeNoAux(bytes.NewReader(headerBytes))
@@ -333,6 +342,37 @@ func (c *ChainStoreFFLDB) GetHeader(hash Uint256) (*common.Header, error) {
 	return &header, nil
 }
 
+// PruneBlocks removes the oldest block files of the block database until the
+// stored block data no longer exceeds targetSize bytes, keeping the block with
+// the given hash and all blocks after it.
+func (c *ChainStoreFFLDB) PruneBlocks(targetSize uint64,
+	keepHash *Uint256) (uint64, error) {
+	pruner, ok := c.db.(database.Pruner)
+	if !ok {
+		return 0, errors.New("block database does not support pruning")
+	}
+	return pruner.PruneBlocks(targetSize, keepHash)
+}
+
+// BlocksSize returns the total size in bytes of the stored block data.
+func (c *ChainStoreFFLDB) BlocksSize() uint64 {
+	pruner, ok := c.db.(database.Pruner)
+	if !ok {
+		return 0
+	}
+	return pruner.BlocksSize()
+}
+
+// StorePrunedBlocks adds the blocks with the given hashes to the block database
+// as pruned blocks without storing their data.
+func (c *ChainStoreFFLDB) StorePrunedBlocks(hashes []Uint256) error {
+	pruner, ok := c.db.(database.Pruner)
+	if !ok {
+		return errors.New("block database does not support pruning")
+	}
+	return pruner.StorePrunedBlocks(hashes)
+}
+
 func (c *ChainStoreFFLDB) IsBlockInStore(hash *Uint256) bool {
 	var hasBlock bool
 	err := c.db.View(func(dbTx database.Tx) error {
@@ -408,6 +448,19 @@ func (c *ChainStoreFFLDB) GetUTXO(programHash *Uint168) ([]*common.UTXO, error)
 	return c.indexManager.FetchUTXO(programHash)
 }
 
+func (c *ChainStoreFFLDB) GetAddressHistory(programHash *Uint168, skip,
+	count uint32, reverse bool) ([]*indexers.AddressHistory, error) {
+	return c.indexManager.FetchAddressHistory(programHash, skip, count, reverse)
+}
+
+func (c *ChainStoreFFLDB) GetAddressHistoryCount(programHash *Uint168) (uint32, error) {
+	return c.indexManager.FetchAddressHistoryCount(programHash)
+}
+
+func (c *ChainStoreFFLDB) GetAddressTxCount(programHash *Uint168) (uint32, error) {
+	return c.indexManager.FetchAddressTxCount(programHash)
+}
+
 func DBFetchTx3IndexEntry(dbTx database.Tx, txHash *Uint256) bool {
 	hashIndex := dbTx.Metadata().Bucket(Tx3IndexBucketName)
 	if hashIndex == nil {
@@ -0,0 +1,381 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package indexers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/database"
+)
+
+const (
+	// AddressIndexName is the human-readable name for the index.
+	AddressIndexName = "address history index"
+
+	// addrHistoryKeySize is the size of the key of an address history entry.
+	addrHistoryKeySize = 4 + 4 + 1
+
+	// addrHistoryValueSize is the size of the value of an address history
+	// entry.
+	addrHistoryValueSize = common.UINT256SIZE + 8
+)
+
+// AddressDirection indicates whether an address received or spent value
+// within a transaction.
+type AddressDirection byte
+
+const (
+	// AddressReceived indicates the address received value from outputs of
+	// the transaction.
+	AddressReceived AddressDirection = iota
+
+	// AddressSent indicates the address spent value through inputs of the
+	// transaction.
+	AddressSent
+)
+
+func (d AddressDirection) String() string {
+	switch d {
+	case AddressReceived:
+		return "received"
+	case AddressSent:
+		return "sent"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	// AddressIndexKey is the key of the address history index and the DB
+	// bucket used to house it.
+	AddressIndexKey = []byte("addrhistoryidx")
+
+	// ErrAddressIndexDisabled indicates the address history index is not
+	// enabled on this node.
+	ErrAddressIndexDisabled = errors.New("address index is not enabled")
+)
+
+// AddressHistory represents a transaction that touched an address.
+type AddressHistory struct {
+	TxID      common.Uint256
+	Height    uint32
+	Direction AddressDirection
+	Amount    common.Fixed64
+}
+
+// -----------------------------------------------------------------------------
+// The address history index consists of a bucket for each program hash which
+// ever appeared in the chain.  Every entry of the bucket describes the amount
+// an address received or sent in one transaction.
+//
+// The keys are big endian so the entries are ordered by height and position
+// of the transaction within the block:
+//
+//   <block height><tx index><direction>
+//
+//   Field           Type              Size
+//   block height    uint32            4 bytes
+//   tx index        uint32            4 bytes
+//   direction       AddressDirection  1 byte
+//
+// The serialized value format is:
+//
+//   <tx hash><amount>
+//
+//   Field           Type              Size
+//   tx hash         common.Uint256    common.UINT256SIZE
+//   amount          common.Fixed64    8 bytes
+// -----------------------------------------------------------------------------
+
+func addrHistoryKey(height uint32, txIndex uint32, dir AddressDirection) []byte {
+	key := make([]byte, addrHistoryKeySize)
+	binary.BigEndian.PutUint32(key[0:4], height)
+	binary.BigEndian.PutUint32(key[4:8], txIndex)
+	key[8] = byte(dir)
+	return key
+}
+
+func deserializeAddrHistory(key, value []byte) (*AddressHistory, error) {
+	if len(key) != addrHistoryKeySize || len(value) != addrHistoryValueSize {
+		return nil, errDeserialize("unexpected size of address history entry")
+	}
+	var history AddressHistory
+	history.Height = binary.BigEndian.Uint32(key[0:4])
+	history.Direction = AddressDirection(key[8])
+	copy(history.TxID[:], value[:common.UINT256SIZE])
+	history.Amount = common.Fixed64(byteOrder.Uint64(value[common.UINT256SIZE:]))
+	return &history, nil
+}
+
+// DBPutAddressHistoryEntry uses an existing database transaction to add an
+// address history entry for the given program hash.
+func DBPutAddressHistoryEntry(dbTx database.Tx, programHash *common.Uint168,
+	height uint32, txIndex uint32, txID *common.Uint256,
+	dir AddressDirection, amount common.Fixed64) error {
+	addrIndex := dbTx.Metadata().Bucket(AddressIndexKey)
+	programHashIndex, err := addrIndex.CreateBucketIfNotExists(programHash.Bytes())
+	if err != nil {
+		return err
+	}
+	value := make([]byte, addrHistoryValueSize)
+	copy(value, txID[:])
+	byteOrder.PutUint64(value[common.UINT256SIZE:], uint64(amount))
+	return programHashIndex.Put(addrHistoryKey(height, txIndex, dir), value)
+}
+
+// dbRemoveAddressHistoryEntry uses an existing database transaction to remove
+// an address history entry of the given program hash.
+func dbRemoveAddressHistoryEntry(dbTx database.Tx, programHash *common.Uint168,
+	height uint32, txIndex uint32, dir AddressDirection) error {
+	programHashIndex := dbTx.Metadata().Bucket(AddressIndexKey).
+		Bucket(programHash.Bytes())
+	if programHashIndex == nil {
+		return nil
+	}
+	return programHashIndex.Delete(addrHistoryKey(height, txIndex, dir))
+}
+
+// DBFetchAddressHistory uses an existing database transaction to fetch at most
+// count history entries of the given program hash after skipping the first
+// skip entries.  Entries are ordered from the oldest to the newest, or the
+// other way round if reverse is set.  A count of zero means no limit.
+func DBFetchAddressHistory(dbTx database.Tx, programHash *common.Uint168,
+	skip, count uint32, reverse bool) ([]*AddressHistory, error) {
+	programHashIndex := dbTx.Metadata().Bucket(AddressIndexKey).
+		Bucket(programHash.Bytes())
+	if programHashIndex == nil {
+		return nil, nil
+	}
+
+	cursor := programHashIndex.Cursor()
+	first, next := cursor.First, cursor.Next
+	if reverse {
+		first, next = cursor.Last, cursor.Prev
+	}
+	histories := make([]*AddressHistory, 0)
+	var skipped uint32
+	for ok := first(); ok; ok = next() {
+		if skipped < skip {
+			skipped++
+			continue
+		}
+		history, err := deserializeAddrHistory(cursor.Key(), cursor.Value())
+		if err != nil {
+			return nil, err
+		}
+		histories = append(histories, history)
+		if count != 0 && uint32(len(histories)) >= count {
+			break
+		}
+	}
+	return histories, nil
+}
+
+// DBFetchAddressHistoryCount uses an existing database transaction to count
+// the history entries of the given program hash, which is the unit of the
+// skip and count of DBFetchAddressHistory.
+func DBFetchAddressHistoryCount(dbTx database.Tx,
+	programHash *common.Uint168) (uint32, error) {
+	programHashIndex := dbTx.Metadata().Bucket(AddressIndexKey).
+		Bucket(programHash.Bytes())
+	if programHashIndex == nil {
+		return 0, nil
+	}
+
+	var count uint32
+	cursor := programHashIndex.Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		count++
+	}
+	return count, nil
+}
+
+// DBFetchAddressTxCount uses an existing database transaction to count the
+// transactions which touched the given program hash.
+func DBFetchAddressTxCount(dbTx database.Tx, programHash *common.Uint168) (
+	uint32, error) {
+	programHashIndex := dbTx.Metadata().Bucket(AddressIndexKey).
+		Bucket(programHash.Bytes())
+	if programHashIndex == nil {
+		return 0, nil
+	}
+
+	// Entries of the same transaction are adjacent and share the same
+	// height and tx index prefix, so only count the changes of the prefix.
+	var count uint32
+	var lastPrefix []byte
+	cursor := programHashIndex.Cursor()
+	for ok := cursor.First(); ok; ok = cursor.Next() {
+		key := cursor.Key()
+		if len(key) != addrHistoryKeySize {
+			return 0, errDeserialize("unexpected size of address history key")
+		}
+		prefix := key[:addrHistoryKeySize-1]
+		if lastPrefix != nil && string(prefix) == string(lastPrefix) {
+			continue
+		}
+		lastPrefix = append(lastPrefix[:0], prefix...)
+		count++
+	}
+	return count, nil
+}
+
+// addrAmounts records the amount an address received or sent within one
+// transaction.
+type addrAmounts map[common.Uint168]map[AddressDirection]common.Fixed64
+
+func (a addrAmounts) add(programHash common.Uint168, dir AddressDirection,
+	amount common.Fixed64) {
+	if _, ok := a[programHash]; !ok {
+		a[programHash] = make(map[AddressDirection]common.Fixed64)
+	}
+	a[programHash][dir] += amount
+}
+
+// AddressIndex implements an address to transaction history index.
+type AddressIndex struct {
+	db database.DB
+}
+
+// Init initializes the address history index. This is part of the Indexer
+// interface.
+func (idx *AddressIndex) Init() error {
+	return nil // Nothing to do.
+}
+
+// Key returns the database key to use for the index as a byte slice.
+//
+// This is part of the Indexer interface.
+func (idx *AddressIndex) Key() []byte {
+	return AddressIndexKey
+}
+
+// Name returns the human-readable name of the index.
+//
+// This is part of the Indexer interface.
+func (idx *AddressIndex) Name() string {
+	return AddressIndexName
+}
+
+// Create is invoked when the indexer manager determines the index needs
+// to be created for the first time.  It creates the buckets for the address
+// history index.
+//
+// This is part of the Indexer interface.
+func (idx *AddressIndex) Create(dbTx database.Tx) error {
+	meta := dbTx.Metadata()
+	_, err := meta.CreateBucket(AddressIndexKey)
+	return err
+}
+
+// blockAddrAmounts collects the amounts received and sent by each address in
+// every transaction of the given block.  The transactions referenced by the
+// inputs are looked up in the block first, and then through the passed
+// database transaction, so the spends of transactions in the same block are
+// resolved while the block is being connected.
+func (idx *AddressIndex) blockAddrAmounts(dbTx database.Tx,
+	block *types.Block) ([]addrAmounts, error) {
+	blockTxs := make(map[common.Uint256]interfaces.Transaction,
+		len(block.Transactions))
+	for _, txn := range block.Transactions {
+		blockTxs[txn.Hash()] = txn
+	}
+
+	amounts := make([]addrAmounts, 0, len(block.Transactions))
+	for _, txn := range block.Transactions {
+		txAmounts := make(addrAmounts)
+		for _, output := range txn.Outputs() {
+			txAmounts.add(output.ProgramHash, AddressReceived, output.Value)
+		}
+		if !txn.IsCoinBaseTx() {
+			for _, input := range txn.Inputs() {
+				referTxID := input.Previous.TxID
+				referTx, ok := blockTxs[referTxID]
+				if !ok {
+					var err error
+					referTx, _, err = dbFetchTx(dbTx, &referTxID)
+					if err != nil {
+						return nil, err
+					}
+				}
+				if int(input.Previous.Index) >= len(referTx.Outputs()) {
+					return nil, fmt.Errorf("output %d of transaction %s "+
+						"not found", input.Previous.Index, referTxID)
+				}
+				referOutput := referTx.Outputs()[input.Previous.Index]
+				txAmounts.add(referOutput.ProgramHash, AddressSent,
+					referOutput.Value)
+			}
+		}
+		amounts = append(amounts, txAmounts)
+	}
+	return amounts, nil
+}
+
+// ConnectBlock is invoked by the index manager when a new block has been
+// connected to the main chain.  This indexer adds a history entry for every
+// address touched by the transactions in the passed block.
+//
+// This is part of the Indexer interface.
+func (idx *AddressIndex) ConnectBlock(dbTx database.Tx, block *types.Block) error {
+	amounts, err := idx.blockAddrAmounts(dbTx, block)
+	if err != nil {
+		return err
+	}
+	for i, txn := range block.Transactions {
+		txID := txn.Hash()
+		for programHash, dirs := range amounts[i] {
+			for dir, amount := range dirs {
+				err := DBPutAddressHistoryEntry(dbTx, &programHash,
+					block.Height, uint32(i), &txID, dir, amount)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// DisconnectBlock is invoked by the index manager when a block has been
+// disconnected from the main chain.  This indexer removes the history entries
+// added for the transactions in the block.
+//
+// This is part of the Indexer interface.
+func (idx *AddressIndex) DisconnectBlock(dbTx database.Tx, block *types.Block) error {
+	amounts, err := idx.blockAddrAmounts(dbTx, block)
+	if err != nil {
+		return err
+	}
+	for i := range block.Transactions {
+		for programHash, dirs := range amounts[i] {
+			for dir := range dirs {
+				err := dbRemoveAddressHistoryEntry(dbTx, &programHash,
+					block.Height, uint32(i), dir)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// NewAddressIndex returns a new instance of an indexer that is used to create
+// a mapping of the program hashes of all addresses be used in the blockchain
+// to the transactions which received or spent their value.
+//
+// It implements the Indexer interface which plugs into the IndexManager that in
+// turn is used by the blockchain package.  This allows the index to be
+// seamlessly maintained along with the chain.
+func NewAddressIndex(db database.DB) *AddressIndex {
+	return &AddressIndex{db}
+}
@@ -71,6 +71,19 @@ type IndexManager interface {
 
 	// IsSideChainReturnDepositExist use to find if return deposit exist in DB
 	IsSideChainReturnDepositExist(txHash *common.Uint256) bool
+
+	// FetchAddressHistory retrieval the transaction history of a account
+	// address
+	FetchAddressHistory(programHash *common.Uint168, skip, count uint32,
+		reverse bool) ([]*AddressHistory, error)
+
+	// FetchAddressHistoryCount retrieval the count of history entries of a
+	// account address
+	FetchAddressHistoryCount(programHash *common.Uint168) (uint32, error)
+
+	// FetchAddressTxCount retrieval the count of transactions which touched
+	// a account address
+	FetchAddressTxCount(programHash *common.Uint168) (uint32, error)
 }
 
 // Indexer provides a generic interface for an indexer that is managed by an
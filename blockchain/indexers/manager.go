@@ -139,6 +139,7 @@ type Manager struct {
 	db             database.DB
 	enabledIndexes []Indexer
 	txStore        ITxStore
+	addrIndex      *AddressIndex
 }
 
 // Ensure the Manager type implements the blockchain.IndexManager interface.
@@ -517,6 +518,63 @@ func (m *Manager) IsSideChainReturnDepositExist(txHash *common.Uint256) bool {
 	return exist
 }
 
+func (m *Manager) FetchAddressHistory(programHash *common.Uint168, skip,
+	count uint32, reverse bool) ([]*AddressHistory, error) {
+	if m.addrIndex == nil {
+		return nil, ErrAddressIndexDisabled
+	}
+
+	var histories []*AddressHistory
+	err := m.db.View(func(dbTx database.Tx) error {
+		var err error
+		histories, err = DBFetchAddressHistory(dbTx, programHash, skip,
+			count, reverse)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return histories, nil
+}
+
+func (m *Manager) FetchAddressHistoryCount(programHash *common.Uint168) (
+	uint32, error) {
+	if m.addrIndex == nil {
+		return 0, ErrAddressIndexDisabled
+	}
+
+	var count uint32
+	err := m.db.View(func(dbTx database.Tx) error {
+		var err error
+		count, err = DBFetchAddressHistoryCount(dbTx, programHash)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (m *Manager) FetchAddressTxCount(programHash *common.Uint168) (uint32, error) {
+	if m.addrIndex == nil {
+		return 0, ErrAddressIndexDisabled
+	}
+
+	var count uint32
+	err := m.db.View(func(dbTx database.Tx) error {
+		var err error
+		count, err = DBFetchAddressTxCount(dbTx, programHash)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 // NewManager returns a new index manager with the provided indexes enabled.
 //
 // The manager returned satisfies the blockchain.IndexManager interface and thus
@@ -528,10 +586,16 @@ func NewManager(db database.DB, params *config.Configuration) *Manager {
 	returnDepositIndex := NewReturnDepositIndex(db)
 	var enabledIndexes []Indexer
 	enabledIndexes = append(enabledIndexes, txIndex, unspentIndex, utxoIndex, returnDepositIndex)
+	var addrIndex *AddressIndex
+	if params.EnableAddressIndex {
+		addrIndex = NewAddressIndex(db)
+		enabledIndexes = append(enabledIndexes, addrIndex)
+	}
 	return &Manager{
 		db:             db,
 		enabledIndexes: enabledIndexes,
 		txStore:        unspentIndex,
+		addrIndex:      addrIndex,
 	}
 }
 
@@ -0,0 +1,97 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package indexers
+
+import (
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/database"
+)
+
+// DBForEachUnspent uses an existing database transaction to call fn with the
+// hash and the unspent output indexes of every transaction in the unspent
+// index.
+func DBForEachUnspent(dbTx database.Tx,
+	fn func(txHash common.Uint256, indexes []uint16) error) error {
+	unspentIndex := dbTx.Metadata().Bucket(UnspentIndexKey)
+	return unspentIndex.ForEach(func(k, v []byte) error {
+		indexes, err := getUint16Array(v)
+		if err != nil {
+			return err
+		}
+		var txHash common.Uint256
+		copy(txHash[:], k)
+		return fn(txHash, indexes)
+	})
+}
+
+// DBCreateSnapshotIndexes uses an existing database transaction to create the
+// buckets of all indexes enabled by the given parameters and sets their tips to
+// the provided block.  It is used when the database is bootstrapped from a
+// snapshot instead of connecting each block, so the indexes hold no history
+// before the block.
+func DBCreateSnapshotIndexes(db database.DB, dbTx database.Tx,
+	params *config.Configuration, hash *common.Uint256, height uint32) error {
+	_, err := dbTx.Metadata().CreateBucketIfNotExists(indexTipsBucketName)
+	if err != nil {
+		return err
+	}
+
+	for _, indexer := range NewManager(db, params).enabledIndexes {
+		if err := indexer.Create(dbTx); err != nil {
+			return err
+		}
+		err := dbPutIndexerTip(dbTx, indexer.Key(), hash, int32(height))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DBPutSnapshotBlockID uses an existing database transaction to assign the
+// internal block ID used by the transaction index to the block with the given
+// hash.  The IDs must be assigned in ascending order starting from one.
+func DBPutSnapshotBlockID(dbTx database.Tx, hash *common.Uint256, id uint32) error {
+	return dbPutBlockIDIndexEntry(dbTx, hash, id)
+}
+
+// DBPutSnapshotTx uses an existing database transaction to add a transaction
+// restored from a snapshot to the transaction index, the transaction lives at
+// the given offset of the block with the given internal ID.  The raw
+// transaction is retained, since the data of the block is not stored.
+func DBPutSnapshotTx(dbTx database.Tx, txHash *common.Uint256, blockID uint32,
+	offset uint32, rawTx []byte) error {
+	serializedData := make([]byte, txEntrySize)
+	putTxIndexEntry(serializedData, blockID, types.TxLoc{
+		TxStart: int(offset),
+		TxLen:   len(rawTx),
+	})
+	if err := dbPutTxIndexEntry(dbTx, txHash, serializedData); err != nil {
+		return err
+	}
+	return DBPutRetainedTx(dbTx, txHash, rawTx)
+}
+
+// DBPutSnapshotBlock uses an existing database transaction to add every
+// transaction of a block restored from a snapshot with its data to the
+// transaction index, and assigns the given internal ID to the block.
+func DBPutSnapshotBlock(dbTx database.Tx, block *types.Block, blockID uint32) error {
+	if err := dbAddTxIndexEntries(dbTx, block, blockID); err != nil {
+		return err
+	}
+	hash := block.Hash()
+	return dbPutBlockIDIndexEntry(dbTx, &hash, blockID)
+}
+
+// DBFetchTxRegion uses an existing database transaction to fetch the block
+// region housing the transaction with the given hash from the transaction
+// index.  When there is no entry for the hash, nil will be returned for both
+// the region and the error.
+func DBFetchTxRegion(dbTx database.Tx, txHash *common.Uint256) (*database.BlockRegion, error) {
+	return dbFetchTxIndexEntry(dbTx, txHash)
+}
@@ -45,6 +45,10 @@ var (
 	// the block hash -> block id index.
 	hashByIDIndexBucketName = []byte("hashbyididx")
 
+	// retainedTxBucketName is the name of the DB bucket used to house the
+	// raw transactions which are kept after their block data is pruned.
+	retainedTxBucketName = []byte("retainedtxs")
+
 	// errNoBlockIDEntry is an error that indicates a requested entry does
 	// not exist in the block ID index.
 	errNoBlockIDEntry = errors.New("no entry in the block ID index")
@@ -296,21 +300,62 @@ func dbRemoveTxIndexEntries(dbTx database.Tx, block *types.Block) error {
 	return nil
 }
 
-// dbFetchTx looks up the passed transaction hash in the transaction index and
-// loads it from the database.
-func dbFetchTx(dbTx database.Tx, hash *common.Uint256) (interfaces.Transaction, *common.Uint256, error) {
+// DBPutRetainedTx stores the raw transaction into the retained transactions
+// bucket, so it is still available after its block data has been pruned.
+func DBPutRetainedTx(dbTx database.Tx, txHash *common.Uint256, rawTx []byte) error {
+	retained, err := dbTx.Metadata().CreateBucketIfNotExists(
+		retainedTxBucketName)
+	if err != nil {
+		return err
+	}
+	return retained.Put(txHash[:], rawTx)
+}
+
+// dbFetchRetainedTx returns the raw transaction from the retained transactions
+// bucket, nil will be returned if the transaction is not retained.
+func dbFetchRetainedTx(dbTx database.Tx, txHash *common.Uint256) []byte {
+	retained := dbTx.Metadata().Bucket(retainedTxBucketName)
+	if retained == nil {
+		return nil
+	}
+	return retained.Get(txHash[:])
+}
 
+// DBFetchRawTx looks up the passed transaction hash in the transaction index
+// and returns the raw transaction bytes along with the block region housing
+// it.  When the block data has been pruned, the transaction is only available
+// if it is retained.
+func DBFetchRawTx(dbTx database.Tx, hash *common.Uint256) ([]byte, *database.BlockRegion, error) {
 	// Look up the location of the transaction.
 	blockRegion, err := dbFetchTxIndexEntry(dbTx, hash)
 	if err != nil {
-		return nil, &common.EmptyHash, err
+		return nil, nil, err
 	}
 	if blockRegion == nil {
-		return nil, &common.EmptyHash, fmt.Errorf("transaction %v not found", hash)
+		return nil, nil, fmt.Errorf("transaction %v not found", hash)
 	}
 
 	// Load the raw transaction bytes from the database.
 	txBytes, err := dbTx.FetchBlockRegion(blockRegion)
+	if dbErr, ok := err.(database.Error); ok &&
+		dbErr.ErrorCode == database.ErrBlockPruned {
+		if rawTx := dbFetchRetainedTx(dbTx, hash); rawTx != nil {
+			txBytes, err = rawTx, nil
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return txBytes, blockRegion, nil
+}
+
+// dbFetchTx looks up the passed transaction hash in the transaction index and
+// loads it from the database.
+func dbFetchTx(dbTx database.Tx, hash *common.Uint256) (interfaces.Transaction, *common.Uint256, error) {
+
+	// Look up the location of the transaction and load its raw bytes.
+	txBytes, blockRegion, err := DBFetchRawTx(dbTx, hash)
 	if err != nil {
 		return nil, &common.EmptyHash, err
 	}
@@ -54,6 +54,7 @@ type IChainStore interface {
 // IChainStore provides func with store package.
 type IFFLDBChainStore interface {
 	database.DB
+	database.Pruner
 
 	// SaveBlock will write block into file DB.
 	SaveBlock(b *Block, node *BlockNode, confirm *payload.Confirm,
@@ -92,6 +93,16 @@ type IFFLDBChainStore interface {
 	// Get utxo by program hash.
 	GetUTXO(programHash *Uint168) ([]*common.UTXO, error)
 
+	// Get transaction history by program hash.
+	GetAddressHistory(programHash *Uint168, skip, count uint32,
+		reverse bool) ([]*indexers.AddressHistory, error)
+
+	// Get count of history entries of the program hash.
+	GetAddressHistoryCount(programHash *Uint168) (uint32, error)
+
+	// Get count of transactions touched the program hash.
+	GetAddressTxCount(programHash *Uint168) (uint32, error)
+
 	// IsTx3Exist use to find if tx3 exist in DB.
 	IsTx3Exist(txHash *Uint256) bool
 
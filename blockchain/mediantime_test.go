@@ -105,3 +105,25 @@ func TestMedianTime(t *testing.T) {
 		}
 	}
 }
+
+func TestSequenceLockActive(t *testing.T) {
+	medianTime := time.Unix(1600000000, 0)
+	tests := []struct {
+		lock   SequenceLock
+		height uint32
+		active bool
+	}{
+		{SequenceLock{Seconds: -1, BlockHeight: -1}, 1, true},
+		{SequenceLock{Seconds: -1, BlockHeight: 99}, 100, true},
+		{SequenceLock{Seconds: -1, BlockHeight: 100}, 100, false},
+		{SequenceLock{Seconds: 1599999999, BlockHeight: -1}, 100, true},
+		{SequenceLock{Seconds: 1600000000, BlockHeight: -1}, 100, false},
+		{SequenceLock{Seconds: 1599999999, BlockHeight: 100}, 100, false},
+	}
+	for i, test := range tests {
+		if active := SequenceLockActive(&test.lock, test.height,
+			medianTime); active != test.active {
+			t.Errorf("test #%d: got %v, want %v", i, active, test.active)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"bytes"
+
+	"github.com/elastos/Elastos.ELA/blockchain/indexers"
+	. "github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/database"
+)
+
+const (
+	// MinPruneTargetSize is the minimum target size in MiB of the stored
+	// block files when pruning is enabled, smaller targets are raised to it.
+	MinPruneTargetSize = 1024
+
+	// pruneMinKeepBlocks is the minimum number of recent blocks whose data
+	// is never pruned, it covers the change history kept by the DPoS and
+	// CR states for rollback with a margin.
+	pruneMinKeepBlocks = 1440
+
+	// pruneCheckInterval is the number of blocks between two attempts to
+	// prune the block files.
+	pruneCheckInterval = 144
+
+	// pruneRetainBatchBlocks is the maximum number of blocks whose
+	// transactions are retained in an attempt to prune, the rest are left to
+	// the next attempts, so accepting a block is never blocked by a scan of
+	// the whole chain.
+	pruneRetainBatchBlocks = 1440
+)
+
+// IsPrunedError returns whether the error is caused by requesting block data
+// which has been removed from disk by pruning.
+func IsPrunedError(err error) bool {
+	dbErr, ok := err.(database.Error)
+	return ok && dbErr.ErrorCode == database.ErrBlockPruned
+}
+
+// IsPruneMode returns whether the node deletes old block files.
+func (b *BlockChain) IsPruneMode() bool {
+	return b.chainParams.PruneTargetSize > 0
+}
+
+// PruneHeight returns the height of the oldest block whose data must be kept.
+// Blocks after the safe height of the checkpoints are replayed to recover the
+// DPoS and CR states on startup, and recent blocks are needed to rollback
+// those states when the chain reorganizes.
+func (b *BlockChain) PruneHeight() uint32 {
+	bestHeight := b.GetHeight()
+	if bestHeight < pruneMinKeepBlocks {
+		return 0
+	}
+
+	height := bestHeight - pruneMinKeepBlocks
+	if b.CkpManager != nil {
+		if safeHeight := b.CkpManager.SafeHeight(); safeHeight < height {
+			height = safeHeight
+		}
+	}
+	return height
+}
+
+// InitPrune retains the transactions of all the blocks which can be pruned
+// when prune mode is enabled, it is called on startup after the checkpoints
+// are initialized.  A database which was not in prune mode is migrated by it,
+// and afterwards each attempt to prune only retains the transactions of a
+// few new blocks.
+func (b *BlockChain) InitPrune(interrupt <-chan struct{}) error {
+	if !b.IsPruneMode() {
+		return nil
+	}
+	_, err := b.retainTxs(b.PruneHeight(), 0, interrupt)
+	return err
+}
+
+// maybePruneBlocks deletes the oldest block files when prune mode is enabled
+// and the stored block files exceed the target size.
+func (b *BlockChain) maybePruneBlocks(height uint32) {
+	if !b.IsPruneMode() || height%pruneCheckInterval != 0 {
+		return
+	}
+
+	targetSize := b.chainParams.PruneTargetSize
+	if targetSize < MinPruneTargetSize {
+		targetSize = MinPruneTargetSize
+	}
+	fflDB := b.db.GetFFLDB()
+	if fflDB.BlocksSize() <= targetSize*1024*1024 {
+		return
+	}
+
+	// The transactions still needed for validation must be retained before
+	// the block files housing them are deleted, only the blocks of which
+	// the transactions have been retained are pruned.
+	retainHeight, err := b.retainTxs(b.PruneHeight(), pruneRetainBatchBlocks,
+		nil)
+	if err != nil {
+		log.Warnf("retain transactions failed, %s", err)
+		return
+	}
+	keepHash, err := b.GetBlockHash(retainHeight)
+	if err != nil {
+		log.Warnf("prune blocks failed, %s", err)
+		return
+	}
+
+	freed, err := fflDB.PruneBlocks(targetSize*1024*1024, &keepHash)
+	if err != nil {
+		log.Warnf("prune blocks failed, %s", err)
+		return
+	}
+	if freed > 0 {
+		log.Infof("pruned %d MiB of block files before height %d",
+			freed/(1024*1024), retainHeight)
+	}
+}
+
+// retainTxs copies the transactions of the blocks before pruneHeight which may
+// still be referenced by new transactions into the retained transactions
+// bucket, so they are available after the block data has been pruned.  These
+// are the transactions with unspent outputs, as well as the transactions
+// spent by the blocks after pruneHeight which might be rolled back.
+//
+// At most maxBlocks blocks are handled if it is not zero, it returns the
+// height below which the transactions of all blocks have been retained.
+func (b *BlockChain) retainTxs(pruneHeight, maxBlocks uint32,
+	interrupt <-chan struct{}) (uint32, error) {
+	fflDB := b.db.GetFFLDB()
+
+	var startHeight uint32
+	err := fflDB.View(func(dbTx database.Tx) error {
+		serializedHeight := dbTx.Metadata().Get(pruneRetainHeightKeyName)
+		if serializedHeight != nil {
+			startHeight = byteOrder.Uint32(serializedHeight)
+		}
+		return nil
+	})
+	if err != nil || startHeight >= pruneHeight {
+		return startHeight, err
+	}
+	endHeight := pruneHeight
+	if maxBlocks > 0 && endHeight-startHeight > maxBlocks {
+		endHeight = startHeight + maxBlocks
+	}
+
+	// Collect the transactions spent by the blocks which are kept, they
+	// become unspent again when these blocks are rolled back.
+	spentTxs := make(map[Uint256]struct{})
+	for height := pruneHeight; height <= b.GetHeight(); height++ {
+		hash, err := b.GetBlockHash(height)
+		if err != nil {
+			return startHeight, err
+		}
+		block, err := fflDB.GetBlock(hash)
+		if err != nil {
+			return startHeight, err
+		}
+		for _, tx := range block.Transactions {
+			for _, input := range tx.Inputs() {
+				spentTxs[input.Previous.TxID] = struct{}{}
+			}
+		}
+	}
+
+	log.Infof("retaining transactions of blocks from height %d to %d",
+		startHeight, endHeight-1)
+	for height := startHeight; height < endHeight; height++ {
+		if interruptRequested(interrupt) {
+			return height, errInterruptRequested
+		}
+		hash, err := b.GetBlockHash(height)
+		if err != nil {
+			return height, err
+		}
+		block, err := fflDB.GetBlock(hash)
+		if err != nil {
+			return height, err
+		}
+
+		err = fflDB.Update(func(dbTx database.Tx) error {
+			for _, tx := range block.Transactions {
+				txHash := tx.Hash()
+				unspent, err := indexers.DBFetchUnspentIndexEntry(dbTx,
+					&txHash)
+				if err != nil {
+					return err
+				}
+				if _, ok := spentTxs[txHash]; !ok && len(unspent) == 0 {
+					continue
+				}
+
+				buf := new(bytes.Buffer)
+				if err := tx.Serialize(buf); err != nil {
+					return err
+				}
+				err = indexers.DBPutRetainedTx(dbTx, &txHash, buf.Bytes())
+				if err != nil {
+					return err
+				}
+			}
+
+			var serializedHeight [4]byte
+			byteOrder.PutUint32(serializedHeight[:], height+1)
+			return dbTx.Metadata().Put(pruneRetainHeightKeyName,
+				serializedHeight[:])
+		})
+		if err != nil {
+			return height, err
+		}
+	}
+
+	return endHeight, nil
+}
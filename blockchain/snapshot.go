@@ -0,0 +1,1028 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/elastos/Elastos.ELA/blockchain/indexers"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/checkpoint"
+	"github.com/elastos/Elastos.ELA/core/types"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/database"
+	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+const (
+	// snapshotVersion is the version of the snapshot file format.
+	snapshotVersion = 1
+
+	// snapshotRecentBlocks is the number of recent blocks whose data is
+	// included in a snapshot, they are needed to rollback the DPoS and CR
+	// states when the chain reorganizes.
+	snapshotRecentBlocks = pruneMinKeepBlocks
+
+	// snapshotBatchSize is the number of entries written into the database
+	// with one database transaction when a snapshot is loaded.
+	snapshotBatchSize = 5000
+
+	// snapshotMaxCheckpointSize is the max size of the serialized data of a
+	// checkpoint in a snapshot.
+	snapshotMaxCheckpointSize = 1 << 30
+)
+
+// snapshotBuckets are the metadata buckets holding consensus data which can
+// not be derived from the UTXO set, they are copied into snapshots as is.
+var snapshotBuckets = [][]byte{
+	common.Tx3IndexBucketName,
+	common.ProposalDraftDataBucketName,
+	indexers.ReturnDepositIndexKey,
+}
+
+// The snapshot file holds the state of the chain at a height, it is used to
+// bootstrap a new node without downloading and validating all blocks.  The
+// format of the file is:
+//
+//  Header       magic, version, height, block hash and the height of the
+//               first recent block (uint32, uint32, uint32, 32 bytes, uint32)
+//  Headers      count followed by the block index row of every block from
+//               the genesis block to the snapshot height
+//  Transactions count followed by the height, block offset, raw data and
+//               unspent output indexes of every transaction with unspent
+//               outputs, or spent by the recent blocks, in height order
+//  Buckets      count followed by the name and key/value pairs of every
+//               bucket in snapshotBuckets
+//  Blocks       count followed by the serialized DposBlocks from the first
+//               recent block to the snapshot height
+//  Checkpoints  count followed by the key and data of every checkpoint
+//  Trailer      SHA-256 of all of the preceding bytes (32 bytes)
+
+// SnapshotInfo describes the content of a snapshot file.
+type SnapshotInfo struct {
+	// Height is the height of the chain in the snapshot.
+	Height uint32
+
+	// Hash is the hash of the block at the snapshot height.
+	Hash common.Uint256
+
+	// ContentHash is the SHA-256 of the snapshot content, it is written as
+	// the trailer of the file and identifies the snapshot.
+	ContentHash [sha256.Size]byte
+
+	// Transactions is the number of transactions in the snapshot.
+	Transactions uint32
+
+	// Blocks is the number of recent blocks in the snapshot.
+	Blocks uint32
+
+	// Checkpoints are the keys of the checkpoints in the snapshot.
+	Checkpoints []string
+}
+
+// snapshotRecentStart returns the height of the first recent block whose data
+// is included in a snapshot at the given height.
+func snapshotRecentStart(height uint32) uint32 {
+	if height < snapshotRecentBlocks {
+		return 1
+	}
+	return height - snapshotRecentBlocks + 1
+}
+
+// snapshotTx is a transaction included in a snapshot.
+type snapshotTx struct {
+	hash    common.Uint256
+	height  uint32
+	unspent []uint16
+}
+
+// DumpSnapshot writes the snapshot of the current best chain into w.  No block
+// is processed while the snapshot is written, so the UTXO set and the states
+// in the checkpoints stay at the same height.
+func (b *BlockChain) DumpSnapshot(w io.Writer) (*SnapshotInfo, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.CkpManager == nil {
+		return nil, errors.New("checkpoint manager is not available")
+	}
+	node := b.GetBlockNode(b.GetHeight())
+	info := &SnapshotInfo{Height: node.Height, Hash: *node.Hash}
+	recentStart := snapshotRecentStart(info.Height)
+
+	checkpoints, err := b.CkpManager.Snapshots(info.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	hasher := sha256.New()
+	mw := io.MultiWriter(w, hasher)
+	err = b.db.GetFFLDB().View(func(dbTx database.Tx) error {
+		if err := common.WriteElements(mw, b.chainParams.Magic,
+			uint32(snapshotVersion), info.Height); err != nil {
+			return err
+		}
+		if err := info.Hash.Serialize(mw); err != nil {
+			return err
+		}
+		if err := common.WriteUint32(mw, recentStart); err != nil {
+			return err
+		}
+
+		if err := writeSnapshotHeaders(dbTx, mw, info.Height); err != nil {
+			return err
+		}
+
+		txs, err := collectSnapshotTxs(dbTx, recentStart, info.Height)
+		if err != nil {
+			return err
+		}
+		info.Transactions = uint32(len(txs))
+		if err := writeSnapshotTxs(dbTx, mw, txs); err != nil {
+			return err
+		}
+
+		if err := writeSnapshotBuckets(dbTx, mw); err != nil {
+			return err
+		}
+
+		info.Blocks = info.Height - recentStart + 1
+		err = common.WriteVarUint(mw, uint64(info.Blocks))
+		if err != nil {
+			return err
+		}
+		for height := recentStart; height <= info.Height; height++ {
+			rawBlock, err := dbTx.FetchBlock(b.GetBlockNode(height).Hash)
+			if err != nil {
+				return fmt.Errorf("fetch block %d failed, %s", height, err)
+			}
+			if err := common.WriteVarBytes(mw, rawBlock); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = common.WriteVarUint(mw, uint64(len(checkpoints)))
+	if err != nil {
+		return nil, err
+	}
+	for _, ckp := range checkpoints {
+		buf := new(bytes.Buffer)
+		if err := ckp.Serialize(buf); err != nil {
+			return nil, err
+		}
+		if err := common.WriteVarString(mw, ckp.Key()); err != nil {
+			return nil, err
+		}
+		if err := common.WriteVarBytes(mw, buf.Bytes()); err != nil {
+			return nil, err
+		}
+		info.Checkpoints = append(info.Checkpoints, ckp.Key())
+	}
+
+	copy(info.ContentHash[:], hasher.Sum(nil))
+	if _, err := w.Write(info.ContentHash[:]); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// writeSnapshotHeaders writes the block index rows of the main chain blocks
+// up to the given height.
+func writeSnapshotHeaders(dbTx database.Tx, w io.Writer, height uint32) error {
+	if err := common.WriteVarUint(w, uint64(height)+1); err != nil {
+		return err
+	}
+
+	meta := dbTx.Metadata()
+	heightIndex := meta.Bucket(heightIndexBucketName)
+	blockIndexBucket := meta.Bucket(blockIndexBucketName)
+	var serializedHeight [4]byte
+	for h := uint32(0); h <= height; h++ {
+		byteOrder.PutUint32(serializedHeight[:], h)
+		var hash common.Uint256
+		copy(hash[:], heightIndex.Get(serializedHeight[:]))
+		blockRow := blockIndexBucket.Get(blockIndexKey(&hash, h))
+		if blockRow == nil {
+			return fmt.Errorf("block %d is not in the block index", h)
+		}
+		if err := common.WriteVarBytes(w, blockRow); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectSnapshotTxs returns the transactions with unspent outputs, and the
+// transactions before the recent blocks spent by them, sorted by height.  The
+// latter become unspent again when the recent blocks are rolled back.
+func collectSnapshotTxs(dbTx database.Tx, recentStart,
+	height uint32) ([]*snapshotTx, error) {
+	var txs []*snapshotTx
+	err := indexers.DBForEachUnspent(dbTx, func(txHash common.Uint256,
+		indexes []uint16) error {
+		txHeight, err := dbFetchTxHeight(dbTx, &txHash)
+		if err != nil {
+			return err
+		}
+		txs = append(txs, &snapshotTx{
+			hash:    txHash,
+			height:  txHeight,
+			unspent: indexes,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	spent := make(map[common.Uint256]struct{})
+	for h := recentStart; h <= height; h++ {
+		hash, err := dbFetchHashByHeight(dbTx, h)
+		if err != nil {
+			return nil, err
+		}
+		rawBlock, err := dbTx.FetchBlock(hash)
+		if err != nil {
+			return nil, fmt.Errorf("fetch block %d failed, %s", h, err)
+		}
+		var block types.Block
+		err = block.Deserialize(bytes.NewReader(rawBlock))
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range block.Transactions {
+			for _, input := range tx.Inputs() {
+				txHash := input.Previous.TxID
+				if _, ok := spent[txHash]; ok {
+					continue
+				}
+				spent[txHash] = struct{}{}
+
+				unspent, err := indexers.DBFetchUnspentIndexEntry(dbTx, &txHash)
+				if err != nil {
+					return nil, err
+				}
+				if len(unspent) > 0 {
+					continue
+				}
+				txHeight, err := dbFetchTxHeight(dbTx, &txHash)
+				if err != nil {
+					return nil, err
+				}
+				if txHeight < recentStart {
+					txs = append(txs, &snapshotTx{hash: txHash, height: txHeight})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].height < txs[j].height
+	})
+	return txs, nil
+}
+
+// writeSnapshotTxs writes the given transactions with their data.
+func writeSnapshotTxs(dbTx database.Tx, w io.Writer, txs []*snapshotTx) error {
+	if err := common.WriteVarUint(w, uint64(len(txs))); err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		rawTx, region, err := indexers.DBFetchRawTx(dbTx, &tx.hash)
+		if err != nil {
+			return err
+		}
+		if err := common.WriteElements(w, tx.height, region.Offset); err != nil {
+			return err
+		}
+		if err := common.WriteVarBytes(w, rawTx); err != nil {
+			return err
+		}
+		if err := common.WriteVarUint(w, uint64(len(tx.unspent))); err != nil {
+			return err
+		}
+		for _, index := range tx.unspent {
+			if err := common.WriteUint16(w, index); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeSnapshotBuckets writes the key/value pairs of the snapshot buckets.
+func writeSnapshotBuckets(dbTx database.Tx, w io.Writer) error {
+	if err := common.WriteVarUint(w, uint64(len(snapshotBuckets))); err != nil {
+		return err
+	}
+	for _, name := range snapshotBuckets {
+		var keys, values [][]byte
+		if bucket := dbTx.Metadata().Bucket(name); bucket != nil {
+			err := bucket.ForEach(func(k, v []byte) error {
+				keys = append(keys, k)
+				values = append(values, v)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := common.WriteVarBytes(w, name); err != nil {
+			return err
+		}
+		if err := common.WriteVarUint(w, uint64(len(keys))); err != nil {
+			return err
+		}
+		for i := range keys {
+			if err := common.WriteVarBytes(w, keys[i]); err != nil {
+				return err
+			}
+			if err := common.WriteVarBytes(w, values[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dbFetchHashByHeight uses an existing database transaction to retrieve the
+// hash of the main chain block at the given height.
+func dbFetchHashByHeight(dbTx database.Tx, height uint32) (*common.Uint256, error) {
+	var serializedHeight [4]byte
+	byteOrder.PutUint32(serializedHeight[:], height)
+	heightIndex := dbTx.Metadata().Bucket(heightIndexBucketName)
+	hashBytes := heightIndex.Get(serializedHeight[:])
+	if hashBytes == nil {
+		return nil, fmt.Errorf("no block at height %d exists", height)
+	}
+
+	var hash common.Uint256
+	copy(hash[:], hashBytes)
+	return &hash, nil
+}
+
+// dbFetchTxHeight uses an existing database transaction to retrieve the height
+// of the block housing the transaction with the given hash.
+func dbFetchTxHeight(dbTx database.Tx, txHash *common.Uint256) (uint32, error) {
+	region, err := indexers.DBFetchTxRegion(dbTx, txHash)
+	if err != nil {
+		return 0, err
+	}
+	if region == nil {
+		return 0, fmt.Errorf("transaction %s not found", txHash)
+	}
+	return dbFetchHeightByHash(dbTx, region.Hash)
+}
+
+// snapshotHandler receives the content of a snapshot file when it is decoded.
+type snapshotHandler interface {
+	onInfo(info *SnapshotInfo, recentStart uint32) error
+	onHeader(header *common2.Header, status blockStatus) error
+	onTx(height, offset uint32, rawTx []byte, unspent []uint16) error
+	onBucketEntry(name, key, value []byte) error
+	onBlock(block *types.DposBlock) error
+	onCheckpoint(key string, data []byte) error
+}
+
+// decodeSnapshot reads a snapshot file from r, checks it is consistent and
+// passes its content to the handler.  The trailer is checked at last, so a
+// file must be verified with VerifySnapshot before its content is written
+// anywhere.
+func decodeSnapshot(r io.Reader, params *config.Configuration,
+	h snapshotHandler) (*SnapshotInfo, error) {
+	hasher := sha256.New()
+	tr := io.TeeReader(r, hasher)
+
+	var magic, version uint32
+	info := &SnapshotInfo{}
+	if err := common.ReadElements(tr, &magic, &version,
+		&info.Height); err != nil {
+		return nil, err
+	}
+	if magic != params.Magic {
+		return nil, fmt.Errorf("unexpected network magic %d, expect %d",
+			magic, params.Magic)
+	}
+	if version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", version)
+	}
+	if err := info.Hash.Deserialize(tr); err != nil {
+		return nil, err
+	}
+	recentStart, err := common.ReadUint32(tr)
+	if err != nil {
+		return nil, err
+	}
+	if recentStart != snapshotRecentStart(info.Height) {
+		return nil, fmt.Errorf("invalid recent block height %d",
+			recentStart)
+	}
+	if err := h.onInfo(info, recentStart); err != nil {
+		return nil, err
+	}
+
+	// Headers must link from the genesis block to the snapshot block.
+	count, err := common.ReadVarUint(tr, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count != uint64(info.Height)+1 {
+		return nil, fmt.Errorf("unexpected header count %d", count)
+	}
+	var prevHash common.Uint256
+	for height := uint32(0); height <= info.Height; height++ {
+		blockRow, err := common.ReadVarBytes(tr, pact.MaxBlockContextSize,
+			"block row")
+		if err != nil {
+			return nil, err
+		}
+		header, status, err := DeserializeBlockRow(blockRow)
+		if err != nil {
+			return nil, err
+		}
+		hash := header.Hash()
+		if header.Height != height {
+			return nil, fmt.Errorf("header %s has height %d, expect %d",
+				hash, header.Height, height)
+		}
+		if height == 0 && !hash.IsEqual(params.GenesisBlock.Hash()) {
+			return nil, fmt.Errorf("genesis block %s does not match the "+
+				"network", hash)
+		}
+		if height > 0 && !header.Previous.IsEqual(prevHash) {
+			return nil, fmt.Errorf("header %d does not link to the "+
+				"previous header", height)
+		}
+		if err := h.onHeader(header, status); err != nil {
+			return nil, err
+		}
+		prevHash = hash
+	}
+	if !prevHash.IsEqual(info.Hash) {
+		return nil, fmt.Errorf("last header %s does not match the snapshot "+
+			"block %s", prevHash, info.Hash)
+	}
+
+	// Transactions must be sorted by height.
+	count, err = common.ReadVarUint(tr, 0)
+	if err != nil {
+		return nil, err
+	}
+	var lastHeight uint32
+	for i := uint64(0); i < count; i++ {
+		var height, offset uint32
+		if err := common.ReadElements(tr, &height, &offset); err != nil {
+			return nil, err
+		}
+		if height > info.Height || height < lastHeight {
+			return nil, fmt.Errorf("transaction at unexpected height %d",
+				height)
+		}
+		lastHeight = height
+		rawTx, err := common.ReadVarBytes(tr, pact.MaxBlockContextSize,
+			"transaction")
+		if err != nil {
+			return nil, err
+		}
+		n, err := common.ReadVarUint(tr, 0)
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(rawTx)) {
+			return nil, fmt.Errorf("invalid unspent output count %d", n)
+		}
+		unspent := make([]uint16, 0, n)
+		for j := uint64(0); j < n; j++ {
+			index, err := common.ReadUint16(tr)
+			if err != nil {
+				return nil, err
+			}
+			unspent = append(unspent, index)
+		}
+		if err := h.onTx(height, offset, rawTx, unspent); err != nil {
+			return nil, err
+		}
+		info.Transactions++
+	}
+
+	count, err = common.ReadVarUint(tr, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count != uint64(len(snapshotBuckets)) {
+		return nil, fmt.Errorf("unexpected bucket count %d", count)
+	}
+	for _, expectName := range snapshotBuckets {
+		name, err := common.ReadVarBytes(tr, pact.MaxBlockContextSize,
+			"bucket name")
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(name, expectName) {
+			return nil, fmt.Errorf("unexpected bucket %s", name)
+		}
+		n, err := common.ReadVarUint(tr, 0)
+		if err != nil {
+			return nil, err
+		}
+		for j := uint64(0); j < n; j++ {
+			key, err := common.ReadVarBytes(tr, pact.MaxBlockContextSize,
+				"bucket key")
+			if err != nil {
+				return nil, err
+			}
+			value, err := common.ReadVarBytes(tr, pact.MaxBlockContextSize,
+				"bucket value")
+			if err != nil {
+				return nil, err
+			}
+			if err := h.onBucketEntry(name, key, value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// Recent blocks must match the headers, the handler checks the hashes.
+	count, err = common.ReadVarUint(tr, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count != uint64(info.Height-recentStart+1) {
+		return nil, fmt.Errorf("unexpected block count %d", count)
+	}
+	for height := recentStart; height <= info.Height; height++ {
+		rawBlock, err := common.ReadVarBytes(tr, p2p.MaxMessagePayload,
+			"block")
+		if err != nil {
+			return nil, err
+		}
+		var block types.DposBlock
+		if err := block.Deserialize(bytes.NewReader(rawBlock)); err != nil {
+			return nil, err
+		}
+		if block.Height != height {
+			return nil, fmt.Errorf("block %s has height %d, expect %d",
+				block.Hash(), block.Height, height)
+		}
+		if err := h.onBlock(&block); err != nil {
+			return nil, err
+		}
+		info.Blocks++
+	}
+
+	count, err = common.ReadVarUint(tr, 0)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < count; i++ {
+		key, err := common.ReadVarString(tr)
+		if err != nil {
+			return nil, err
+		}
+		data, err := common.ReadVarBytes(tr, snapshotMaxCheckpointSize,
+			"checkpoint")
+		if err != nil {
+			return nil, err
+		}
+		if err := h.onCheckpoint(key, data); err != nil {
+			return nil, err
+		}
+		info.Checkpoints = append(info.Checkpoints, key)
+	}
+
+	var trailer [sha256.Size]byte
+	if _, err := io.ReadFull(r, trailer[:]); err != nil {
+		return nil, err
+	}
+	copy(info.ContentHash[:], hasher.Sum(nil))
+	if trailer != info.ContentHash {
+		return nil, errors.New("snapshot content hash mismatch")
+	}
+	return info, nil
+}
+
+// snapshotVerifier is a snapshotHandler which only checks the recent blocks
+// match the headers.
+type snapshotVerifier struct {
+	hashes []common.Uint256
+}
+
+func (v *snapshotVerifier) onInfo(info *SnapshotInfo, recentStart uint32) error {
+	return nil
+}
+
+func (v *snapshotVerifier) onHeader(header *common2.Header, status blockStatus) error {
+	v.hashes = append(v.hashes, header.Hash())
+	return nil
+}
+
+func (v *snapshotVerifier) onTx(height, offset uint32, rawTx []byte,
+	unspent []uint16) error {
+	return nil
+}
+
+func (v *snapshotVerifier) onBucketEntry(name, key, value []byte) error {
+	return nil
+}
+
+func (v *snapshotVerifier) onBlock(block *types.DposBlock) error {
+	if !block.Hash().IsEqual(v.hashes[block.Height]) {
+		return fmt.Errorf("block %d does not match the header", block.Height)
+	}
+	return nil
+}
+
+func (v *snapshotVerifier) onCheckpoint(key string, data []byte) error {
+	return nil
+}
+
+// VerifySnapshot reads a snapshot file from r and checks it is complete and
+// consistent, the returned content hash must be compared with a trusted one.
+// The headers are only checked to link together, their proof of work is not
+// validated.
+func VerifySnapshot(r io.Reader, params *config.Configuration) (*SnapshotInfo, error) {
+	return decodeSnapshot(r, params, &snapshotVerifier{})
+}
+
+// snapshotLoader is a snapshotHandler which writes the content of a snapshot
+// into an empty chain database.
+type snapshotLoader struct {
+	snapshotVerifier
+	db          IFFLDBChainStore
+	params      *config.Configuration
+	ckpManager  *checkpoint.Manager
+	dbTx        database.Tx
+	pending     int
+	height      uint32
+	recentStart uint32
+	workSum     *big.Int
+	nextBlockID uint32
+	lastTxBlock int64
+	pruned      []common.Uint256
+	utxoHeight  uint32
+	utxos       map[common.Uint168][]*common2.UTXO
+}
+
+// commit commits the current database transaction and begins a new one.
+func (l *snapshotLoader) commit() error {
+	if err := l.flushUtxos(); err != nil {
+		return err
+	}
+	if err := l.dbTx.Commit(); err != nil {
+		return err
+	}
+	l.dbTx = nil
+	l.pending = 0
+
+	// Blocks before the recent blocks are never stored, mark them as
+	// pruned so they are treated as the blocks removed by pruning.
+	if len(l.pruned) > 0 {
+		if err := l.db.StorePrunedBlocks(l.pruned); err != nil {
+			return err
+		}
+		l.pruned = nil
+	}
+
+	dbTx, err := l.db.Begin(true)
+	if err != nil {
+		return err
+	}
+	l.dbTx = dbTx
+	return nil
+}
+
+// added counts a new entry written into the database and commits the database
+// transaction when there are enough entries.
+func (l *snapshotLoader) added() error {
+	l.pending++
+	if l.pending < snapshotBatchSize {
+		return nil
+	}
+	return l.commit()
+}
+
+// flushUtxos writes the utxo index entries of the current height.
+func (l *snapshotLoader) flushUtxos() error {
+	for programHash, utxos := range l.utxos {
+		err := indexers.DBPutUtxoIndexEntry(l.dbTx, &programHash,
+			l.utxoHeight, utxos)
+		if err != nil {
+			return err
+		}
+	}
+	l.utxos = make(map[common.Uint168][]*common2.UTXO)
+	return nil
+}
+
+func (l *snapshotLoader) onInfo(info *SnapshotInfo, recentStart uint32) error {
+	l.height = info.Height
+	l.recentStart = recentStart
+	return indexers.DBCreateSnapshotIndexes(l.db, l.dbTx, l.params,
+		&info.Hash, info.Height)
+}
+
+func (l *snapshotLoader) onHeader(header *common2.Header, status blockStatus) error {
+	if err := l.snapshotVerifier.onHeader(header, status); err != nil {
+		return err
+	}
+	hash := header.Hash()
+	if err := DBStoreBlockNode(l.dbTx, header, status); err != nil {
+		return err
+	}
+	if err := dbPutBlockIndex(l.dbTx, &hash, header.Height); err != nil {
+		return err
+	}
+	l.workSum.Add(l.workSum, CalcWork(header.Bits))
+	if header.Height > 0 && header.Height < l.recentStart {
+		l.pruned = append(l.pruned, hash)
+	}
+	return l.added()
+}
+
+func (l *snapshotLoader) onTx(height, offset uint32, rawTx []byte,
+	unspent []uint16) error {
+	r := bytes.NewReader(rawTx)
+	tx, err := functions.GetTransactionByBytes(r)
+	if err != nil {
+		return err
+	}
+	if err := tx.Deserialize(r); err != nil {
+		return err
+	}
+	txHash := tx.Hash()
+
+	// The transactions of the recent blocks are indexed with the blocks,
+	// the others get a block ID for each height in ascending order.
+	if height < l.recentStart {
+		if int64(height) != l.lastTxBlock {
+			l.nextBlockID++
+			l.lastTxBlock = int64(height)
+			err := indexers.DBPutSnapshotBlockID(l.dbTx, &l.hashes[height],
+				l.nextBlockID)
+			if err != nil {
+				return err
+			}
+		}
+		err := indexers.DBPutSnapshotTx(l.dbTx, &txHash, l.nextBlockID,
+			offset, rawTx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(unspent) > 0 {
+		if err := indexers.DBPutUnspentIndexEntry(l.dbTx, &txHash,
+			unspent); err != nil {
+			return err
+		}
+	}
+
+	if height != l.utxoHeight {
+		if err := l.flushUtxos(); err != nil {
+			return err
+		}
+		l.utxoHeight = height
+	}
+	outputs := tx.Outputs()
+	for _, index := range unspent {
+		if int(index) >= len(outputs) {
+			return fmt.Errorf("transaction %s has no output %d", txHash,
+				index)
+		}
+		output := outputs[index]
+		if output.Value == 0 {
+			continue
+		}
+		utxos, ok := l.utxos[output.ProgramHash]
+		if !ok {
+			utxos, err = indexers.DBFetchUtxoIndexEntryByHeight(l.dbTx,
+				&output.ProgramHash, height)
+			if err != nil {
+				return err
+			}
+		}
+		l.utxos[output.ProgramHash] = append(utxos, &common2.UTXO{
+			TxID:  txHash,
+			Index: index,
+			Value: output.Value,
+		})
+	}
+	return l.added()
+}
+
+func (l *snapshotLoader) onBucketEntry(name, key, value []byte) error {
+	bucket, err := l.dbTx.Metadata().CreateBucketIfNotExists(name)
+	if err != nil {
+		return err
+	}
+	if err := bucket.Put(key, value); err != nil {
+		return err
+	}
+	return l.added()
+}
+
+func (l *snapshotLoader) onBlock(block *types.DposBlock) error {
+	if err := l.snapshotVerifier.onBlock(block); err != nil {
+		return err
+	}
+	if err := dbStoreBlock(l.dbTx, block); err != nil {
+		return err
+	}
+	l.nextBlockID++
+	err := indexers.DBPutSnapshotBlock(l.dbTx, block.Block, l.nextBlockID)
+	if err != nil {
+		return err
+	}
+	return l.commit()
+}
+
+func (l *snapshotLoader) onCheckpoint(key string, data []byte) error {
+	return l.ckpManager.ImportCheckpoint(key, l.height, data)
+}
+
+// LoadSnapshotFile bootstraps an empty chain database from the snapshot file
+// at the given path.  The file is verified completely before anything is
+// written, and its content hash must match params.SnapshotHash when it is set.
+// It returns nil info without error when the chain database has already been
+// initialized, so the snapshot is only loaded on the first start.
+//
+// The blocks before the recent blocks of the snapshot are never downloaded,
+// they are treated as pruned blocks, and the indexes hold no history before
+// the snapshot height.
+func LoadSnapshotFile(db IChainStore, params *config.Configuration,
+	ckpManager *checkpoint.Manager, path string) (*SnapshotInfo, error) {
+	fflDB := db.GetFFLDB()
+	var initialized bool
+	err := fflDB.View(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		initialized = meta.Get(chainStateKeyName) != nil ||
+			meta.Bucket(blockIndexBucketName) != nil
+		return nil
+	})
+	if err != nil || initialized {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	log.Infof("verifying snapshot %s", path)
+	info, err := VerifySnapshot(bufio.NewReader(file), params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot, %s", err)
+	}
+	contentHash := hex.EncodeToString(info.ContentHash[:])
+	if params.SnapshotHash != "" && params.SnapshotHash != contentHash {
+		return nil, fmt.Errorf("snapshot content hash %s does not match "+
+			"the expected hash %s", contentHash, params.SnapshotHash)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	log.Infof("loading snapshot at height %d", info.Height)
+	dbTx, err := fflDB.Begin(true)
+	if err != nil {
+		return nil, err
+	}
+	loader := &snapshotLoader{
+		db:          fflDB,
+		params:      params,
+		ckpManager:  ckpManager,
+		dbTx:        dbTx,
+		workSum:     new(big.Int),
+		lastTxBlock: -1,
+		utxos:       make(map[common.Uint168][]*common2.UTXO),
+	}
+	err = dbCreateChainBuckets(dbTx)
+	if err == nil {
+		err = dbStoreBlock(dbTx, &types.DposBlock{Block: params.GenesisBlock})
+	}
+	if err == nil {
+		_, err = decodeSnapshot(bufio.NewReader(file), params, loader)
+	}
+	if err == nil {
+		// The best state is written at last, so the database is only
+		// considered as initialized when the snapshot is fully loaded.
+		err = loader.finish(info)
+	}
+	if err != nil {
+		if loader.dbTx != nil {
+			loader.dbTx.Rollback()
+		}
+		return nil, fmt.Errorf("load snapshot failed, remove the data "+
+			"directory before trying again, %s", err)
+	}
+
+	log.Infof("loaded snapshot at height %d with %d transactions",
+		info.Height, info.Transactions)
+	return info, nil
+}
+
+// finish writes the best state of the loaded snapshot.
+func (l *snapshotLoader) finish(info *SnapshotInfo) error {
+	if err := l.flushUtxos(); err != nil {
+		return err
+	}
+
+	var serializedHeight [4]byte
+	byteOrder.PutUint32(serializedHeight[:], info.Height)
+	meta := l.dbTx.Metadata()
+	if err := meta.Put(snapshotHeightKeyName, serializedHeight[:]); err != nil {
+		return err
+	}
+
+	// The transactions needed by the recent blocks are retained already.
+	byteOrder.PutUint32(serializedHeight[:], l.recentStart)
+	if err := meta.Put(pruneRetainHeightKeyName, serializedHeight[:]); err != nil {
+		return err
+	}
+
+	err := dbPutBestState(l.dbTx, &BestState{Hash: info.Hash,
+		Height: info.Height}, l.workSum)
+	if err != nil {
+		return err
+	}
+	if err := l.dbTx.Commit(); err != nil {
+		return err
+	}
+	l.dbTx = nil
+	if len(l.pruned) > 0 {
+		return l.db.StorePrunedBlocks(l.pruned)
+	}
+	return nil
+}
+
+// SnapshotHeight returns the height of the snapshot the chain database was
+// bootstrapped from, and whether it was bootstrapped from a snapshot.
+func (b *BlockChain) SnapshotHeight() (uint32, bool) {
+	var height uint32
+	var ok bool
+	b.db.GetFFLDB().View(func(dbTx database.Tx) error {
+		serializedHeight := dbTx.Metadata().Get(snapshotHeightKeyName)
+		if serializedHeight != nil {
+			height, ok = byteOrder.Uint32(serializedHeight), true
+		}
+		return nil
+	})
+	return height, ok
+}
+
+// VerifySnapshotHeaders validates the headers of the blocks up to the height
+// of the snapshot the chain was bootstrapped from, they were accepted without
+// validation.  The difficulty and the timestamp of each header are checked in
+// the same way as a received block.  Only the headers are validated, the UTXO
+// set and the checkpoints of the snapshot are not replayed from blocks, so
+// they are trusted by the content hash of the snapshot.
+func (b *BlockChain) VerifySnapshotHeaders(interrupt <-chan struct{}) error {
+	snapshotHeight, ok := b.SnapshotHeight()
+	if !ok {
+		return nil
+	}
+
+	log.Infof("verifying headers of the snapshot up to height %d",
+		snapshotHeight)
+	for height := uint32(1); height <= snapshotHeight; height++ {
+		if interruptRequested(interrupt) {
+			return errInterruptRequested
+		}
+
+		node := b.GetBlockNode(height)
+		header, err := b.db.GetFFLDB().GetHeader(*node.Hash)
+		if err != nil {
+			return err
+		}
+		if err := b.CheckHeaderContext(header, node.Parent); err != nil {
+			return fmt.Errorf("header of block %d is invalid, %s", height,
+				err)
+		}
+	}
+	log.Infof("headers of the snapshot up to height %d are valid",
+		snapshotHeight)
+	return nil
+}
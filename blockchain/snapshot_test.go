@@ -0,0 +1,109 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core/types"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// encodeTestSnapshot encodes a snapshot of the given headers, the blocks of
+// all headers except the genesis block are included as recent blocks.
+func encodeTestSnapshot(t *testing.T, magic uint32,
+	headers []*common2.Header) []byte {
+	buf := new(bytes.Buffer)
+	height := uint32(len(headers) - 1)
+	last := headers[height].Hash()
+	assert.NoError(t, common.WriteElements(buf, magic,
+		uint32(snapshotVersion), height))
+	assert.NoError(t, last.Serialize(buf))
+	assert.NoError(t, common.WriteUint32(buf, snapshotRecentStart(height)))
+
+	assert.NoError(t, common.WriteVarUint(buf, uint64(len(headers))))
+	for _, header := range headers {
+		row := new(bytes.Buffer)
+		assert.NoError(t, header.SerializeNoAux(row))
+		row.WriteByte(byte(statusDataStored | statusValid))
+		assert.NoError(t, common.WriteVarBytes(buf, row.Bytes()))
+	}
+
+	assert.NoError(t, common.WriteVarUint(buf, 0))
+
+	assert.NoError(t, common.WriteVarUint(buf, uint64(len(snapshotBuckets))))
+	for _, name := range snapshotBuckets {
+		assert.NoError(t, common.WriteVarBytes(buf, name))
+		assert.NoError(t, common.WriteVarUint(buf, 0))
+	}
+
+	assert.NoError(t, common.WriteVarUint(buf, uint64(height)))
+	for _, header := range headers[1:] {
+		block := &types.DposBlock{Block: &types.Block{Header: *header}}
+		raw := new(bytes.Buffer)
+		assert.NoError(t, block.Serialize(raw))
+		assert.NoError(t, common.WriteVarBytes(buf, raw.Bytes()))
+	}
+
+	assert.NoError(t, common.WriteVarUint(buf, 1))
+	assert.NoError(t, common.WriteVarString(buf, "dpos"))
+	assert.NoError(t, common.WriteVarBytes(buf, []byte{1, 2, 3}))
+
+	sum := sha256.Sum256(buf.Bytes())
+	buf.Write(sum[:])
+	return buf.Bytes()
+}
+
+func TestVerifySnapshot(t *testing.T) {
+	genesis := &types.Block{Header: common2.Header{Timestamp: 1}}
+	params := &config.Configuration{Magic: 2017001, GenesisBlock: genesis}
+
+	headers := []*common2.Header{&genesis.Header}
+	for height := uint32(1); height <= 3; height++ {
+		headers = append(headers, &common2.Header{
+			Previous:  headers[height-1].Hash(),
+			Timestamp: height + 1,
+			Height:    height,
+		})
+	}
+
+	data := encodeTestSnapshot(t, params.Magic, headers)
+	info, err := VerifySnapshot(bytes.NewReader(data), params)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(3), info.Height)
+	assert.Equal(t, headers[3].Hash(), info.Hash)
+	assert.Equal(t, uint32(3), info.Blocks)
+	assert.Equal(t, []string{"dpos"}, info.Checkpoints)
+	assert.Equal(t, sha256.Sum256(data[:len(data)-sha256.Size]),
+		info.ContentHash)
+
+	// Snapshots of another network are rejected.
+	_, err = VerifySnapshot(bytes.NewReader(
+		encodeTestSnapshot(t, 2018101, headers)), params)
+	assert.Error(t, err)
+
+	// Any change of the content is detected by the trailer.
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-sha256.Size-1] ^= 0xff
+	_, err = VerifySnapshot(bytes.NewReader(tampered), params)
+	assert.Error(t, err)
+
+	// A truncated snapshot is rejected.
+	_, err = VerifySnapshot(bytes.NewReader(data[:len(data)-1]), params)
+	assert.Error(t, err)
+
+	// Headers must link to each other.
+	headers[2].Previous = common.Uint256{1}
+	_, err = VerifySnapshot(bytes.NewReader(
+		encodeTestSnapshot(t, params.Magic, headers)), params)
+	assert.Error(t, err)
+}
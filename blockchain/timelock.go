@@ -0,0 +1,120 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blockchain
+
+import (
+	"fmt"
+	"time"
+
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+)
+
+// SequenceLock is the latest block height and median time past at which a
+// transaction with relative lock times is still locked, -1 means no lock.
+type SequenceLock struct {
+	Seconds     int64
+	BlockHeight int64
+}
+
+// PastMedianTime returns the median time past of the best chain, which the
+// time based locks of the transactions in the next block are compared with.
+func (b *BlockChain) PastMedianTime() time.Time {
+	if b.BestChain == nil {
+		return time.Time{}
+	}
+	return CalcPastMedianTime(b.BestChain)
+}
+
+// relativeLockBase returns the height of the block containing the
+// transaction, and the median time past of its previous block, which the
+// relative lock times of the outputs of the transaction are relative to.
+func (b *BlockChain) relativeLockBase(outPoint common2.OutPoint) (uint32,
+	time.Time, error) {
+	_, height, err := b.db.GetTransaction(outPoint.TxID)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	prevHeight := height
+	if prevHeight > 0 {
+		prevHeight--
+	}
+	node := b.GetBlockNode(prevHeight)
+	if node == nil {
+		return 0, time.Time{}, fmt.Errorf("no block at height %d exists",
+			prevHeight)
+	}
+	return height, CalcPastMedianTime(node), nil
+}
+
+// CalcSequenceLock calculates the sequence lock of the transaction from the
+// relative lock times of its inputs.
+func (b *BlockChain) CalcSequenceLock(txn interfaces.Transaction) (
+	*SequenceLock, error) {
+	lock := &SequenceLock{Seconds: -1, BlockHeight: -1}
+	for _, input := range txn.Inputs() {
+		if !input.HasRelativeLock() {
+			continue
+		}
+		height, medianTime, err := b.relativeLockBase(input.Previous)
+		if err != nil {
+			return nil, err
+		}
+
+		lockTime, isSeconds := common2.RelativeLockTime(input.Sequence)
+		if isSeconds {
+			seconds := medianTime.Unix() + int64(lockTime) - 1
+			if seconds > lock.Seconds {
+				lock.Seconds = seconds
+			}
+		} else {
+			blockHeight := int64(height) + int64(lockTime) - 1
+			if blockHeight > lock.BlockHeight {
+				lock.BlockHeight = blockHeight
+			}
+		}
+	}
+	return lock, nil
+}
+
+// SequenceLockActive returns whether the sequence lock has passed in a block
+// at the height, of which the median time past of the previous block is the
+// given time.
+func SequenceLockActive(lock *SequenceLock, blockHeight uint32,
+	medianTime time.Time) bool {
+	return lock.Seconds < medianTime.Unix() &&
+		lock.BlockHeight < int64(blockHeight)
+}
+
+// CalcUTXOLock returns the minimum height of the block which can pack a
+// transaction spending the locked output, and the minimum median time past
+// of its previous block, zero means no limit.  The output belongs to the
+// transaction with the given outpoint.
+func (b *BlockChain) CalcUTXOLock(outPoint common2.OutPoint,
+	output *common2.Output) (uint32, int64, error) {
+	switch {
+	case output.OutputLock == 0:
+		return 0, 0, nil
+
+	case output.IsRelativeLock():
+		height, medianTime, err := b.relativeLockBase(outPoint)
+		if err != nil {
+			return 0, 0, err
+		}
+		lockTime, isSeconds := common2.RelativeLockTime(
+			output.RelativeLockSequence())
+		if isSeconds {
+			return 0, medianTime.Unix() + int64(lockTime), nil
+		}
+		return height + lockTime, 0, nil
+
+	case output.IsTimeLock():
+		return 0, int64(output.OutputLock) + 1, nil
+
+	default:
+		return output.OutputLock + 1, 0, nil
+	}
+}
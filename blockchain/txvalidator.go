@@ -88,6 +88,18 @@ func (b *BlockChain) CheckTransactionContext(blockHeight uint32,
 	return references, nil
 }
 
+// TraceTransaction runs all sanity and context checks of a transaction
+// without stopping at the first failure, and returns the result of each
+// check and the state lookups they made.
+func (b *BlockChain) TraceTransaction(blockHeight uint32,
+	tx interfaces.Transaction, proposalsUsedAmount common.Fixed64) *interfaces.CheckTrace {
+
+	para := functions.GetTransactionParameters(
+		tx, blockHeight, 0, b.chainParams, b, proposalsUsedAmount)
+
+	return tx.TraceCheck(para)
+}
+
 func (b *BlockChain) CheckVoteOutputs(
 	blockHeight uint32, outputs []*common2.Output, references map[*common2.Input]common2.Output,
 	pds map[string]struct{}, pds2 map[string]uint32, crs map[common.Uint168]struct{}) error {
@@ -298,7 +310,7 @@ func (b *BlockChain) checkVoteCRContent(blockHeight uint32,
 	if payloadVersion < outputpayload.VoteProducerAndCRVersion {
 		return errors.New("payload VoteProducerVersion not support vote CR")
 	}
-	if blockHeight >= b.chainParams.CRConfiguration.CheckVoteCRCountHeight {
+	if b.chainParams.IsActive(config.DeploymentCheckVoteCRCount, blockHeight) {
 		if len(content.CandidateVotes) > outputpayload.MaxVoteProducersPerTransaction {
 			return errors.New("invalid count of CR candidates ")
 		}
@@ -427,7 +439,7 @@ func (b *BlockChain) CheckTransactionOutput(txn interfaces.Transaction,
 
 		foundationReward := txn.Outputs()[0].Value
 		var totalReward = common.Fixed64(0)
-		if blockHeight < b.chainParams.PublicDPOSHeight {
+		if !b.chainParams.IsActive(config.DeploymentPublicDPOS, blockHeight) {
 			for _, output := range txn.Outputs() {
 				if output.AssetID != core.ELAAssetID {
 					return errors.New("asset ID in coinbase is invalid")
@@ -522,7 +534,7 @@ func (b *BlockChain) CheckTransactionOutput(txn interfaces.Transaction,
 		return nil
 	}
 
-	if b.GetHeight() >= b.chainParams.PublicDPOSHeight && specialOutputCount > 1 {
+	if b.chainParams.IsActive(config.DeploymentPublicDPOS, b.GetHeight()) && specialOutputCount > 1 {
 		return errors.New("special output count should less equal than 1")
 	}
 
@@ -531,7 +543,7 @@ func (b *BlockChain) CheckTransactionOutput(txn interfaces.Transaction,
 
 func CheckOutputProgramHash(height uint32, programHash common.Uint168) error {
 	// main version >= 88812
-	if height >= config.DefaultParams.CheckAddressHeight {
+	if config.DefaultParams.IsActive(config.DeploymentCheckAddress, height) {
 		var empty = common.Uint168{}
 		if programHash.IsEqual(empty) {
 			return nil
@@ -550,6 +562,10 @@ func CheckOutputProgramHash(height uint32, programHash common.Uint168) error {
 		case contract.PrefixCrossChain:
 		case contract.PrefixDeposit:
 		case contract.PrefixDPoSV2:
+		case contract.PrefixHTLC:
+			if !config.DefaultParams.IsActive(config.DeploymentHTLC, height) {
+				return errors.New("htlc program hash is not supported before HTLCStartHeight")
+			}
 		default:
 			return errors.New("invalid program hash prefix")
 		}
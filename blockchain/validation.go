@@ -6,6 +6,7 @@
 package blockchain
 
 import (
+	"crypto/sha256"
 	"errors"
 
 	"sort"
@@ -67,6 +68,10 @@ func RunPrograms(data []byte, programHashes []common.Uint168, programs []*Progra
 			if err := crypto.CheckMultiSigSignatures(*program, data); err != nil {
 				return err
 			}
+		} else if prefixType == contract.PrefixHTLC {
+			if err := CheckHTLCSignature(*program, data); err != nil {
+				return err
+			}
 		} else {
 			return errors.New("unknown signature type")
 		}
@@ -120,6 +125,37 @@ func CheckStandardSignature(program Program, data []byte) error {
 	return crypto.Verify(*publicKey, data, program.Parameter[1:])
 }
 
+// CheckHTLCSignature checks the program spending a HTLC.  The recipient
+// redeems it with a signature followed by the preimage of the hash lock, and
+// the refund key refunds it with a signature only, of which the refund lock
+// is checked with the transaction by the transaction checker.
+func CheckHTLCSignature(program Program, data []byte) error {
+	htlc, err := contract.ParseHTLC(program.Code)
+	if err != nil {
+		return err
+	}
+
+	param := program.Parameter
+	switch len(param) {
+	case contract.HTLCRedeemParameterLength:
+		preimage := param[crypto.SignatureScriptLength:]
+		if int(preimage[0]) != contract.HTLCPreimageLength {
+			return errors.New("invalid htlc preimage")
+		}
+		hash := sha256.Sum256(preimage[1:])
+		if !htlc.HashLock.IsEqual(hash) {
+			return errors.New("htlc preimage does not match the hash lock")
+		}
+		return crypto.Verify(*htlc.Recipient, data, param[1:crypto.SignatureScriptLength])
+
+	case contract.HTLCRefundParameterLength:
+		return crypto.Verify(*htlc.Refund, data, param[1:])
+
+	default:
+		return errors.New("invalid htlc parameter length")
+	}
+}
+
 func checkSchnorrSignatures(program Program, data [32]byte) (bool, error) {
 	publicKey := [33]byte{}
 	copy(publicKey[:], program.Code[2:])
@@ -0,0 +1,284 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blocks
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/config/settings"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/checkpoint"
+	"github.com/elastos/Elastos.ELA/database"
+	"github.com/elastos/Elastos.ELA/mempool"
+	"github.com/elastos/Elastos.ELA/node"
+	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/utils/elalog"
+	"github.com/elastos/Elastos.ELA/utils/signal"
+
+	"github.com/urfave/cli"
+	"gopkg.in/cheggaaa/pb.v1"
+)
+
+const (
+	// dataPath indicates the path storing the chain data.
+	dataPath = "data"
+
+	// nodeLogPath indicates the path storing the node log.
+	nodeLogPath = "logs/node"
+
+	// progressRefreshRate indicates the duration between refresh progress.
+	progressRefreshRate = time.Millisecond * 500
+)
+
+var appSettings = settings.NewSettings()
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "blocks",
+		Usage: "Export or import blockchain data with bootstrap files",
+		Description: "With ela-cli blocks command, you could export blocks " +
+			"into a bootstrap file and import them into another node offline.",
+		ArgsUsage: "[args]",
+		Subcommands: []cli.Command{
+			{
+				Name:  "export",
+				Usage: "Export blocks and confirms into a bootstrap file",
+				Flags: []cli.Flag{
+					cli.Uint64Flag{
+						Name:  "from",
+						Usage: "the height of the first exported block",
+					},
+					cli.Uint64Flag{
+						Name:  "to",
+						Usage: "the height of the last exported block, default is the best height",
+					},
+					cli.StringFlag{
+						Name:  "out",
+						Usage: "the bootstrap `<file>` to write",
+						Value: "bootstrap.dat",
+					},
+					cmdcom.ConfigFileFlag,
+					cmdcom.DataDirFlag,
+				},
+				Action: exportAction,
+			},
+			{
+				Name:  "import",
+				Usage: "Import blocks from a bootstrap file with full validation",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "in",
+						Usage: "the bootstrap `<file>` to read",
+						Value: "bootstrap.dat",
+					},
+					cmdcom.ConfigFileFlag,
+					cmdcom.DataDirFlag,
+				},
+				Action: importAction,
+			},
+		},
+	}
+}
+
+// setupConfig loads the node configuration and returns it with the path of the
+// data directory.
+func setupConfig(c *cli.Context) (*config.Configuration, string) {
+	config.DefaultParams.Conf = c.String("conf")
+	cfg := appSettings.SetupConfig(false, "", "")
+
+	flagDataDir := c.String("datadir")
+	if cfg.DataDir != "" && !c.IsSet("datadir") {
+		flagDataDir = cfg.DataDir
+	}
+	logger := log.NewDefault(filepath.Join(flagDataDir, nodeLogPath),
+		uint8(cfg.PrintLevel), cfg.MaxPerLogSize, cfg.MaxLogsSize)
+	mempool.UseLogger(logger.Subsystem("mempool",
+		elalog.Level(cfg.PrintLevel)))
+
+	return cfg, flagDataDir
+}
+
+// newProgressBar creates a progress bar shown in the terminal.
+func newProgressBar(total int64) *pb.ProgressBar {
+	bar := pb.New64(total)
+	bar.ShowTimeLeft = true
+	bar.ShowFinalTime = true
+	bar.SetRefreshRate(progressRefreshRate)
+	return bar.Start()
+}
+
+func exportAction(c *cli.Context) error {
+	cfg, flagDataDir := setupConfig(c)
+	dataDir := filepath.Join(flagDataDir, dataPath)
+
+	chainStore, err := blockchain.NewChainStore(dataDir, cfg)
+	if err != nil {
+		return fmt.Errorf("create chain store failed, %s", err)
+	}
+	defer chainStore.Close()
+	chain, err := blockchain.New(chainStore, cfg, nil, nil,
+		checkpoint.NewManager(cfg))
+	if err != nil {
+		return fmt.Errorf("create blockchain failed, %s", err)
+	}
+
+	from := uint32(c.Uint64("from"))
+	to := chain.GetHeight()
+	if c.IsSet("to") {
+		to = uint32(c.Uint64("to"))
+	}
+	if from > to || to > chain.GetHeight() {
+		return fmt.Errorf("invalid height range [%d, %d], best height "+
+			"is %d", from, to, chain.GetHeight())
+	}
+
+	file, err := os.Create(c.String("out"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	w := bufio.NewWriter(file)
+	writer := newBootstrapWriter(w, cfg.Magic)
+
+	interrupt := signal.NewInterrupt()
+	bar := newProgressBar(int64(to - from + 1))
+	for height := from; height <= to; height++ {
+		if interrupt.Interrupted() {
+			bar.Finish()
+			return errors.New("export interrupted")
+		}
+
+		hash, err := chain.GetBlockHash(height)
+		if err != nil {
+			return err
+		}
+
+		// The stored blocks are serialized DposBlocks, so write them
+		// into the bootstrap file directly.
+		err = chainStore.GetFFLDB().View(func(dbTx database.Tx) error {
+			rawBlock, err := dbTx.FetchBlock(&hash)
+			if err != nil {
+				return err
+			}
+			return writer.WriteBlock(rawBlock)
+		})
+		if err != nil {
+			bar.Finish()
+			return fmt.Errorf("export block %d failed, %s", height, err)
+		}
+		bar.Increment()
+	}
+	bar.FinishPrint(fmt.Sprintf("exported blocks from height %d to %d "+
+		"into %s", from, to, c.String("out")))
+
+	return w.Flush()
+}
+
+// newImporter creates the chain which processes the blocks of a bootstrap
+// file in the same way as the blocks received from the P2P network.
+func newImporter(cfg *config.Configuration, dataDir string,
+	interrupt <-chan struct{}) (*node.Node, error) {
+	imp, err := node.New(&node.Config{
+		ChainParams: cfg,
+		DataDir:     dataDir,
+		Interrupt:   interrupt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The importer is not connected to the network, so nothing will be
+	// broadcast and the node is never considered as current.
+	imp.RegisterFunctions(func() bool { return false },
+		func(msg p2p.Message) {})
+	if err = imp.InitCheckpoint(nil, nil); err != nil {
+		imp.Close()
+		return nil, err
+	}
+	return imp, nil
+}
+
+func importAction(c *cli.Context) error {
+	cfg, flagDataDir := setupConfig(c)
+	dataDir := filepath.Join(flagDataDir, dataPath)
+
+	file, err := os.Open(c.String("in"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	interrupt := signal.NewInterrupt()
+	imp, err := newImporter(cfg, dataDir, interrupt.C)
+	if err != nil {
+		return fmt.Errorf("initialize blockchain failed, %s", err)
+	}
+	defer imp.Close()
+
+	reader := newBootstrapReader(bufio.NewReader(file), cfg.Magic)
+	bar := newProgressBar(info.Size())
+	bar.SetUnits(pb.U_BYTES)
+	var imported, skipped uint32
+	for {
+		if interrupt.Interrupted() {
+			bar.Finish()
+			return fmt.Errorf("import interrupted at height %d, run the "+
+				"command again to resume", imp.Chain.GetHeight())
+		}
+
+		block, size, err := reader.ReadBlock()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			bar.Finish()
+			return fmt.Errorf("read bootstrap file failed, %s", err)
+		}
+		bar.Add(size)
+
+		// Blocks already in the chain are skipped, this is what allows
+		// the import to be resumed after an interruption.
+		if block.Height <= imp.Chain.GetHeight() {
+			hash, err := imp.Chain.GetBlockHash(block.Height)
+			if err != nil || !hash.IsEqual(block.Hash()) {
+				bar.Finish()
+				return fmt.Errorf("block %d conflicts with the local "+
+					"chain", block.Height)
+			}
+			skipped++
+			continue
+		}
+
+		if _, _, err := imp.BlockPool.AddDposBlock(block); err != nil {
+			bar.Finish()
+			return fmt.Errorf("import block %d failed, %s",
+				block.Height, err)
+		}
+		if imp.Chain.GetHeight() != block.Height {
+			bar.Finish()
+			return fmt.Errorf("import block %d failed, block is not "+
+				"connected to the best chain", block.Height)
+		}
+		imported++
+	}
+	bar.FinishPrint(fmt.Sprintf("imported %d blocks, skipped %d blocks, "+
+		"best height %d", imported, skipped, imp.Chain.GetHeight()))
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blocks
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// recordHeaderSize is the size of the header in front of each block record of
+// a bootstrap file, it contains the network magic and the length of the block.
+const recordHeaderSize = 8
+
+// The bootstrap file is a sequence of records, one for each block in ascending
+// height order.  The format of each record is:
+//
+//  [0:4]  Network magic (4 bytes)
+//  [4:8]  Length of the serialized DposBlock (4 bytes)
+//  [8:]   Serialized DposBlock, the block and its confirm
+
+// bootstrapWriter writes the serialized DposBlocks into a bootstrap file.
+type bootstrapWriter struct {
+	w     io.Writer
+	magic uint32
+}
+
+// WriteBlock writes a serialized DposBlock as a record of the bootstrap file.
+func (bw *bootstrapWriter) WriteBlock(rawBlock []byte) error {
+	if err := common.WriteUint32(bw.w, bw.magic); err != nil {
+		return err
+	}
+	if err := common.WriteUint32(bw.w, uint32(len(rawBlock))); err != nil {
+		return err
+	}
+	_, err := bw.w.Write(rawBlock)
+	return err
+}
+
+// newBootstrapWriter returns a bootstrap file writer for the given network.
+func newBootstrapWriter(w io.Writer, magic uint32) *bootstrapWriter {
+	return &bootstrapWriter{w: w, magic: magic}
+}
+
+// bootstrapReader reads the DposBlocks from a bootstrap file.
+type bootstrapReader struct {
+	r     io.Reader
+	magic uint32
+}
+
+// ReadBlock reads the next block record from the bootstrap file, it returns
+// the block and the size of the record.  io.EOF is returned when there are no
+// more records.
+func (br *bootstrapReader) ReadBlock() (*types.DposBlock, int, error) {
+	magic, err := common.ReadUint32(br.r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if magic != br.magic {
+		return nil, 0, fmt.Errorf("unexpected network magic %d, expect %d",
+			magic, br.magic)
+	}
+
+	length, err := common.ReadUint32(br.r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if length > p2p.MaxMessagePayload {
+		return nil, 0, fmt.Errorf("block record length %d exceeds the "+
+			"max length %d", length, p2p.MaxMessagePayload)
+	}
+
+	rawBlock := make([]byte, length)
+	if _, err := io.ReadFull(br.r, rawBlock); err != nil {
+		return nil, 0, err
+	}
+
+	var block types.DposBlock
+	if err := block.Deserialize(bytes.NewReader(rawBlock)); err != nil {
+		return nil, 0, err
+	}
+	return &block, recordHeaderSize + int(length), nil
+}
+
+// newBootstrapReader returns a bootstrap file reader for the given network.
+func newBootstrapReader(r io.Reader, magic uint32) *bootstrapReader {
+	return &bootstrapReader{r: r, magic: magic}
+}
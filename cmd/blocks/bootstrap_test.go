@@ -0,0 +1,72 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package blocks
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootstrapFile(t *testing.T) {
+	blocks := []*types.DposBlock{
+		{
+			Block: &types.Block{
+				Header: common2.Header{Height: 1, Previous: common.Uint256{1}},
+			},
+		},
+		{
+			Block: &types.Block{
+				Header: common2.Header{Height: 2, Previous: common.Uint256{2}},
+			},
+			HaveConfirm: true,
+			Confirm: &payload.Confirm{
+				Proposal: payload.DPOSProposal{BlockHash: common.Uint256{3}},
+			},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	writer := newBootstrapWriter(buf, 2017001)
+	for _, block := range blocks {
+		raw := new(bytes.Buffer)
+		assert.NoError(t, block.Serialize(raw))
+		assert.NoError(t, writer.WriteBlock(raw.Bytes()))
+	}
+	data := buf.Bytes()
+
+	reader := newBootstrapReader(bytes.NewReader(data), 2017001)
+	total := 0
+	for _, block := range blocks {
+		decoded, size, err := reader.ReadBlock()
+		assert.NoError(t, err)
+		assert.Equal(t, block.Hash(), decoded.Hash())
+		assert.Equal(t, block.HaveConfirm, decoded.HaveConfirm)
+		total += size
+	}
+	assert.Equal(t, len(data), total)
+	_, _, err := reader.ReadBlock()
+	assert.Equal(t, io.EOF, err)
+
+	// Records of another network are rejected.
+	reader = newBootstrapReader(bytes.NewReader(data), 2018101)
+	_, _, err = reader.ReadBlock()
+	assert.Error(t, err)
+
+	// A truncated record is reported as an error instead of io.EOF.
+	reader = newBootstrapReader(bytes.NewReader(data[:len(data)-1]), 2017001)
+	_, _, err = reader.ReadBlock()
+	assert.NoError(t, err)
+	_, _, err = reader.ReadBlock()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
@@ -34,6 +34,41 @@ var (
 		Name:  "pubkeys, pks",
 		Usage: "public key list of multi signature address, separate public keys with comma `,`",
 	}
+	AccountHDFlag = cli.BoolFlag{
+		Name:  "hd",
+		Usage: "create a HD wallet which can be restored from a mnemonic",
+	}
+	AccountMnemonicFlag = cli.StringFlag{
+		Name:  "mnemonic",
+		Usage: "the mnemonic `<words>` of HD wallet, separate words with space",
+	}
+	AccountPassphraseFlag = cli.StringFlag{
+		Name:  "passphrase",
+		Usage: "the optional passphrase protecting the mnemonic of HD wallet",
+	}
+	AccountGapLimitFlag = cli.IntFlag{
+		Name:  "gaplimit",
+		Usage: "stop discovering addresses after `<number>` consecutive unused addresses",
+		Value: account.DefaultGapLimit,
+	}
+	AccountSignerSocketFlag = cli.StringFlag{
+		Name:  "socket",
+		Usage: "the unix `<socket>` file path the remote signer listens on",
+		Value: "signer.sock",
+	}
+	AccountProtectionFlag = cli.StringFlag{
+		Name:  "protection",
+		Usage: "the `<path>` of the database recording the signed proposals and votes",
+		Value: "protection",
+	}
+	AccountSignerRefuseFlag = cli.BoolFlag{
+		Name:  "refuse",
+		Usage: "log and refuse all requests instead of signing them",
+	}
+	AccountSignerAllowFlag = cli.StringFlag{
+		Name:  "allow",
+		Usage: "the message `<types>` allowed to sign, separate types with comma `,`: proposal, vote, transaction, message (default: all)",
+	}
 
 	// Transaction flags
 	TransactionFromFlag = cli.StringFlag{
@@ -67,11 +102,15 @@ var (
 	}
 	TransactionOutputLockFlag = cli.StringFlag{
 		Name:  "outputlock",
-		Usage: "the `<lock height>` to specify when the received asset can be spent",
+		Usage: "the `<lock height or time>` to specify when the received asset can be spent",
+	}
+	TransactionRelativeLockFlag = cli.StringFlag{
+		Name:  "relativelock",
+		Usage: "the `<blocks>`, or seconds with suffix s, after which the received asset can be spent since being packaged",
 	}
 	TransactionTxLockFlag = cli.StringFlag{
 		Name:  "txlock",
-		Usage: "the `<lock height>` to specify when the transaction can be packaged",
+		Usage: "the `<lock height or time>` to specify when the transaction can be packaged",
 	}
 	TransactionHexFlag = cli.StringFlag{
 		Name:  "hex",
@@ -81,6 +120,10 @@ var (
 		Name:  "file, f",
 		Usage: "the file path to specify a transaction file path with the hex string content to be sign",
 	}
+	TransactionSchnorrFlag = cli.StringFlag{
+		Name:  "schnorr",
+		Usage: "public key list aggregated into a Schnorr address, separate public keys with comma `,`",
+	}
 	TransactionNodePublicKeyFlag = cli.StringFlag{
 		Name:  "nodepublickey",
 		Usage: "the node public key of an arbitrator which have been inactivated (default: same as owner public key)",
@@ -265,6 +308,40 @@ var (
 		Name:  "digest",
 		Usage: "digest hex-string",
 	}
+	TransactionIDFlag = cli.StringFlag{
+		Name:  "txid",
+		Usage: "the `<hash>` of the transaction",
+	}
+	TransactionRBFFlag = cli.BoolFlag{
+		Name:  "rbf",
+		Usage: "signal that the transaction can be replaced by a transaction with higher fee",
+	}
+
+	// HTLC flags
+	HTLCRecipientFlag = cli.StringFlag{
+		Name:  "recipient",
+		Usage: "the `<public key>` redeeming the contract with the preimage",
+	}
+	HTLCRefundFlag = cli.StringFlag{
+		Name:  "refund",
+		Usage: "the `<public key>` refunded after the lock time",
+	}
+	HTLCHashFlag = cli.StringFlag{
+		Name:  "hash",
+		Usage: "the sha256 `<hash>` of the preimage, a random preimage is generated if not given",
+	}
+	HTLCLockTimeFlag = cli.StringFlag{
+		Name:  "locktime",
+		Usage: "the refund `<lock time>`, a block height below 500000000 or a unix timestamp",
+	}
+	HTLCPreimageFlag = cli.StringFlag{
+		Name:  "preimage",
+		Usage: "the `<preimage>` of the hash lock in hex string",
+	}
+	HTLCScriptFlag = cli.StringFlag{
+		Name:  "script",
+		Usage: "the redeem `<script>` of the contract in hex string",
+	}
 
 	// RPC flags
 	RPCUserFlag = cli.StringFlag{
@@ -0,0 +1,216 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package devnet
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/utils/http"
+
+	"github.com/urfave/cli"
+)
+
+const (
+	// dataPath indicates the path storing the chain data.
+	dataPath = "data"
+
+	// nodeLogPath indicates the path storing the node log.
+	nodeLogPath = "logs/node"
+
+	// keysFile is the default name of the keys file in the data directory.
+	keysFile = "keys.json"
+
+	// configFile is the default name of the config file in the data
+	// directory.
+	configFile = "config.json"
+
+	// defaultDataDir is the default data directory of the devnet.
+	defaultDataDir = "devnet"
+
+	// defaultArbiters is the default count of simulated arbiters.
+	defaultArbiters = 5
+)
+
+func printFormat(data interface{}) {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	json.Indent(buf, dataBytes, "", "    ")
+	fmt.Println(string(buf.Bytes()))
+}
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "devnet",
+		Usage: "Run a local development network with simulated arbiters",
+		Description: "With ela-cli devnet command, you could run a single " +
+			"node with in-process simulated DPOS arbiters, and generate " +
+			"blocks, jump to deployment heights and fund addresses on demand.",
+		ArgsUsage: "[args]",
+		Subcommands: []cli.Command{
+			{
+				Name:  "start",
+				Usage: "Start the devnet node and the simulated arbiters",
+				Flags: []cli.Flag{
+					cli.IntFlag{
+						Name:  "arbiters",
+						Usage: "the `<count>` of simulated arbiters when generating keys",
+						Value: defaultArbiters,
+					},
+					cli.StringFlag{
+						Name:  "keys",
+						Usage: "the keys `<file>`, generated if not exist, default is keys.json in the data directory",
+					},
+					cli.DurationFlag{
+						Name:  "blocktime",
+						Usage: "generate a block every `<duration>`, blocks are generated on demand only if not set",
+					},
+					cli.StringFlag{
+						Name:  "conf",
+						Usage: "config `<file>` path, default is config.json in the data directory",
+					},
+					cli.StringFlag{
+						Name:  "datadir",
+						Usage: "block data, keys and logs storage `<path>`",
+						Value: defaultDataDir,
+					},
+				},
+				Action: startAction,
+			},
+			{
+				Name:  "generate",
+				Usage: "Generate blocks on the running devnet",
+				Flags: []cli.Flag{
+					cli.Uint64Flag{
+						Name:  "count",
+						Usage: "the `<count>` of blocks to generate",
+					},
+					cli.Uint64Flag{
+						Name:  "height",
+						Usage: "generate blocks until the best height reaches `<height>`",
+					},
+					cli.StringFlag{
+						Name:  "fork",
+						Usage: "generate blocks until the best height reaches the deployment `<name>`",
+					},
+				},
+				Action: generateAction,
+			},
+			{
+				Name:  "fund",
+				Usage: "Send coins from the faucet of the running devnet",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "address",
+						Usage: "the `<address>` to fund",
+					},
+					cli.StringFlag{
+						Name:  "amount",
+						Usage: "the `<amount>` of ELA to send",
+					},
+				},
+				Action: fundAction,
+			},
+			{
+				Name:   "info",
+				Usage:  "Show the faucet and arbiters of the running devnet",
+				Action: infoAction,
+			},
+		},
+	}
+}
+
+func generateAction(c *cli.Context) error {
+	var method string
+	params := http.Params{}
+	switch {
+	case c.IsSet("count"):
+		method = "generateblocks"
+		params["count"] = c.Uint64("count")
+	case c.IsSet("height"):
+		method = "generatetoheight"
+		params["height"] = c.Uint64("height")
+	case c.IsSet("fork"):
+		method = "generatetoheight"
+		params["fork"] = strings.ToLower(c.String("fork"))
+	default:
+		return errors.New("one of --count, --height and --fork is required")
+	}
+
+	result, err := cmdcom.RPCCall(method, params)
+	if err != nil {
+		fmt.Println("error: generate blocks failed,", err)
+		return err
+	}
+	printFormat(result)
+	return nil
+}
+
+func fundAction(c *cli.Context) error {
+	address := c.String("address")
+	if address == "" {
+		return errors.New("use --address to specify the address to fund")
+	}
+	amount := c.String("amount")
+	if amount == "" {
+		return errors.New("use --amount to specify the amount to send")
+	}
+
+	result, err := cmdcom.RPCCall("fundaddress", http.Params{
+		"address": address,
+		"amount":  amount,
+	})
+	if err != nil {
+		fmt.Println("error: fund address failed,", err)
+		return err
+	}
+	printFormat(result)
+	return nil
+}
+
+func infoAction(c *cli.Context) error {
+	result, err := cmdcom.RPCCall("getdevnetinfo", http.Params{})
+	if err != nil {
+		fmt.Println("error: get devnet info failed,", err)
+		return err
+	}
+	printFormat(result)
+	return nil
+}
+
+func startAction(c *cli.Context) error {
+	dataDir := c.String("datadir")
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return err
+	}
+
+	keysPath := c.String("keys")
+	if keysPath == "" {
+		keysPath = filepath.Join(dataDir, keysFile)
+	}
+	confPath := c.String("conf")
+	if confPath == "" {
+		confPath = filepath.Join(dataDir, configFile)
+	}
+
+	node, err := newNode(dataDir, confPath, keysPath, c.Int("arbiters"),
+		c.IsSet("arbiters"))
+	if err != nil {
+		return err
+	}
+	return node.run(c.Duration("blocktime"))
+}
@@ -0,0 +1,264 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package devnet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/config/settings"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/types"
+	devnet2 "github.com/elastos/Elastos.ELA/devnet"
+	"github.com/elastos/Elastos.ELA/dpos"
+	dposaccount "github.com/elastos/Elastos.ELA/dpos/account"
+	dlog "github.com/elastos/Elastos.ELA/dpos/log"
+	dp2p "github.com/elastos/Elastos.ELA/dpos/p2p"
+	"github.com/elastos/Elastos.ELA/elanet"
+	"github.com/elastos/Elastos.ELA/elanet/routes"
+	"github.com/elastos/Elastos.ELA/mempool"
+	elanode "github.com/elastos/Elastos.ELA/node"
+	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+	"github.com/elastos/Elastos.ELA/servers"
+	"github.com/elastos/Elastos.ELA/servers/httpjsonrpc"
+	"github.com/elastos/Elastos.ELA/utils/elalog"
+	"github.com/elastos/Elastos.ELA/utils/signal"
+)
+
+// nodeVersion is the version announced by the devnet node.
+const nodeVersion = "ela-devnet"
+
+// node is a devnet node with its simulated arbiters.
+type node struct {
+	cfg         *config.Configuration
+	dataDir     string
+	keys        *devnet2.Keys
+	interrupt   chan struct{}
+	base        *elanode.Node
+	netServer   elanet.Server
+	arbitrators []*dpos.Arbitrator
+	devNet      *devnet2.DevNet
+}
+
+// loadConfig loads the devnet configuration from the given config file, the
+// network parameters are replaced by the devnet ones unless the config file
+// specifies another network.
+func loadConfig(confPath string) (*config.Configuration, error) {
+	config.DefaultParams.Conf = confPath
+	config.DefaultParams.ActiveNet = "devnet"
+	cfg := settings.NewSettings().SetupConfig(false, "", "")
+	switch strings.ToLower(cfg.ActiveNet) {
+	case "devnet", "dev":
+	default:
+		return nil, fmt.Errorf("active net %s is not devnet", cfg.ActiveNet)
+	}
+	return cfg, nil
+}
+
+// loadKeys loads the keys from the keys file, or generates and saves the
+// keys if the keys file does not exist.
+func loadKeys(path string, arbiters int, arbitersSet bool) (*devnet2.Keys,
+	error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		keys, err := devnet2.GenerateKeys(arbiters)
+		if err != nil {
+			return nil, err
+		}
+		if err := keys.Save(path); err != nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+
+	keys, err := devnet2.LoadKeys(path)
+	if err != nil {
+		return nil, fmt.Errorf("load keys file %s failed, %s", path, err)
+	}
+	if arbitersSet && len(keys.Arbiters) != arbiters {
+		return nil, fmt.Errorf("keys file %s contains %d arbiters, remove "+
+			"it to generate %d arbiters", path, len(keys.Arbiters), arbiters)
+	}
+	return keys, nil
+}
+
+func newNode(dataDir, confPath, keysPath string, arbiters int,
+	arbitersSet bool) (*node, error) {
+	cfg, err := loadConfig(confPath)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := loadKeys(keysPath, arbiters, arbitersSet)
+	if err != nil {
+		return nil, err
+	}
+	if err := keys.Apply(cfg); err != nil {
+		return nil, err
+	}
+
+	logger := log.NewDefault(filepath.Join(dataDir, nodeLogPath),
+		uint8(cfg.PrintLevel), cfg.MaxPerLogSize, cfg.MaxLogsSize)
+	mempool.UseLogger(logger.Subsystem("mempool",
+		elalog.Level(cfg.PrintLevel)))
+	httpjsonrpc.UseLogger(logger.Subsystem("rpc",
+		elalog.Level(cfg.PrintLevel)))
+	dlog.Init(dataDir, uint8(cfg.PrintLevel), cfg.MaxPerLogSize,
+		cfg.MaxLogsSize)
+
+	return &node{
+		cfg:       cfg,
+		dataDir:   dataDir,
+		keys:      keys,
+		interrupt: signal.NewInterrupt().C,
+	}, nil
+}
+
+// start creates the chain in the same way as a full node, and the network
+// server and the simulated arbiters on it.
+func (n *node) start() error {
+	cfg := n.cfg
+	dataDir := filepath.Join(n.dataDir, dataPath)
+
+	base, err := elanode.New(&elanode.Config{
+		ChainParams: cfg,
+		DataDir:     dataDir,
+		Interrupt:   n.interrupt,
+	})
+	if err != nil {
+		return err
+	}
+	n.base = base
+	chain, txMemPool, arbiters := base.Chain, base.TxPool, base.Arbiters
+
+	routesCfg := &routes.Config{TimeSource: chain.TimeSource}
+	route := routes.New(routesCfg)
+	netServer, err := elanet.NewServer(dataDir, &elanet.Config{
+		Chain:          chain,
+		ChainParams:    cfg,
+		PermanentPeers: cfg.PermanentPeers,
+		TxMemPool:      txMemPool,
+		BlockMemPool:   base.BlockPool,
+		Routes:         route,
+	}, nodeVersion)
+	if err != nil {
+		return err
+	}
+	routesCfg.IsCurrent = netServer.IsCurrent
+	routesCfg.RelayAddr = netServer.RelayInventory
+	broadcast := func(msg p2p.Message) {
+		netServer.BroadcastMessage(msg)
+	}
+	base.RegisterFunctions(netServer.IsCurrent, broadcast)
+
+	// All simulated arbiters share the chain and the pools of the node, and
+	// exchange DPOS messages through an in-memory network.
+	localNetwork := dp2p.NewLocalNetwork()
+	for _, acc := range n.keys.Arbiters {
+		arbitrator, err := dpos.NewArbitrator(dposaccount.New(acc),
+			dpos.Config{
+				Chain:        chain,
+				ChainParams:  cfg,
+				Arbitrators:  arbiters,
+				Server:       netServer,
+				TxMemPool:    txMemPool,
+				BlockMemPool: base.BlockPool,
+				Broadcast:    broadcast,
+				AnnounceAddr: func() {},
+				NodeVersion:  nodeVersion,
+				LocalNetwork: localNetwork,
+			})
+		if err != nil {
+			return err
+		}
+		n.arbitrators = append(n.arbitrators, arbitrator)
+	}
+	for _, arbitrator := range n.arbitrators {
+		arbitrator.Start()
+	}
+
+	powService := base.NewPowService(func(block *types.Block) {
+		hash := block.Hash()
+		netServer.RelayInventory(msg.NewInvVect(msg.InvTypeBlock, &hash), block)
+	})
+	servers.ChainParams = cfg
+	servers.Chain = chain
+	servers.Store = base.Store
+	servers.TxMemPool = txMemPool
+	servers.Server = netServer
+	servers.Arbiters = arbiters
+	servers.Arbiter = n.arbitrators[0]
+	servers.Pow = powService
+
+	if err = base.InitCheckpoint(nil, nil); err != nil {
+		return err
+	}
+	netServer.Start()
+	base.LoadMempool()
+
+	n.netServer = netServer
+	n.devNet = devnet2.New(&devnet2.Config{
+		Chain:       chain,
+		ChainParams: cfg,
+		TxMemPool:   txMemPool,
+		Pow:         powService,
+		Keys:        n.keys,
+	})
+	servers.DevNet = n.devNet
+	return nil
+}
+
+// stop stops the simulated arbiters and the network server, saves the
+// transaction pool and closes the chain store.
+func (n *node) stop() {
+	if n.devNet != nil {
+		n.devNet.Stop()
+		n.base.SaveMempool()
+	}
+	for _, arbitrator := range n.arbitrators {
+		arbitrator.Stop()
+	}
+	if n.netServer != nil {
+		n.netServer.Stop()
+	}
+	if n.base != nil {
+		n.base.Close()
+	}
+}
+
+// run starts the devnet and blocks until interrupted.
+func (n *node) run(blockTime time.Duration) error {
+	if !n.cfg.EnableRPC {
+		return errors.New("RPC service is required by devnet")
+	}
+	defer n.stop()
+	if err := n.start(); err != nil {
+		return err
+	}
+	go httpjsonrpc.StartRPCServer()
+	if blockTime > 0 {
+		n.devNet.Start(blockTime)
+	}
+
+	arbiters, err := n.keys.ArbiterPublicKeys()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Devnet started")
+	fmt.Println("  faucet:  ", n.keys.Faucet.Address)
+	for i, arbiter := range arbiters {
+		fmt.Printf("  arbiter %d: %s\n", i, arbiter)
+	}
+	fmt.Println("  rpc port:", n.cfg.HttpJsonPort)
+	fmt.Println("  height:  ", n.base.Chain.GetHeight())
+
+	<-n.interrupt
+	return nil
+}
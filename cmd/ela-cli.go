@@ -10,11 +10,14 @@ import (
 	"os"
 	"time"
 
+	"github.com/elastos/Elastos.ELA/cmd/blocks"
 	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/cmd/devnet"
 	"github.com/elastos/Elastos.ELA/cmd/info"
 	"github.com/elastos/Elastos.ELA/cmd/mine"
 	"github.com/elastos/Elastos.ELA/cmd/rollback"
 	"github.com/elastos/Elastos.ELA/cmd/script"
+	"github.com/elastos/Elastos.ELA/cmd/snapshot"
 	"github.com/elastos/Elastos.ELA/cmd/wallet"
 	"github.com/elastos/Elastos.ELA/common/config"
 	transaction2 "github.com/elastos/Elastos.ELA/core/transaction"
@@ -57,6 +60,9 @@ func main() {
 		*mine.NewCommand(),
 		*script.NewCommand(),
 		*rollback.NewCommand(),
+		*blocks.NewCommand(),
+		*snapshot.NewCommand(),
+		*devnet.NewCommand(),
 	}
 
 	//sort.Sort(cli.CommandsByName(app.Commands))
@@ -273,6 +273,7 @@ func RegisterDataType(L *lua.LState) int {
 	RegisterDposNetworkType(L)
 	RegisterDposManagerType(L)
 	RegisterArbitratorsType(L)
+	RegisterChainType(L)
 	RegisterRegisterProducerType(L)
 
 	RegisterRegisterV2ProducerType(L)
@@ -0,0 +1,317 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package api
+
+import (
+	"github.com/elastos/Elastos.ELA/cmd/script/api/mock"
+	"github.com/elastos/Elastos.ELA/common"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+const (
+	luaChainTypeName = "chain"
+
+	defaultChainArbiters = 5
+)
+
+// RegisterChainType registers the chain type, which runs a blockchain without
+// network for the scenario scripts.
+func RegisterChainType(L *lua.LState) {
+	mt := L.NewTypeMetatable(luaChainTypeName)
+	L.SetGlobal("chain", mt)
+	// static attributes
+	L.SetField(mt, "new", L.NewFunction(newChain))
+	// methods
+	L.SetField(mt, "__index", L.SetFuncs(L.NewTable(), chainMethods))
+}
+
+// Constructor
+func newChain(L *lua.LState) int {
+	arbiters := L.OptInt(1, defaultChainArbiters)
+	logLevel := uint8(L.OptInt(2, 5))
+	if arbiters <= 0 {
+		L.ArgError(1, "arbiters count must be positive")
+		return 0
+	}
+
+	c, err := mock.NewChain(arbiters, logLevel)
+	if err != nil {
+		L.RaiseError("new chain error: %s", err.Error())
+		return 0
+	}
+
+	ud := L.NewUserData()
+	ud.Value = c
+	L.SetMetatable(ud, L.GetTypeMetatable(luaChainTypeName))
+	L.Push(ud)
+
+	return 1
+}
+
+// Checks whether the first lua argument is a *LUserData with *mock.Chain and
+// returns this *mock.Chain.
+func checkChain(L *lua.LState, idx int) *mock.Chain {
+	ud := L.CheckUserData(idx)
+	if v, ok := ud.Value.(*mock.Chain); ok {
+		return v
+	}
+	L.ArgError(1, "Chain expected")
+	return nil
+}
+
+var chainMethods = map[string]lua.LGFunction{
+	"height":       chainHeight,
+	"best_hash":    chainBestHash,
+	"mine":         chainMine,
+	"rollback":     chainRollback,
+	"send_tx":      chainSendTx,
+	"fund":         chainFund,
+	"faucet":       chainFaucet,
+	"balance":      chainBalance,
+	"utxos":        chainUTXOs,
+	"arbiters":     chainArbiters,
+	"producer":     chainProducer,
+	"cr_candidate": chainCRCandidate,
+	"cr_member":    chainCRMember,
+	"close":        chainClose,
+}
+
+func chainHeight(L *lua.LState) int {
+	c := checkChain(L, 1)
+	L.Push(lua.LNumber(c.Height()))
+
+	return 1
+}
+
+func chainBestHash(L *lua.LState) int {
+	c := checkChain(L, 1)
+	L.Push(lua.LString(c.Chain.GetBestBlockHash().String()))
+
+	return 1
+}
+
+// chainMine generates the given count of blocks, and returns the hashes of
+// them in a table.
+func chainMine(L *lua.LState) int {
+	c := checkChain(L, 1)
+	count := L.OptInt(2, 1)
+	if count <= 0 {
+		L.ArgError(2, "count must be positive")
+		return 0
+	}
+
+	hashes, err := c.Mine(uint32(count))
+	if err != nil {
+		L.RaiseError("mine error at height %d: %s", c.Height(), err.Error())
+		return 0
+	}
+	table := L.NewTable()
+	for _, hash := range hashes {
+		table.Append(lua.LString(hash.String()))
+	}
+	L.Push(table)
+
+	return 1
+}
+
+func chainRollback(L *lua.LState) int {
+	c := checkChain(L, 1)
+	height := L.CheckInt(2)
+	if height < 0 {
+		L.ArgError(2, "height must not be negative")
+		return 0
+	}
+
+	if err := c.Rollback(uint32(height)); err != nil {
+		L.RaiseError("rollback error: %s", err.Error())
+	}
+
+	return 0
+}
+
+// chainSendTx appends the transaction to the transaction pool, and returns
+// the transaction hash.
+func chainSendTx(L *lua.LState) int {
+	c := checkChain(L, 1)
+	txn := checkTransaction(L, 2)
+
+	if err := c.TxPool.AppendToTxPool(txn); err != nil {
+		L.RaiseError("send transaction error: %s", err.Error())
+		return 0
+	}
+	L.Push(lua.LString(txn.Hash().String()))
+
+	return 1
+}
+
+// chainFund sends the given amount of ELA from the faucet to the address, and
+// returns the transaction hash.
+func chainFund(L *lua.LState) int {
+	c := checkChain(L, 1)
+	address := checkAddress(L, 2)
+	amount := float64(L.CheckNumber(3))
+
+	txn, err := c.Fund(address, common.Fixed64(int64(amount*1e8)))
+	if err != nil {
+		L.RaiseError("fund error: %s", err.Error())
+		return 0
+	}
+	L.Push(lua.LString(txn.Hash().String()))
+
+	return 1
+}
+
+// chainFaucet returns the address and the private key of the faucet.
+func chainFaucet(L *lua.LState) int {
+	c := checkChain(L, 1)
+	L.Push(lua.LString(c.Keys.Faucet.Address))
+	L.Push(lua.LString(common.BytesToHexString(c.Keys.Faucet.PrivateKey)))
+
+	return 2
+}
+
+// chainBalance returns the amount of ELA in the UTXOs of the address.
+func chainBalance(L *lua.LState) int {
+	c := checkChain(L, 1)
+	address := checkAddress(L, 2)
+
+	utxos, err := c.Store.GetFFLDB().GetUTXO(&address)
+	if err != nil {
+		L.RaiseError("get utxo error: %s", err.Error())
+		return 0
+	}
+	var balance common.Fixed64
+	for _, utxo := range utxos {
+		balance += utxo.Value
+	}
+	L.Push(lua.LNumber(float64(balance) / 1e8))
+
+	return 1
+}
+
+// chainUTXOs returns the UTXOs of the address in a table, each of which has
+// the txid, index and amount fields.
+func chainUTXOs(L *lua.LState) int {
+	c := checkChain(L, 1)
+	address := checkAddress(L, 2)
+
+	utxos, err := c.Store.GetFFLDB().GetUTXO(&address)
+	if err != nil {
+		L.RaiseError("get utxo error: %s", err.Error())
+		return 0
+	}
+	table := L.NewTable()
+	for _, utxo := range utxos {
+		t := L.NewTable()
+		t.RawSetString("txid", lua.LString(utxo.TxID.String()))
+		t.RawSetString("index", lua.LNumber(utxo.Index))
+		t.RawSetString("amount", lua.LNumber(float64(utxo.Value)/1e8))
+		table.Append(t)
+	}
+	L.Push(table)
+
+	return 1
+}
+
+// chainArbiters returns the node public keys of the current arbiters in a
+// table.
+func chainArbiters(L *lua.LState) int {
+	c := checkChain(L, 1)
+
+	table := L.NewTable()
+	for _, arbiter := range c.Arbiters.GetArbitrators() {
+		table.Append(lua.LString(common.BytesToHexString(arbiter.NodePublicKey)))
+	}
+	L.Push(table)
+
+	return 1
+}
+
+// chainProducer returns the state and votes of the producer with the given
+// owner or node public key, nil is returned if the producer does not exist.
+func chainProducer(L *lua.LState) int {
+	c := checkChain(L, 1)
+	publicKey, err := common.HexStringToBytes(L.CheckString(2))
+	if err != nil {
+		L.ArgError(2, "invalid public key hex")
+		return 0
+	}
+
+	p := c.Arbiters.State.GetProducer(publicKey)
+	if p == nil {
+		L.Push(lua.LNil)
+		return 1
+	}
+	table := L.NewTable()
+	table.RawSetString("state", lua.LString(p.State().String()))
+	table.RawSetString("votes", lua.LNumber(float64(p.Votes())/1e8))
+	table.RawSetString("owner_public_key",
+		lua.LString(common.BytesToHexString(p.OwnerPublicKey())))
+	table.RawSetString("node_public_key",
+		lua.LString(common.BytesToHexString(p.NodePublicKey())))
+	L.Push(table)
+
+	return 1
+}
+
+// chainCRCandidate returns the state and votes of the CR candidate with the
+// given CID address, nil is returned if the candidate does not exist.
+func chainCRCandidate(L *lua.LState) int {
+	c := checkChain(L, 1)
+	cid := checkAddress(L, 2)
+
+	candidate := c.Committee.GetCandidate(cid)
+	if candidate == nil {
+		L.Push(lua.LNil)
+		return 1
+	}
+	table := L.NewTable()
+	table.RawSetString("state", lua.LString(candidate.State.String()))
+	table.RawSetString("votes", lua.LNumber(float64(candidate.Votes)/1e8))
+	L.Push(table)
+
+	return 1
+}
+
+// chainCRMember returns the state of the CR member with the given DID
+// address, nil is returned if the member does not exist.
+func chainCRMember(L *lua.LState) int {
+	c := checkChain(L, 1)
+	did := checkAddress(L, 2)
+
+	member := c.Committee.GetMember(did)
+	if member == nil {
+		L.Push(lua.LNil)
+		return 1
+	}
+	table := L.NewTable()
+	table.RawSetString("state", lua.LString(member.MemberState.String()))
+	table.RawSetString("impeachment_votes",
+		lua.LNumber(float64(member.ImpeachmentVotes)/1e8))
+	L.Push(table)
+
+	return 1
+}
+
+func chainClose(L *lua.LState) int {
+	c := checkChain(L, 1)
+	c.Close()
+
+	return 0
+}
+
+// checkAddress checks whether the lua argument is an address and returns its
+// program hash.
+func checkAddress(L *lua.LState, idx int) common.Uint168 {
+	address := L.CheckString(idx)
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		L.ArgError(idx, "invalid address "+address)
+		return common.Uint168{}
+	}
+	return *programHash
+}
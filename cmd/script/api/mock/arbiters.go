@@ -0,0 +1,156 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mock
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/core/types"
+	dposaccount "github.com/elastos/Elastos.ELA/dpos/account"
+	"github.com/elastos/Elastos.ELA/dpos/dtime"
+	"github.com/elastos/Elastos.ELA/dpos/log"
+	. "github.com/elastos/Elastos.ELA/dpos/manager"
+	"github.com/elastos/Elastos.ELA/dpos/p2p/msg"
+	"github.com/elastos/Elastos.ELA/dpos/p2p/peer"
+	"github.com/elastos/Elastos.ELA/elanet"
+)
+
+// confirmTimeout is the duration to wait for the arbiters to confirm a
+// block.
+const confirmTimeout = 10 * time.Second
+
+// server is the network server seen by the arbiters, the chain is always
+// current as it has no peers to sync with.
+type server struct {
+	elanet.Server
+}
+
+func (s *server) IsCurrent() bool {
+	return true
+}
+
+// arbiter is a DPOS manager running on a mock network, the messages sent
+// through the network are delivered to the other arbiters by the chain.
+type arbiter struct {
+	pid       peer.PID
+	network   *network
+	manager   *DPOSManager
+	delivered int
+}
+
+// newArbiters creates a DPOS manager for each of the arbiter keys, the
+// managers share the pools of the chain in the same way as the arbiters of
+// a devnet node.
+func (c *Chain) newArbiters() []*arbiter {
+	cfg := c.Params
+	arbiters := make([]*arbiter, 0, len(c.Keys.Arbiters))
+	for _, key := range c.Keys.Arbiters {
+		acc := dposaccount.New(key)
+		medianTime := dtime.NewMedianTime()
+		dposManager := NewManager(DPOSManagerConfig{
+			PublicKey:   acc.PublicKeyBytes(),
+			Arbitrators: c.Arbiters,
+			ChainParams: cfg,
+			TimeSource:  medianTime,
+			Server:      &server{},
+		})
+		n := &network{}
+		eventMonitor := log.NewEventMonitor()
+		handler := NewHandler(DPOSHandlerConfig{
+			Network:     n,
+			Manager:     dposManager,
+			Monitor:     eventMonitor,
+			Arbitrators: c.Arbiters,
+			TimeSource:  medianTime,
+		})
+		consensus := NewConsensus(dposManager,
+			cfg.DPoSConfiguration.SignTolerance, handler,
+			cfg.DPoSConfiguration.ChangeViewV1Height)
+		dispatcher, illegalMonitor := NewDispatcherAndIllegalMonitor(
+			ProposalDispatcherConfig{
+				EventMonitor: eventMonitor,
+				Consensus:    consensus,
+				Network:      n,
+				Manager:      dposManager,
+				Account:      acc,
+				ChainParams:  cfg,
+				TimeSource:   medianTime,
+				EventAnalyzerConfig: EventAnalyzerConfig{
+					Arbitrators: c.Arbiters,
+				},
+			})
+		handler.Initialize(dispatcher, consensus)
+		dposManager.Initialize(acc, handler, dispatcher, consensus, n,
+			illegalMonitor, c.BlockPool, c.TxPool, c.broadcast)
+		n.Initialize(DPOSNetworkConfig{ProposalDispatcher: dispatcher})
+		n.SetListener(dposManager)
+
+		var pid peer.PID
+		copy(pid[:], acc.PublicKeyBytes())
+		arbiters = append(arbiters, &arbiter{
+			pid:     pid,
+			network: n,
+			manager: dposManager,
+		})
+	}
+	return arbiters
+}
+
+// confirm runs the DPOS consensus of the arbiters on the block in the block
+// pool, and waits until the block is confirmed and connected to the chain.
+func (c *Chain) confirm(block *types.Block) error {
+	for _, a := range c.arbiters {
+		a.network.FireBlockReceived(block, false)
+	}
+
+	deadline := time.Now().Add(confirmTimeout)
+	for c.Height() < block.Height {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("block at height %d is not confirmed in %s",
+				block.Height, confirmTimeout)
+		}
+		if !c.deliverMessages() {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	for _, a := range c.arbiters {
+		a.network.FireBlockReceived(block, true)
+	}
+	return nil
+}
+
+// deliverMessages delivers the proposals and votes sent by each arbiter to
+// the other arbiters, and returns if any message is delivered.
+func (c *Chain) deliverMessages() bool {
+	delivered := false
+	for _, from := range c.arbiters {
+		items := from.network.messages(from.delivered)
+		from.delivered += len(items)
+		for _, item := range items {
+			for _, to := range c.arbiters {
+				if to == from || item.ID != nil &&
+					!bytes.Equal(item.ID[:], to.pid[:]) {
+					continue
+				}
+				switch m := item.Message.(type) {
+				case *msg.Proposal:
+					to.network.FireProposalReceived(from.pid, &m.Proposal)
+				case *msg.Vote:
+					if m.Command == msg.CmdAcceptVote {
+						to.network.FireVoteReceived(from.pid, &m.Vote)
+					} else if m.Command == msg.CmdRejectVote {
+						to.network.FireVoteRejected(from.pid, &m.Vote)
+					}
+				}
+			}
+			delivered = true
+		}
+	}
+	return delivered
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/devnet"
+	dlog "github.com/elastos/Elastos.ELA/dpos/log"
+	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/node"
+	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/pow"
+)
+
+// chainLogPath is the path of the log files in the data directory of a
+// scenario chain.
+const chainLogPath = "logs"
+
+// current is the chain in use, the ledger of the blockchain package is
+// global so only one chain can be used at a time.
+var current *Chain
+
+// Chain is a blockchain running without network, which is used by scenario
+// scripts.  The chain uses the devnet parameters with generated keys and the
+// instant block difficulty, blocks are generated on demand and confirmed by
+// the DPOS managers of the arbiter keys, which exchange the proposals and
+// votes through mock networks.
+type Chain struct {
+	*node.Node
+	Params *config.Configuration
+	Keys   *devnet.Keys
+
+	pow       *pow.Service
+	arbiters  []*arbiter
+	dataDir   string
+	relayMtx  sync.Mutex
+	relayList []p2p.Message
+}
+
+// NewChain creates a chain with the given count of arbiters in a temporary
+// directory, which is removed when the chain is closed.  The log of the
+// chain is written into the directory with the given level.
+func NewChain(arbiters int, logLevel uint8) (*Chain, error) {
+	if current != nil {
+		return nil, errors.New("another chain is in use, close it first")
+	}
+
+	keys, err := devnet.GenerateKeys(arbiters)
+	if err != nil {
+		return nil, err
+	}
+	params := config.GetDefaultParams().DevNet().InstantBlock()
+	if err := keys.Apply(params); err != nil {
+		return nil, err
+	}
+	params = params.Sterilize()
+
+	dataDir, err := ioutil.TempDir("", "ela-scenario")
+	if err != nil {
+		return nil, err
+	}
+	log.NewDefault(filepath.Join(dataDir, chainLogPath), logLevel, 0, 0)
+	dlog.Init(dataDir, logLevel, 0, 0)
+	n, err := node.New(&node.Config{
+		ChainParams: params,
+		DataDir:     dataDir,
+	})
+	if err != nil {
+		os.RemoveAll(dataDir)
+		return nil, err
+	}
+	c := &Chain{Node: n, Params: params, Keys: keys, dataDir: dataDir}
+	n.RegisterFunctions(c.isCurrent, c.broadcast)
+	c.pow = n.NewPowService(func(block *types.Block) {})
+	if err := n.InitCheckpoint(nil, nil); err != nil {
+		c.Close()
+		return nil, err
+	}
+	c.arbiters = c.newArbiters()
+	current = c
+	return c, nil
+}
+
+func (c *Chain) isCurrent() bool {
+	return true
+}
+
+// broadcast records the messages broadcast by the chain states, such as the
+// transactions appended by the arbiters.
+func (c *Chain) broadcast(msg p2p.Message) {
+	c.relayMtx.Lock()
+	c.relayList = append(c.relayList, msg)
+	c.relayMtx.Unlock()
+}
+
+// Relays returns the messages broadcast by the chain states.
+func (c *Chain) Relays() []p2p.Message {
+	c.relayMtx.Lock()
+	defer c.relayMtx.Unlock()
+	return append([]p2p.Message(nil), c.relayList...)
+}
+
+// Height returns the height of the best block.
+func (c *Chain) Height() uint32 {
+	return c.Chain.GetHeight()
+}
+
+// Mine generates the given count of blocks with the transactions in the
+// transaction pool, and returns their hashes.
+func (c *Chain) Mine(count uint32) ([]common.Uint256, error) {
+	hashes := make([]common.Uint256, 0, count)
+	for i := uint32(0); i < count; i++ {
+		block, err := c.pow.GenerateBlock(c.Params.PowConfiguration.PayToAddr,
+			pact.MaxTxPerBlock)
+		if err != nil {
+			return hashes, err
+		}
+		if !c.pow.SolveBlock(block, nil) {
+			return hashes, fmt.Errorf("solve block at height %d failed",
+				block.Height)
+		}
+		if err := c.ProcessBlock(block); err != nil {
+			return hashes, err
+		}
+		hashes = append(hashes, block.Hash())
+	}
+	return hashes, nil
+}
+
+// ProcessBlock adds the block to the block pool like the sync manager does,
+// and runs the DPOS consensus of the arbiters on it if the block needs to be
+// confirmed, then checks that it is connected to the best chain.
+func (c *Chain) ProcessBlock(block *types.Block) error {
+	_, _, err := c.BlockPool.AddDposBlock(&types.DposBlock{Block: block})
+	if err != nil {
+		return err
+	}
+	if c.Height() < block.Height {
+		if err := c.confirm(block); err != nil {
+			return err
+		}
+	}
+
+	hash := block.Hash()
+	if bestHash := c.Chain.GetBestBlockHash(); !bestHash.IsEqual(hash) {
+		return fmt.Errorf("block at height %d is not connected to the "+
+			"best chain", block.Height)
+	}
+
+	// Remove the transactions in the block from the transaction pool, like
+	// the sync manager does when a block is connected.
+	c.TxPool.CleanSubmittedTransactions(block)
+	c.Chain.UTXOCache.CleanTxCache()
+	return nil
+}
+
+// Rollback disconnects the blocks above the given height from the best
+// chain.
+func (c *Chain) Rollback(height uint32) error {
+	if height >= c.Height() {
+		return fmt.Errorf("height %d is not below the best height %d",
+			height, c.Height())
+	}
+	hash, err := c.Chain.GetBlockHash(height)
+	if err != nil {
+		return err
+	}
+	block, err := c.Store.GetFFLDB().GetBlock(hash)
+	if err != nil {
+		return err
+	}
+	if err := c.Chain.ReorganizeChain(block.Block); err != nil {
+		return err
+	}
+	if c.Height() != height {
+		return fmt.Errorf("rollback to height %d failed, the best height "+
+			"is %d", height, c.Height())
+	}
+
+	// The arbiters have finished the consensus on the disconnected blocks,
+	// restart them to confirm the blocks mined on the rolled back chain.
+	c.arbiters = c.newArbiters()
+	return nil
+}
+
+// Fund sends the given amount from the faucet to the address through the
+// transaction pool, the transaction is packed by the next mined block.
+func (c *Chain) Fund(address common.Uint168,
+	amount common.Fixed64) (interfaces.Transaction, error) {
+	if amount <= 0 {
+		return nil, errors.New("invalid amount")
+	}
+	tx, err := devnet.CreateFundTransaction(c.Chain, c.TxPool,
+		c.Keys.Faucet, address, amount)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.TxPool.AppendToTxPool(tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// Close closes the chain and removes its data directory.
+func (c *Chain) Close() {
+	c.Node.Close()
+	os.RemoveAll(c.dataDir)
+	if current == c {
+		current = nil
+	}
+}
+
+// CloseChain closes the chain in use if any.
+func CloseChain() {
+	if current != nil {
+		current.Close()
+	}
+}
@@ -7,6 +7,7 @@ package mock
 
 import (
 	"fmt"
+	"sync"
 
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/core/types"
@@ -59,7 +60,10 @@ type messageItem struct {
 
 //mock object of dposNetwork
 type network struct {
-	listener    NetworkEventListener
+	listener NetworkEventListener
+
+	// messageList is appended by the goroutines of the DPOS manager.
+	mtx         sync.Mutex
 	messageList []messageItem
 }
 
@@ -68,6 +72,8 @@ func (n *network) RecoverTimeout() {
 }
 
 func (n *network) DumpMessages(level uint32) string {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
 	result := ""
 
 	switch level {
@@ -81,6 +87,8 @@ func (n *network) DumpMessages(level uint32) string {
 }
 
 func (n *network) GetLastMessage() p2p.Message {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
 	if len(n.messageList) == 0 {
 		return nil
 	}
@@ -88,6 +96,8 @@ func (n *network) GetLastMessage() p2p.Message {
 }
 
 func (n *network) GetLastPID() *peer.PID {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
 	if len(n.messageList) == 0 {
 		return nil
 	}
@@ -110,12 +120,23 @@ func (n *network) Stop() error {
 	return nil
 }
 
+// messages returns the messages sent after the given count of messages.
+func (n *network) messages(from int) []messageItem {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	return append([]messageItem(nil), n.messageList[from:]...)
+}
+
 func (n *network) SendMessageToPeer(id peer.PID, msg p2p.Message) error {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
 	n.messageList = append(n.messageList, messageItem{ID: &id, Message: msg})
 	return nil
 }
 
 func (n *network) BroadcastMessage(msg p2p.Message) {
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
 	n.messageList = append(n.messageList, messageItem{ID: nil, Message: msg})
 }
 
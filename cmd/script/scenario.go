@@ -0,0 +1,297 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package script
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/cmd/script/api"
+	"github.com/elastos/Elastos.ELA/cmd/script/api/mock"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// scenarioCase is a case registered by scenario.case in a scenario file.
+type scenarioCase struct {
+	name string
+	fn   *lua.LFunction
+}
+
+// junitFailure is the failure or error element of a JUnit test case.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// junitTestCase is the testcase element of a JUnit report.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+}
+
+// junitTestSuite is the testsuite element of a JUnit report, a scenario file
+// is reported as a test suite.
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      float64         `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestSuites is the root element of a JUnit report.
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Errors     int              `xml:"errors,attr"`
+	Time       float64          `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// add appends the test case to the suite and updates the counters.
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.TestCases = append(s.TestCases, tc)
+	s.Tests++
+	s.Time += tc.Time
+	if tc.Failure != nil {
+		s.Failures++
+	}
+	if tc.Error != nil {
+		s.Errors++
+	}
+}
+
+// add appends the test suite to the report and updates the counters.
+func (r *junitTestSuites) add(s junitTestSuite) {
+	r.TestSuites = append(r.TestSuites, s)
+	r.Tests += s.Tests
+	r.Failures += s.Failures
+	r.Errors += s.Errors
+	r.Time += s.Time
+}
+
+// scenarioFiles returns the scenario file of the path, or the lua files under
+// the path in lexical order if it is a directory.
+func scenarioFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = filepath.Walk(path, func(file string, info os.FileInfo,
+		err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".lua") {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no scenario file found in %s", path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// registerScenario registers the scenario table, which is used by the
+// scenario files to define cases and make assertions.
+func registerScenario(L *lua.LState, cases *[]scenarioCase) {
+	fail := func(L *lua.LState, idx int, format string, args ...interface{}) {
+		if msg, ok := L.Get(idx).(lua.LString); ok {
+			L.RaiseError("%s", string(msg))
+			return
+		}
+		L.RaiseError(format, args...)
+	}
+
+	mod := L.SetFuncs(L.NewTable(), map[string]lua.LGFunction{
+		"case": func(L *lua.LState) int {
+			*cases = append(*cases, scenarioCase{
+				name: L.CheckString(1),
+				fn:   L.CheckFunction(2),
+			})
+			return 0
+		},
+		"assert_true": func(L *lua.LState) int {
+			if !lua.LVAsBool(L.Get(1)) {
+				fail(L, 2, "assertion failed")
+			}
+			return 0
+		},
+		"assert_false": func(L *lua.LState) int {
+			if lua.LVAsBool(L.Get(1)) {
+				fail(L, 2, "assertion failed")
+			}
+			return 0
+		},
+		"assert_equal": func(L *lua.LState) int {
+			expected, actual := L.Get(1), L.Get(2)
+			if !L.Equal(expected, actual) {
+				fail(L, 3, "expected %s, got %s", expected.String(),
+					actual.String())
+			}
+			return 0
+		},
+		"assert_not_equal": func(L *lua.LState) int {
+			a, b := L.Get(1), L.Get(2)
+			if L.Equal(a, b) {
+				fail(L, 3, "expected not %s", a.String())
+			}
+			return 0
+		},
+		"assert_nil": func(L *lua.LState) int {
+			if v := L.Get(1); v != lua.LNil {
+				fail(L, 2, "expected nil, got %s", v.String())
+			}
+			return 0
+		},
+		"assert_not_nil": func(L *lua.LState) int {
+			if L.Get(1) == lua.LNil {
+				fail(L, 2, "expected not nil")
+			}
+			return 0
+		},
+		"assert_error": func(L *lua.LState) int {
+			L.Push(L.CheckFunction(1))
+			if err := L.PCall(0, 0, nil); err == nil {
+				fail(L, 2, "expected an error")
+			}
+			return 0
+		},
+	})
+	L.SetGlobal("scenario", mod)
+}
+
+// runScenarioFile runs the cases of the scenario file, a file without cases
+// is run as a single case.
+func runScenarioFile(file string) junitTestSuite {
+	name := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	suite := junitTestSuite{Name: name}
+
+	L := lua.NewState()
+	defer L.Close()
+	defer mock.CloseChain()
+	L.PreloadModule("api", api.Loader)
+	api.RegisterDataType(L)
+	var cases []scenarioCase
+	registerScenario(L, &cases)
+
+	start := time.Now()
+	if err := L.DoFile(file); err != nil {
+		tc := junitTestCase{Name: name, ClassName: name,
+			Time: time.Since(start).Seconds()}
+		if len(cases) == 0 {
+			tc.Failure = newJUnitFailure("failure", err)
+		} else {
+			tc.Error = newJUnitFailure("error", err)
+		}
+		printScenarioCase(&tc)
+		suite.add(tc)
+		return suite
+	}
+	if len(cases) == 0 {
+		tc := junitTestCase{Name: name, ClassName: name,
+			Time: time.Since(start).Seconds()}
+		printScenarioCase(&tc)
+		suite.add(tc)
+		return suite
+	}
+
+	for _, c := range cases {
+		start := time.Now()
+		tc := junitTestCase{Name: c.name, ClassName: name}
+		L.Push(c.fn)
+		if err := L.PCall(0, 0, nil); err != nil {
+			tc.Failure = newJUnitFailure("failure", err)
+		}
+		tc.Time = time.Since(start).Seconds()
+		printScenarioCase(&tc)
+		suite.add(tc)
+	}
+	return suite
+}
+
+func newJUnitFailure(typ string, err error) *junitFailure {
+	msg := err.Error()
+	if e, ok := err.(*lua.ApiError); ok {
+		msg = e.Object.String()
+	}
+	return &junitFailure{
+		Message: strings.SplitN(msg, "\n", 2)[0],
+		Type:    typ,
+		Content: err.Error(),
+	}
+}
+
+func printScenarioCase(tc *junitTestCase) {
+	switch {
+	case tc.Failure != nil:
+		fmt.Printf("--- FAIL: %s/%s (%.2fs)\n    %s\n", tc.ClassName,
+			tc.Name, tc.Time, tc.Failure.Message)
+	case tc.Error != nil:
+		fmt.Printf("--- ERROR: %s/%s (%.2fs)\n    %s\n", tc.ClassName,
+			tc.Name, tc.Time, tc.Error.Message)
+	default:
+		fmt.Printf("--- PASS: %s/%s (%.2fs)\n", tc.ClassName, tc.Name,
+			tc.Time)
+	}
+}
+
+// runScenarios runs the scenario files of the path, and writes the JUnit
+// report to the report file if it is not empty.
+func runScenarios(path, report string) error {
+	files, err := scenarioFiles(path)
+	if err != nil {
+		return err
+	}
+
+	var result junitTestSuites
+	for _, file := range files {
+		result.add(runScenarioFile(file))
+	}
+
+	if report != "" {
+		data, err := xml.MarshalIndent(&result, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = append([]byte(xml.Header), data...)
+		if err := ioutil.WriteFile(report, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+
+	if result.Failures > 0 || result.Errors > 0 {
+		fmt.Printf("FAIL %d cases, %d failures, %d errors (%.2fs)\n",
+			result.Tests, result.Failures, result.Errors, result.Time)
+		return fmt.Errorf("%d of %d scenario cases failed",
+			result.Failures+result.Errors, result.Tests)
+	}
+	fmt.Printf("PASS %d cases (%.2fs)\n", result.Tests, result.Time)
+	return nil
+}
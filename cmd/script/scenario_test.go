@@ -0,0 +1,80 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package script
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/core/transaction"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	functions.GetTransactionByTxType = transaction.GetTransaction
+	functions.GetTransactionByBytes = transaction.GetTransactionByBytes
+	functions.CreateTransaction = transaction.CreateTransaction
+	functions.GetTransactionParameters = transaction.GetTransactionparameters
+}
+
+func TestRunScenarios(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenario-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	report := filepath.Join(dir, "report.xml")
+	assert.NoError(t, runScenarios("../../test/scenario", report))
+
+	data, err := ioutil.ReadFile(report)
+	assert.NoError(t, err)
+	var result junitTestSuites
+	assert.NoError(t, xml.Unmarshal(data, &result))
+	assert.Equal(t, 2, len(result.TestSuites))
+	assert.Equal(t, 0, result.Failures)
+	assert.Equal(t, 0, result.Errors)
+	assert.True(t, result.Tests > 2)
+}
+
+func TestRunScenariosFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scenario-test")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "failure.lua")
+	assert.NoError(t, ioutil.WriteFile(file, []byte(`
+local c = chain.new(3)
+scenario.case("pass", function()
+    c:mine(2)
+    scenario.assert_equal(2, c:height())
+end)
+scenario.case("fail", function()
+    scenario.assert_equal(3, c:height(), "wrong height")
+end)
+scenario.case("raise", function()
+    c:rollback(5)
+end)
+`), 0644))
+
+	report := filepath.Join(dir, "report.xml")
+	assert.Error(t, runScenarios(file, report))
+
+	data, err := ioutil.ReadFile(report)
+	assert.NoError(t, err)
+	var result junitTestSuites
+	assert.NoError(t, xml.Unmarshal(data, &result))
+	assert.Equal(t, 3, result.Tests)
+	assert.Equal(t, 2, result.Failures)
+
+	cases := result.TestSuites[0].TestCases
+	assert.Nil(t, cases[0].Failure)
+	assert.Contains(t, cases[1].Failure.Message, "wrong height")
+	assert.Contains(t, cases[2].Failure.Message, "rollback error")
+}
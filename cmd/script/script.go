@@ -485,6 +485,11 @@ func scriptAction(c *cli.Context) error {
 	fileContent := c.String("file")
 	strContent := c.String("str")
 	testContent := c.String("test")
+	scenarioContent := c.String("scenario")
+
+	if scenarioContent != "" {
+		return runScenarios(scenarioContent, c.String("report"))
+	}
 
 	L := lua.NewState()
 	defer L.Close()
@@ -538,6 +543,14 @@ func NewCommand() *cli.Command {
 				Name:  "test, t",
 				Usage: "white box test",
 			},
+			cli.StringFlag{
+				Name:  "scenario",
+				Usage: "run the scenario file or the scenario files in the directory on an offline chain",
+			},
+			cli.StringFlag{
+				Name:  "report",
+				Usage: "write the JUnit report of the scenarios to the file",
+			},
 			cli.StringFlag{
 				Name:  "publickey, pk",
 				Usage: "set the public key",
@@ -0,0 +1,125 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/config/settings"
+	"github.com/elastos/Elastos.ELA/utils/http"
+
+	"github.com/urfave/cli"
+)
+
+func printFormat(data interface{}) {
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	json.Indent(buf, dataBytes, "", "    ")
+	fmt.Println(string(buf.Bytes()))
+}
+
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "snapshot",
+		Usage: "Dump or verify UTXO set snapshots",
+		Description: "With ela-cli snapshot command, you could dump the " +
+			"chain state of a running node into a snapshot file, and " +
+			"verify a snapshot file before bootstrapping a node from it.",
+		ArgsUsage: "[args]",
+		Subcommands: []cli.Command{
+			{
+				Name:  "dump",
+				Usage: "Dump the chain state of the node into a snapshot file",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "path",
+						Usage: "the snapshot `<file>` to create",
+						Value: "snapshot.dat",
+					},
+				},
+				Action: dumpAction,
+			},
+			{
+				Name:  "verify",
+				Usage: "Verify a snapshot file and show its content hash",
+				Flags: []cli.Flag{
+					cli.StringFlag{
+						Name:  "in",
+						Usage: "the snapshot `<file>` to verify",
+						Value: "snapshot.dat",
+					},
+					cli.StringFlag{
+						Name:  "hash",
+						Usage: "the expected content `<hash>` of the snapshot",
+					},
+					cmdcom.ConfigFileFlag,
+				},
+				Action: verifyAction,
+			},
+		},
+	}
+}
+
+func dumpAction(c *cli.Context) error {
+	// The file is written by the node, so pass an absolute path.
+	path, err := filepath.Abs(c.String("path"))
+	if err != nil {
+		return err
+	}
+	result, err := cmdcom.RPCCall("dumpsnapshot", http.Params{"path": path})
+	if err != nil {
+		fmt.Println("error: dump snapshot failed,", err)
+		return err
+	}
+	printFormat(result)
+	return nil
+}
+
+func verifyAction(c *cli.Context) error {
+	config.DefaultParams.Conf = c.String("conf")
+	cfg := settings.NewSettings().SetupConfig(false, "", "")
+
+	file, err := os.Open(c.String("in"))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := blockchain.VerifySnapshot(bufio.NewReader(file), cfg)
+	if err != nil {
+		fmt.Println("error: invalid snapshot,", err)
+		return err
+	}
+	contentHash := hex.EncodeToString(info.ContentHash[:])
+	printFormat(map[string]interface{}{
+		"height":       info.Height,
+		"hash":         common.ToReversedString(info.Hash),
+		"contenthash":  contentHash,
+		"transactions": info.Transactions,
+		"blocks":       info.Blocks,
+		"checkpoints":  info.Checkpoints,
+	})
+	if c.IsSet("hash") && c.String("hash") != contentHash {
+		return errors.New("content hash does not match the expected hash")
+	}
+	return nil
+}
@@ -6,6 +6,7 @@
 package wallet
 
 import (
+	"bufio"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -18,6 +19,7 @@ import (
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/crypto"
 	"github.com/elastos/Elastos.ELA/utils"
+	"github.com/elastos/Elastos.ELA/utils/http"
 
 	"github.com/urfave/cli"
 )
@@ -31,9 +33,44 @@ var accountCommand = []cli.Command{
 		Flags: []cli.Flag{
 			cmdcom.AccountWalletFlag,
 			cmdcom.AccountPasswordFlag,
+			cmdcom.AccountHDFlag,
+			cmdcom.AccountPassphraseFlag,
 		},
 		Action: createAccount,
 	},
+	{
+		Category: "Account",
+		Name:     "restore",
+		Usage:    "Restore a HD wallet from mnemonic",
+		Flags: []cli.Flag{
+			cmdcom.AccountWalletFlag,
+			cmdcom.AccountPasswordFlag,
+			cmdcom.AccountMnemonicFlag,
+			cmdcom.AccountPassphraseFlag,
+			cmdcom.AccountGapLimitFlag,
+		},
+		Action: restoreAccount,
+	},
+	{
+		Category: "Account",
+		Name:     "mnemonic",
+		Usage:    "Show the mnemonic of HD wallet",
+		Flags: []cli.Flag{
+			cmdcom.AccountWalletFlag,
+			cmdcom.AccountPasswordFlag,
+		},
+		Action: showMnemonic,
+	},
+	{
+		Category: "Account",
+		Name:     "upgrade",
+		Usage:    "Re-encrypt the keystore in the latest version",
+		Flags: []cli.Flag{
+			cmdcom.AccountWalletFlag,
+			cmdcom.AccountPasswordFlag,
+		},
+		Action: upgradeKeystore,
+	},
 	{
 		Category: "Account",
 		Name:     "account",
@@ -77,6 +114,57 @@ var accountCommand = []cli.Command{
 		},
 		Action: addMultiSigAccount,
 	},
+	{
+		Category: "Account",
+		Name:     "addschnorr",
+		Usage:    "Add a Schnorr account aggregated from public keys",
+		Flags: []cli.Flag{
+			cmdcom.AccountWalletFlag,
+			cmdcom.AccountPasswordFlag,
+			cmdcom.AccountMultiPubKeyFlag,
+		},
+		Action: addSchnorrAccount,
+	},
+	{
+		Category: "Account",
+		Name:     "signer",
+		Usage:    "Serve the main account as a remote signer of an arbiter node",
+		Flags: []cli.Flag{
+			cmdcom.AccountWalletFlag,
+			cmdcom.AccountPasswordFlag,
+			cmdcom.AccountSignerSocketFlag,
+			cmdcom.AccountSignerAllowFlag,
+			cmdcom.AccountProtectionFlag,
+			cmdcom.AccountSignerRefuseFlag,
+		},
+		Action: serveSigner,
+	},
+	{
+		Category:  "Account",
+		Name:      "protection",
+		Usage:     "Export or import the signed proposals and votes of an arbiter",
+		ArgsUsage: "[args]",
+		Subcommands: []cli.Command{
+			{
+				Name:      "export",
+				Usage:     "Export the records to a file, or print them if no file given",
+				ArgsUsage: "[file]",
+				Flags: []cli.Flag{
+					cmdcom.AccountProtectionFlag,
+				},
+				Action: exportProtection,
+			},
+			{
+				Name:      "import",
+				Usage:     "Import the records exported on another machine",
+				ArgsUsage: "<file>",
+				Flags: []cli.Flag{
+					cmdcom.AccountProtectionFlag,
+				},
+				Action: importProtection,
+			},
+		},
+	},
 	{
 		Category: "Account",
 		Name:     "delete",
@@ -155,7 +243,57 @@ func createAccount(c *cli.Context) error {
 		p = []byte(password)
 	}
 
-	client, err := account.Create(walletPath, p)
+	if !c.Bool(cmdcom.AccountHDFlag.Name) {
+		client, err := account.Create(walletPath, p)
+		if err != nil {
+			return err
+		}
+		return ShowAccountInfo(client)
+	}
+
+	client, err := account.CreateHD(walletPath, p, "",
+		c.String(cmdcom.AccountPassphraseFlag.Name))
+	if err != nil {
+		return err
+	}
+	if err := printMnemonic(client); err != nil {
+		return err
+	}
+	return ShowAccountInfo(client)
+}
+
+func restoreAccount(c *cli.Context) error {
+	walletPath := c.String("wallet")
+	if utils.FileExisted(walletPath) {
+		return fmt.Errorf("%s already exist", walletPath)
+	}
+	mnemonic := c.String(cmdcom.AccountMnemonicFlag.Name)
+	if mnemonic == "" {
+		fmt.Print("Mnemonic:")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return err
+		}
+		mnemonic = strings.TrimSpace(line)
+	}
+	if _, err := crypto.MnemonicToEntropy(mnemonic); err != nil {
+		return err
+	}
+
+	var p []byte
+	if password := c.String("password"); password == "" {
+		var err error
+		p, err = utils.GetConfirmedPassword()
+		if err != nil {
+			return err
+		}
+	} else {
+		p = []byte(password)
+	}
+
+	client, err := account.Restore(walletPath, p, mnemonic,
+		c.String(cmdcom.AccountPassphraseFlag.Name),
+		c.Int(cmdcom.AccountGapLimitFlag.Name), isAddressUsed)
 	if err != nil {
 		return err
 	}
@@ -163,6 +301,71 @@ func createAccount(c *cli.Context) error {
 	return ShowAccountInfo(client)
 }
 
+// isAddressUsed checks whether the address of account has ever received
+// transactions by the address index of node, and falls back to the UTXO
+// index if the address index is not enabled.
+func isAddressUsed(acc *account.Account) (bool, error) {
+	result, err := cmdcom.RPCCall("getaddresstxcount", http.Params{
+		"address": acc.Address,
+	})
+	if err == nil {
+		count, ok := result.(float64)
+		if !ok {
+			return false, errors.New("invalid address tx count")
+		}
+		return count > 0, nil
+	}
+
+	available, locked, err := getAddressUTXOs(acc.Address)
+	if err != nil {
+		return false, err
+	}
+	return len(available) > 0 || len(locked) > 0, nil
+}
+
+func showMnemonic(c *cli.Context) error {
+	walletPath := c.String("wallet")
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return err
+	}
+
+	client, err := account.Open(walletPath, password)
+	if err != nil {
+		return err
+	}
+	return printMnemonic(client)
+}
+
+func printMnemonic(client *account.Client) error {
+	mnemonic, err := client.GetMnemonic()
+	if err != nil {
+		return err
+	}
+	fmt.Println("MNEMONIC (write it down and keep it safe, anyone has it" +
+		" can spend your assets):")
+	fmt.Println(mnemonic)
+	fmt.Println()
+	return nil
+}
+
+func upgradeKeystore(c *cli.Context) error {
+	walletPath := c.String("wallet")
+	if exist := utils.FileExisted(walletPath); !exist {
+		return fmt.Errorf("%s is not found", walletPath)
+	}
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return err
+	}
+
+	if err := account.Upgrade(walletPath, password); err != nil {
+		return err
+	}
+	fmt.Println("keystore upgraded to version", account.KeystoreVersion)
+	return nil
+}
+
 func accountInfo(c *cli.Context) error {
 	walletPath := c.String("wallet")
 	if exist := utils.FileExisted(walletPath); !exist {
@@ -248,6 +451,39 @@ func addMultiSigAccount(c *cli.Context) error {
 	return nil
 }
 
+func addSchnorrAccount(c *cli.Context) error {
+	walletPath := c.String("wallet")
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return err
+	}
+	pksStr := c.String("pubkeys")
+	pksStr = strings.TrimSpace(strings.Trim(pksStr, ","))
+	if pksStr == "" {
+		return errors.New("missing arguments. pubkeys expected")
+	}
+
+	pubKeys := make([][]byte, 0)
+	for _, pk := range strings.Split(pksStr, ",") {
+		pkBytes, err := common.HexStringToBytes(strings.TrimSpace(pk))
+		if err != nil {
+			return err
+		}
+		if _, err := crypto.DecodePoint(pkBytes); err != nil {
+			return err
+		}
+		pubKeys = append(pubKeys, pkBytes)
+	}
+
+	account, err := account.AddSchnorr(walletPath, password, pubKeys)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(account.Address)
+	return nil
+}
+
 func delAccount(c *cli.Context) error {
 	walletPath := c.String("wallet")
 	password, err := cmdcom.GetFlagPassword(c)
@@ -0,0 +1,187 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/crypto"
+	"github.com/elastos/Elastos.ELA/utils/http"
+
+	"github.com/urfave/cli"
+)
+
+// signalReplacement marks the inputs of the transaction to signal that it
+// can be replaced by fee, inputs spending locked outputs are left untouched.
+func signalReplacement(txn interfaces.Transaction) {
+	for _, input := range txn.Inputs() {
+		if input.Sequence == math.MaxUint32 {
+			input.Sequence = common2.RBFSequence
+		}
+	}
+}
+
+// getRawTransaction gets the transaction by hash from the node.
+func getRawTransaction(txID string) (interfaces.Transaction, error) {
+	result, err := cmdcom.RPCCall("getrawtransaction", http.Params{
+		"txid": txID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	txHex, ok := result.(string)
+	if !ok {
+		return nil, errors.New("invalid transaction data")
+	}
+	rawData, err := common.HexStringToBytes(txHex)
+	if err != nil {
+		return nil, errors.New("decode transaction content failed")
+	}
+
+	r := bytes.NewReader(rawData)
+	txn, err := functions.GetTransactionByBytes(r)
+	if err != nil {
+		return nil, errors.New("invalid transaction")
+	}
+	if err := txn.Deserialize(r); err != nil {
+		return nil, errors.New("deserialize transaction failed")
+	}
+	return txn, nil
+}
+
+// getTransactionFee calculates the fee of transaction by getting the outputs
+// referenced by inputs from the node.
+func getTransactionFee(txn interfaces.Transaction) (common.Fixed64, error) {
+	var inputAmount common.Fixed64
+	for _, input := range txn.Inputs() {
+		referTx, err := getRawTransaction(input.Previous.TxID.ReversedString())
+		if err != nil {
+			return 0, err
+		}
+		if int(input.Previous.Index) >= len(referTx.Outputs()) {
+			return 0, errors.New("invalid input " + input.ReferKey())
+		}
+		inputAmount += referTx.Outputs()[input.Previous.Index].Value
+	}
+
+	var outputAmount common.Fixed64
+	for _, output := range txn.Outputs() {
+		outputAmount += output.Value
+	}
+	return inputAmount - outputAmount, nil
+}
+
+func bumpFee(c *cli.Context) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	txID := c.String(cmdcom.TransactionIDFlag.Name)
+	if txID == "" {
+		return errors.New("use --txid to specify the transaction to replace")
+	}
+	feeStr := c.String(cmdcom.TransactionFeeFlag.Name)
+	if feeStr == "" {
+		return errors.New("use --fee to specify the new transaction fee")
+	}
+	fee, err := common.StringToFixed64(feeStr)
+	if err != nil {
+		return errors.New("invalid transaction fee")
+	}
+
+	walletPath := c.String("wallet")
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return err
+	}
+	client, err := account.Open(walletPath, password)
+	if err != nil {
+		return err
+	}
+
+	txn, err := getRawTransaction(txID)
+	if err != nil {
+		return err
+	}
+	replaceable := false
+	for _, input := range txn.Inputs() {
+		if input.SignalsReplacement() {
+			replaceable = true
+			break
+		}
+	}
+	if !replaceable {
+		return errors.New("transaction did not signal replace-by-fee")
+	}
+
+	oldFee, err := getTransactionFee(txn)
+	if err != nil {
+		return err
+	}
+	// The transaction pool accepts a replacement only if it pays for the
+	// replaced transactions and for its own relay, and has a higher fee rate.
+	minFee := oldFee + config.GetDefaultParams().MinTransactionFee
+	if *fee < minFee {
+		return fmt.Errorf("new fee must be at least %s, the current fee %s"+
+			" plus the minimum transaction fee", minFee, oldFee)
+	}
+	oldSize := txn.GetSize()
+
+	// Pay the increased fee from the last output back to the wallet.
+	var change *common2.Output
+	for i := len(txn.Outputs()) - 1; i >= 0; i-- {
+		output := txn.Outputs()[i]
+		acc := client.GetAccountByCodeHash(output.ProgramHash.ToCodeHash())
+		if acc != nil && acc.ProgramHash.IsEqual(output.ProgramHash) {
+			change = output
+			break
+		}
+	}
+	if change == nil {
+		return errors.New("no change output of the wallet found in transaction")
+	}
+	if change.Value <= *fee-oldFee {
+		return errors.New("change output is not enough to pay the new fee")
+	}
+	change.Value -= *fee - oldFee
+	if float64(*fee)/float64(txn.GetSize()) <=
+		float64(oldFee)/float64(oldSize) {
+		return errors.New("fee rate of the new transaction is not higher" +
+			" than the current one")
+	}
+
+	// Signatures of the replaced transaction are invalid now.
+	for _, program := range txn.Programs() {
+		program.Parameter = nil
+	}
+	txnSigned, err := client.Sign(txn)
+	if err != nil {
+		return err
+	}
+
+	if len(txnSigned.Programs()) == 0 {
+		return errors.New("no program found in transaction")
+	}
+	haveSign, needSign, _ := crypto.GetSignStatus(txnSigned.Programs()[0].Code,
+		txnSigned.Programs()[0].Parameter)
+	fmt.Println("Fee bumped from", oldFee, "to", fee.String())
+	fmt.Println("[", haveSign, "/", needSign, "] BaseTransaction was successfully signed")
+
+	OutputTx(haveSign, needSign, txnSigned)
+
+	return nil
+}
@@ -64,6 +64,9 @@ func ShowAccountInfo(client *account.Client) error {
 		if err != nil {
 			return err
 		}
+		if contract.IsSchnorr(acc.RedeemScript) {
+			publicKey = acc.RedeemScript[2:]
+		}
 		prefixType := contract.GetPrefixType(acc.ProgramHash)
 		if prefixType == contract.PrefixStandard {
 			fmt.Printf("%-34s %-66s\n", addr, hex.EncodeToString(publicKey))
@@ -0,0 +1,317 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package wallet
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	mrand "math/rand"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+
+	"github.com/urfave/cli"
+)
+
+var htlcCommand = cli.Command{
+	Category:    "Transaction",
+	Name:        "htlc",
+	Usage:       "Create, redeem and refund a hash time locked contract",
+	Description: "With ela-cli wallet htlc, ELA could be swapped with other chains atomically.",
+	ArgsUsage:   "[args]",
+	Subcommands: []cli.Command{
+		{
+			Name:        "create",
+			Usage:       "Create the address of a hash time locked contract",
+			Description: "use --recipient, --refund and --locktime to specify the terms of the contract",
+			Flags: []cli.Flag{
+				cmdcom.HTLCRecipientFlag,
+				cmdcom.HTLCRefundFlag,
+				cmdcom.HTLCHashFlag,
+				cmdcom.HTLCLockTimeFlag,
+			},
+			Action: createHTLC,
+		},
+		{
+			Name:        "redeem",
+			Usage:       "Redeem the contract with the preimage by the recipient",
+			Description: "use --script and --preimage to specify the contract and its preimage",
+			Flags: []cli.Flag{
+				cmdcom.HTLCScriptFlag,
+				cmdcom.HTLCPreimageFlag,
+				cmdcom.TransactionToFlag,
+				cmdcom.TransactionFeeFlag,
+				cmdcom.AccountWalletFlag,
+				cmdcom.AccountPasswordFlag,
+			},
+			Action: redeemHTLC,
+		},
+		{
+			Name:        "refund",
+			Usage:       "Refund the contract after the lock time",
+			Description: "use --script to specify the contract",
+			Flags: []cli.Flag{
+				cmdcom.HTLCScriptFlag,
+				cmdcom.TransactionToFlag,
+				cmdcom.TransactionFeeFlag,
+				cmdcom.AccountWalletFlag,
+				cmdcom.AccountPasswordFlag,
+			},
+			Action: refundHTLC,
+		},
+	},
+}
+
+func parsePublicKey(pubKeyHex string) (*crypto.PublicKey, error) {
+	pubKey, err := common.HexStringToBytes(pubKeyHex)
+	if err != nil {
+		return nil, errors.New("invalid public key " + pubKeyHex)
+	}
+	return crypto.DecodePoint(pubKey)
+}
+
+func createHTLC(c *cli.Context) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	recipientStr := c.String(cmdcom.HTLCRecipientFlag.Name)
+	if recipientStr == "" {
+		return errors.New("use --recipient to specify the public key of recipient")
+	}
+	recipient, err := parsePublicKey(recipientStr)
+	if err != nil {
+		return err
+	}
+	refundStr := c.String(cmdcom.HTLCRefundFlag.Name)
+	if refundStr == "" {
+		return errors.New("use --refund to specify the public key to refund")
+	}
+	refund, err := parsePublicKey(refundStr)
+	if err != nil {
+		return err
+	}
+	lockTimeStr := c.String(cmdcom.HTLCLockTimeFlag.Name)
+	if lockTimeStr == "" {
+		return errors.New("use --locktime to specify the refund lock time")
+	}
+	lockTime, err := strconv.ParseUint(lockTimeStr, 10, 32)
+	if err != nil {
+		return errors.New("invalid lock time")
+	}
+
+	var preimage []byte
+	var hashLock common.Uint256
+	if hashStr := c.String(cmdcom.HTLCHashFlag.Name); hashStr != "" {
+		hash, err := common.HexStringToBytes(hashStr)
+		if err != nil || len(hash) != len(hashLock) {
+			return errors.New("invalid hash lock")
+		}
+		copy(hashLock[:], hash)
+	} else {
+		preimage = make([]byte, contract.HTLCPreimageLength)
+		if _, err := rand.Read(preimage); err != nil {
+			return err
+		}
+		hashLock = sha256.Sum256(preimage)
+	}
+
+	ct, err := contract.CreateHTLCContract(hashLock, recipient, refund,
+		uint32(lockTime))
+	if err != nil {
+		return err
+	}
+	address, err := ct.ToProgramHash().ToAddress()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Address:     ", address)
+	fmt.Println("RedeemScript:", common.BytesToHexString(ct.Code))
+	fmt.Println("HashLock:    ", common.BytesToHexString(hashLock[:]))
+	if preimage != nil {
+		fmt.Println("Preimage:    ", common.BytesToHexString(preimage))
+	}
+	return nil
+}
+
+func redeemHTLC(c *cli.Context) error {
+	return spendHTLC(c, false)
+}
+
+func refundHTLC(c *cli.Context) error {
+	return spendHTLC(c, true)
+}
+
+// spendHTLC transfers all coins locked in the contract to the recipient by
+// preimage, or to the refund key after the lock time.
+func spendHTLC(c *cli.Context, refund bool) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	scriptStr := c.String(cmdcom.HTLCScriptFlag.Name)
+	if scriptStr == "" {
+		return errors.New("use --script to specify the redeem script of contract")
+	}
+	code, err := common.HexStringToBytes(scriptStr)
+	if err != nil {
+		return errors.New("invalid redeem script")
+	}
+	htlc, err := contract.ParseHTLC(code)
+	if err != nil {
+		return err
+	}
+
+	var preimage []byte
+	if !refund {
+		preimage, err = common.HexStringToBytes(c.String(cmdcom.HTLCPreimageFlag.Name))
+		if err != nil || len(preimage) != contract.HTLCPreimageLength {
+			return errors.New("use --preimage to specify the 32 bytes preimage")
+		}
+		if !htlc.HashLock.IsEqual(sha256.Sum256(preimage)) {
+			return errors.New("preimage does not match the hash lock")
+		}
+	}
+
+	feeStr := c.String(cmdcom.TransactionFeeFlag.Name)
+	if feeStr == "" {
+		return errors.New("use --fee to specify transfer fee")
+	}
+	fee, err := common.StringToFixed64(feeStr)
+	if err != nil {
+		return errors.New("invalid transaction fee")
+	}
+
+	walletPath := c.String("wallet")
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return err
+	}
+	client, err := account.Open(walletPath, password)
+	if err != nil {
+		return err
+	}
+	signer := htlc.Recipient
+	if refund {
+		signer = htlc.Refund
+	}
+	acc, err := client.GetAccount(signer)
+	if err != nil {
+		return err
+	}
+	if acc == nil {
+		return errors.New("no account of the contract key found in wallet")
+	}
+
+	to := c.String(cmdcom.TransactionToFlag.Name)
+	if to == "" {
+		to = acc.Address
+	}
+	recipient, err := common.Uint168FromAddress(to)
+	if err != nil {
+		return errors.New("invalid receiver address: " + to)
+	}
+
+	// Spend all coins locked in the contract.
+	address, err := common.ToProgramHash(byte(contract.PrefixHTLC), code).ToAddress()
+	if err != nil {
+		return err
+	}
+	UTXOs, _, err := getAddressUTXOs(address)
+	if err != nil {
+		return err
+	}
+	if len(UTXOs) == 0 {
+		return errors.New("no unspent output found in " + address)
+	}
+	sequence := uint32(math.MaxUint32)
+	if refund {
+		sequence = math.MaxUint32 - 1
+	}
+	var txInputs []*common2.Input
+	var amount common.Fixed64
+	for _, utxo := range UTXOs {
+		txID, err := common.Uint256FromReversedHexString(utxo.TxID)
+		if err != nil {
+			return err
+		}
+		value, err := common.StringToFixed64(utxo.Amount)
+		if err != nil {
+			return err
+		}
+		txInputs = append(txInputs, &common2.Input{
+			Previous: common2.OutPoint{
+				TxID:  *txID,
+				Index: utxo.VOut,
+			},
+			Sequence: sequence,
+		})
+		amount += *value
+	}
+	if amount <= *fee {
+		return errors.New("locked amount is not enough to pay the fee")
+	}
+	txOutputs := []*common2.Output{{
+		AssetID:     *account.SystemAssetID,
+		Value:       amount - *fee,
+		OutputLock:  0,
+		ProgramHash: *recipient,
+		Type:        common2.OTNone,
+		Payload:     &outputpayload.DefaultOutput{},
+	}}
+
+	// A refund locked by height can not be packed until the lock height.
+	var txLock uint32
+	if refund && !htlc.IsTimeLock() {
+		txLock = htlc.RefundLock
+	}
+
+	txAttr := common2.NewAttribute(common2.Nonce,
+		[]byte(strconv.FormatInt(mrand.Int63(), 10)))
+	txn := functions.CreateTransaction(
+		common2.TxVersion09,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{&txAttr},
+		txInputs,
+		txOutputs,
+		txLock,
+		[]*pg.Program{{Code: code}},
+	)
+
+	signature, err := account.SignBySigner(txn, acc)
+	if err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(len(signature)))
+	buf.Write(signature)
+	if !refund {
+		buf.WriteByte(byte(len(preimage)))
+		buf.Write(preimage)
+	}
+	txn.Programs()[0].Parameter = buf.Bytes()
+
+	fmt.Println("[ 1 / 1 ] BaseTransaction was successfully signed")
+	return OutputTx(1, 1, txn)
+}
@@ -0,0 +1,462 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package wallet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/account"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/psbt"
+
+	"github.com/urfave/cli"
+)
+
+// psbtFileName is the file the partially signed transaction is written to.
+const psbtFileName = "partially_signed.psbt"
+
+var psbtCommand = cli.Command{
+	Category:    "Transaction",
+	Name:        "psbt",
+	Usage:       "Create, sign, combine and finalize a partially signed transaction",
+	Description: "With ela-cli wallet psbt, signers could sign a transaction offline.",
+	ArgsUsage:   "[args]",
+	Subcommands: []cli.Command{
+		{
+			Name:        "create",
+			Usage:       "Create a partially signed transaction from a transaction",
+			Description: "use --file or --hex to specify the transaction built by buildtx",
+			Flags: []cli.Flag{
+				cmdcom.TransactionHexFlag,
+				cmdcom.TransactionFileFlag,
+				cmdcom.TransactionSchnorrFlag,
+				cmdcom.AccountWalletFlag,
+			},
+			Action: createPSBT,
+		},
+		{
+			Name:        "show",
+			Usage:       "Show the inputs, outputs and signing status",
+			Description: "use --file or --hex to specify the partially signed transaction",
+			Flags: []cli.Flag{
+				cmdcom.TransactionHexFlag,
+				cmdcom.TransactionFileFlag,
+			},
+			Action: showPSBT,
+		},
+		{
+			Name:        "nonce",
+			Usage:       "Add the nonces of wallet accounts to Schnorr programs",
+			Description: "use --file or --hex to specify the partially signed transaction",
+			Flags: []cli.Flag{
+				cmdcom.TransactionHexFlag,
+				cmdcom.TransactionFileFlag,
+				cmdcom.AccountWalletFlag,
+				cmdcom.AccountPasswordFlag,
+			},
+			Action: noncePSBT,
+		},
+		{
+			Name:        "sign",
+			Usage:       "Sign by wallet accounts",
+			Description: "use --file or --hex to specify the partially signed transaction",
+			Flags: []cli.Flag{
+				cmdcom.TransactionHexFlag,
+				cmdcom.TransactionFileFlag,
+				cmdcom.AccountWalletFlag,
+				cmdcom.AccountPasswordFlag,
+			},
+			Action: signPSBT,
+		},
+		{
+			Name:      "combine",
+			Usage:     "Combine the signatures of partially signed transactions",
+			ArgsUsage: "<file> <file> [file...]",
+			Action:    combinePSBT,
+		},
+		{
+			Name:        "finalize",
+			Usage:       "Build the signed transaction ready to send",
+			Description: "use --file or --hex to specify the partially signed transaction",
+			Flags: []cli.Flag{
+				cmdcom.TransactionHexFlag,
+				cmdcom.TransactionFileFlag,
+			},
+			Action: finalizePSBT,
+		},
+	},
+}
+
+func parsePacket(content string) (*psbt.Packet, error) {
+	data, err := common.HexStringToBytes(strings.TrimSpace(content))
+	if err != nil {
+		return nil, errors.New("decode partially signed transaction failed")
+	}
+	if !psbt.IsPacket(data) {
+		return nil, errors.New("not a partially signed transaction")
+	}
+	var packet psbt.Packet
+	if err := packet.Deserialize(bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+	return &packet, nil
+}
+
+func readPacket(c *cli.Context) (*psbt.Packet, error) {
+	content, err := getTransactionHex(c)
+	if err != nil {
+		return nil, err
+	}
+	return parsePacket(content)
+}
+
+func outputPacket(p *psbt.Packet) error {
+	buf := new(bytes.Buffer)
+	if err := p.Serialize(buf); err != nil {
+		return err
+	}
+	content := common.BytesToHexString(buf.Bytes())
+	if len(content) > maxPrintLen {
+		fmt.Println("Hex: ", content[:maxPrintLen], "... ...")
+	} else {
+		fmt.Println("Hex: ", content)
+	}
+	if err := ioutil.WriteFile(psbtFileName, []byte(content), 0600); err != nil {
+		return err
+	}
+	fmt.Println("File: ", psbtFileName)
+	return nil
+}
+
+func printPacket(p *psbt.Packet) {
+	fmt.Println("Transaction:", p.Transaction.Hash().String())
+
+	fmt.Println("Inputs:")
+	for i, input := range p.Inputs {
+		previous := p.Transaction.Inputs()[i].Previous
+		line := fmt.Sprintf("  %d %s:%d", i, previous.TxID.ReversedString(),
+			previous.Index)
+		if input.PreviousOutput == nil {
+			line += " unknown"
+		} else {
+			address, _ := input.PreviousOutput.ProgramHash.ToAddress()
+			line += fmt.Sprintf(" %s %s", address, input.PreviousOutput.Value)
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Println("Outputs:")
+	for i, output := range p.Outputs {
+		txOutput := p.Transaction.Outputs()[i]
+		address, _ := txOutput.ProgramHash.ToAddress()
+		line := fmt.Sprintf("  %d %s %s", i, address, txOutput.Value)
+		if len(output.RedeemScript) != 0 {
+			line += " (wallet)"
+		}
+		fmt.Println(line)
+	}
+	if fee, err := p.Fee(); err == nil {
+		fmt.Println("Fee:", fee)
+	}
+
+	fmt.Println("Programs:")
+	complete := len(p.Programs) > 0
+	for _, program := range p.Programs {
+		address, _ := program.Address()
+		required, err := program.Required()
+		if err != nil || program.Signed() < required {
+			complete = false
+		}
+		fmt.Printf("  %s [ %d / %d ]\n", address, program.Signed(), required)
+		if contract.IsSchnorr(program.Code) {
+			fmt.Printf("    nonces [ %d / %d ]\n", len(program.Nonces),
+				len(program.PublicKeys))
+		}
+		for _, pk := range program.Missing() {
+			fmt.Println("    missing", common.BytesToHexString(pk))
+		}
+	}
+	fmt.Println("Complete:", complete)
+}
+
+// signingAccounts returns the accounts with private keys of the wallet.
+func signingAccounts(c *cli.Context) (*account.Client, []*account.Account,
+	error) {
+	walletPath := c.String("wallet")
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := account.Open(walletPath, password)
+	if err != nil {
+		return nil, nil, err
+	}
+	accounts := make([]*account.Account, 0)
+	for _, acc := range client.GetAccounts() {
+		if acc.PrivateKey != nil {
+			accounts = append(accounts, acc)
+		}
+	}
+	return client, accounts, nil
+}
+
+func createPSBT(c *cli.Context) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	txHex, err := getTransactionHex(c)
+	if err != nil {
+		return err
+	}
+	rawData, err := common.HexStringToBytes(txHex)
+	if err != nil {
+		return errors.New("decode transaction content failed")
+	}
+	r := bytes.NewReader(rawData)
+	txn, err := functions.GetTransactionByBytes(r)
+	if err != nil {
+		return errors.New("invalid transaction")
+	}
+	if err := txn.Deserialize(r); err != nil {
+		return errors.New("deserialize transaction failed")
+	}
+
+	previousOutputs := make([]*common2.Output, 0, len(txn.Inputs()))
+	for _, input := range txn.Inputs() {
+		referTx, err := getRawTransaction(input.Previous.TxID.ReversedString())
+		if err != nil {
+			return err
+		}
+		if int(input.Previous.Index) >= len(referTx.Outputs()) {
+			return errors.New("invalid input " + input.ReferKey())
+		}
+		previousOutputs = append(previousOutputs,
+			referTx.Outputs()[input.Previous.Index])
+	}
+	p, err := psbt.New(txn, previousOutputs)
+	if err != nil {
+		return err
+	}
+
+	if pks := c.String(cmdcom.TransactionSchnorrFlag.Name); pks != "" {
+		publicKeys := make([][]byte, 0)
+		for _, pk := range strings.Split(pks, ",") {
+			publicKey, err := common.HexStringToBytes(strings.TrimSpace(pk))
+			if err != nil {
+				return errors.New("invalid public key " + pk)
+			}
+			publicKeys = append(publicKeys, publicKey)
+		}
+		if _, err := p.SetSchnorrPublicKeys(publicKeys); err != nil {
+			return err
+		}
+	}
+
+	// Mark the outputs paying back to the wallet.
+	walletPath := c.String("wallet")
+	if _, err := os.Stat(walletPath); err == nil {
+		storeAccounts, err := account.GetWalletAccountData(walletPath)
+		if err != nil {
+			return err
+		}
+		for _, a := range storeAccounts {
+			code, err := common.HexStringToBytes(a.RedeemScript)
+			if err != nil {
+				return err
+			}
+			if _, err := p.SetOutputRedeemScript(code); err != nil {
+				return err
+			}
+		}
+	}
+
+	printPacket(p)
+	return outputPacket(p)
+}
+
+func showPSBT(c *cli.Context) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	p, err := readPacket(c)
+	if err != nil {
+		return err
+	}
+	printPacket(p)
+	return nil
+}
+
+func noncePSBT(c *cli.Context) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	p, err := readPacket(c)
+	if err != nil {
+		return err
+	}
+	client, accounts, err := signingAccounts(c)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for _, program := range p.Programs {
+		if !contract.IsSchnorr(program.Code) {
+			continue
+		}
+		for _, acc := range accounts {
+			publicKey, err := acc.PublicKey.EncodePoint(true)
+			if err != nil {
+				return err
+			}
+			if !program.IsSigner(publicKey) || program.Nonce(publicKey) != nil {
+				continue
+			}
+			nonce, err := client.NewSchnorrNonce()
+			if err != nil {
+				return err
+			}
+			if err := program.AddNonce(publicKey, nonce); err != nil {
+				return err
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		return errors.New("no nonce needed from wallet accounts")
+	}
+	fmt.Println(count, "nonces added")
+
+	printPacket(p)
+	return outputPacket(p)
+}
+
+func signPSBT(c *cli.Context) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	p, err := readPacket(c)
+	if err != nil {
+		return err
+	}
+	client, accounts, err := signingAccounts(c)
+	if err != nil {
+		return err
+	}
+
+	var count int
+	for _, acc := range accounts {
+		n, err := p.Sign(acc.PrivateKey)
+		if err != nil {
+			return err
+		}
+		count += n
+
+		publicKey, err := acc.PublicKey.EncodePoint(true)
+		if err != nil {
+			return err
+		}
+		for _, program := range p.Programs {
+			if !contract.IsSchnorr(program.Code) ||
+				!program.IsSigner(publicKey) || program.IsSigned(publicKey) {
+				continue
+			}
+			nonce := program.Nonce(publicKey)
+			if nonce == nil {
+				fmt.Println("skip", acc.Address, "without nonce, "+
+					"use psbt nonce first")
+				continue
+			}
+			if len(program.Nonces) < len(program.PublicKeys) {
+				fmt.Println("skip", acc.Address, "waiting for nonces of "+
+					"other signers")
+				continue
+			}
+			secret, err := client.TakeSchnorrNonce(nonce)
+			if err != nil {
+				return err
+			}
+			err = p.SignSchnorr(program, acc.PrivateKey, secret)
+			common.ClearBytes(secret)
+			if err != nil {
+				return err
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		return errors.New("no signature made by wallet accounts")
+	}
+	fmt.Println(count, "signatures added")
+
+	printPacket(p)
+	return outputPacket(p)
+}
+
+func combinePSBT(c *cli.Context) error {
+	if c.NArg() < 2 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	var p *psbt.Packet
+	for _, path := range c.Args() {
+		content, err := cmdcom.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		packet, err := parsePacket(content)
+		if err != nil {
+			return errors.New(path + ": " + err.Error())
+		}
+		if p == nil {
+			p = packet
+			continue
+		}
+		if err := p.Combine(packet); err != nil {
+			return errors.New(path + ": " + err.Error())
+		}
+	}
+
+	printPacket(p)
+	return outputPacket(p)
+}
+
+func finalizePSBT(c *cli.Context) error {
+	if c.NumFlags() == 0 {
+		cli.ShowSubcommandHelp(c)
+		return nil
+	}
+
+	p, err := readPacket(c)
+	if err != nil {
+		return err
+	}
+	txn, err := p.Finalize()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Transaction was finalized")
+
+	return OutputTx(len(txn.Programs()), len(txn.Programs()), txn)
+}
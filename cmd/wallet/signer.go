@@ -0,0 +1,113 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/account/signer"
+	cmdcom "github.com/elastos/Elastos.ELA/cmd/common"
+	"github.com/elastos/Elastos.ELA/common"
+
+	"github.com/urfave/cli"
+)
+
+func serveSigner(c *cli.Context) error {
+	walletPath := c.String("wallet")
+	password, err := cmdcom.GetFlagPassword(c)
+	if err != nil {
+		return err
+	}
+	types, err := signer.ParseMessageTypes(c.String("allow"))
+	if err != nil {
+		return err
+	}
+
+	policy, err := signer.OpenPolicy(c.String("protection"), types...)
+	if err != nil {
+		return err
+	}
+	defer policy.Close()
+	policy.SetRefusal(c.Bool("refuse"))
+
+	s, err := signer.OpenKeystore(walletPath, password)
+	if err != nil {
+		return err
+	}
+	guarded, err := signer.Guard(s, policy)
+	if err != nil {
+		return err
+	}
+	server := signer.NewServer(guarded)
+	server.Log = func(format string, a ...interface{}) {
+		fmt.Println(time.Now().Format("2006-01-02 15:04:05"),
+			fmt.Sprintf(format, a...))
+	}
+	socket := c.String("socket")
+	if err := server.Listen(socket); err != nil {
+		return err
+	}
+
+	fmt.Println("Public key:", common.BytesToHexString(s.PublicKey()))
+	fmt.Println("Listening on:", socket)
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		server.Close()
+	}()
+	server.Serve()
+	return nil
+}
+
+func exportProtection(c *cli.Context) error {
+	policy, err := signer.OpenPolicy(c.String("protection"))
+	if err != nil {
+		return err
+	}
+	defer policy.Close()
+
+	if c.NArg() < 1 {
+		return policy.Export(os.Stdout)
+	}
+	file, err := os.OpenFile(c.Args().First(), os.O_CREATE|os.O_EXCL|os.O_WRONLY,
+		0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return policy.Export(file)
+}
+
+func importProtection(c *cli.Context) error {
+	if c.NArg() < 1 {
+		return errors.New("missing argument. file expected")
+	}
+	file, err := os.Open(c.Args().First())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	policy, err := signer.OpenPolicy(c.String("protection"))
+	if err != nil {
+		return err
+	}
+	defer policy.Close()
+
+	count, err := policy.Import(file)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Imported records:", count)
+	return nil
+}
@@ -37,7 +37,9 @@ var txCommand = []cli.Command{
 			cmdcom.TransactionAmountFlag,
 			cmdcom.TransactionFeeFlag,
 			cmdcom.TransactionOutputLockFlag,
+			cmdcom.TransactionRelativeLockFlag,
 			cmdcom.TransactionTxLockFlag,
+			cmdcom.TransactionRBFFlag,
 			cmdcom.AccountWalletFlag,
 		},
 		Subcommands: buildTxCommand,
@@ -79,6 +81,19 @@ var txCommand = []cli.Command{
 		},
 		Action: verifyDigest,
 	},
+	{
+		Category:    "Transaction",
+		Name:        "bumpfee",
+		Usage:       "Replace a transaction in pool with a higher fee",
+		Description: "use --txid to specify the replaceable transaction and --fee to specify the new fee",
+		Flags: []cli.Flag{
+			cmdcom.TransactionIDFlag,
+			cmdcom.TransactionFeeFlag,
+			cmdcom.AccountWalletFlag,
+			cmdcom.AccountPasswordFlag,
+		},
+		Action: bumpFee,
+	},
 	{
 		Category:    "Transaction",
 		Name:        "sendtx",
@@ -100,6 +115,8 @@ var txCommand = []cli.Command{
 		},
 		Action: showTx,
 	},
+	psbtCommand,
+	htlcCommand,
 }
 
 var buildTxCommand = []cli.Command{
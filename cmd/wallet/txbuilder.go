@@ -87,11 +87,23 @@ func CreateTransaction(c *cli.Context) error {
 	outputLock := uint64(0)
 	if outputLockStr != "" {
 		outputLock, err = strconv.ParseUint(outputLockStr, 10, 32)
-		if err != nil {
+		if err != nil || outputLock&common2.OutputLockRelative != 0 {
 			return errors.New("invalid output lock height")
 		}
 	}
 
+	relativeLockStr := c.String(cmdcom.TransactionRelativeLockFlag.Name)
+	if relativeLockStr != "" {
+		if outputLockStr != "" {
+			return errors.New("'--outputlock' cannot be specified when specify '--relativelock' option")
+		}
+		relativeLock, err := parseRelativeLock(relativeLockStr)
+		if err != nil {
+			return err
+		}
+		outputLock = uint64(relativeLock)
+	}
+
 	txLockStr := c.String("txlock")
 	txLock := uint64(0)
 	if txLockStr != "" {
@@ -107,6 +119,9 @@ func CreateTransaction(c *cli.Context) error {
 	if err != nil {
 		return errors.New("create transaction failed: " + err.Error())
 	}
+	if c.Bool(cmdcom.TransactionRBFFlag.Name) {
+		signalReplacement(txn)
+	}
 
 	OutputTx(0, 1, txn)
 
@@ -195,8 +210,10 @@ func createInputs(fromAddr string, totalAmount common.Fixed64) ([]*common2.Input
 	for _, utxo := range UTXOs {
 		txIDReverse, _ := hex.DecodeString(utxo.TxID)
 		txID, _ := common.Uint256FromBytes(common.BytesReverse(txIDReverse))
-		sequence := math.MaxUint32
-		if utxo.OutputLock > 0 {
+		sequence := uint32(math.MaxUint32)
+		if utxo.OutputLock&common2.OutputLockRelative != 0 {
+			sequence = utxo.OutputLock &^ common2.OutputLockRelative
+		} else if utxo.OutputLock > 0 {
 			sequence = math.MaxUint32 - 1
 		}
 		input := &common2.Input{
@@ -204,7 +221,7 @@ func createInputs(fromAddr string, totalAmount common.Fixed64) ([]*common2.Input
 				TxID:  *txID,
 				Index: utxo.VOut,
 			},
-			Sequence: uint32(sequence),
+			Sequence: sequence,
 		}
 		txInputs = append(txInputs, input)
 		amount, err := common.StringToFixed64(utxo.Amount)
@@ -241,6 +258,26 @@ func createInputs(fromAddr string, totalAmount common.Fixed64) ([]*common2.Input
 	return txInputs, changeOutputs, nil
 }
 
+// parseRelativeLock parses a relative lock in blocks, or in seconds with the
+// suffix "s", to an output lock.
+func parseRelativeLock(lock string) (uint32, error) {
+	isSeconds := strings.HasSuffix(lock, "s")
+	value, err := strconv.ParseUint(strings.TrimSuffix(lock, "s"), 10, 32)
+	if err != nil || value == 0 {
+		return 0, errors.New("invalid relative lock " + lock)
+	}
+	maxValue := uint64(common2.SequenceLockTimeMask)
+	if isSeconds {
+		maxValue <<= common2.SequenceLockTimeGranularity
+	}
+	if value > maxValue {
+		return 0, fmt.Errorf("relative lock %s exceeds the maximum %d", lock,
+			maxValue)
+	}
+	return common2.OutputLockRelative |
+		common2.LockTimeToSequence(isSeconds, uint32(value)), nil
+}
+
 func createNormalOutputs(outputs []*OutputInfo, fee common.Fixed64, lockedUntil uint32) ([]*common2.Output, common.Fixed64, error) {
 	var totalAmount = common.Fixed64(0) // The total amount will be spend
 	var txOutputs []*common2.Output     // The outputs in transaction
@@ -233,6 +233,7 @@ func GetDefaultParams() *Configuration {
 		CheckRewardHeight:               436812,
 		VoteStatisticsHeight:            512881,
 		EnableUtxoDB:                    true,
+		PersistMempool:                  true,
 		EnableCORS:                      false,
 		WalletPath:                      "keystore.dat",
 		RPCServiceLevel:                 ConfigurationPermitted.String(),
@@ -261,12 +262,15 @@ func GetDefaultParams() *Configuration {
 		VotesSchnorrStartHeight:         math.MaxUint32,
 		CrossChainMonitorStartHeight:    math.MaxUint32,
 		CrossChainMonitorInterval:       100,
+		HTLCStartHeight:                 math.MaxUint32,
+		TimeLockStartHeight:             math.MaxUint32,
 		SupportMultiCodeHeight:          math.MaxUint32, // todo complete me
 		MultiExchangeVotesStartHeight:   math.MaxUint32, // todo complete me
 		HttpInfoPort:                    20333,
 		HttpRestPort:                    20334,
 		HttpWsPort:                      20335,
 		HttpJsonPort:                    20336,
+		MetricsPort:                     20337,
 		PowConfiguration: PowConfiguration{
 			PowLimit:           powLimit,
 			PowLimitBits:       0x1f0008ff,
@@ -346,6 +350,7 @@ func (p *Configuration) TestNet() *Configuration {
 	p.VoteStatisticsHeight = 0
 	p.CRConfiguration.RegisterCRByDIDHeight = 483500
 	p.EnableUtxoDB = true
+	p.PersistMempool = true
 	p.EnableCORS = false
 	p.CRConfiguration.VoterRejectPercentage = 10
 	p.CRConfiguration.CRCAppropriatePercentage = 10
@@ -391,6 +396,8 @@ func (p *Configuration) TestNet() *Configuration {
 	p.DPoSConfiguration.CRDPoSNodeHotFixHeight = 0
 	p.CrossChainMonitorStartHeight = 965800 + 720*3
 	p.CrossChainMonitorInterval = 100
+	p.HTLCStartHeight = math.MaxUint32
+	p.TimeLockStartHeight = math.MaxUint32
 	p.CRConfiguration.CRClaimPeriod = 10080
 	p.DPoSConfiguration.NFTStartHeight = 1098000
 	p.DPoSConfiguration.SponsorsFilePath = "sponsors"
@@ -402,6 +409,7 @@ func (p *Configuration) TestNet() *Configuration {
 	p.HttpRestPort = 21334
 	p.HttpWsPort = 21335
 	p.HttpJsonPort = 21336
+	p.MetricsPort = 21337
 	p.ProducerSchnorrStartHeight = math.MaxUint32
 	p.CRSchnorrStartHeight = math.MaxUint32
 	p.VotesSchnorrStartHeight = math.MaxUint32
@@ -475,6 +483,7 @@ func (p *Configuration) RegNet() *Configuration {
 	p.CRConfiguration.RegisterCRByDIDHeight = 393000
 
 	p.EnableUtxoDB = true
+	p.PersistMempool = true
 	p.EnableCORS = false
 	p.CRConfiguration.VoterRejectPercentage = 10
 	p.CRConfiguration.CRCAppropriatePercentage = 10
@@ -520,6 +529,8 @@ func (p *Configuration) RegNet() *Configuration {
 	p.DPoSConfiguration.CRDPoSNodeHotFixHeight = 0
 	p.CrossChainMonitorStartHeight = 875544 + 720*2
 	p.CrossChainMonitorInterval = 100
+	p.HTLCStartHeight = math.MaxUint32
+	p.TimeLockStartHeight = math.MaxUint32
 	p.CRConfiguration.CRClaimPeriod = 10080
 	p.DPoSConfiguration.NFTStartHeight = 968000
 	p.DPoSConfiguration.SponsorsFilePath = "sponsors"
@@ -529,6 +540,7 @@ func (p *Configuration) RegNet() *Configuration {
 	p.HttpRestPort = 22334
 	p.HttpWsPort = 22335
 	p.HttpJsonPort = 22336
+	p.MetricsPort = 22337
 	p.ProducerSchnorrStartHeight = math.MaxUint32
 	p.CRSchnorrStartHeight = math.MaxUint32
 	p.VotesSchnorrStartHeight = math.MaxUint32
@@ -540,6 +552,40 @@ func (p *Configuration) RegNet() *Configuration {
 	return p
 }
 
+// DevNet returns the network parameters for the local development network.
+// It is based on the regression test network, with DPOS consensus starting
+// from the first blocks and the later forks left at the heights of RegNet.
+// The arbiters and the foundation address are set by the devnet itself.
+func (p *Configuration) DevNet() *Configuration {
+	p.RegNet()
+
+	p.Magic = 2018301
+	p.NodePort = 23338
+	p.DNSSeeds = nil
+	p.PermanentPeers = nil
+
+	p.DPoSConfiguration.Magic = 2019300
+	p.DPoSConfiguration.DPoSPort = 23339
+	p.DPoSConfiguration.PreConnectOffset = 5
+	p.VoteStartHeight = 10
+	p.CRCOnlyDPOSHeight = 20
+	p.PublicDPOSHeight = 30
+	p.HTLCStartHeight = 0
+	p.TimeLockStartHeight = 0
+	p.DPoSConfiguration.RevertToPOWStartHeight = math.MaxUint32
+	p.PowConfiguration.InstantBlock = true
+	p.PowConfiguration.CoinbaseMaturity = 1
+
+	p.EnableRPC = true
+	p.HttpInfoPort = 23333
+	p.HttpRestPort = 23334
+	p.HttpWsPort = 23335
+	p.HttpJsonPort = 23336
+	p.MetricsPort = 23337
+
+	return p
+}
+
 // Configuration defines the configurable parameters to run a ELA node.
 type Configuration struct {
 	Conf          string `screw:"--conf" usage:"set the config file path"`
@@ -557,6 +603,8 @@ type Configuration struct {
 	ProfileHost   string `screw:"--profilehost" usage:"port for the http profile rpc host server"`
 	DisableDNS    bool   `screw:"--disabledns" usage:"disable DNS for node"`
 	EnableRPC     bool   `screw:"--enablerpc" usage:"enable RPC for node"`
+	MetricsPort   uint16 `screw:"--metricsport" usage:"port for the prometheus metrics server"`
+	EnableMetrics bool   `screw:"--enablemetrics" usage:"enable the prometheus metrics server at /metrics"`
 	MaxLogsSize   int64  `json:"MaxLogsSize"`
 	MaxPerLogSize int64  `json:"MaxPerLogSize"`
 	RestCertPath  string `json:"RestCertPath"`
@@ -576,6 +624,11 @@ type Configuration struct {
 	DisableTxFilters bool
 	// PrintLevel defines the level to print log.
 	PrintLevel uint32 `screw:"--printlevel" usage:"level to print log"`
+	// LogFormat defines the format of log messages, "text" or "json".
+	LogFormat string `screw:"--logformat" usage:"format of log messages, text or json"`
+	// LogLevels defines the log levels of subsystems which override the
+	// PrintLevel, such as "netsync=0,mempool=2".
+	LogLevels string `screw:"--loglevels" usage:"log levels of subsystems, such as netsync=0,mempool=2"`
 	// NodePort defines the default peer-to-peer port for the network.
 	NodePort uint16 `screw:"--nodeport" usage:"default peer-to-peer node port for the network"`
 	// Magic defines the magic number of the peer-to-peer network.
@@ -618,6 +671,23 @@ type Configuration struct {
 	VoteStatisticsHeight uint32 `screw:"--votestatisticsheight" usage:"defines the height to fix vote statistics error"`
 	// EnableUtxoDB indicate whether to enable utxo database.
 	EnableUtxoDB bool `json:"EnableUtxoDB"`
+	// PersistMempool indicate whether to save the transaction pool on shutdown
+	// and load it on startup.
+	PersistMempool bool `json:"PersistMempool"`
+	// EnableAddressIndex indicate whether to maintain the address history index.
+	EnableAddressIndex bool `screw:"--addressindex" usage:"enable the address history index"`
+	// PruneTargetSize defines the target size in MiB of the stored block files,
+	// the oldest block files are deleted once it is exceeded. 0 disables pruning.
+	PruneTargetSize uint64 `screw:"--prune" usage:"target size in MiB of the stored block files, 0 disables block pruning"`
+	// SnapshotFile defines the path of the snapshot file used to bootstrap
+	// the chain database on the first start.
+	SnapshotFile string `screw:"--snapshot" usage:"snapshot file to bootstrap the chain database on the first start"`
+	// SnapshotHash defines the expected content hash of the snapshot file.
+	SnapshotHash string `screw:"--snapshothash" usage:"expected content hash of the snapshot file"`
+	// SnapshotVerifyHeaders indicate whether to validate the headers before
+	// the snapshot height in the background.  The UTXO set and checkpoints
+	// of the snapshot are not validated, they are trusted by SnapshotHash.
+	SnapshotVerifyHeaders bool `screw:"--snapshotverifyheaders" usage:"validate the headers before the snapshot height in the background, the UTXO set and checkpoints are not validated"`
 	// Enable cors for http server.
 	EnableCORS bool `json:"EnableCORS"`
 	// WalletPath defines the wallet path used by DPoS arbiters and CR members.
@@ -673,6 +743,12 @@ type Configuration struct {
 	MultiExchangeVotesStartHeight uint32 `screw:"--multiexchangevotesstartheight" usage:"defines the start height to support multi-addr exchange votes transaction"`
 	// CrossChainMonitorStartHeight indicates the monitor height of cr cross chain arbitration
 	CrossChainMonitorStartHeight uint32 `screw:"--crosschainmonitorstartheight" usage:"defines the start height to monitor cr cross chain transaction"`
+	// HTLCStartHeight indicates the start height of hash time locked contracts
+	HTLCStartHeight uint32 `screw:"--htlcstartheight" usage:"defines the start height to support hash time locked contracts"`
+	// TimeLockStartHeight indicates the start height of relative and median time based locks
+	TimeLockStartHeight uint32 `screw:"--timelockstartheight" usage:"defines the start height to support relative and median time based locks"`
+	// Deployments overrides the activation heights of the named deployments.
+	Deployments map[string]uint32 `json:"Deployments"`
 	// CrossChainMonitorInterval indicates the interval value of cr cross chain arbitration
 	CrossChainMonitorInterval uint32                  `screw:"--crosschainmonitorinterval" usage:"defines the interval cross chain arbitration"`
 	CRConfiguration           CRConfiguration         `json:"CRConfiguration"`
@@ -696,6 +772,12 @@ type CheckPointConfiguration struct {
 // DPoSConfiguration defines the DPoS consensus parameters.
 type DPoSConfiguration struct {
 	EnableArbiter bool `screw:"--arbiter" usage:"indicates where or not to enable DPoS arbiter switch"`
+	// SignerSocket defines the Unix socket of the remote signer holding the
+	// arbiter key, the key is loaded from the keystore file if it is empty.
+	SignerSocket string `screw:"--signersocket" usage:"defines the unix socket of the remote signer holding the arbiter key"`
+	// RefuseSigning indicates whether to log and refuse to sign all DPoS
+	// messages, which is used when migrating a producer to a new machine.
+	RefuseSigning bool `screw:"--refusesigning" usage:"indicates whether to log and refuse to sign all DPoS messages"`
 	// Magic defines the magic number used in the DPoS network.
 	Magic uint32 `screw:"--dposmagic" usage:"defines the magic number used in the DPoS network"`
 	// DPoSIPAddress defines the IP address for the DPoS network.
@@ -921,7 +1003,7 @@ func rewardPerBlock(targetTimePerBlock time.Duration) common.Fixed64 {
 }
 
 func (p *Configuration) GetBlockReward(height uint32) (rewardPerBlock common.Fixed64) {
-	if height < p.NewELAIssuanceHeight {
+	if !p.IsActive(DeploymentNewELAIssuance, height) {
 		rewardPerBlock = p.PowConfiguration.RewardPerBlock
 	} else {
 		rewardPerBlock = p.newRewardPerBlock(2*time.Minute, height)
@@ -934,7 +1016,7 @@ func (p *Configuration) newRewardPerBlock(targetTimePerBlock time.Duration, heig
 	blockGenerateInterval := int64(targetTimePerBlock / time.Second)
 	generatedBlocksPerYear := 365 * 24 * 60 * 60 / blockGenerateInterval
 	factor := uint32(1)
-	if height >= p.HalvingRewardHeight {
+	if p.IsActive(DeploymentHalvingReward, height) {
 		factor = 2 + (height-p.HalvingRewardHeight)/p.HalvingRewardInterval // HalvingRewardHeight: 1051200
 	}
 	return common.Fixed64(float64(newInflationPerYear) / float64(generatedBlocksPerYear) / math.Pow(2, float64(factor-1)))
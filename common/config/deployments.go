@@ -0,0 +1,238 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Deployment is the name of a protocol feature activated at a height.
+type Deployment string
+
+const (
+	DeploymentCheckAddress                 Deployment = "checkaddress"
+	DeploymentVoteStart                    Deployment = "votestart"
+	DeploymentCRCOnlyDPOS                  Deployment = "crconlydpos"
+	DeploymentPublicDPOS                   Deployment = "publicdpos"
+	DeploymentEnableActivateIllegal        Deployment = "enableactivateillegal"
+	DeploymentCheckReward                  Deployment = "checkreward"
+	DeploymentVoteStatistics               Deployment = "votestatistics"
+	DeploymentProhibitTransferToDID        Deployment = "prohibittransfertodid"
+	DeploymentCustomIDProposal             Deployment = "customidproposal"
+	DeploymentHalvingReward                Deployment = "halvingreward"
+	DeploymentNewELAIssuance               Deployment = "newelaissuance"
+	DeploymentNewCrossChain                Deployment = "newcrosschainstart"
+	DeploymentReturnCrossChainCoin         Deployment = "returncrosschaincoin"
+	DeploymentDPoSV2                       Deployment = "dposv2start"
+	DeploymentSupportMultiCode             Deployment = "supportmulticode"
+	DeploymentSchnorr                      Deployment = "schnorr"
+	DeploymentNormalSchnorr                Deployment = "normalschnorr"
+	DeploymentProducerSchnorr              Deployment = "producerschnorr"
+	DeploymentCRSchnorr                    Deployment = "crschnorr"
+	DeploymentVotesSchnorr                 Deployment = "votesschnorr"
+	DeploymentMultiExchangeVotes           Deployment = "multiexchangevotes"
+	DeploymentCrossChainMonitor            Deployment = "crosschainmonitor"
+	DeploymentNoCRCDPOSNode                Deployment = "nocrcdposnode"
+	DeploymentDPOSNodeCrossChain           Deployment = "dposnodecrosschain"
+	DeploymentChangeViewV1                 Deployment = "changeviewv1"
+	DeploymentRevertToPOW                  Deployment = "reverttopowstart"
+	DeploymentCRDPoSNodeHotFix             Deployment = "crdposnodehotfix"
+	DeploymentNFT                          Deployment = "nftstart"
+	DeploymentRecordSponsor                Deployment = "recordsponsor"
+	DeploymentNFTV2                        Deployment = "nftv2start"
+	DeploymentDex                          Deployment = "dex"
+	DeploymentCheckVoteCRCount             Deployment = "checkvotecrcount"
+	DeploymentRegisterCRByDID              Deployment = "registercrbydid"
+	DeploymentCRAssetsRectify              Deployment = "crassetsrectify"
+	DeploymentCRCProposalWithdrawPayloadV1 Deployment = "crcproposalwithdrawpayloadv1"
+	DeploymentCRCProposalV1                Deployment = "crcproposalv1"
+	DeploymentCRVoting                     Deployment = "crvotingstart"
+	DeploymentCRCommittee                  Deployment = "crcommitteestart"
+	DeploymentCRClaimDPOSNode              Deployment = "crclaimdposnode"
+	DeploymentChangeCommitteeNewCR         Deployment = "changecommitteenewcr"
+	DeploymentCRCProposalDraftData         Deployment = "crcproposaldraftdata"
+	DeploymentHTLC                         Deployment = "htlc"
+	DeploymentTimeLock                     Deployment = "timelock"
+)
+
+// deployment describes a registered deployment, the activation height is
+// stored in the configuration field returned by height, so the deployment
+// and the legacy field always agree.
+type deployment struct {
+	name        Deployment
+	description string
+	height      func(p *Configuration) *uint32
+}
+
+// deployments is the registry of the deployments in order of introduction.
+var deployments = []deployment{
+	{DeploymentCheckAddress, "check the output program hash",
+		func(p *Configuration) *uint32 { return &p.CheckAddressHeight }},
+	{DeploymentVoteStart, "register producers and vote",
+		func(p *Configuration) *uint32 { return &p.VoteStartHeight }},
+	{DeploymentCRCOnlyDPOS, "DPOS consensus with CRC producers only",
+		func(p *Configuration) *uint32 { return &p.CRCOnlyDPOSHeight }},
+	{DeploymentPublicDPOS, "DPOS consensus with elected producers",
+		func(p *Configuration) *uint32 { return &p.PublicDPOSHeight }},
+	{DeploymentEnableActivateIllegal, "activate illegal producers",
+		func(p *Configuration) *uint32 { return &p.EnableActivateIllegalHeight }},
+	{DeploymentCheckReward, "check the reward of coinbase",
+		func(p *Configuration) *uint32 { return &p.CheckRewardHeight }},
+	{DeploymentVoteStatistics, "fix the vote statistics",
+		func(p *Configuration) *uint32 { return &p.VoteStatisticsHeight }},
+	{DeploymentCheckVoteCRCount, "check the count of voted CR candidates",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.CheckVoteCRCountHeight }},
+	{DeploymentCRVoting, "register CR candidates and vote",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.CRVotingStartHeight }},
+	{DeploymentCRCommittee, "CR committee",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.CRCommitteeStartHeight }},
+	{DeploymentRegisterCRByDID, "register CR candidates by DID",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.RegisterCRByDIDHeight }},
+	{DeploymentCRAssetsRectify, "CR assets rectify transaction",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.CRAssetsRectifyTransactionHeight }},
+	{DeploymentCRCProposalWithdrawPayloadV1, "CRC proposal withdraw payload version 1",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.CRCProposalWithdrawPayloadV1Height }},
+	{DeploymentCRCProposalV1, "change owner, close and secretary general proposals",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.CRCProposalV1Height }},
+	{DeploymentCRClaimDPOSNode, "CR members claim DPOS nodes",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.CRClaimDPOSNodeStartHeight }},
+	{DeploymentChangeCommitteeNewCR, "change committee with new CR members",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.ChangeCommitteeNewCRHeight }},
+	{DeploymentCRCProposalDraftData, "CRC proposal draft data",
+		func(p *Configuration) *uint32 { return &p.CRConfiguration.CRCProposalDraftDataStartHeight }},
+	{DeploymentCustomIDProposal, "custom ID proposals",
+		func(p *Configuration) *uint32 { return &p.CustomIDProposalStartHeight }},
+	{DeploymentNoCRCDPOSNode, "no DPOS node of CRC",
+		func(p *Configuration) *uint32 { return &p.DPoSConfiguration.NoCRCDPOSNodeHeight }},
+	{DeploymentProhibitTransferToDID, "prohibit transfer to DID side chain",
+		func(p *Configuration) *uint32 { return &p.ProhibitTransferToDIDHeight }},
+	{DeploymentDPOSNodeCrossChain, "DPOS nodes work across chains",
+		func(p *Configuration) *uint32 { return &p.DPoSConfiguration.DPOSNodeCrossChainHeight }},
+	{DeploymentChangeViewV1, "change view version 1",
+		func(p *Configuration) *uint32 { return &p.DPoSConfiguration.ChangeViewV1Height }},
+	{DeploymentNewELAIssuance, "new ELA issuance",
+		func(p *Configuration) *uint32 { return &p.NewELAIssuanceHeight }},
+	{DeploymentHalvingReward, "halving reward",
+		func(p *Configuration) *uint32 { return &p.HalvingRewardHeight }},
+	{DeploymentNewCrossChain, "cross chain transaction version 1 only",
+		func(p *Configuration) *uint32 { return &p.NewCrossChainStartHeight }},
+	{DeploymentRevertToPOW, "revert to POW",
+		func(p *Configuration) *uint32 { return &p.DPoSConfiguration.RevertToPOWStartHeight }},
+	{DeploymentReturnCrossChainCoin, "return cross chain deposit coin",
+		func(p *Configuration) *uint32 { return &p.ReturnCrossChainCoinStartHeight }},
+	{DeploymentDPoSV2, "DPOS 2.0",
+		func(p *Configuration) *uint32 { return &p.DPoSV2StartHeight }},
+	{DeploymentSupportMultiCode, "multi-sign producers",
+		func(p *Configuration) *uint32 { return &p.SupportMultiCodeHeight }},
+	{DeploymentCRDPoSNodeHotFix, "CR DPOS node hot fix",
+		func(p *Configuration) *uint32 { return &p.DPoSConfiguration.CRDPoSNodeHotFixHeight }},
+	{DeploymentNFT, "NFT transactions",
+		func(p *Configuration) *uint32 { return &p.DPoSConfiguration.NFTStartHeight }},
+	{DeploymentSchnorr, "schnorr withdraw transactions",
+		func(p *Configuration) *uint32 { return &p.SchnorrStartHeight }},
+	{DeploymentRecordSponsor, "record the sponsor of blocks",
+		func(p *Configuration) *uint32 { return &p.DPoSConfiguration.RecordSponsorStartHeight }},
+	{DeploymentCrossChainMonitor, "monitor CR cross chain transactions",
+		func(p *Configuration) *uint32 { return &p.CrossChainMonitorStartHeight }},
+	{DeploymentNFTV2, "NFT 2.0 transactions",
+		func(p *Configuration) *uint32 { return &p.DPoSConfiguration.NFTV2StartHeight }},
+	{DeploymentDex, "Dex support",
+		func(p *Configuration) *uint32 { return &p.DPoSConfiguration.DexStartHeight }},
+	{DeploymentNormalSchnorr, "schnorr transfer asset transactions",
+		func(p *Configuration) *uint32 { return &p.NormalSchnorrStartHeight }},
+	{DeploymentProducerSchnorr, "schnorr producer transactions",
+		func(p *Configuration) *uint32 { return &p.ProducerSchnorrStartHeight }},
+	{DeploymentCRSchnorr, "schnorr CR transactions",
+		func(p *Configuration) *uint32 { return &p.CRSchnorrStartHeight }},
+	{DeploymentVotesSchnorr, "schnorr votes transactions",
+		func(p *Configuration) *uint32 { return &p.VotesSchnorrStartHeight }},
+	{DeploymentMultiExchangeVotes, "multi-address exchange votes",
+		func(p *Configuration) *uint32 { return &p.MultiExchangeVotesStartHeight }},
+	{DeploymentHTLC, "hash time locked contracts",
+		func(p *Configuration) *uint32 { return &p.HTLCStartHeight }},
+	{DeploymentTimeLock, "relative and median time based locks",
+		func(p *Configuration) *uint32 { return &p.TimeLockStartHeight }},
+}
+
+// deploymentIndex maps the name of a deployment to its index in registry.
+var deploymentIndex = make(map[Deployment]int)
+
+func init() {
+	for i, d := range deployments {
+		deploymentIndex[d.name] = i
+	}
+}
+
+// DeploymentInfo is the status of a deployment.
+type DeploymentInfo struct {
+	Name        Deployment
+	Description string
+	Height      uint32
+}
+
+// IsActive returns if the deployment is active at the given height, a
+// deployment is active from its activation height.
+func (p *Configuration) IsActive(feature Deployment, height uint32) bool {
+	activation, ok := p.DeploymentHeight(feature)
+	return ok && height >= activation
+}
+
+// IsActiveAfter returns if the deployment is active at the given height and
+// the given height is not the activation height, so the deployment was
+// already active at the previous height.
+func (p *Configuration) IsActiveAfter(feature Deployment, height uint32) bool {
+	activation, ok := p.DeploymentHeight(feature)
+	return ok && height > activation
+}
+
+// DeploymentHeight returns the activation height of the deployment.
+func (p *Configuration) DeploymentHeight(feature Deployment) (uint32, bool) {
+	index, ok := deploymentIndex[feature]
+	if !ok {
+		return 0, false
+	}
+	return *deployments[index].height(p), true
+}
+
+// SetDeploymentHeight changes the activation height of the deployment.
+func (p *Configuration) SetDeploymentHeight(feature Deployment,
+	height uint32) error {
+	index, ok := deploymentIndex[feature]
+	if !ok {
+		return fmt.Errorf("unknown deployment %s", feature)
+	}
+	*deployments[index].height(p) = height
+	return nil
+}
+
+// ListDeployments returns all registered deployments in ascending order of
+// activation height.
+func (p *Configuration) ListDeployments() []DeploymentInfo {
+	infos := make([]DeploymentInfo, 0, len(deployments))
+	for _, d := range deployments {
+		infos = append(infos, DeploymentInfo{
+			Name:        d.name,
+			Description: d.description,
+			Height:      *d.height(p),
+		})
+	}
+	sort.SliceStable(infos, func(i, j int) bool {
+		return infos[i].Height < infos[j].Height
+	})
+	return infos
+}
+
+// ApplyDeployments overrides the activation heights with the schedule set by
+// Deployments.
+func (p *Configuration) ApplyDeployments() error {
+	for name, height := range p.Deployments {
+		if err := p.SetDeploymentHeight(Deployment(name), height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
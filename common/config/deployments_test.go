@@ -0,0 +1,58 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployments(t *testing.T) {
+	params := GetDefaultParams()
+
+	// Every deployment is registered once and follows its config field.
+	assert.Equal(t, len(deployments), len(deploymentIndex))
+	height, ok := params.DeploymentHeight(DeploymentNFT)
+	assert.True(t, ok)
+	assert.Equal(t, params.DPoSConfiguration.NFTStartHeight, height)
+
+	assert.False(t, params.IsActive(DeploymentNFT, height-1))
+	assert.True(t, params.IsActive(DeploymentNFT, height))
+	assert.True(t, params.IsActive(DeploymentNFT, height+1))
+	assert.False(t, params.IsActive(Deployment("unknown"), height))
+
+	assert.False(t, params.IsActiveAfter(DeploymentNFT, height-1))
+	assert.False(t, params.IsActiveAfter(DeploymentNFT, height))
+	assert.True(t, params.IsActiveAfter(DeploymentNFT, height+1))
+	assert.False(t, params.IsActiveAfter(Deployment("unknown"), height+1))
+
+	// Changing the height of a deployment changes the config field.
+	assert.NoError(t, params.SetDeploymentHeight(DeploymentDex, 100))
+	assert.Equal(t, uint32(100), params.DPoSConfiguration.DexStartHeight)
+	assert.Error(t, params.SetDeploymentHeight(Deployment("unknown"), 100))
+
+	infos := params.ListDeployments()
+	assert.Equal(t, len(deployments), len(infos))
+	for i := 1; i < len(infos); i++ {
+		assert.True(t, infos[i-1].Height <= infos[i].Height)
+	}
+}
+
+func TestConfiguration_ApplyDeployments(t *testing.T) {
+	params := GetDefaultParams()
+	params.Deployments = map[string]uint32{
+		"schnorr":   10,
+		"votestart": 20,
+	}
+	assert.NoError(t, params.ApplyDeployments())
+	assert.Equal(t, uint32(10), params.SchnorrStartHeight)
+	assert.Equal(t, uint32(20), params.VoteStartHeight)
+	assert.True(t, params.IsActive(DeploymentSchnorr, 10))
+
+	params.Deployments = map[string]uint32{"unknown": 10}
+	assert.Error(t, params.ApplyDeployments())
+}
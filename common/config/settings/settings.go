@@ -6,6 +6,9 @@
 package settings
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/RainFallsSilent/screw"
 	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/transaction"
@@ -72,6 +75,9 @@ func (s *Settings) SetupConfig(withScrew bool, about string, version string) *co
 	case "regnet", "regtest", "reg":
 		conf.RegNet()
 		s.loadConfigFile(conf.Conf, conf)
+	case "devnet", "dev":
+		conf.DevNet()
+		s.loadConfigFile(conf.Conf, conf)
 	}
 
 	if conf.MaxBlockSize > 0 {
@@ -97,6 +103,10 @@ func (s *Settings) SetupConfig(withScrew bool, about string, version string) *co
 	if withScrew {
 		screw.Bind(conf.Configuration, version, about)
 	}
+	if err := conf.ApplyDeployments(); err != nil {
+		fmt.Println("invalid deployments,", err)
+		os.Exit(1)
+	}
 	conf.Configuration = conf.Sterilize()
 	config.Parameters = conf.Configuration
 	return conf.Configuration
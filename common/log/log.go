@@ -12,7 +12,12 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/utils/elalog"
@@ -54,11 +59,33 @@ var (
 const (
 	calldepth = 2
 
+	// FormatText is the default log format, a line of text per message.
+	FormatText = "text"
+
+	// FormatJSON is the log format writing a JSON object per message.
+	FormatJSON = "json"
+
+	// DefaultSubsystem is the name of the default logger, which is used by
+	// the packages logging through the package level functions.
+	DefaultSubsystem = "ela"
+
+	// AllSubsystems is the name used to set the level of all loggers.
+	AllSubsystems = "all"
+
 	defaultPerLogFileSize int64 = 20 * elalog.MBSize
 	defaultLogsFolderSize int64 = 5 * elalog.GBSize
 )
 
-var logger *Logger
+var (
+	logger *Logger
+
+	// jsonFormat is set to 1 if messages are written in JSON format.
+	jsonFormat int32
+
+	// subsystems are the loggers of which the level can be changed by name.
+	subsystemsMtx sync.Mutex
+	subsystems    = make(map[string]*Logger)
+)
 
 func levelName(level uint8) string {
 	if int(level) >= len(levels) {
@@ -68,32 +95,34 @@ func levelName(level uint8) string {
 }
 
 type Logger struct {
-	level  uint8 // The log print level
-	writer io.Writer
-	logger *log.Logger
+	level     uint32 // The log print level, atomic
+	tag       string // The subsystem name in JSON messages
+	calldepth int    // The depth of the logging call in the stack
+	writer    io.Writer
+	logger    *log.Logger
+	json      *log.Logger
 }
 
-func NewLogger(outputPath string, level uint8, maxPerLogSizeMb,
-	maxLogsSizeMb int64) *Logger {
-	fileWriter := fileWriter(outputPath, maxPerLogSizeMb, maxLogsSizeMb)
-	logWriter := io.MultiWriter(os.Stdout, fileWriter)
-
+func newLogger(w io.Writer, level uint8) *Logger {
 	return &Logger{
-		level:  level,
-		writer: logWriter,
-		logger: log.New(logWriter, "", log.Ldate|log.Lmicroseconds),
+		level:     uint32(level),
+		calldepth: calldepth,
+		writer:    w,
+		logger:    log.New(w, "", log.Ldate|log.Lmicroseconds),
+		json:      log.New(w, "", 0),
 	}
 }
 
-func NewFileLogger(outputPath string, level uint8, maxPerLogSizeMb,
+func NewLogger(outputPath string, level uint8, maxPerLogSizeMb,
 	maxLogsSizeMb int64) *Logger {
 	fileWriter := fileWriter(outputPath, maxPerLogSizeMb, maxLogsSizeMb)
+	return newLogger(io.MultiWriter(os.Stdout, fileWriter), level)
+}
 
-	return &Logger{
-		level:  level,
-		writer: fileWriter,
-		logger: log.New(fileWriter, "", log.Ldate|log.Lmicroseconds),
-	}
+func NewFileLogger(outputPath string, level uint8, maxPerLogSizeMb,
+	maxLogsSizeMb int64) *Logger {
+	return newLogger(fileWriter(outputPath, maxPerLogSizeMb, maxLogsSizeMb),
+		level)
 }
 
 func fileWriter(outputPath string, maxPerLogSizeMb,
@@ -113,57 +142,124 @@ func fileWriter(outputPath string, maxPerLogSizeMb,
 
 func NewDefault(path string, level uint8, maxPerLogSizeMb, maxLogsSizeMb int64) *Logger {
 	logger = NewLogger(path, level, maxPerLogSizeMb, maxLogsSizeMb)
+	Register(DefaultSubsystem, logger)
 	return logger
 }
 
+// Subsystem returns a logger of the subsystem writing to the same output,
+// the logger is registered so the level can be changed by name.  The
+// returned logger is meant to be used directly by the subsystem, such as
+// through the UseLogger function of a package.
+func (l *Logger) Subsystem(name string, level elalog.Level) *Logger {
+	sub := &Logger{
+		level:     uint32(level),
+		tag:       name,
+		calldepth: calldepth - 1,
+		writer:    l.writer,
+		logger:    l.logger,
+		json:      l.json,
+	}
+	Register(name, sub)
+	return sub
+}
+
 func (l *Logger) Writer() io.Writer {
 	return l.writer
 }
 
+func (l *Logger) enabled(level uint8) bool {
+	return atomic.LoadUint32(&l.level) <= uint32(level)
+}
+
 func (l *Logger) Output(level uint8, a ...interface{}) {
-	if l.level <= level {
+	if l.enabled(level) {
+		a, fields := elalog.SplitFields(a)
+		if atomic.LoadInt32(&jsonFormat) != 0 {
+			l.outputJSON(level, "", sprintln(a...), fields)
+			return
+		}
 		a = append([]interface{}{levelName(level), "GID", common.Goid() + ","}, a...)
-		l.logger.Output(calldepth, fmt.Sprintln(a...))
+		msg := elalog.AppendFields([]byte(sprintln(a...)), fields)
+		l.logger.Output(calldepth, string(msg))
 	}
 }
 
 func (l *Logger) Outputf(level uint8, format string, v ...interface{}) {
-	if l.level <= level {
+	if l.enabled(level) {
+		v, fields := elalog.SplitFields(v)
+		if atomic.LoadInt32(&jsonFormat) != 0 {
+			l.outputJSON(level, "", fmt.Sprintf(format, v...), fields)
+			return
+		}
 		v = append([]interface{}{levelName(level), "GID", common.Goid()}, v...)
-		l.logger.Output(calldepth, fmt.Sprintf("%s %s %s, "+format+"\n", v...))
+		msg := fmt.Sprintf("%s %s %s, "+format, v...)
+		l.logger.Output(calldepth, string(elalog.AppendFields([]byte(msg), fields)))
 	}
 }
 
+// outputJSON writes the message in JSON format, the caller is omitted if
+// empty.
+func (l *Logger) outputJSON(level uint8, caller, msg string,
+	fields elalog.Fields) {
+	buf := elalog.AppendJSON(nil, time.Now(), elalog.Level(level), l.tag,
+		caller, msg, fields)
+	l.json.Output(calldepth, string(buf))
+}
+
+// sprintln formats the operands like fmt.Sprintln without the newline.
+func sprintln(a ...interface{}) string {
+	msg := fmt.Sprintln(a...)
+	return msg[:len(msg)-1]
+}
+
+// caller returns the function name and position of the logging call.
+func (l *Logger) caller() (string, string, bool) {
+	pc, file, line, ok := runtime.Caller(l.calldepth + 1)
+	if !ok {
+		return "", "", false
+	}
+	return runtime.FuncForPC(pc).Name(),
+		filepath.Base(file) + ":" + strconv.Itoa(line), true
+}
+
 func (l *Logger) Debug(a ...interface{}) {
-	if l.level > debugLog {
+	if !l.enabled(debugLog) {
 		return
 	}
 
-	pc, file, line, ok := runtime.Caller(calldepth)
+	fn, pos, ok := l.caller()
 	if !ok {
 		return
 	}
 
-	fn := runtime.FuncForPC(pc)
-	a = append([]interface{}{fn.Name(), filepath.Base(file) + ":" + strconv.Itoa(line)}, a...)
+	if atomic.LoadInt32(&jsonFormat) != 0 {
+		a, fields := elalog.SplitFields(a)
+		l.outputJSON(debugLog, fn+" "+pos, sprintln(a...), fields)
+		return
+	}
+	a = append([]interface{}{fn, pos}, a...)
 
 	l.Output(debugLog, a...)
 }
 
 func (l *Logger) Debugf(format string, a ...interface{}) {
-	if l.level > debugLog {
+	if !l.enabled(debugLog) {
 		return
 	}
 
-	pc, file, line, ok := runtime.Caller(calldepth)
+	fn, pos, ok := l.caller()
 	if !ok {
 		return
 	}
 
-	fn := runtime.FuncForPC(pc)
-	a = append([]interface{}{fn.Name(), filepath.Base(file), line}, a...)
+	if atomic.LoadInt32(&jsonFormat) != 0 {
+		a, fields := elalog.SplitFields(a)
+		l.outputJSON(debugLog, fn+" "+pos, fmt.Sprintf(format, a...), fields)
+		return
+	}
+	a = append([]interface{}{fn, pos}, a...)
 
-	l.Outputf(debugLog, "%s %s:%d "+format, a...)
+	l.Outputf(debugLog, "%s %s "+format, a...)
 }
 
 func (l *Logger) Info(a ...interface{}) {
@@ -183,9 +279,7 @@ func (l *Logger) Warnf(format string, a ...interface{}) {
 }
 
 func (l *Logger) Error(a ...interface{}) {
-	if l.level <= errorLog {
-		l.Output(errorLog, a...)
-	}
+	l.Output(errorLog, a...)
 }
 
 func (l *Logger) Errorf(format string, a ...interface{}) {
@@ -202,12 +296,125 @@ func (l *Logger) Fatalf(format string, a ...interface{}) {
 
 // Level returns the current logging level.
 func (l *Logger) Level() elalog.Level {
-	return elalog.Level(l.level)
+	return elalog.Level(atomic.LoadUint32(&l.level))
 }
 
 // SetLevel changes the logging level to the passed level.
 func (l *Logger) SetLevel(level elalog.Level) {
-	l.level = uint8(level)
+	atomic.StoreUint32(&l.level, uint32(level))
+}
+
+// Register registers the logger of the subsystem, so the level can be
+// changed by name.  A logger registered with the same name is replaced.  The
+// name is used as the subsystem in JSON messages if the logger has none.
+func Register(name string, l *Logger) {
+	subsystemsMtx.Lock()
+	if l.tag == "" {
+		l.tag = name
+	}
+	subsystems[name] = l
+	subsystemsMtx.Unlock()
+}
+
+// Subsystems returns the names of the registered loggers in order.
+func Subsystems() []string {
+	subsystemsMtx.Lock()
+	names := make([]string, 0, len(subsystems))
+	for name := range subsystems {
+		names = append(names, name)
+	}
+	subsystemsMtx.Unlock()
+	sort.Strings(names)
+	return names
+}
+
+// SetLevel changes the logging level of the subsystem, or of all subsystems
+// if the name is AllSubsystems.
+func SetLevel(name string, level elalog.Level) error {
+	if level > elalog.LevelOff {
+		return fmt.Errorf("invalid log level %d", level)
+	}
+
+	subsystemsMtx.Lock()
+	defer subsystemsMtx.Unlock()
+	if name == AllSubsystems {
+		for _, l := range subsystems {
+			l.SetLevel(level)
+		}
+		return nil
+	}
+	l, ok := subsystems[name]
+	if !ok {
+		names := make([]string, 0, len(subsystems))
+		for name := range subsystems {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown log subsystem %q, expecting %s or "+
+			"one of %s", name, AllSubsystems, strings.Join(names, ", "))
+	}
+	l.SetLevel(level)
+	return nil
+}
+
+// ParseLevel parses a logging level from a number, such as "0", or a name,
+// such as "debug" or "dbg".
+func ParseLevel(str string) (elalog.Level, error) {
+	if n, err := strconv.ParseUint(str, 10, 8); err == nil {
+		if n > uint64(elalog.LevelOff) {
+			return 0, fmt.Errorf("invalid log level %s", str)
+		}
+		return elalog.Level(n), nil
+	}
+	level, ok := elalog.LevelFromString(str)
+	if !ok {
+		return 0, fmt.Errorf("invalid log level %s", str)
+	}
+	return level, nil
+}
+
+// SetLevels changes the logging levels of subsystems by a comma separated
+// list of name=level pairs, such as "netsync=debug,mempool=2".
+func SetLevels(levels string) error {
+	for _, pair := range strings.Split(levels, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		fields := strings.Split(pair, "=")
+		if len(fields) != 2 {
+			return fmt.Errorf("invalid log level %q, expecting "+
+				"subsystem=level", pair)
+		}
+		level, err := ParseLevel(strings.TrimSpace(fields[1]))
+		if err != nil {
+			return err
+		}
+		if err := SetLevel(strings.TrimSpace(fields[0]), level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetFormat changes the format of log messages, which is FormatText or
+// FormatJSON.
+func SetFormat(format string) error {
+	switch format {
+	case "", FormatText:
+		atomic.StoreInt32(&jsonFormat, 0)
+	case FormatJSON:
+		atomic.StoreInt32(&jsonFormat, 1)
+	default:
+		return fmt.Errorf("invalid log format %q, expecting %s or %s",
+			format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
+// IsJSONFormat returns whether log messages are written in JSON format.
+func IsJSONFormat() bool {
+	return atomic.LoadInt32(&jsonFormat) != 0
 }
 
 func Debug(a ...interface{}) {
@@ -0,0 +1,83 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/utils/elalog"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLevels(t *testing.T) {
+	var buf bytes.Buffer
+	root := newLogger(&buf, 1)
+	Register("test", root)
+	synclog := root.Subsystem("testsync", elalog.LevelInfo)
+	pool := root.Subsystem("testpool", elalog.LevelInfo)
+
+	assert.NoError(t, SetLevels("testsync=debug, testpool=3"))
+	assert.Equal(t, elalog.LevelDebug, synclog.Level())
+	assert.Equal(t, elalog.LevelError, pool.Level())
+	assert.Equal(t, elalog.LevelInfo, root.Level())
+
+	assert.Error(t, SetLevels("testsync=6"))
+	assert.Error(t, SetLevels("testsync"))
+	assert.Error(t, SetLevels("unknown=0"))
+
+	assert.NoError(t, SetLevel(AllSubsystems, elalog.LevelWarn))
+	for _, l := range []*Logger{root, synclog, pool} {
+		assert.Equal(t, elalog.LevelWarn, l.Level())
+	}
+
+	synclog.Info("filtered")
+	pool.Warn("written")
+	assert.NotContains(t, buf.String(), "filtered")
+	assert.Contains(t, buf.String(), "written")
+}
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	l := newLogger(&buf, 0).Subsystem("testjson", elalog.LevelDebug)
+
+	l.Info("receive block", elalog.Fields{"height": 100, "hash": "abcd"})
+	assert.True(t, strings.HasSuffix(buf.String(),
+		"receive block hash=abcd height=100\n"))
+
+	assert.Error(t, SetFormat("xml"))
+	assert.NoError(t, SetFormat(FormatJSON))
+	defer SetFormat(FormatText)
+
+	buf.Reset()
+	l.Warnf("lost peer %s", "1.2.3.4", elalog.Fields{"peer": "1.2.3.4"})
+	l.Debug("debug message")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	var m struct {
+		Level     string
+		Subsystem string
+		Caller    string
+		Msg       string
+		Fields    map[string]interface{}
+	}
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &m))
+	assert.Equal(t, "warn", m.Level)
+	assert.Equal(t, "testjson", m.Subsystem)
+	assert.Equal(t, "lost peer 1.2.3.4", m.Msg)
+	assert.Equal(t, "1.2.3.4", m.Fields["peer"])
+
+	m.Fields = nil
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &m))
+	assert.Equal(t, "debug", m.Level)
+	assert.Contains(t, m.Caller, "log_test.go:")
+	assert.Nil(t, m.Fields)
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+// Package metrics implements counters, gauges and histograms of the node, and
+// exports them in the Prometheus text exposition format.
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+)
+
+// DefaultBuckets is the default upper bounds of histogram buckets in seconds.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5,
+	10}
+
+// metric is a metric with the label values.
+type metric struct {
+	labelValues []string
+	value       float64
+
+	// buckets, sum and count are used by histogram only.
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+// family is a group of metrics with the same name and label names.
+type family struct {
+	mtx        sync.Mutex
+	name       string
+	help       string
+	typ        string
+	labelNames []string
+	buckets    []float64
+	metrics    map[string]*metric
+}
+
+func (f *family) get(labelValues []string) *metric {
+	if len(labelValues) != len(f.labelNames) {
+		panic("metrics: " + f.name + " needs " +
+			strconv.Itoa(len(f.labelNames)) + " label values")
+	}
+	key := strings.Join(labelValues, "\xff")
+	m, ok := f.metrics[key]
+	if !ok {
+		m = &metric{labelValues: append([]string(nil), labelValues...)}
+		if f.typ == typeHistogram {
+			m.buckets = make([]uint64, len(f.buckets))
+		}
+		f.metrics[key] = m
+	}
+	return m
+}
+
+// Counter is a metric that only increases, such as the count of evictions.
+type Counter struct {
+	f *family
+}
+
+// Inc increases the counter of the label values by 1.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add increases the counter of the label values by v, v should not be
+// negative.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.f.mtx.Lock()
+	c.f.get(labelValues).value += v
+	c.f.mtx.Unlock()
+}
+
+// Gauge is a metric that can go up and down, such as the best height.
+type Gauge struct {
+	f *family
+}
+
+// Set sets the gauge of the label values to v.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.mtx.Lock()
+	g.f.get(labelValues).value = v
+	g.f.mtx.Unlock()
+}
+
+// Add adds v to the gauge of the label values.
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.f.mtx.Lock()
+	g.f.get(labelValues).value += v
+	g.f.mtx.Unlock()
+}
+
+// Reset removes the gauges of all label values, it is used before setting
+// the gauges of label values which may disappear, such as peers by services.
+func (g *Gauge) Reset() {
+	g.f.mtx.Lock()
+	g.f.metrics = make(map[string]*metric)
+	g.f.mtx.Unlock()
+}
+
+// Histogram samples observations into buckets, such as the latency of RPC.
+type Histogram struct {
+	f *family
+}
+
+// Observe adds an observation of the label values.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.f.mtx.Lock()
+	m := h.f.get(labelValues)
+	for i, bound := range h.f.buckets {
+		if v <= bound {
+			m.buckets[i]++
+		}
+	}
+	m.sum += v
+	m.count++
+	h.f.mtx.Unlock()
+}
+
+// Registry holds the metrics to be exported.
+type Registry struct {
+	mtx        sync.Mutex
+	families   []*family
+	names      map[string]struct{}
+	collectors []func()
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]struct{})}
+}
+
+func (r *Registry) register(name, help, typ string, buckets []float64,
+	labelNames []string) *family {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	if _, ok := r.names[name]; ok {
+		panic("metrics: duplicate metric " + name)
+	}
+	r.names[name] = struct{}{}
+	f := &family{
+		name:       name,
+		help:       help,
+		typ:        typ,
+		labelNames: labelNames,
+		buckets:    buckets,
+		metrics:    make(map[string]*metric),
+	}
+	r.families = append(r.families, f)
+	return f
+}
+
+// NewCounter registers a counter with the label names.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{f: r.register(name, help, typeCounter, nil, labelNames)}
+}
+
+// NewGauge registers a gauge with the label names.
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{f: r.register(name, help, typeGauge, nil, labelNames)}
+}
+
+// NewHistogram registers a histogram with the bucket upper bounds and the
+// label names, DefaultBuckets is used if buckets is empty.
+func (r *Registry) NewHistogram(name, help string, buckets []float64,
+	labelNames ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{f: r.register(name, help, typeHistogram, buckets,
+		labelNames)}
+}
+
+// RegisterCollector registers a function to be called before the metrics are
+// written, it is used to update gauges sampled from the node state.
+func (r *Registry) RegisterCollector(collect func()) {
+	r.mtx.Lock()
+	r.collectors = append(r.collectors, collect)
+	r.mtx.Unlock()
+}
+
+// Write runs the collectors then writes all metrics in the Prometheus text
+// exposition format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mtx.Lock()
+	collectors := append([]func(){}, r.collectors...)
+	families := append([]*family{}, r.families...)
+	r.mtx.Unlock()
+
+	for _, collect := range collectors {
+		collect()
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		f.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (f *family) write(w *bufio.Writer) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	w.WriteString("# HELP " + f.name + " " + escapeHelp(f.help) + "\n")
+	w.WriteString("# TYPE " + f.name + " " + f.typ + "\n")
+
+	keys := make([]string, 0, len(f.metrics))
+	for key := range f.metrics {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		m := f.metrics[key]
+		labels := f.labels(m.labelValues)
+		if f.typ != typeHistogram {
+			writeSample(w, f.name, labels, "", m.value)
+			continue
+		}
+		for i, bound := range f.buckets {
+			writeSample(w, f.name+"_bucket", labels,
+				`le="`+formatFloat(bound)+`"`, float64(m.buckets[i]))
+		}
+		writeSample(w, f.name+"_bucket", labels, `le="+Inf"`,
+			float64(m.count))
+		writeSample(w, f.name+"_sum", labels, "", m.sum)
+		writeSample(w, f.name+"_count", labels, "", float64(m.count))
+	}
+}
+
+func (f *family) labels(values []string) string {
+	pairs := make([]string, len(values))
+	for i, value := range values {
+		pairs[i] = f.labelNames[i] + `="` + escapeLabel(value) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+func writeSample(w *bufio.Writer, name, labels, extra string, value float64) {
+	w.WriteString(name)
+	if labels != "" && extra != "" {
+		labels += ","
+	}
+	labels += extra
+	if labels != "" {
+		w.WriteString("{" + labels + "}")
+	}
+	w.WriteString(" " + formatFloat(value) + "\n")
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeHelp(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	return strings.Replace(s, "\n", `\n`, -1)
+}
+
+func escapeLabel(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return strings.Replace(s, "\n", `\n`, -1)
+}
+
+// DefaultRegistry is the registry of the node metrics.
+var DefaultRegistry = NewRegistry()
+
+// NewCounter registers a counter in the default registry.
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	return DefaultRegistry.NewCounter(name, help, labelNames...)
+}
+
+// NewGauge registers a gauge in the default registry.
+func NewGauge(name, help string, labelNames ...string) *Gauge {
+	return DefaultRegistry.NewGauge(name, help, labelNames...)
+}
+
+// NewHistogram registers a histogram in the default registry.
+func NewHistogram(name, help string, buckets []float64,
+	labelNames ...string) *Histogram {
+	return DefaultRegistry.NewHistogram(name, help, buckets, labelNames...)
+}
+
+// RegisterCollector registers a collector in the default registry.
+func RegisterCollector(collect func()) {
+	DefaultRegistry.RegisterCollector(collect)
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package metrics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	r := NewRegistry()
+	height := r.NewGauge("ela_best_height", "Height of the best block.")
+	peers := r.NewGauge("ela_peers", "Connected peers.", "service")
+	evictions := r.NewCounter("ela_evictions_total", "Evictions.", "reason")
+	latency := r.NewHistogram("ela_rpc_seconds", "Latency.",
+		[]float64{1, 0.1}, "method")
+
+	r.RegisterCollector(func() {
+		height.Set(100)
+	})
+	peers.Set(2, `a"b`)
+	evictions.Inc("full")
+	evictions.Add(2, "full")
+	latency.Observe(0.05, "getinfo")
+	latency.Observe(0.5, "getinfo")
+
+	var buf bytes.Buffer
+	assert.NoError(t, r.Write(&buf))
+	assert.Equal(t, `# HELP ela_best_height Height of the best block.
+# TYPE ela_best_height gauge
+ela_best_height 100
+# HELP ela_peers Connected peers.
+# TYPE ela_peers gauge
+ela_peers{service="a\"b"} 2
+# HELP ela_evictions_total Evictions.
+# TYPE ela_evictions_total counter
+ela_evictions_total{reason="full"} 3
+# HELP ela_rpc_seconds Latency.
+# TYPE ela_rpc_seconds histogram
+ela_rpc_seconds_bucket{method="getinfo",le="0.1"} 1
+ela_rpc_seconds_bucket{method="getinfo",le="1"} 2
+ela_rpc_seconds_bucket{method="getinfo",le="+Inf"} 2
+ela_rpc_seconds_sum{method="getinfo"} 0.55
+ela_rpc_seconds_count{method="getinfo"} 2
+`, buf.String())
+
+	peers.Reset()
+	buf.Reset()
+	assert.NoError(t, r.Write(&buf))
+	assert.NotContains(t, buf.String(), "ela_peers{")
+
+	assert.Panics(t, func() {
+		r.NewGauge("ela_best_height", "")
+	})
+	assert.Panics(t, func() {
+		peers.Set(1)
+	})
+}
@@ -26,9 +26,10 @@ import (
 
 // todo remove this
 const (
-	txpoolCheckpointKey = "cp_txPool"
-	dposCheckpointKey   = "cp_dpos"
-	crCheckpointKey     = "cp_cr"
+	txpoolCheckpointKey       = "cp_txPool"
+	dposCheckpointKey         = "cp_dpos"
+	crCheckpointKey           = "cp_cr"
+	feeEstimatorCheckpointKey = "cp_feeEstimator"
 
 	MaxCheckPointFilesCount int = 36
 )
@@ -263,6 +264,12 @@ func (m *Manager) Reset(filter func(point ICheckPoint) bool) {
 	}
 }
 
+// isLocalCheckpoint returns whether the checkpoint holds states of the local
+// node only, which are not derived from blocks.
+func isLocalCheckpoint(key string) bool {
+	return key == txpoolCheckpointKey || key == feeEstimatorCheckpointKey
+}
+
 // SafeHeight returns the minimum height of all checkpoints from which we can
 // rescan block chain data.
 func (m *Manager) SafeHeight() uint32 {
@@ -271,7 +278,7 @@ func (m *Manager) SafeHeight() uint32 {
 
 	height := uint32(math.MaxUint32)
 	for _, v := range m.checkpoints {
-		if v.Key() == "cp_txPool" {
+		if isLocalCheckpoint(v.Key()) {
 			continue
 		}
 		var recordHeight uint32
@@ -289,6 +296,63 @@ func (m *Manager) SafeHeight() uint32 {
 	return height
 }
 
+// Snapshots returns a snapshot of each registered checkpoint ordered by
+// priority, with the height of each snapshot set to the given height.  The
+// checkpoints of local states such as the transaction pool are excluded.  The
+// memory states must currently be at the given height, so the caller must
+// prevent blocks from being processed while taking the snapshots.
+func (m *Manager) Snapshots(height uint32) ([]ICheckPoint, error) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	sortedPoints := m.getOrderedCheckpoints()
+	snapshots := make([]ICheckPoint, 0, len(sortedPoints))
+	for _, v := range sortedPoints {
+		if isLocalCheckpoint(v.Key()) {
+			continue
+		}
+		snapshot := v.Snapshot()
+		if snapshot == nil {
+			return nil, fmt.Errorf("take snapshot of checkpoint %s failed",
+				v.Key())
+		}
+		snapshot.SetHeight(height)
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// ImportCheckpoint saves the serialized data of the registered checkpoint with
+// the given key as both its default checkpoint file and the checkpoint file of
+// the given height, so the data is loaded by Restore.
+func (m *Manager) ImportCheckpoint(key string, height uint32,
+	data []byte) error {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	current, ok := m.checkpoints[key]
+	if !ok {
+		return fmt.Errorf("checkpoint %s is not registered", key)
+	}
+	if isLocalCheckpoint(key) {
+		return fmt.Errorf("checkpoint %s holds local states only", key)
+	}
+
+	root := m.cfg.CheckPointConfiguration.DataPath
+	dir := getCheckpointDirectory(root, current)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	paths := []string{getDefaultPath(root, current),
+		getFilePathByHeight(root, current, height)}
+	for _, path := range paths {
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Close will clean all related resources.
 func (m *Manager) Close() {
 	m.mtx.Lock()
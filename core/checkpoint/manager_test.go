@@ -93,6 +93,15 @@ func (*checkpoint) Key() string {
 	return test.DataDir
 }
 
+// localCheckpoint is a checkpoint of local states of the node.
+type localCheckpoint struct {
+	checkpoint
+}
+
+func (*localCheckpoint) Key() string {
+	return txpoolCheckpointKey
+}
+
 func (c *checkpoint) Snapshot() ICheckPoint {
 	data := *c.data // deep copy here
 	return &checkpoint{
@@ -195,6 +204,47 @@ func TestManager_SaveAndRestore(t *testing.T) {
 	cleanCheckpoints()
 }
 
+func TestManager_SnapshotsAndImport(t *testing.T) {
+	data := uint64(5)
+	pt := &checkpoint{
+		data:   &data,
+		height: 10,
+	}
+	cfg := &config.Configuration{
+		CheckPointConfiguration: config.CheckPointConfiguration{
+			EnableHistory: false,
+		}}
+	manager := NewManager(cfg)
+	manager.Register(pt)
+	local := &localCheckpoint{checkpoint{data: &data, height: 10}}
+	manager.Register(local)
+
+	// local checkpoints are not included in snapshots
+	snapshots, err := manager.Snapshots(12)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(snapshots))
+	assert.Equal(t, pt.Key(), snapshots[0].Key())
+	assert.Equal(t, uint32(12), snapshots[0].GetHeight())
+	assert.Equal(t, uint32(10), pt.GetHeight())
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, snapshots[0].Serialize(buf))
+	assert.Error(t, manager.ImportCheckpoint("unknown", 12, buf.Bytes()))
+	assert.Error(t, manager.ImportCheckpoint(local.Key(), 12, buf.Bytes()))
+
+	// Restore the imported data with a new manager.
+	manager2 := NewManager(cfg)
+	restored := &checkpoint{}
+	manager2.Register(restored)
+	assert.NoError(t, manager2.ImportCheckpoint(restored.Key(), 12,
+		buf.Bytes()))
+	assert.NoError(t, manager2.Restore())
+	assert.Equal(t, uint32(12), restored.GetHeight())
+	assert.Equal(t, data, *restored.data)
+
+	cleanCheckpoints()
+}
+
 func TestManager_GetCheckpoint_DisableHistory(t *testing.T) {
 	data := uint64(1)
 	currentHeight := uint32(10)
@@ -17,6 +17,7 @@ const (
 	MultiSig
 	Custom
 	Schnorr
+	HTLC
 )
 
 func IsStandard(code []byte) bool {
@@ -133,6 +134,9 @@ func GetCodeType(code []byte) ContractType {
 	if IsSchnorr(code) {
 		return Schnorr
 	}
+	if IsHTLC(code) {
+		return HTLC
+	}
 	return Custom
 }
 
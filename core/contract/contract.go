@@ -18,6 +18,7 @@ const (
 	PrefixDeposit    PrefixType = 0x1F
 	PrefixCRDID      PrefixType = 0x67
 	PrefixDPoSV2     PrefixType = 0x3f
+	PrefixHTLC       PrefixType = 0x28
 )
 
 // Contract include the redeem script and hash prefix
@@ -6,6 +6,7 @@
 package contract
 
 import (
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"testing"
@@ -128,3 +129,36 @@ func TestBasicAlgorithm(t *testing.T) {
 	fmt.Println("stakeProgramHash3", stakeProgramHash3)
 	assert.Equal(t, stakeProgramHash3, *stakeProgramHash4)
 }
+
+func TestHTLC(t *testing.T) {
+	_, recipient, _ := crypto.GenerateKeyPair()
+	_, refund, _ := crypto.GenerateKeyPair()
+	hashLock := common.Uint256(sha256.Sum256([]byte("preimage")))
+
+	for _, refundLock := range []uint32{1000, 1600000000} {
+		ct, err := CreateHTLCContract(hashLock, recipient, refund, refundLock)
+		assert.NoError(t, err)
+		assert.Equal(t, PrefixHTLC, ct.Prefix)
+		assert.True(t, IsHTLC(ct.Code))
+		assert.Equal(t, HTLC, GetCodeType(ct.Code))
+
+		addr, err := ct.ToProgramHash().ToAddress()
+		assert.NoError(t, err)
+		assert.Equal(t, "H", addr[:1])
+
+		htlc, err := ParseHTLC(ct.Code)
+		assert.NoError(t, err)
+		assert.Equal(t, hashLock, htlc.HashLock)
+		assert.True(t, crypto.Equal(recipient, htlc.Recipient))
+		assert.True(t, crypto.Equal(refund, htlc.Refund))
+		assert.Equal(t, refundLock, htlc.RefundLock)
+		assert.Equal(t, refundLock >= HTLCLockTimeThreshold, htlc.IsTimeLock())
+	}
+
+	// the templates of other contracts are not HTLC
+	code, err := CreateStandardRedeemScript(recipient)
+	assert.NoError(t, err)
+	assert.False(t, IsHTLC(code))
+	_, err = ParseHTLC(code)
+	assert.Error(t, err)
+}
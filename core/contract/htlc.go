@@ -0,0 +1,134 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package contract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/crypto"
+	"github.com/elastos/Elastos.ELA/vm"
+)
+
+const (
+	// HTLCLockTimeThreshold is the same as the lock time threshold of
+	// bitcoin, a refund lock below it is a block height, otherwise it is a
+	// unix timestamp compared with the median time past.
+	HTLCLockTimeThreshold = 500000000
+
+	// HTLCPreimageLength is the length of the secret revealed by the
+	// recipient to redeem a HTLC.
+	HTLCPreimageLength = 32
+
+	// HTLCRedeemParameterLength is the length of the program parameter to
+	// redeem a HTLC, a signature of the recipient followed by the preimage.
+	HTLCRedeemParameterLength = crypto.SignatureScriptLength + 1 +
+		HTLCPreimageLength
+
+	// HTLCRefundParameterLength is the length of the program parameter to
+	// refund a HTLC, a signature of the refund key.
+	HTLCRefundParameterLength = crypto.SignatureScriptLength
+
+	// htlcScriptLength is the length of the redeem script:
+	// 32 <hash lock> 33 <recipient> 33 <refund> 4 <refund lock> HTLC
+	htlcScriptLength = 1 + 32 + 1 + 33 + 1 + 33 + 1 + 4 + 1
+)
+
+// HashTimeLock is the terms of a hash time locked contract.  The locked coins
+// can be redeemed by the recipient with the preimage of the hash lock, or
+// refunded to the refund key after the refund lock.
+type HashTimeLock struct {
+	HashLock   common.Uint256
+	Recipient  *crypto.PublicKey
+	Refund     *crypto.PublicKey
+	RefundLock uint32
+}
+
+// IsTimeLock returns if the refund lock is a unix timestamp instead of a
+// block height.
+func (h *HashTimeLock) IsTimeLock() bool {
+	return h.RefundLock >= HTLCLockTimeThreshold
+}
+
+func CreateHTLCRedeemScript(hashLock common.Uint256, recipient,
+	refund *crypto.PublicKey, refundLock uint32) ([]byte, error) {
+	if recipient == nil || refund == nil {
+		return nil, errors.New("public key is nil")
+	}
+	recipientKey, err := recipient.EncodePoint(true)
+	if err != nil {
+		return nil, errors.New("create htlc redeem script, encode recipient failed")
+	}
+	refundKey, err := refund.EncodePoint(true)
+	if err != nil {
+		return nil, errors.New("create htlc redeem script, encode refund failed")
+	}
+
+	var lock [4]byte
+	binary.LittleEndian.PutUint32(lock[:], refundLock)
+
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(len(hashLock)))
+	buf.Write(hashLock[:])
+	buf.WriteByte(byte(len(recipientKey)))
+	buf.Write(recipientKey)
+	buf.WriteByte(byte(len(refundKey)))
+	buf.Write(refundKey)
+	buf.WriteByte(byte(len(lock)))
+	buf.Write(lock[:])
+	buf.WriteByte(byte(vm.HTLC))
+
+	return buf.Bytes(), nil
+}
+
+func CreateHTLCContract(hashLock common.Uint256, recipient,
+	refund *crypto.PublicKey, refundLock uint32) (*Contract, error) {
+	redeemScript, err := CreateHTLCRedeemScript(hashLock, recipient, refund,
+		refundLock)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Contract{
+		Code:   redeemScript,
+		Prefix: PrefixHTLC,
+	}, nil
+}
+
+func IsHTLC(code []byte) bool {
+	if len(code) != htlcScriptLength {
+		return false
+	}
+	if code[0] != 32 || code[33] != 33 || code[67] != 33 || code[101] != 4 {
+		return false
+	}
+	return code[htlcScriptLength-1] == byte(vm.HTLC)
+}
+
+// ParseHTLC parses the terms of the HTLC from the redeem script.
+func ParseHTLC(code []byte) (*HashTimeLock, error) {
+	if !IsHTLC(code) {
+		return nil, errors.New("invalid htlc redeem script")
+	}
+
+	var h HashTimeLock
+	copy(h.HashLock[:], code[1:33])
+	recipient, err := crypto.DecodePoint(code[34:67])
+	if err != nil {
+		return nil, err
+	}
+	refund, err := crypto.DecodePoint(code[68:101])
+	if err != nil {
+		return nil, err
+	}
+	h.Recipient = recipient
+	h.Refund = refund
+	h.RefundLock = binary.LittleEndian.Uint32(code[102:106])
+
+	return &h, nil
+}
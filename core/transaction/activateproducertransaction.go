@@ -12,6 +12,7 @@ import (
 	"math"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
@@ -27,9 +28,7 @@ type ActivateProducerTransaction struct {
 func (t *ActivateProducerTransaction) CheckTransactionInput() error {
 	chainParams := t.parameters.Config
 	blockHeight := t.parameters.BlockHeight
-	newActivateHeight := chainParams.DPoSConfiguration.NFTStartHeight
-
-	if blockHeight <= newActivateHeight {
+	if !chainParams.IsActiveAfter(config.DeploymentNFT, blockHeight) {
 		if len(t.Inputs()) != 0 {
 			return errors.New("no cost transactions must has no input")
 		}
@@ -40,9 +39,7 @@ func (t *ActivateProducerTransaction) CheckTransactionInput() error {
 func (t *ActivateProducerTransaction) CheckTransactionOutput() error {
 	chainParams := t.parameters.Config
 	blockHeight := t.parameters.BlockHeight
-	newActivateHeight := chainParams.DPoSConfiguration.NFTStartHeight
-
-	if blockHeight <= newActivateHeight {
+	if !chainParams.IsActiveAfter(config.DeploymentNFT, blockHeight) {
 		if len(t.Outputs()) > math.MaxUint16 {
 			return errors.New("output count should not be greater than 65535(MaxUint16)")
 		}
@@ -56,8 +53,7 @@ func (t *ActivateProducerTransaction) CheckTransactionOutput() error {
 func (t *ActivateProducerTransaction) CheckAttributeProgram() error {
 	chainParams := t.parameters.Config
 	blockHeight := t.parameters.BlockHeight
-	newActivateHeight := chainParams.DPoSConfiguration.NFTStartHeight
-	if blockHeight <= newActivateHeight {
+	if !chainParams.IsActiveAfter(config.DeploymentNFT, blockHeight) {
 		if len(t.Programs()) != 0 || len(t.Attributes()) != 0 {
 			return errors.New("zero cost tx should have no attributes and programs")
 		}
@@ -112,7 +108,7 @@ func (t *ActivateProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bo
 		crMember := t.parameters.BlockChain.GetCRCommittee().GetMemberByNodePublicKey(activateProducer.NodePublicKey)
 		if crMember != nil && (crMember.MemberState == crstate.MemberInactive ||
 			crMember.MemberState == crstate.MemberIllegal) {
-			if t.parameters.BlockHeight < t.parameters.Config.EnableActivateIllegalHeight &&
+			if !t.parameters.Config.IsActive(config.DeploymentEnableActivateIllegal, t.parameters.BlockHeight) &&
 				crMember.MemberState == crstate.MemberIllegal {
 				return elaerr.Simple(elaerr.ErrTxPayload, errors.New(
 					"activate MemberIllegal CR is not allowed before EnableActivateIllegalHeight")), true
@@ -125,17 +121,18 @@ func (t *ActivateProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bo
 	}
 
 	producer := t.parameters.BlockChain.GetState().GetProducer(activateProducer.NodePublicKey)
+	t.lookupExists("Producer", common.BytesToHexString(activateProducer.NodePublicKey), producer != nil)
 	if producer == nil || !bytes.Equal(producer.NodePublicKey(),
 		activateProducer.NodePublicKey) {
 		return elaerr.Simple(elaerr.ErrTxPayload, errors.New("getting unknown producer")), true
 	}
 
-	if t.parameters.BlockHeight < t.parameters.Config.EnableActivateIllegalHeight {
+	if !t.parameters.Config.IsActive(config.DeploymentEnableActivateIllegal, t.parameters.BlockHeight) {
 		if producer.State() != state.Inactive {
 			return elaerr.Simple(elaerr.ErrTxPayload, errors.New("can not activate this producer")), true
 		}
 	} else {
-		if t.parameters.BlockHeight < t.parameters.Config.CRConfiguration.ChangeCommitteeNewCRHeight {
+		if !t.parameters.Config.IsActive(config.DeploymentChangeCommitteeNewCR, t.parameters.BlockHeight) {
 			if producer.State() != state.Active &&
 				producer.State() != state.Inactive &&
 				producer.State() != state.Illegal {
@@ -156,7 +153,7 @@ func (t *ActivateProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bo
 	}
 
 	depositAmount := common.Fixed64(0)
-	if t.parameters.BlockHeight < t.parameters.Config.CRConfiguration.CRVotingStartHeight {
+	if !t.parameters.Config.IsActive(config.DeploymentCRVoting, t.parameters.BlockHeight) {
 		programHash, err := state.GetOwnerKeyDepositProgramHash(producer.OwnerPublicKey())
 		if err != nil {
 			return elaerr.Simple(elaerr.ErrTxPayload, err), true
@@ -197,9 +194,8 @@ func (t *ActivateProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bo
 
 	chainParams := t.parameters.Config
 	blockHeight := t.parameters.BlockHeight
-	newActivateHeight := chainParams.DPoSConfiguration.NFTStartHeight
 	end := false
-	if blockHeight <= newActivateHeight {
+	if !chainParams.IsActiveAfter(config.DeploymentNFT, blockHeight) {
 		end = true
 	}
 	return nil, end
@@ -10,6 +10,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/types/interfaces"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
@@ -39,7 +41,7 @@ func (t *CancelProducerTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.SupportMultiCodeHeight {
+	if !chainParams.IsActive(config.DeploymentSupportMultiCode, blockHeight) {
 		if t.PayloadVersion() == payload.ProcessMultiCodeVersion {
 			return errors.New(fmt.Sprintf("not support %s transaction "+
 				"with payload version %d before SupportMultiCodeHeight",
@@ -111,6 +113,7 @@ func (t *CancelProducerTransaction) checkProcessProducer(params *TransactionPara
 	}
 
 	producer := t.parameters.BlockChain.GetState().GetProducer(processProducer.OwnerKey)
+	t.lookupExists("Producer", common.BytesToHexString(processProducer.OwnerKey), producer != nil)
 	if producer == nil || !bytes.Equal(producer.OwnerPublicKey(),
 		processProducer.OwnerKey) {
 		return nil, errors.New("getting unknown producer")
@@ -10,6 +10,7 @@ import (
 	"math"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
 	"github.com/elastos/Elastos.ELA/core/types/interfaces"
@@ -50,7 +51,7 @@ func (t *CoinBaseTransaction) CheckTransactionOutput() error {
 
 	foundationReward := t.Outputs()[0].Value
 	var totalReward = common.Fixed64(0)
-	if blockHeight < chainParams.PublicDPOSHeight {
+	if !chainParams.IsActive(config.DeploymentPublicDPOS, blockHeight) {
 		for _, output := range t.Outputs() {
 			if output.AssetID != core.ELAAssetID {
 				return errors.New("asset ID in coinbase is invalid")
@@ -97,7 +98,7 @@ func (t *CoinBaseTransaction) IsAllowedInPOWConsensus() bool {
 
 func (a *CoinBaseTransaction) SpecialContextCheck() (result elaerr.ELAError, end bool) {
 	para := a.parameters
-	if para.BlockHeight >= para.Config.CRConfiguration.CRCommitteeStartHeight {
+	if para.Config.IsActive(config.DeploymentCRCommittee, para.BlockHeight) {
 		if para.BlockChain.GetState().GetConsensusAlgorithm() == 0x01 {
 			if !a.outputs[0].ProgramHash.IsEqual(*para.Config.DestroyELAProgramHash) {
 				return elaerr.Simple(elaerr.ErrTxInvalidOutput,
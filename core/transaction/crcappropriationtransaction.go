@@ -11,6 +11,7 @@ import (
 	"math"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
@@ -66,7 +67,7 @@ func (t *CRCAppropriationTransaction) CheckTransactionOutput() error {
 		}
 	}
 
-	if t.parameters.BlockChain.GetHeight() >= chainParams.PublicDPOSHeight && specialOutputCount > 1 {
+	if chainParams.IsActive(config.DeploymentPublicDPOS, t.parameters.BlockChain.GetHeight()) && specialOutputCount > 1 {
 		return errors.New("special output count should less equal than 1")
 	}
 
@@ -100,7 +101,7 @@ func (t *CRCAppropriationTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.CRConfiguration.CRCommitteeStartHeight {
+	if !chainParams.IsActive(config.DeploymentCRCommittee, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before CRCommitteeStartHeight", t.TxType().Name()))
 	}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 
 	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
 	"github.com/elastos/Elastos.ELA/crypto"
@@ -39,7 +40,7 @@ func (t *CRCouncilMemberClaimNodeTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.CRConfiguration.CRClaimDPOSNodeStartHeight {
+	if !chainParams.IsActive(config.DeploymentCRClaimDPOSNode, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before CRClaimDPOSNodeStartHeight", t.TxType().Name()))
 	}
@@ -52,7 +53,7 @@ func (t *CRCouncilMemberClaimNodeTransaction) SpecialContextCheck() (result elae
 		return elaerr.Simple(elaerr.ErrTxPayload, errors.New("invalid payload")), true
 	}
 
-	if t.parameters.BlockHeight < t.parameters.Config.DPoSV2StartHeight &&
+	if !t.parameters.Config.IsActive(config.DeploymentDPoSV2, t.parameters.BlockHeight) &&
 		!t.parameters.BlockChain.GetCRCommittee().IsInElectionPeriod() {
 		return elaerr.Simple(elaerr.ErrTxPayload, errors.New("CRCouncilMemberClaimNode must during election period")), true
 	}
@@ -60,7 +61,7 @@ func (t *CRCouncilMemberClaimNodeTransaction) SpecialContextCheck() (result elae
 	did := manager.CRCouncilCommitteeDID
 	var crMember *crstate.CRMember
 	comm := t.parameters.BlockChain.GetCRCommittee()
-	if t.parameters.BlockHeight >= t.parameters.Config.DPoSV2StartHeight {
+	if t.parameters.Config.IsActive(config.DeploymentDPoSV2, t.parameters.BlockHeight) {
 		switch t.payloadVersion {
 		case payload.CurrentCRClaimDPoSNodeVersion:
 			crMember = t.parameters.BlockChain.GetCRCommittee().GetMember(did)
@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	elaerr "github.com/elastos/Elastos.ELA/errors"
 )
@@ -45,7 +46,7 @@ func (t *CRCProposalRealWithdrawTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.CRConfiguration.CRAssetsRectifyTransactionHeight {
+	if !chainParams.IsActive(config.DeploymentCRAssetsRectify, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before CRCProposalWithdrawPayloadV1Height", t.TxType().Name()))
 	}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
 	"github.com/elastos/Elastos.ELA/database"
@@ -39,10 +40,10 @@ func (t *CRCProposalReviewTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.CRConfiguration.CRCommitteeStartHeight {
+	if !chainParams.IsActive(config.DeploymentCRCommittee, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before CRCommitteeStartHeight", t.TxType().Name()))
-	} else if blockHeight < chainParams.CRConfiguration.CRCProposalDraftDataStartHeight {
+	} else if !chainParams.IsActive(config.DeploymentCRCProposalDraftData, blockHeight) {
 		if t.PayloadVersion() != payload.CRCProposalVersion {
 			return errors.New("payload version should be CRCProposalVersion")
 		}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
@@ -42,10 +43,10 @@ func (t *CRCProposalTrackingTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.CRConfiguration.CRCommitteeStartHeight {
+	if !chainParams.IsActive(config.DeploymentCRCommittee, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before CRCommitteeStartHeight", t.TxType().Name()))
-	} else if blockHeight < chainParams.CRConfiguration.CRCProposalDraftDataStartHeight {
+	} else if !chainParams.IsActive(config.DeploymentCRCProposalDraftData, blockHeight) {
 		if t.PayloadVersion() != payload.CRCProposalVersion {
 			return errors.New("payload version should be CRCProposalVersion")
 		}
@@ -288,7 +289,7 @@ func (t *CRCProposalTrackingTransaction) checkCRCProposalProgressTracking(
 func (t *CRCProposalTrackingTransaction) checkCRCProposalRejectedTracking(
 	params *TransactionParameters, cptPayload *payload.CRCProposalTracking, pState *crstate.ProposalState,
 	blockHeight uint32, payloadVersion byte) error {
-	if blockHeight < t.parameters.Config.CRConfiguration.CRCProposalWithdrawPayloadV1Height {
+	if !t.parameters.Config.IsActive(config.DeploymentCRCProposalWithdrawPayloadV1, blockHeight) {
 		return t.checkCRCProposalProgressTracking(t.parameters, cptPayload, pState, payloadVersion)
 	}
 	// Check stage of proposal
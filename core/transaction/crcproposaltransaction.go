@@ -13,6 +13,7 @@ import (
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
@@ -43,7 +44,7 @@ func (t *CRCProposalTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.CRConfiguration.CRCProposalDraftDataStartHeight {
+	if !chainParams.IsActive(config.DeploymentCRCProposalDraftData, blockHeight) {
 		if t.PayloadVersion() != payload.CRCProposalVersion {
 			return errors.New("payload version should be CRCProposalVersion")
 		}
@@ -59,22 +60,22 @@ func (t *CRCProposalTransaction) HeightVersionCheck() error {
 	}
 	switch p.ProposalType {
 	case payload.ChangeProposalOwner, payload.CloseProposal, payload.SecretaryGeneral:
-		if blockHeight < chainParams.CRConfiguration.CRCProposalV1Height {
+		if !chainParams.IsActive(config.DeploymentCRCProposalV1, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s CRCProposal"+
 				" transactio before CRCProposalV1Height", p.ProposalType.Name()))
 		}
 	case payload.ReserveCustomID, payload.ReceiveCustomID, payload.ChangeCustomIDFee:
-		if blockHeight < chainParams.CustomIDProposalStartHeight {
+		if !chainParams.IsActive(config.DeploymentCustomIDProposal, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s CRCProposal"+
 				" transaction before CustomIDProposalStartHeight", p.ProposalType.Name()))
 		}
 	case payload.RegisterSideChain:
-		if blockHeight < chainParams.NewCrossChainStartHeight {
+		if !chainParams.IsActive(config.DeploymentNewCrossChain, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s CRCProposal"+
 				" transaction before NewCrossChainStartHeight", p.ProposalType.Name()))
 		}
 	default:
-		if blockHeight < chainParams.CRConfiguration.CRCommitteeStartHeight {
+		if !chainParams.IsActive(config.DeploymentCRCommittee, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s CRCProposal"+
 				" transaction before CRCommitteeStartHeight", p.ProposalType.Name()))
 		}
@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/contract/program"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
@@ -67,16 +68,16 @@ func (t *CreateNFTTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.DPoSConfiguration.NFTStartHeight {
+	if !chainParams.IsActive(config.DeploymentNFT, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before NFTStartHeight", t.TxType().Name()))
 	}
-	if blockHeight < chainParams.DPoSConfiguration.NFTV2StartHeight &&
+	if !chainParams.IsActive(config.DeploymentNFTV2, blockHeight) &&
 		t.payloadVersion >= payload.CreateNFTVersion2 {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before NFTV2StartHeight", t.TxType().Name()))
 	}
-	if blockHeight >= chainParams.DPoSConfiguration.NFTV2StartHeight &&
+	if chainParams.IsActive(config.DeploymentNFTV2, blockHeight) &&
 		t.payloadVersion != payload.CreateNFTVersion2 {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"with payload version 0 after NFTV2StartHeight", t.TxType().Name()))
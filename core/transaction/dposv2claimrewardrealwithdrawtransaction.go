@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	elaerr "github.com/elastos/Elastos.ELA/errors"
 )
@@ -45,7 +46,7 @@ func (t *DposV2ClaimRewardRealWithdrawTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.DPoSV2StartHeight {
+	if !chainParams.IsActive(config.DeploymentDPoSV2, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before DPoSV2StartHeight", t.TxType().Name()))
 	}
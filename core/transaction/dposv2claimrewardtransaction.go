@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/elastos/Elastos.ELA/common/config"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
 
 	"github.com/elastos/Elastos.ELA/blockchain"
@@ -29,7 +30,7 @@ func (t *DPoSV2ClaimRewardTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.DPoSV2StartHeight {
+	if !chainParams.IsActive(config.DeploymentDPoSV2, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before DPoSV2StartHeight", t.TxType().Name()))
 	}
@@ -76,7 +77,7 @@ func (t *DPoSV2ClaimRewardTransaction) IsAllowedInPOWConsensus() bool {
 }
 
 func (t *DPoSV2ClaimRewardTransaction) SpecialContextCheck() (elaerr.ELAError, bool) {
-	if t.parameters.BlockHeight < t.parameters.Config.DPoSV2StartHeight {
+	if !t.parameters.Config.IsActive(config.DeploymentDPoSV2, t.parameters.BlockHeight) {
 		return elaerr.Simple(elaerr.ErrTxPayload, errors.New("can not claim reward before dposv2startheight")), true
 	}
 
@@ -10,6 +10,7 @@ import (
 	"fmt"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	program2 "github.com/elastos/Elastos.ELA/core/contract/program"
@@ -28,13 +29,13 @@ func (t *ExchangeVotesTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.DPoSV2StartHeight {
+	if !chainParams.IsActive(config.DeploymentDPoSV2, blockHeight) {
 		log.Debug("blockHeight:", blockHeight, "DPoSV2StartHeight:", chainParams.DPoSV2StartHeight)
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before DPoSV2StartHeight", t.TxType().Name()))
 	}
 
-	if blockHeight < chainParams.MultiExchangeVotesStartHeight &&
+	if !chainParams.IsActive(config.DeploymentMultiExchangeVotes, blockHeight) &&
 		len(t.programs) > 1 {
 		return errors.New(fmt.Sprintf("not support multi-addr %s transaction "+
 			"before MultiExchangeVotesStartHeight", t.TxType().Name()))
@@ -159,7 +160,7 @@ func (t *ExchangeVotesTransaction) CheckOutputMultiInputs() error {
 
 func (t *ExchangeVotesTransaction) CheckTransactionOutput() error {
 	inPow := t.parameters.BlockChain.GetState().GetConsensusAlgorithm() == state.POW
-	if inPow || t.parameters.BlockHeight < t.parameters.Config.MultiExchangeVotesStartHeight {
+	if inPow || !t.parameters.Config.IsActive(config.DeploymentMultiExchangeVotes, t.parameters.BlockHeight) {
 		return t.CheckOutputSingleInput()
 	} else {
 		return t.CheckOutputMultiInputs()
@@ -215,7 +216,7 @@ func (t *ExchangeVotesTransaction) IsAllowedInPOWConsensus() bool {
 
 func (t *ExchangeVotesTransaction) SpecialContextCheck() (result elaerr.ELAError, end bool) {
 
-	if t.parameters.BlockHeight < t.parameters.Config.VotesSchnorrStartHeight {
+	if !t.parameters.Config.IsActive(config.DeploymentVotesSchnorr, t.parameters.BlockHeight) {
 		for _, program := range t.programs {
 			if contract.IsSchnorr(program.Code) {
 				return elaerr.Simple(elaerr.ErrTxPayload,
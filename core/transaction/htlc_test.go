@@ -0,0 +1,139 @@
+package transaction
+
+import (
+	"math"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	"github.com/elastos/Elastos.ELA/core/contract/program"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+func (s *txValidatorTestSuite) TestCheckHTLCInputs() {
+	_, recipient, _ := crypto.GenerateKeyPair()
+	_, refund, _ := crypto.GenerateKeyPair()
+	newHTLC := func(refundLock uint32) *contract.Contract {
+		c, err := contract.CreateHTLCContract(common.Uint256{1}, recipient,
+			refund, refundLock)
+		s.NoError(err)
+		return c
+	}
+	heightHTLC := newHTLC(100)
+	timeHTLC := newHTLC(1600000000)
+
+	// The median time past of the best chain is the timestamp of the only
+	// block in it.
+	bestChain := s.Chain.BestChain
+	defer func() { s.Chain.BestChain = bestChain }()
+	s.Chain.BestChain = &blockchain.BlockNode{Timestamp: 1600000000}
+
+	redeem := make([]byte, contract.HTLCRedeemParameterLength)
+	refundSig := make([]byte, contract.HTLCRefundParameterLength)
+	tests := []struct {
+		name      string
+		htlc      *contract.Contract
+		code      []byte
+		parameter []byte
+		sequence  uint32
+		lockTime  uint32
+		err       string
+	}{
+		{"redeem", heightHTLC, heightHTLC.Code, redeem,
+			math.MaxUint32, 0, ""},
+		{"redeem ignores refund lock", timeHTLC, timeHTLC.Code, redeem,
+			math.MaxUint32 - 1, 0, ""},
+		{"refund at height", heightHTLC, heightHTLC.Code, refundSig,
+			math.MaxUint32 - 1, 100, ""},
+		{"refund before height", heightHTLC, heightHTLC.Code, refundSig,
+			math.MaxUint32 - 1, 99, "htlc refund locked by height"},
+		{"refund with wrong sequence", heightHTLC, heightHTLC.Code,
+			refundSig, math.MaxUint32, 100, "invalid htlc input sequence"},
+		{"refund with relative lock sequence", heightHTLC, heightHTLC.Code,
+			refundSig, common2.LockTimeToSequence(false, 100), 100,
+			"invalid htlc input sequence"},
+		{"refund at time", timeHTLC, timeHTLC.Code, refundSig,
+			math.MaxUint32 - 1, 0, ""},
+		{"refund before time", newHTLC(1600000001),
+			newHTLC(1600000001).Code, refundSig, math.MaxUint32 - 1,
+			math.MaxUint32, "htlc refund locked by time"},
+		{"program not found", heightHTLC, timeHTLC.Code, refundSig,
+			math.MaxUint32 - 1, 100, "htlc program not found"},
+	}
+	for _, test := range tests {
+		input := &common2.Input{
+			Previous: common2.OutPoint{TxID: common.Uint256{2}},
+			Sequence: test.sequence,
+		}
+		txn := functions.CreateTransaction(
+			0,
+			common2.TransferAsset,
+			0,
+			&payload.TransferAsset{},
+			[]*common2.Attribute{},
+			[]*common2.Input{input},
+			[]*common2.Output{},
+			test.lockTime,
+			[]*program.Program{{
+				Code:      test.code,
+				Parameter: test.parameter,
+			}},
+		)
+		checker := &DefaultChecker{parameters: &TransactionParameters{
+			Transaction: txn,
+			BlockHeight: s.Chain.GetHeight() + 1,
+			Config:      s.Chain.GetParams(),
+			BlockChain:  s.Chain,
+		}}
+		references := map[*common2.Input]common2.Output{
+			input: {
+				AssetID:     core.ELAAssetID,
+				Value:       common.Fixed64(s.ELA),
+				ProgramHash: *test.htlc.ToProgramHash(),
+			},
+		}
+
+		err := checker.checkHTLCInputs(txn, references)
+		if test.err == "" {
+			s.NoError(err, test.name)
+		} else {
+			s.EqualError(err, test.err, test.name)
+		}
+	}
+
+	// Inputs of other contracts are not checked.
+	input := &common2.Input{Sequence: math.MaxUint32}
+	txn := functions.CreateTransaction(0, common2.TransferAsset, 0,
+		&payload.TransferAsset{}, []*common2.Attribute{},
+		[]*common2.Input{input}, []*common2.Output{}, 0,
+		[]*program.Program{})
+	checker := &DefaultChecker{parameters: &TransactionParameters{
+		Transaction: txn,
+		BlockChain:  s.Chain,
+	}}
+	s.NoError(checker.checkHTLCInputs(txn, map[*common2.Input]common2.Output{
+		input: {ProgramHash: s.foundationAddress},
+	}))
+}
+
+func (s *txValidatorTestSuite) TestCheckOutputProgramHash_HTLC() {
+	_, key, _ := crypto.GenerateKeyPair()
+	htlc, err := contract.CreateHTLCContract(common.Uint256{1}, key, key, 100)
+	s.NoError(err)
+	programHash := *htlc.ToProgramHash()
+
+	originHeight := config.DefaultParams.HTLCStartHeight
+	defer func() { config.DefaultParams.HTLCStartHeight = originHeight }()
+	startHeight := config.DefaultParams.CheckAddressHeight + 100
+	config.DefaultParams.HTLCStartHeight = startHeight
+
+	// HTLC outputs are refused before DeploymentHTLC is active.
+	s.EqualError(checkOutputProgramHash(startHeight-1, programHash),
+		"htlc program hash is not supported before HTLCStartHeight")
+	s.NoError(checkOutputProgramHash(startHeight, programHash))
+}
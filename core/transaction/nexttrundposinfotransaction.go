@@ -13,6 +13,7 @@ import (
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	"github.com/elastos/Elastos.ELA/dpos/state"
 	elaerr "github.com/elastos/Elastos.ELA/errors"
@@ -32,7 +33,7 @@ func (t *NextTurnDPOSInfoTransaction) CheckTransactionInput() error {
 func (t *NextTurnDPOSInfoTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
-	if t.PayloadVersion() >= payload.NextTurnDPOSInfoVersion2 && blockHeight < chainParams.DPoSConfiguration.DexStartHeight {
+	if t.PayloadVersion() >= payload.NextTurnDPOSInfoVersion2 && !chainParams.IsActive(config.DeploymentDex, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before DexStartHeight", t.TxType().Name()))
 	}
@@ -10,6 +10,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"fmt"
+	"github.com/elastos/Elastos.ELA/common/config"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
 
 	"github.com/elastos/Elastos.ELA/blockchain"
@@ -81,7 +82,7 @@ func (t *NFTDestroyTransactionFromSideChain) CheckTransactionPayload() error {
 func (t *NFTDestroyTransactionFromSideChain) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
-	if blockHeight < chainParams.DPoSConfiguration.NFTStartHeight {
+	if !chainParams.IsActive(config.DeploymentNFT, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before NFTStartHeight", t.TxType().Name()))
 	}
@@ -131,7 +132,7 @@ func (t *NFTDestroyTransactionFromSideChain) checkNFTDestroyTransactionFromSideC
 		}
 		var arbiters []*state.ArbiterInfo
 		var minCount uint32
-		if height >= t.parameters.Config.DPoSConfiguration.DPOSNodeCrossChainHeight {
+		if t.parameters.Config.IsActive(config.DeploymentDPOSNodeCrossChain, height) {
 			arbiters = blockchain.DefaultLedger.Arbitrators.GetArbitrators()
 			minCount = uint32(t.parameters.Config.DPoSConfiguration.NormalArbitratorsCount) + 1
 		} else {
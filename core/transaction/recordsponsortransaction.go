@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	elaerr "github.com/elastos/Elastos.ELA/errors"
 )
@@ -26,7 +27,7 @@ func (t *RecordSponsorTransaction) HeightVersionCheck() error {
 		return errors.New("invalid payload version, need to be zero")
 	}
 
-	if blockHeight < chainParams.DPoSConfiguration.RecordSponsorStartHeight {
+	if !chainParams.IsActive(config.DeploymentRecordSponsor, blockHeight) {
 		return fmt.Errorf("not support %s transaction before RecordSponsorStartHeight", t.TxType().Name())
 	}
 
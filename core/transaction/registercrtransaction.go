@@ -11,6 +11,7 @@ import (
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
@@ -41,17 +42,17 @@ func (t *RegisterCRTransaction) HeightVersionCheck() error {
 
 	switch t.payloadVersion {
 	case payload.CRInfoVersion:
-		if blockHeight < chainParams.CRConfiguration.CRVotingStartHeight {
+		if !chainParams.IsActive(config.DeploymentCRVoting, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s transaction "+
 				"before CRVotingStartHeight", t.TxType().Name()))
 		}
 	case payload.CRInfoDIDVersion:
-		if blockHeight < chainParams.CRConfiguration.RegisterCRByDIDHeight {
+		if !chainParams.IsActive(config.DeploymentRegisterCRByDID, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s transaction "+
 				"before RegisterCRByDIDHeight", t.TxType().Name()))
 		}
 	case payload.CRInfoSchnorrVersion:
-		if blockHeight < chainParams.CRSchnorrStartHeight {
+		if !chainParams.IsActive(config.DeploymentCRSchnorr, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s transaction "+
 				"before CRSchnorrStartHeight", t.TxType().Name()))
 		}
@@ -60,7 +61,7 @@ func (t *RegisterCRTransaction) HeightVersionCheck() error {
 			"%s transaction", t.TxType().Name()))
 	}
 
-	if blockHeight < chainParams.DPoSConfiguration.NFTStartHeight {
+	if !chainParams.IsActive(config.DeploymentNFT, blockHeight) {
 		if t.PayloadVersion() == payload.CRInfoSchnorrVersion ||
 			t.PayloadVersion() == payload.CRInfoMultiSignVersion {
 			return errors.New(fmt.Sprintf("not support %s transaction "+
@@ -96,6 +97,8 @@ func (t *RegisterCRTransaction) SpecialContextCheck() (elaerr.ELAError, bool) {
 	}
 
 	cr := t.parameters.BlockChain.GetCRCommittee().GetCandidate(info.CID)
+	cid, _ := info.CID.ToAddress()
+	t.lookupExists("CRCandidate", cid, cr != nil)
 	if cr != nil {
 		return elaerr.Simple(elaerr.ErrTxPayload, fmt.Errorf("cid %s already exist", info.CID)), true
 	}
@@ -141,7 +144,7 @@ func (t *RegisterCRTransaction) SpecialContextCheck() (elaerr.ELAError, bool) {
 			common.BytesToHexString(pk))), true
 	}
 
-	if t.parameters.BlockHeight >= t.parameters.Config.CRConfiguration.RegisterCRByDIDHeight &&
+	if t.parameters.Config.IsActive(config.DeploymentRegisterCRByDID, t.parameters.BlockHeight) &&
 		t.PayloadVersion() == payload.CRInfoDIDVersion {
 		// get DID program hash
 
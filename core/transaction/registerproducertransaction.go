@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"github.com/elastos/Elastos.ELA/common/config"
 	state2 "github.com/elastos/Elastos.ELA/dpos/state"
 
 	"github.com/elastos/Elastos.ELA/blockchain"
@@ -35,17 +36,17 @@ func (t *RegisterProducerTransaction) HeightVersionCheck() error {
 	switch t.payloadVersion {
 	case payload.ProducerInfoVersion:
 	case payload.ProducerInfoDposV2Version:
-		if blockHeight < chainParams.DPoSV2StartHeight {
+		if !chainParams.IsActive(config.DeploymentDPoSV2, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s transaction "+
 				"before DPoSV2StartHeight", t.TxType().Name()))
 		}
 	case payload.ProducerInfoSchnorrVersion:
-		if blockHeight < chainParams.ProducerSchnorrStartHeight {
+		if !chainParams.IsActive(config.DeploymentProducerSchnorr, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s transaction "+
 				"before ProducerSchnorrStartHeight", t.TxType().Name()))
 		}
 	case payload.ProducerInfoMultiVersion:
-		if blockHeight < chainParams.SupportMultiCodeHeight {
+		if !chainParams.IsActive(config.DeploymentSupportMultiCode, blockHeight) {
 			return errors.New(fmt.Sprintf("not support %s transaction "+
 				"with payload version %d before SupportMultiCodeHeight",
 				t.TxType().Name(), t.PayloadVersion()))
@@ -94,7 +95,9 @@ func (t *RegisterProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bo
 	}
 
 	// check duplication of node.
-	if t.parameters.BlockChain.GetState().ProducerOrCRNodePublicKeyExists(info.NodePublicKey) {
+	nodeExists := t.parameters.BlockChain.GetState().ProducerOrCRNodePublicKeyExists(info.NodePublicKey)
+	t.lookupExists("ProducerOrCRNodePublicKey", common.BytesToHexString(info.NodePublicKey), nodeExists)
+	if nodeExists {
 		return elaerr.Simple(elaerr.ErrTxPayload, fmt.Errorf("Same NodePublicKey producer/cr already registered")), true
 	}
 
@@ -102,7 +105,7 @@ func (t *RegisterProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bo
 	nodeCode = append([]byte{byte(crypto.COMPRESSEDLEN)}, info.NodePublicKey...)
 	nodeCode = append(nodeCode, vm.CHECKSIG)
 
-	if t.parameters.BlockHeight >= t.parameters.Config.DPoSV2StartHeight {
+	if t.parameters.Config.IsActive(config.DeploymentDPoSV2, t.parameters.BlockHeight) {
 		// OwnerKey is  already other's NodePublicKey
 		//OwnerKey will not be other's nodepublic key if OwnerKey is multicode
 		if !multiSignOwner {
@@ -118,12 +121,16 @@ func (t *RegisterProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bo
 		}
 	}
 	// check duplication of owner.
-	if t.parameters.BlockChain.GetState().ProducerOwnerPublicKeyExists(info.OwnerKey) {
+	ownerExists := t.parameters.BlockChain.GetState().ProducerOwnerPublicKeyExists(info.OwnerKey)
+	t.lookupExists("ProducerOwnerPublicKey", common.BytesToHexString(info.OwnerKey), ownerExists)
+	if ownerExists {
 		return elaerr.Simple(elaerr.ErrTxPayload, fmt.Errorf("producer owner already registered")), true
 	}
 
 	// check duplication of nickname.
-	if t.parameters.BlockChain.GetState().NicknameExists(info.NickName) {
+	nicknameExists := t.parameters.BlockChain.GetState().NicknameExists(info.NickName)
+	t.lookupExists("ProducerNickname", info.NickName, nicknameExists)
+	if nicknameExists {
 		return elaerr.Simple(elaerr.ErrTxPayload, fmt.Errorf("nick name %s already inuse", info.NickName)), true
 	}
 
@@ -199,11 +206,11 @@ func (t *RegisterProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bo
 
 	height := t.parameters.BlockChain.GetHeight()
 	state := t.parameters.BlockChain.GetState()
-	if height < t.parameters.Config.DPoSV2StartHeight && t.payloadVersion == payload.ProducerInfoDposV2Version {
+	if !t.parameters.Config.IsActive(config.DeploymentDPoSV2, height) && t.payloadVersion == payload.ProducerInfoDposV2Version {
 		return elaerr.Simple(elaerr.ErrTxPayload, fmt.Errorf("can not register dposv2 before dposv2 start height")), true
 	} else if height > state.DPoSV2ActiveHeight && t.payloadVersion == payload.ProducerInfoVersion {
 		return elaerr.Simple(elaerr.ErrTxPayload, fmt.Errorf("can not register dposv1 after dposv2 active height")), true
-	} else if height < t.parameters.Config.SupportMultiCodeHeight && t.payloadVersion == payload.ProducerInfoMultiVersion {
+	} else if !t.parameters.Config.IsActive(config.DeploymentSupportMultiCode, height) && t.payloadVersion == payload.ProducerInfoMultiVersion {
 		return elaerr.Simple(elaerr.ErrTxPayload, fmt.Errorf("not support ProducerInfoMultiVersion when height is not reach  SupportMultiCodeHeight")), true
 	}
 
@@ -268,7 +275,7 @@ func checkStringField(rawStr string, field string, allowEmpty bool) error {
 }
 
 func (t *RegisterProducerTransaction) additionalProducerInfoCheck(info *payload.ProducerInfo) error {
-	if t.parameters.BlockChain.GetHeight() >= t.parameters.Config.PublicDPOSHeight {
+	if t.parameters.Config.IsActive(config.DeploymentPublicDPOS, t.parameters.BlockChain.GetHeight()) {
 		_, err := crypto.DecodePoint(info.NodePublicKey)
 		if err != nil {
 			return errors.New("invalid node public key in payload")
@@ -8,6 +8,7 @@ package transaction
 import (
 	"errors"
 	"fmt"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract/program"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -70,7 +71,7 @@ func (t *ReturnCRDepositCoinTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.CRConfiguration.CRVotingStartHeight {
+	if !chainParams.IsActive(config.DeploymentCRVoting, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before CRVotingStartHeight", t.TxType().Name()))
 	}
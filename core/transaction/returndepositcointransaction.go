@@ -8,6 +8,7 @@ package transaction
 import (
 	"errors"
 	"fmt"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/contract/program"
 	state2 "github.com/elastos/Elastos.ELA/dpos/state"
@@ -24,7 +25,7 @@ type ReturnDepositCoinTransaction struct {
 
 func (t *ReturnDepositCoinTransaction) CheckAttributeProgram() error {
 
-	if t.parameters.BlockHeight >= t.parameters.Config.CRConfiguration.CRVotingStartHeight {
+	if t.parameters.Config.IsActive(config.DeploymentCRVoting, t.parameters.BlockHeight) {
 		if len(t.Programs()) != 1 {
 			return errors.New("return deposit coin transactions should have one and only one program")
 		}
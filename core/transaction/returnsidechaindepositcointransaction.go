@@ -12,6 +12,7 @@ import (
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
 	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
@@ -90,7 +91,7 @@ func (t *ReturnSideChainDepositCoinTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.ReturnCrossChainCoinStartHeight {
+	if !chainParams.IsActive(config.DeploymentReturnCrossChainCoin, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before ReturnCrossChainCoinStartHeight", t.TxType().Name()))
 	}
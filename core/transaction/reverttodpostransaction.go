@@ -11,6 +11,7 @@ import (
 	"math"
 
 	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract/program"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
@@ -91,7 +92,7 @@ func (t *RevertToDPOSTransaction) IsAllowedInPOWConsensus() bool {
 }
 
 func (t *RevertToDPOSTransaction) HeightVersionCheck() error {
-	if t.parameters.BlockHeight < t.parameters.Config.DPoSConfiguration.RevertToPOWStartHeight {
+	if !t.parameters.Config.IsActive(config.DeploymentRevertToPOW, t.parameters.BlockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before RevertToPOWStartHeight", t.TxType().Name()))
 	}
@@ -11,6 +11,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	elaerr "github.com/elastos/Elastos.ELA/errors"
 )
@@ -59,7 +60,7 @@ func (t *RevertToPOWTransaction) IsAllowedInPOWConsensus() bool {
 }
 
 func (t *RevertToPOWTransaction) HeightVersionCheck() error {
-	if t.parameters.BlockHeight < t.parameters.Config.DPoSConfiguration.RevertToPOWStartHeight {
+	if !t.parameters.Config.IsActive(config.DeploymentRevertToPOW, t.parameters.BlockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before RevertToPOWStartHeight", t.TxType().Name()))
 	}
@@ -82,7 +83,7 @@ func (t *RevertToPOWTransaction) SpecialContextCheck() (result elaerr.ELAError,
 		lastBlockTime := int64(t.parameters.BlockChain.BestChain.Timestamp)
 
 		var noBlockTime int64
-		if t.parameters.BlockHeight < t.parameters.Config.DPoSConfiguration.ChangeViewV1Height {
+		if !t.parameters.Config.IsActive(config.DeploymentChangeViewV1, t.parameters.BlockHeight) {
 			noBlockTime = t.parameters.Config.DPoSConfiguration.RevertToPOWNoBlockTime
 		} else {
 			noBlockTime = t.parameters.Config.DPoSConfiguration.RevertToPOWNoBlockTimeV1
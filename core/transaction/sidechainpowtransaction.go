@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract/program"
 	"math"
 
@@ -99,7 +100,7 @@ func (t *SideChainPOWTransaction) CheckTransactionOutput() error {
 			}
 		}
 
-		if t.parameters.BlockChain.GetHeight() >= chainParams.PublicDPOSHeight && specialOutputCount > 1 {
+		if chainParams.IsActive(config.DeploymentPublicDPOS, t.parameters.BlockChain.GetHeight()) && specialOutputCount > 1 {
 			return errors.New("special output count should less equal than 1")
 		}
 	}
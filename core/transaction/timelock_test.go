@@ -0,0 +1,224 @@
+package transaction
+
+import (
+	"errors"
+	"math"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core"
+	"github.com/elastos/Elastos.ELA/core/contract/program"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+)
+
+// timeLockTestStore returns the heights of the transactions referenced by
+// the relative lock times.
+type timeLockTestStore struct {
+	blockchain.IChainStore
+	heights map[common.Uint256]uint32
+}
+
+func (s *timeLockTestStore) GetTransaction(txID common.Uint256) (
+	interfaces.Transaction, uint32, error) {
+	height, ok := s.heights[txID]
+	if !ok {
+		return nil, 0, errors.New("transaction not found")
+	}
+	return nil, height, nil
+}
+
+const (
+	timeLockTestReferHeight = 100
+	timeLockTestReferTime   = 1600000000
+)
+
+// newTimeLockTestChain returns a chain on which the transaction {1} is
+// packed at height 100, and the median time past of its previous block is
+// 1600000000.  Time locks are active from height 105.
+func (s *txValidatorTestSuite) newTimeLockTestChain() *blockchain.BlockChain {
+	params := *s.Chain.GetParams()
+	params.GenesisBlock = core.GenesisBlock(*params.FoundationProgramHash)
+	params.TimeLockStartHeight = 105
+	store := &timeLockTestStore{
+		IChainStore: s.Chain.GetDB(),
+		heights: map[common.Uint256]uint32{
+			{1}: timeLockTestReferHeight,
+		},
+	}
+	chain, err := blockchain.New(store, &params, s.Chain.GetState(),
+		s.Chain.GetCRCommittee(), s.Chain.CkpManager)
+	s.NoError(err)
+
+	// Nodes without parents make the median time past of each block its
+	// own timestamp.
+	chain.Nodes = make([]*blockchain.BlockNode, 0, 120)
+	for i := uint32(0); i < 120; i++ {
+		chain.Nodes = append(chain.Nodes, &blockchain.BlockNode{
+			Height: i,
+			Timestamp: uint32(timeLockTestReferTime +
+				(int64(i)-timeLockTestReferHeight+1)*120),
+		})
+	}
+	return chain
+}
+
+func newTimeLockTestTx(lockTime uint32,
+	inputs ...*common2.Input) interfaces.Transaction {
+	return functions.CreateTransaction(
+		0,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{},
+		inputs,
+		[]*common2.Output{},
+		lockTime,
+		[]*program.Program{},
+	)
+}
+
+func (s *txValidatorTestSuite) TestCalcSequenceLock() {
+	chain := s.newTimeLockTestChain()
+	refer := common2.OutPoint{TxID: common.Uint256{1}}
+
+	// Inputs without relative lock times are ignored.
+	lock, err := chain.CalcSequenceLock(newTimeLockTestTx(0,
+		&common2.Input{Previous: refer, Sequence: math.MaxUint32},
+		&common2.Input{Previous: refer, Sequence: math.MaxUint32 - 1}))
+	s.NoError(err)
+	s.Equal(blockchain.SequenceLock{Seconds: -1, BlockHeight: -1}, *lock)
+
+	// The maximum lock of each type is taken.
+	lock, err = chain.CalcSequenceLock(newTimeLockTestTx(0,
+		&common2.Input{Previous: refer,
+			Sequence: common2.LockTimeToSequence(false, 10)},
+		&common2.Input{Previous: refer,
+			Sequence: common2.LockTimeToSequence(false, 20)},
+		&common2.Input{Previous: refer,
+			Sequence: common2.LockTimeToSequence(true, 1024)},
+		&common2.Input{Previous: refer,
+			Sequence: common2.LockTimeToSequence(true, 512)},
+		&common2.Input{Previous: refer, Sequence: math.MaxUint32}))
+	s.NoError(err)
+	s.Equal(blockchain.SequenceLock{
+		Seconds:     timeLockTestReferTime + 1024 - 1,
+		BlockHeight: timeLockTestReferHeight + 20 - 1,
+	}, *lock)
+
+	// The referenced transaction must be found.
+	_, err = chain.CalcSequenceLock(newTimeLockTestTx(0,
+		&common2.Input{Previous: common2.OutPoint{TxID: common.Uint256{2}},
+			Sequence: common2.LockTimeToSequence(false, 10)}))
+	s.EqualError(err, "transaction not found")
+}
+
+func (s *txValidatorTestSuite) TestCheckSequenceLocks() {
+	chain := s.newTimeLockTestChain()
+	refer := common2.OutPoint{TxID: common.Uint256{1}}
+	blocks := &common2.Input{Previous: refer,
+		Sequence: common2.LockTimeToSequence(false, 10)}
+	seconds := &common2.Input{Previous: refer,
+		Sequence: common2.LockTimeToSequence(true, 1024)}
+
+	tests := []struct {
+		name        string
+		input       *common2.Input
+		blockHeight uint32
+		medianTime  int64
+		err         string
+	}{
+		{"blocks before maturity", blocks, timeLockTestReferHeight + 9,
+			0, "transaction sequence locks not met"},
+		{"blocks at maturity", blocks, timeLockTestReferHeight + 10,
+			0, ""},
+		{"seconds before maturity", seconds, timeLockTestReferHeight + 10,
+			timeLockTestReferTime + 1023,
+			"transaction sequence locks not met"},
+		{"seconds at maturity", seconds, timeLockTestReferHeight + 10,
+			timeLockTestReferTime + 1024, ""},
+		{"before DeploymentTimeLock", blocks, 104, 0, ""},
+		{"disabled sequence", &common2.Input{Previous: refer,
+			Sequence: math.MaxUint32 - 1}, 105, 0, ""},
+	}
+	for _, test := range tests {
+		// The median time past of the best chain is the timestamp of the
+		// only block in it.
+		chain.BestChain = &blockchain.BlockNode{
+			Height:    test.blockHeight - 1,
+			Timestamp: uint32(test.medianTime),
+		}
+		txn := newTimeLockTestTx(0, test.input)
+		checker := &DefaultChecker{parameters: &TransactionParameters{
+			Transaction: txn,
+			BlockHeight: test.blockHeight,
+			Config:      chain.GetParams(),
+			BlockChain:  chain,
+		}}
+
+		err := checker.checkSequenceLocks(txn)
+		if test.err == "" {
+			s.NoError(err, test.name)
+		} else {
+			s.EqualError(err, test.err, test.name)
+		}
+	}
+}
+
+func (s *txValidatorTestSuite) TestCheckTransactionUTXOLock_Relative() {
+	chain := s.newTimeLockTestChain()
+	output := common2.Output{
+		AssetID: core.ELAAssetID,
+		Value:   common.Fixed64(s.ELA),
+		OutputLock: common2.OutputLockRelative |
+			common2.LockTimeToSequence(false, 10),
+		ProgramHash: s.foundationAddress,
+	}
+
+	tests := []struct {
+		name        string
+		sequence    uint32
+		lockTime    uint32
+		blockHeight uint32
+		err         string
+	}{
+		{"equal lock", common2.LockTimeToSequence(false, 10), 0, 105, ""},
+		{"greater lock", common2.LockTimeToSequence(false, 11), 0, 105, ""},
+		{"smaller lock", common2.LockTimeToSequence(false, 9), 0, 105,
+			"UTXO relative locked"},
+		{"lock type mismatch", common2.LockTimeToSequence(true, 5120), 0,
+			105, "UTXO relative lock type mismatch"},
+		{"disabled sequence", math.MaxUint32 - 1, 0, 105,
+			"Invalid input sequence"},
+		{"relative sequence before DeploymentTimeLock",
+			common2.LockTimeToSequence(false, 10), 0, 104,
+			"Invalid input sequence"},
+		{"absolute lock before DeploymentTimeLock", math.MaxUint32 - 1,
+			0, 104, "UTXO output locked"},
+		{"absolute lock time before DeploymentTimeLock", math.MaxUint32 - 1,
+			output.OutputLock, 104, ""},
+	}
+	for _, test := range tests {
+		input := &common2.Input{
+			Previous: common2.OutPoint{TxID: common.Uint256{1}},
+			Sequence: test.sequence,
+		}
+		txn := newTimeLockTestTx(test.lockTime, input)
+		checker := &DefaultChecker{parameters: &TransactionParameters{
+			Transaction: txn,
+			BlockHeight: test.blockHeight,
+			Config:      chain.GetParams(),
+			BlockChain:  chain,
+		}}
+
+		err := checker.CheckTransactionUTXOLock(txn,
+			map[*common2.Input]common2.Output{input: output})
+		if test.err == "" {
+			s.NoError(err, test.name)
+		} else {
+			s.EqualError(err, test.err, test.name)
+		}
+	}
+}
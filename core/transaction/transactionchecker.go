@@ -31,6 +31,15 @@ type DefaultChecker struct {
 	// config
 	parameters *TransactionParameters
 	references map[*common2.Input]common2.Output
+
+	// trace records the result of every check if the transaction is traced.
+	trace *interfaces.CheckTrace
+}
+
+// namedCheck is a single check of a transaction, named in the trace.
+type namedCheck struct {
+	name  string
+	check func() elaerr.ELAError
 }
 
 func (t *DefaultChecker) SanityCheck(params interfaces.Parameters) elaerr.ELAError {
@@ -38,47 +47,69 @@ func (t *DefaultChecker) SanityCheck(params interfaces.Parameters) elaerr.ELAErr
 		return elaerr.Simple(elaerr.ErrFail, errors.New("invalid parameters"))
 	}
 
-	if err := t.parameters.Transaction.HeightVersionCheck(); err != nil {
-		log.Warn("[HeightVersionCheck],", err)
-		return elaerr.Simple(elaerr.ErrTxHeightVersion, err)
-	}
-
-	if err := t.parameters.Transaction.CheckTransactionSize(); err != nil {
-		log.Warn("[CheckTransactionSize],", err)
-		return elaerr.Simple(elaerr.ErrTxSize, err)
-	}
-
-	if err := t.parameters.Transaction.CheckTransactionInput(); err != nil {
-		log.Warn("[CheckTransactionInput],", err)
-		return elaerr.Simple(elaerr.ErrTxInvalidInput, err)
-	}
-
-	if err := t.parameters.Transaction.CheckTransactionOutput(); err != nil {
-		log.Warn("[CheckTransactionOutput],", err)
-		return elaerr.Simple(elaerr.ErrTxInvalidOutput, err)
-	}
-
-	if err := checkAssetPrecision(t.parameters.Transaction); err != nil {
-		log.Warn("[CheckAssetPrecesion],", err)
-		return elaerr.Simple(elaerr.ErrTxAssetPrecision, err)
-	}
-
-	if err := t.parameters.Transaction.CheckAttributeProgram(); err != nil {
-		log.Warn("[CheckAttributeProgram],", err)
-		return elaerr.Simple(elaerr.ErrTxAttributeProgram, err)
-	}
-
-	if err := t.parameters.Transaction.CheckTransactionPayload(); err != nil {
-		log.Warn("[CheckTransactionPayload],", err)
-		return elaerr.Simple(elaerr.ErrTxPayload, err)
-	}
+	return t.runChecks(t.sanityChecks())
+}
 
-	if err := blockchain.CheckDuplicateSidechainTx(t.parameters.Transaction); err != nil {
-		log.Warn("[CheckDuplicateSidechainTx],", err)
-		return elaerr.Simple(elaerr.ErrTxSidechainDuplicate, err)
+func (t *DefaultChecker) sanityChecks() []namedCheck {
+	txn := t.parameters.Transaction
+	return []namedCheck{
+		{"HeightVersionCheck", func() elaerr.ELAError {
+			if err := txn.HeightVersionCheck(); err != nil {
+				log.Warn("[HeightVersionCheck],", err)
+				return elaerr.Simple(elaerr.ErrTxHeightVersion, err)
+			}
+			return nil
+		}},
+		{"CheckTransactionSize", func() elaerr.ELAError {
+			if err := txn.CheckTransactionSize(); err != nil {
+				log.Warn("[CheckTransactionSize],", err)
+				return elaerr.Simple(elaerr.ErrTxSize, err)
+			}
+			return nil
+		}},
+		{"CheckTransactionInput", func() elaerr.ELAError {
+			if err := txn.CheckTransactionInput(); err != nil {
+				log.Warn("[CheckTransactionInput],", err)
+				return elaerr.Simple(elaerr.ErrTxInvalidInput, err)
+			}
+			return nil
+		}},
+		{"CheckTransactionOutput", func() elaerr.ELAError {
+			if err := txn.CheckTransactionOutput(); err != nil {
+				log.Warn("[CheckTransactionOutput],", err)
+				return elaerr.Simple(elaerr.ErrTxInvalidOutput, err)
+			}
+			return nil
+		}},
+		{"CheckAssetPrecision", func() elaerr.ELAError {
+			if err := checkAssetPrecision(txn); err != nil {
+				log.Warn("[CheckAssetPrecesion],", err)
+				return elaerr.Simple(elaerr.ErrTxAssetPrecision, err)
+			}
+			return nil
+		}},
+		{"CheckAttributeProgram", func() elaerr.ELAError {
+			if err := txn.CheckAttributeProgram(); err != nil {
+				log.Warn("[CheckAttributeProgram],", err)
+				return elaerr.Simple(elaerr.ErrTxAttributeProgram, err)
+			}
+			return nil
+		}},
+		{"CheckTransactionPayload", func() elaerr.ELAError {
+			if err := txn.CheckTransactionPayload(); err != nil {
+				log.Warn("[CheckTransactionPayload],", err)
+				return elaerr.Simple(elaerr.ErrTxPayload, err)
+			}
+			return nil
+		}},
+		{"CheckDuplicateSidechainTx", func() elaerr.ELAError {
+			if err := blockchain.CheckDuplicateSidechainTx(txn); err != nil {
+				log.Warn("[CheckDuplicateSidechainTx],", err)
+				return elaerr.Simple(elaerr.ErrTxSidechainDuplicate, err)
+			}
+			return nil
+		}},
 	}
-
-	return nil
 }
 
 func (t *DefaultChecker) ContextCheck(params interfaces.Parameters) (
@@ -89,85 +120,256 @@ func (t *DefaultChecker) ContextCheck(params interfaces.Parameters) (
 		return nil, elaerr.Simple(elaerr.ErrTxDuplicate, errors.New("invalid parameters"))
 	}
 
-	if err := t.parameters.Transaction.HeightVersionCheck(); err != nil {
-		log.Warn("[CheckTransactionContext] height version check failed.")
-		return nil, elaerr.Simple(elaerr.ErrTxHeightVersion, nil)
-	}
-
-	if exist := t.IsTxHashDuplicate(t.parameters.Transaction.Hash()); exist {
-		log.Warn("[CheckTransactionContext] duplicate transaction check failed.")
-		return nil, elaerr.Simple(elaerr.ErrTxDuplicate, nil)
-	}
+	return t.contextCheck()
+}
 
-	references, err := t.GetTxReference(t.parameters.Transaction)
-	if err != nil {
-		log.Warn("[CheckTransactionContext] get transaction reference failed")
-		return nil, elaerr.Simple(elaerr.ErrTxUnknownReferredTx, nil)
-	}
-	t.references = references
+func (t *DefaultChecker) contextCheck() (
+	map[*common2.Input]common2.Output, elaerr.ELAError) {
+	txn := t.parameters.Transaction
 
-	if t.parameters.BlockChain.GetState().GetConsensusAlgorithm() == state.POW {
-		if !t.parameters.Transaction.IsAllowedInPOWConsensus() {
-			log.Warnf("[CheckTransactionContext], %s transaction is not allowed in POW", t.parameters.Transaction.TxType().Name())
-			return nil, elaerr.Simple(elaerr.ErrTxValidation, nil)
-		}
+	var references map[*common2.Input]common2.Output
+	var referenced bool
+	if err := t.runChecks([]namedCheck{
+		{"HeightVersionCheck", func() elaerr.ELAError {
+			if err := txn.HeightVersionCheck(); err != nil {
+				log.Warn("[CheckTransactionContext] height version check failed.")
+				return elaerr.Simple(elaerr.ErrTxHeightVersion, nil)
+			}
+			return nil
+		}},
+		{"IsTxHashDuplicate", func() elaerr.ELAError {
+			exist := t.IsTxHashDuplicate(txn.Hash())
+			t.lookupExists("Transaction", txn.Hash().ReversedString(), exist)
+			if exist {
+				log.Warn("[CheckTransactionContext] duplicate transaction check failed.")
+				return elaerr.Simple(elaerr.ErrTxDuplicate, nil)
+			}
+			return nil
+		}},
+		{"GetTxReference", func() elaerr.ELAError {
+			refs, err := t.GetTxReference(txn)
+			if err != nil {
+				log.Warn("[CheckTransactionContext] get transaction reference failed")
+				return elaerr.Simple(elaerr.ErrTxUnknownReferredTx, nil)
+			}
+			for input, output := range refs {
+				address, _ := output.ProgramHash.ToAddress()
+				t.lookup("UTXO", fmt.Sprintf("%s:%d",
+					input.Previous.TxID.ReversedString(), input.Previous.Index),
+					fmt.Sprintf("%s %s", address, output.Value))
+			}
+			references, referenced = refs, true
+			return nil
+		}},
+	}); err != nil {
+		return nil, err
 	}
-
-	// check double spent transaction
-	if blockchain.DefaultLedger.IsDoubleSpend(t.parameters.Transaction) {
-		log.Warn("[CheckTransactionContext] IsDoubleSpend check failed")
-		return nil, elaerr.Simple(elaerr.ErrTxDoubleSpend, nil)
+	// The following checks depend on the references of the transaction.
+	if !referenced {
+		t.skipChecks(t.referenceChecks(references))
+		t.skipChecks(t.balanceChecks(references))
+		return nil, nil
 	}
+	t.references = references
 
-	if err := t.CheckTransactionUTXOLock(t.parameters.Transaction, references); err != nil {
-		log.Warn("[CheckTransactionUTXOLock],", err)
-		return nil, elaerr.Simple(elaerr.ErrTxUTXOLocked, err)
+	var end bool
+	var specialErr elaerr.ELAError
+	if err := t.runChecks(append(t.referenceChecks(references), namedCheck{
+		"SpecialContextCheck", func() elaerr.ELAError {
+			specialErr, end = txn.SpecialContextCheck()
+			if specialErr != nil {
+				log.Warn("[SpecialContextCheck],", specialErr.InnerError())
+			}
+			return specialErr
+		}})); err != nil && err != specialErr {
+		return nil, err
 	}
-
-	cerr, end := t.parameters.Transaction.SpecialContextCheck()
-	if cerr != nil {
-		log.Warn("[SpecialContextCheck],", cerr.InnerError())
+	if specialErr != nil && !end {
+		// The transaction is not rejected by the error of a special context
+		// check which does not end the check.
+		t.trace.Ignore("SpecialContextCheck")
 	}
 	if end {
-		return references, cerr
+		t.skipChecks(t.balanceChecks(references))
+		return references, specialErr
 	}
 
-	if err := t.parameters.Transaction.CheckTransactionFee(references); err != nil {
-		log.Warn("[CheckTransactionFee],", err)
-		return nil, elaerr.Simple(elaerr.ErrTxBalance, err)
+	if err := t.runChecks(t.balanceChecks(references)); err != nil {
+		return nil, err
 	}
 
-	if err := checkDestructionAddress(references); err != nil {
-		log.Warn("[CheckDestructionAddress], ", err)
-		return nil, elaerr.Simple(elaerr.ErrTxInvalidInput, err)
-	}
+	return references, nil
+}
 
-	if err := checkTransactionDepositUTXO(t.parameters.Transaction, references); err != nil {
-		log.Warn("[CheckTransactionDepositUTXO],", err)
-		return nil, elaerr.Simple(elaerr.ErrTxInvalidInput, err)
+func (t *DefaultChecker) referenceChecks(
+	references map[*common2.Input]common2.Output) []namedCheck {
+	txn := t.parameters.Transaction
+	return []namedCheck{
+		{"IsAllowedInPOWConsensus", func() elaerr.ELAError {
+			consensus := t.parameters.BlockChain.GetState().GetConsensusAlgorithm()
+			t.lookup("ConsensusAlgorithm", "", consensus.String())
+			if consensus == state.POW {
+				if !txn.IsAllowedInPOWConsensus() {
+					log.Warnf("[CheckTransactionContext], %s transaction is not allowed in POW", txn.TxType().Name())
+					return elaerr.Simple(elaerr.ErrTxValidation, nil)
+				}
+			}
+			return nil
+		}},
+		{"IsDoubleSpend", func() elaerr.ELAError {
+			// check double spent transaction
+			if blockchain.DefaultLedger.IsDoubleSpend(txn) {
+				log.Warn("[CheckTransactionContext] IsDoubleSpend check failed")
+				return elaerr.Simple(elaerr.ErrTxDoubleSpend, nil)
+			}
+			return nil
+		}},
+		{"CheckTransactionUTXOLock", func() elaerr.ELAError {
+			if err := t.CheckTransactionUTXOLock(txn, references); err != nil {
+				log.Warn("[CheckTransactionUTXOLock],", err)
+				return elaerr.Simple(elaerr.ErrTxUTXOLocked, err)
+			}
+			return nil
+		}},
+		{"CheckSequenceLocks", func() elaerr.ELAError {
+			if err := t.checkSequenceLocks(txn); err != nil {
+				log.Warn("[CheckSequenceLocks],", err)
+				return elaerr.Simple(elaerr.ErrTxUTXOLocked, err)
+			}
+			return nil
+		}},
+		{"CheckHTLCInputs", func() elaerr.ELAError {
+			if err := t.checkHTLCInputs(txn, references); err != nil {
+				log.Warn("[CheckHTLCInputs],", err)
+				return elaerr.Simple(elaerr.ErrTxUTXOLocked, err)
+			}
+			return nil
+		}},
 	}
+}
 
-	if err := checkTransactionDepositOutputs(t.parameters.BlockChain, t.parameters.Transaction); err != nil {
-		log.Warn("[checkTransactionDepositOutputs],", err)
-		return nil, elaerr.Simple(elaerr.ErrTxInvalidInput, err)
+func (t *DefaultChecker) balanceChecks(
+	references map[*common2.Input]common2.Output) []namedCheck {
+	txn := t.parameters.Transaction
+	return []namedCheck{
+		{"CheckTransactionFee", func() elaerr.ELAError {
+			if err := txn.CheckTransactionFee(references); err != nil {
+				log.Warn("[CheckTransactionFee],", err)
+				return elaerr.Simple(elaerr.ErrTxBalance, err)
+			}
+			return nil
+		}},
+		{"CheckDestructionAddress", func() elaerr.ELAError {
+			if err := checkDestructionAddress(references); err != nil {
+				log.Warn("[CheckDestructionAddress], ", err)
+				return elaerr.Simple(elaerr.ErrTxInvalidInput, err)
+			}
+			return nil
+		}},
+		{"CheckTransactionDepositUTXO", func() elaerr.ELAError {
+			if err := checkTransactionDepositUTXO(txn, references); err != nil {
+				log.Warn("[CheckTransactionDepositUTXO],", err)
+				return elaerr.Simple(elaerr.ErrTxInvalidInput, err)
+			}
+			return nil
+		}},
+		{"CheckTransactionDepositOutputs", func() elaerr.ELAError {
+			if err := checkTransactionDepositOutputs(t.parameters.BlockChain, txn); err != nil {
+				log.Warn("[checkTransactionDepositOutputs],", err)
+				return elaerr.Simple(elaerr.ErrTxInvalidInput, err)
+			}
+			return nil
+		}},
+		{"CheckTransactionSignature", func() elaerr.ELAError {
+			if err := checkTransactionSignature(txn, references); err != nil {
+				log.Warn("[checkTransactionSignature],", err)
+				return elaerr.Simple(elaerr.ErrTxSignature, err)
+			}
+			return nil
+		}},
+		{"CheckInvalidUTXO", func() elaerr.ELAError {
+			if err := t.checkInvalidUTXO(txn); err != nil {
+				log.Warn("[checkInvalidUTXO]", err)
+				return elaerr.Simple(elaerr.ErrBlockIneffectiveCoinbase, err)
+			}
+			return nil
+		}},
+		{"CheckVoteOutputs", func() elaerr.ELAError {
+			if err := t.tryCheckVoteOutputs(); err != nil {
+				log.Warn("[tryCheckVoteOutputs]", err)
+				return elaerr.Simple(elaerr.ErrTxInvalidOutput, err)
+			}
+			return nil
+		}},
 	}
+}
 
-	if err := checkTransactionSignature(t.parameters.Transaction, references); err != nil {
-		log.Warn("[checkTransactionSignature],", err)
-		return nil, elaerr.Simple(elaerr.ErrTxSignature, err)
+// TraceCheck runs every sanity and context check of the transaction without
+// stopping at the first failure, and returns the result of each check.
+// Checks depending on the references of the transaction are skipped if the
+// references can not be found.
+func (t *DefaultChecker) TraceCheck(
+	params interfaces.Parameters) *interfaces.CheckTrace {
+	trace := new(interfaces.CheckTrace)
+	if err := t.SetParameters(params); err != nil {
+		trace.Add("SetParameters", err)
+		return trace
 	}
 
-	if err := t.checkInvalidUTXO(t.parameters.Transaction); err != nil {
-		log.Warn("[checkInvalidUTXO]", err)
-		return nil, elaerr.Simple(elaerr.ErrBlockIneffectiveCoinbase, err)
+	t.trace = trace
+	defer func() { t.trace = nil }()
+	t.runChecks(t.sanityChecks())
+	t.contextCheck()
+	return trace
+}
+
+// runChecks runs the checks in order and returns the first failure. While
+// tracing, all checks are run and their results are recorded in the trace
+// instead.
+func (t *DefaultChecker) runChecks(checks []namedCheck) elaerr.ELAError {
+	for _, c := range checks {
+		if t.trace == nil {
+			if err := c.check(); err != nil {
+				return err
+			}
+			continue
+		}
+		t.trace.Add(c.name, t.traceCheck(c))
 	}
+	return nil
+}
+
+// traceCheck runs a check while tracing. Checks normally run only after the
+// checks before them have passed, so a panic caused by an earlier failure is
+// reported as the failure of the check.
+func (t *DefaultChecker) traceCheck(c namedCheck) (err elaerr.ELAError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = elaerr.Simple(elaerr.ErrTxValidation,
+				fmt.Errorf("check panicked: %v", r))
+		}
+	}()
+	return c.check()
+}
 
-	if err := t.tryCheckVoteOutputs(); err != nil {
-		log.Warn("[tryCheckVoteOutputs]", err)
-		return nil, elaerr.Simple(elaerr.ErrTxInvalidOutput, err)
+func (t *DefaultChecker) skipChecks(checks []namedCheck) {
+	for _, c := range checks {
+		t.trace.Skip(c.name)
 	}
+}
 
-	return references, nil
+// lookup records a state lookup made by a check if the transaction is
+// traced.
+func (t *DefaultChecker) lookup(name, key, result string) {
+	t.trace.Lookup(name, key, result)
+}
+
+func (t *DefaultChecker) lookupExists(name, key string, exists bool) {
+	if exists {
+		t.trace.Lookup(name, key, "exists")
+	} else {
+		t.trace.Lookup(name, key, "not exists")
+	}
 }
 
 func (t *DefaultChecker) SetParameters(params interface{}) elaerr.ELAError {
@@ -251,7 +453,7 @@ func (t *DefaultChecker) CheckTransactionOutput() error {
 		}
 	}
 
-	if blockHeight >= t.parameters.Config.PublicDPOSHeight && specialOutputCount > 1 {
+	if t.parameters.Config.IsActive(config.DeploymentPublicDPOS, blockHeight) && specialOutputCount > 1 {
 		return errors.New("special output count should less equal than 1")
 	}
 
@@ -282,7 +484,7 @@ func (t *DefaultChecker) CheckAttributeProgram() error {
 			return fmt.Errorf("invalid program parameter nil")
 		}
 
-		if t.parameters.BlockHeight < t.parameters.Config.NormalSchnorrStartHeight && contract.IsSchnorr(p.Code) {
+		if !t.parameters.Config.IsActive(config.DeploymentNormalSchnorr, t.parameters.BlockHeight) && contract.IsSchnorr(p.Code) {
 			return fmt.Errorf("invalid program code with schnorr before SchnorrStartHeight")
 		}
 	}
@@ -319,15 +521,38 @@ func (t *DefaultChecker) IsAllowedInPOWConsensus() bool {
 }
 
 func (t *DefaultChecker) CheckTransactionUTXOLock(txn interfaces.Transaction, references map[*common2.Input]common2.Output) error {
+	timeLock := t.parameters.Config.IsActive(config.DeploymentTimeLock,
+		t.parameters.BlockHeight)
 	for input, output := range references {
 
 		if output.OutputLock == 0 {
 			//check next utxo
 			continue
 		}
+		if timeLock && output.IsRelativeLock() {
+			// The relative lock time of the input is checked with the
+			// sequence locks, it must be no less than the output lock.
+			if !input.HasRelativeLock() {
+				return errors.New("Invalid input sequence")
+			}
+			lock := output.RelativeLockSequence()
+			if input.Sequence&common2.SequenceLockTimeIsSeconds !=
+				lock&common2.SequenceLockTimeIsSeconds {
+				return errors.New("UTXO relative lock type mismatch")
+			}
+			if input.Sequence&common2.SequenceLockTimeMask <
+				lock&common2.SequenceLockTimeMask {
+				return errors.New("UTXO relative locked")
+			}
+			continue
+		}
 		if input.Sequence != math.MaxUint32-1 {
 			return errors.New("Invalid input sequence")
 		}
+		if timeLock && output.IsTimeLock() !=
+			(txn.LockTime() >= common2.LockTimeThreshold) {
+			return errors.New("UTXO output lock type mismatch")
+		}
 		if txn.LockTime() < output.OutputLock {
 			return errors.New("UTXO output locked")
 		}
@@ -335,6 +560,69 @@ func (t *DefaultChecker) CheckTransactionUTXOLock(txn interfaces.Transaction, re
 	return nil
 }
 
+// checkSequenceLocks checks the relative lock times of the inputs have
+// passed once the time locks are active.
+func (t *DefaultChecker) checkSequenceLocks(txn interfaces.Transaction) error {
+	blockHeight := t.parameters.BlockHeight
+	if !t.parameters.Config.IsActive(config.DeploymentTimeLock, blockHeight) {
+		return nil
+	}
+	chain := t.parameters.BlockChain
+	lock, err := chain.CalcSequenceLock(txn)
+	if err != nil {
+		return err
+	}
+	if !blockchain.SequenceLockActive(lock, blockHeight, chain.PastMedianTime()) {
+		return errors.New("transaction sequence locks not met")
+	}
+	return nil
+}
+
+// checkHTLCInputs checks the refund lock of the HTLC inputs refunded by the
+// transaction.  Like a locked UTXO, the input sequence must enable the lock
+// time, a refund lock by height is checked with the lock time of the
+// transaction, and a refund lock by time is checked with the median time past.
+func (t *DefaultChecker) checkHTLCInputs(txn interfaces.Transaction,
+	references map[*common2.Input]common2.Output) error {
+	for input, output := range references {
+		if contract.GetPrefixType(output.ProgramHash) != contract.PrefixHTLC {
+			continue
+		}
+
+		var code, parameter []byte
+		codeHash := output.ProgramHash.ToCodeHash()
+		for _, p := range txn.Programs() {
+			if common.ToCodeHash(p.Code).IsEqual(codeHash) {
+				code, parameter = p.Code, p.Parameter
+				break
+			}
+		}
+		if code == nil {
+			return errors.New("htlc program not found")
+		}
+		if len(parameter) != contract.HTLCRefundParameterLength {
+			continue
+		}
+
+		htlc, err := contract.ParseHTLC(code)
+		if err != nil {
+			return err
+		}
+		if input.Sequence != math.MaxUint32-1 {
+			return errors.New("invalid htlc input sequence")
+		}
+		if htlc.IsTimeLock() {
+			medianTime := t.parameters.BlockChain.PastMedianTime().Unix()
+			if medianTime < int64(htlc.RefundLock) {
+				return errors.New("htlc refund locked by time")
+			}
+		} else if txn.LockTime() < htlc.RefundLock {
+			return errors.New("htlc refund locked by height")
+		}
+	}
+	return nil
+}
+
 func (t *DefaultChecker) SpecialContextCheck() (elaerr.ELAError, bool) {
 	return nil, false
 }
@@ -348,7 +636,7 @@ func (t *DefaultChecker) tryCheckVoteOutputs() error {
 
 	if txn.Version() >= common2.TxVersion09 {
 		producers := dposState.GetActiveV1Producers()
-		if blockHeight < t.parameters.Config.PublicDPOSHeight {
+		if !t.parameters.Config.IsActive(config.DeploymentPublicDPOS, blockHeight) {
 			producers = append(producers, dposState.GetPendingCanceledProducers()...)
 		}
 		var candidates []*crstate.Candidate
@@ -513,7 +801,7 @@ func (t *DefaultChecker) checkVoteCRContent(blockHeight uint32,
 	if payloadVersion < outputpayload.VoteProducerAndCRVersion {
 		return errors.New("payload VoteProducerVersion not support vote CR")
 	}
-	if blockHeight >= t.parameters.Config.CRConfiguration.CheckVoteCRCountHeight {
+	if t.parameters.Config.IsActive(config.DeploymentCheckVoteCRCount, blockHeight) {
 		if len(content.CandidateVotes) > outputpayload.MaxVoteProducersPerTransaction {
 			return errors.New("invalid count of CR candidates ")
 		}
@@ -679,7 +967,7 @@ func (t *DefaultChecker) CheckTransactionFee(references map[*common2.Input]commo
 
 func checkOutputProgramHash(height uint32, programHash common.Uint168) error {
 	// main version >= 88812
-	if height >= config.DefaultParams.CheckAddressHeight {
+	if config.DefaultParams.IsActive(config.DeploymentCheckAddress, height) {
 		var empty = common.Uint168{}
 		if programHash.IsEqual(empty) {
 			return nil
@@ -698,6 +986,10 @@ func checkOutputProgramHash(height uint32, programHash common.Uint168) error {
 		case contract.PrefixCrossChain:
 		case contract.PrefixDeposit:
 		case contract.PrefixDPoSV2:
+		case contract.PrefixHTLC:
+			if !config.DefaultParams.IsActive(config.DeploymentHTLC, height) {
+				return errors.New("htlc program hash is not supported before HTLCStartHeight")
+			}
 		default:
 			return errors.New("invalid program hash prefix")
 		}
@@ -0,0 +1,165 @@
+package transaction
+
+import (
+	"errors"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core"
+	"github.com/elastos/Elastos.ELA/core/contract/program"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	elaerr "github.com/elastos/Elastos.ELA/errors"
+)
+
+// traceChecks returns the names of the failed and the skipped checks in the
+// trace.
+func traceChecks(trace *interfaces.CheckTrace) (failed, skipped []string) {
+	failed, skipped = make([]string, 0), make([]string, 0)
+	for _, c := range trace.Checks {
+		switch {
+		case c.Skipped:
+			skipped = append(skipped, c.Name)
+		case !c.Passed():
+			failed = append(failed, c.Name)
+		}
+	}
+	return failed, skipped
+}
+
+// specialErrorTx fails its special context check without ending the
+// context check.
+type specialErrorTx struct {
+	interfaces.Transaction
+}
+
+func (t *specialErrorTx) SpecialContextCheck() (elaerr.ELAError, bool) {
+	return elaerr.Simple(elaerr.ErrTxPayload, errors.New("special")), false
+}
+
+func (s *txValidatorTestSuite) TestTraceCheck() {
+	// A transaction with an unknown reference and without programs.
+	txn := functions.CreateTransaction(
+		0,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{},
+		[]*common2.Input{{
+			Previous: common2.OutPoint{
+				TxID:  common.Uint256{1},
+				Index: 0,
+			},
+		}},
+		[]*common2.Output{{
+			AssetID:     core.ELAAssetID,
+			ProgramHash: s.foundationAddress,
+			Value:       common.Fixed64(s.ELA),
+		}},
+		0,
+		[]*program.Program{},
+	)
+	params := &TransactionParameters{
+		Transaction: txn,
+		BlockHeight: s.Chain.GetHeight() + 1,
+		Config:      s.Chain.GetParams(),
+		BlockChain:  s.Chain,
+	}
+
+	// The sanity check stops at the first failure.
+	err := txn.SanityCheck(params)
+	s.NotNil(err)
+	s.Equal(elaerr.ErrTxAttributeProgram, err.Code())
+
+	// The trace goes on after failures, and skips the checks depending on
+	// the references which are not found.
+	trace := txn.TraceCheck(params)
+	s.Equal(elaerr.ErrTxAttributeProgram, trace.Err().Code())
+	failed, skipped := traceChecks(trace)
+	s.Equal([]string{"CheckAttributeProgram", "GetTxReference"}, failed)
+	s.Equal([]string{
+		"IsAllowedInPOWConsensus",
+		"IsDoubleSpend",
+		"CheckTransactionUTXOLock",
+		"CheckSequenceLocks",
+		"CheckHTLCInputs",
+		"CheckTransactionFee",
+		"CheckDestructionAddress",
+		"CheckTransactionDepositUTXO",
+		"CheckTransactionDepositOutputs",
+		"CheckTransactionSignature",
+		"CheckInvalidUTXO",
+		"CheckVoteOutputs",
+	}, skipped)
+
+	s.Equal(1, len(trace.Lookups))
+	s.Equal("Transaction", trace.Lookups[0].Name)
+	s.Equal("not exists", trace.Lookups[0].Result)
+}
+
+func (s *txValidatorTestSuite) TestContextCheck_SpecialError() {
+	// The output of the genesis coinbase is unspent.
+	genesis := core.GenesisBlock(s.foundationAddress)
+	input := &common2.Input{
+		Previous: common2.OutPoint{
+			TxID:  genesis.Transactions[0].Hash(),
+			Index: 0,
+		},
+	}
+	reference := genesis.Transactions[0].Outputs()[0]
+
+	// A transaction spending more than its reference.
+	txn := &specialErrorTx{functions.CreateTransaction(
+		0,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{},
+		[]*common2.Input{input},
+		[]*common2.Output{{
+			AssetID:     core.ELAAssetID,
+			ProgramHash: s.foundationAddress,
+			Value:       reference.Value + 1,
+		}},
+		0,
+		[]*program.Program{},
+	)}
+	params := &TransactionParameters{
+		Transaction: txn,
+		BlockHeight: s.Chain.GetHeight() + 1,
+		Config:      s.Chain.GetParams(),
+		BlockChain:  s.Chain,
+	}
+
+	// The special error does not end the context check, so the balance
+	// checks still run and report their own failure.
+	_, err := txn.ContextCheck(params)
+	s.NotNil(err)
+	s.Equal(elaerr.ErrTxBalance, err.Code())
+
+	// The special error is recorded by the trace, and the balance checks
+	// are not skipped.
+	trace := txn.TraceCheck(params)
+	failed, skipped := traceChecks(trace)
+	s.Equal([]string{"CheckAttributeProgram", "SpecialContextCheck",
+		"CheckTransactionFee", "CheckTransactionSignature",
+		"CheckInvalidUTXO"}, failed)
+	s.Empty(skipped)
+
+	// The special error is ignored as in the context check, so it does not
+	// reject the transaction by itself.
+	for _, c := range trace.Checks {
+		s.Equal(c.Name == "SpecialContextCheck", c.Ignored, c.Name)
+		if c.Name == "SpecialContextCheck" {
+			s.Equal(elaerr.ErrTxPayload, c.Error.Code())
+			special := &interfaces.CheckTrace{
+				Checks: []interfaces.CheckResult{c},
+			}
+			s.Nil(special.Err())
+		}
+		if c.Name == "CheckAttributeProgram" {
+			s.Equal(c.Error, trace.Err())
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"math"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
@@ -144,7 +145,7 @@ func (t *TransferAssetTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight >= chainParams.CRConfiguration.CRVotingStartHeight {
+	if chainParams.IsActive(config.DeploymentCRVoting, blockHeight) {
 		return nil
 	}
 	if t.Version() >= common2.TxVersion09 {
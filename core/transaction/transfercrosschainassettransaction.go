@@ -8,6 +8,7 @@ package transaction
 import (
 	"bytes"
 	"errors"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core"
 	"math"
 
@@ -91,7 +92,7 @@ func (t *TransferCrossChainAssetTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight <= chainParams.NewCrossChainStartHeight {
+	if !chainParams.IsActiveAfter(config.DeploymentNewCrossChain, blockHeight) {
 		if t.PayloadVersion() != payload.TransferCrossChainVersion {
 			return errors.New("not support " +
 				"TransferCrossChainAsset payload version V1 before NewCrossChainStartHeight")
@@ -133,7 +134,7 @@ func (t *TransferCrossChainAssetTransaction) checkTransferCrossChainAssetTransac
 		switch output.Type {
 		case common2.OTNone:
 		case common2.OTCrossChain:
-			if t.parameters.BlockHeight >= t.parameters.Config.ProhibitTransferToDIDHeight {
+			if t.parameters.Config.IsActive(config.DeploymentProhibitTransferToDID, t.parameters.BlockHeight) {
 				address, err := output.ProgramHash.ToAddress()
 				if err != nil {
 					return err
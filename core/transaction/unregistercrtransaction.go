@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
 	elaerr "github.com/elastos/Elastos.ELA/errors"
@@ -37,7 +38,7 @@ func (t *UnregisterCRTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.CRConfiguration.CRVotingStartHeight {
+	if !chainParams.IsActive(config.DeploymentCRVoting, blockHeight) {
 		return errors.New(fmt.Sprintf("not support %s transaction "+
 			"before CRVotingStartHeight", t.TxType().Name()))
 	}
@@ -55,6 +56,8 @@ func (t *UnregisterCRTransaction) SpecialContextCheck() (elaerr.ELAError, bool)
 	}
 
 	cr := t.parameters.BlockChain.GetCRCommittee().GetCandidate(info.CID)
+	cid, _ := info.CID.ToAddress()
+	t.lookupExists("CRCandidate", cid, cr != nil)
 	if cr == nil {
 		return elaerr.Simple(elaerr.ErrTxPayload, errors.New("unregister unknown CR")), true
 	}
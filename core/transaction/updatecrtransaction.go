@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
@@ -69,7 +70,7 @@ func (t *UpdateCRTransaction) SpecialContextCheck() (elaerr.ELAError, bool) {
 		return elaerr.Simple(elaerr.ErrTxPayload, errors.New("invalid cid address")), true
 	}
 
-	if t.parameters.BlockHeight >= t.parameters.Config.CRConfiguration.RegisterCRByDIDHeight &&
+	if t.parameters.Config.IsActive(config.DeploymentRegisterCRByDID, t.parameters.BlockHeight) &&
 		t.PayloadVersion() == payload.CRInfoDIDVersion {
 		// get DID program hash
 
@@ -10,6 +10,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 
 	"github.com/elastos/Elastos.ELA/common"
@@ -28,7 +29,7 @@ func (t *UpdateProducerTransaction) HeightVersionCheck() error {
 	blockHeight := t.parameters.BlockHeight
 	chainParams := t.parameters.Config
 
-	if blockHeight < chainParams.DPoSV2StartHeight {
+	if !chainParams.IsActive(config.DeploymentDPoSV2, blockHeight) {
 		producerPayload, ok := t.Payload().(*payload.ProducerInfo)
 		if !ok {
 			return errors.New("[HeightVersionCheck] invalid UpdateProducer  payload")
@@ -38,7 +39,7 @@ func (t *UpdateProducerTransaction) HeightVersionCheck() error {
 				"2.0 producer transaction before RevertToPOWStartHeight")
 		}
 	}
-	if blockHeight < chainParams.SupportMultiCodeHeight {
+	if !chainParams.IsActive(config.DeploymentSupportMultiCode, blockHeight) {
 		if t.PayloadVersion() == payload.ProducerInfoMultiVersion {
 			return errors.New(fmt.Sprintf("not support %s transaction "+
 				"with payload version %d before SupportMultiCodeHeight",
@@ -146,6 +147,7 @@ func (t *UpdateProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bool
 	}
 
 	producer := t.parameters.BlockChain.GetState().GetProducer(info.OwnerKey)
+	t.lookupExists("Producer", common.BytesToHexString(info.OwnerKey), producer != nil)
 	if producer == nil {
 		return elaerr.Simple(elaerr.ErrTxPayload, errors.New("updating unknown producer")), true
 	}
@@ -213,7 +215,7 @@ func (t *UpdateProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bool
 	}
 
 	//if update producer tx change NodePublicKey
-	if t.parameters.BlockChain.GetHeight() < t.parameters.Config.PublicDPOSHeight {
+	if !t.parameters.Config.IsActive(config.DeploymentPublicDPOS, t.parameters.BlockChain.GetHeight()) {
 		if t.parameters.BlockChain.GetState().ProducerExists(info.NodePublicKey) {
 			return elaerr.Simple(elaerr.ErrTxPayload, fmt.Errorf("producer %s already exist",
 				hex.EncodeToString(info.NodePublicKey))), true
@@ -240,7 +242,7 @@ func (t *UpdateProducerTransaction) SpecialContextCheck() (elaerr.ELAError, bool
 }
 
 func (t *UpdateProducerTransaction) additionalProducerInfoCheck(info *payload.ProducerInfo) error {
-	if t.parameters.BlockChain.GetHeight() >= t.parameters.Config.PublicDPOSHeight {
+	if t.parameters.Config.IsActive(config.DeploymentPublicDPOS, t.parameters.BlockChain.GetHeight()) {
 		_, err := crypto.DecodePoint(info.NodePublicKey)
 		if err != nil {
 			return errors.New("invalid node public key in payload")
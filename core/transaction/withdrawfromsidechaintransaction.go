@@ -14,6 +14,7 @@ import (
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
@@ -102,7 +103,7 @@ func (t *WithdrawFromSideChainTransaction) IsAllowedInPOWConsensus() bool {
 }
 
 func (t *WithdrawFromSideChainTransaction) SpecialContextCheck() (elaerr.ELAError, bool) {
-	if t.parameters.BlockHeight > t.parameters.Config.SchnorrStartHeight && t.PayloadVersion() != payload.WithdrawFromSideChainVersionV2 {
+	if t.parameters.Config.IsActiveAfter(config.DeploymentSchnorr, t.parameters.BlockHeight) && t.PayloadVersion() != payload.WithdrawFromSideChainVersionV2 {
 		return elaerr.Simple(elaerr.ErrTxPayload, errors.New("only support schnorr type of  withdraw from sidechain transaction")), true
 	}
 	var err error
@@ -145,10 +146,10 @@ func (t *WithdrawFromSideChainTransaction) checkWithdrawFromSideChainTransaction
 			return err
 		}
 
-		if height >= t.parameters.Config.CRConfiguration.CRClaimDPOSNodeStartHeight {
+		if t.parameters.Config.IsActive(config.DeploymentCRClaimDPOSNode, height) {
 			var arbiters []*state.ArbiterInfo
 			var minCount uint32
-			if height >= t.parameters.Config.DPoSConfiguration.DPOSNodeCrossChainHeight {
+			if t.parameters.Config.IsActive(config.DeploymentDPOSNodeCrossChain, height) {
 				arbiters = blockchain.DefaultLedger.Arbitrators.GetArbitrators()
 				minCount = uint32(t.parameters.Config.DPoSConfiguration.NormalArbitratorsCount) + 1
 			} else {
@@ -243,7 +244,7 @@ func (t *WithdrawFromSideChainTransaction) checkWithdrawFromSideChainTransaction
 		}
 		var arbiters []*state.ArbiterInfo
 		var minCount uint32
-		if height >= t.parameters.Config.DPoSConfiguration.DPOSNodeCrossChainHeight {
+		if t.parameters.Config.IsActive(config.DeploymentDPOSNodeCrossChain, height) {
 			arbiters = blockchain.DefaultLedger.Arbitrators.GetArbitrators()
 			minCount = uint32(t.parameters.Config.DPoSConfiguration.NormalArbitratorsCount) + 1
 		} else {
@@ -278,11 +279,11 @@ func (t *WithdrawFromSideChainTransaction) checkWithdrawFromSideChainTransaction
 	}
 
 	currentHeight := t.parameters.BlockHeight
-	if currentHeight <= t.parameters.Config.CRConfiguration.CRClaimDPOSNodeStartHeight {
+	if !t.parameters.Config.IsActiveAfter(config.DeploymentCRClaimDPOSNode, currentHeight) {
 		if len(pld.Signers) < (int(t.parameters.Config.CRConfiguration.MemberCount)*2/3 + 1) {
 			return errors.New("Signers number must be bigger than 2/3+1 CRMemberCount")
 		}
-	} else if currentHeight < t.parameters.Config.DPoSConfiguration.DPOSNodeCrossChainHeight {
+	} else if !t.parameters.Config.IsActive(config.DeploymentDPOSNodeCrossChain, currentHeight) {
 		if len(pld.Signers) < (int(t.parameters.Config.CRConfiguration.MemberCount) * 2 / 3) {
 			return errors.New("Signers number must be bigger than 2/3 CRMemberCount")
 		}
@@ -12,6 +12,11 @@ import (
 	"github.com/elastos/Elastos.ELA/common"
 )
 
+// RBFSequence is the sequence number an input sets to signal that the
+// transaction spending it can be replaced by a transaction with higher fee
+// before being packed.  Only this exact value signals replaceability.
+const RBFSequence = 0xfffffffd
+
 type Input struct {
 	// Reference outpoint of this input
 	Previous OutPoint
@@ -28,6 +33,12 @@ func (i *Input) Deserialize(r io.Reader) error {
 	return common.ReadElements(r, &i.Previous.TxID, &i.Previous.Index, &i.Sequence)
 }
 
+// SignalsReplacement returns whether the input signals the transaction
+// spending it can be replaced by fee.
+func (i *Input) SignalsReplacement() bool {
+	return i.Sequence == RBFSequence
+}
+
 func (i *Input) ReferKey() string {
 	return i.Previous.ReferKey()
 }
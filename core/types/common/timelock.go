@@ -0,0 +1,80 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package common
+
+const (
+	// LockTimeThreshold is the number below which a lock time is a block
+	// height, otherwise it is a unix timestamp compared with the median time
+	// past once the time locks are active.
+	LockTimeThreshold = 500000000
+
+	// SequenceLockTimeDisabled is the flag of an input sequence, if set the
+	// sequence is not a relative lock time.  The sequences used to enable
+	// the lock time or signal replacement all have the flag set.
+	SequenceLockTimeDisabled = 1 << 31
+
+	// SequenceLockTimeIsSeconds is the flag of a relative lock time, if set
+	// the lock time is in units of 512 seconds, otherwise in blocks.
+	SequenceLockTimeIsSeconds = 1 << 22
+
+	// SequenceLockTimeMask extracts the relative lock time from a sequence.
+	SequenceLockTimeMask = 0x0000ffff
+
+	// SequenceLockTimeGranularity is the number of bits the seconds of a
+	// relative lock time are shifted by, which makes a unit of 512 seconds.
+	SequenceLockTimeGranularity = 9
+
+	// OutputLockRelative is the flag of an output lock, if set the output can
+	// only be spent by an input with a relative lock time no less than the
+	// one in the low bits of the output lock, which are encoded like a
+	// sequence.
+	OutputLockRelative = 1 << 31
+)
+
+// LockTimeToSequence converts a relative lock time in blocks or seconds to
+// a sequence, the seconds are rounded up to units of 512 seconds.
+func LockTimeToSequence(isSeconds bool, lockTime uint32) uint32 {
+	if !isSeconds {
+		return lockTime & SequenceLockTimeMask
+	}
+	units := (uint64(lockTime) + (1 << SequenceLockTimeGranularity) - 1) >>
+		SequenceLockTimeGranularity
+	if units > SequenceLockTimeMask {
+		units = SequenceLockTimeMask
+	}
+	return SequenceLockTimeIsSeconds | uint32(units)
+}
+
+// RelativeLockTime returns the relative lock time of the sequence in blocks
+// or seconds, and if it is in seconds.
+func RelativeLockTime(sequence uint32) (uint32, bool) {
+	lockTime := sequence & SequenceLockTimeMask
+	if sequence&SequenceLockTimeIsSeconds != 0 {
+		return lockTime << SequenceLockTimeGranularity, true
+	}
+	return lockTime, false
+}
+
+// HasRelativeLock returns whether the input sequence is a relative lock time.
+func (i *Input) HasRelativeLock() bool {
+	return i.Sequence&SequenceLockTimeDisabled == 0
+}
+
+// IsRelativeLock returns whether the output lock is a relative lock time.
+func (o *Output) IsRelativeLock() bool {
+	return o.OutputLock&OutputLockRelative != 0
+}
+
+// IsTimeLock returns whether the output lock is an absolute unix timestamp.
+func (o *Output) IsTimeLock() bool {
+	return !o.IsRelativeLock() && o.OutputLock >= LockTimeThreshold
+}
+
+// RelativeLockSequence returns the minimum sequence of the input spending
+// the output locked by a relative lock time.
+func (o *Output) RelativeLockSequence() uint32 {
+	return o.OutputLock &^ OutputLockRelative
+}
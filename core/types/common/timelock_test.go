@@ -0,0 +1,57 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package common
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockTimeToSequence(t *testing.T) {
+	// Blocks
+	sequence := LockTimeToSequence(false, 100)
+	assert.Equal(t, uint32(100), sequence)
+	lockTime, isSeconds := RelativeLockTime(sequence)
+	assert.Equal(t, uint32(100), lockTime)
+	assert.False(t, isSeconds)
+
+	// Seconds are rounded up to units of 512 seconds
+	sequence = LockTimeToSequence(true, 1000)
+	assert.Equal(t, uint32(SequenceLockTimeIsSeconds|2), sequence)
+	lockTime, isSeconds = RelativeLockTime(sequence)
+	assert.Equal(t, uint32(1024), lockTime)
+	assert.True(t, isSeconds)
+
+	// Seconds are capped by the mask
+	sequence = LockTimeToSequence(true, math.MaxUint32)
+	assert.Equal(t, uint32(SequenceLockTimeIsSeconds|SequenceLockTimeMask),
+		sequence)
+}
+
+func TestRelativeLock(t *testing.T) {
+	for _, sequence := range []uint32{math.MaxUint32, math.MaxUint32 - 1} {
+		input := Input{Sequence: sequence}
+		assert.False(t, input.HasRelativeLock())
+	}
+	input := Input{Sequence: LockTimeToSequence(false, 10)}
+	assert.True(t, input.HasRelativeLock())
+
+	output := Output{OutputLock: 1000}
+	assert.False(t, output.IsRelativeLock())
+	assert.False(t, output.IsTimeLock())
+
+	output.OutputLock = LockTimeThreshold
+	assert.False(t, output.IsRelativeLock())
+	assert.True(t, output.IsTimeLock())
+
+	output.OutputLock = OutputLockRelative | LockTimeToSequence(true, 3600)
+	assert.True(t, output.IsRelativeLock())
+	assert.False(t, output.IsTimeLock())
+	assert.Equal(t, LockTimeToSequence(true, 3600),
+		output.RelativeLockSequence())
+}
@@ -0,0 +1,102 @@
+// Copyright (c) 2017-2021 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package interfaces
+
+import (
+	elaerr "github.com/elastos/Elastos.ELA/errors"
+)
+
+// CheckResult is the outcome of a single validation check of a transaction.
+type CheckResult struct {
+	Name string
+
+	// Skipped is true if the check was not run because a check it depends
+	// on has failed.
+	Skipped bool
+
+	// Ignored is true if the check has failed but its error does not reject
+	// the transaction.
+	Ignored bool
+
+	// Error is nil if the check has passed or has been skipped.
+	Error elaerr.ELAError
+}
+
+// Passed returns if the check was run and has passed.
+func (r *CheckResult) Passed() bool {
+	return !r.Skipped && r.Error == nil
+}
+
+// CheckLookup is a chain or state lookup made by a check.
+type CheckLookup struct {
+	Name   string
+	Key    string
+	Result string
+}
+
+// CheckTrace records every check run against a transaction and the lookups
+// they made. A nil CheckTrace records nothing, so checks can report to it
+// without knowing whether the transaction is traced.
+type CheckTrace struct {
+	Checks  []CheckResult
+	Lookups []CheckLookup
+}
+
+// Add records the result of the named check.
+func (t *CheckTrace) Add(name string, err elaerr.ELAError) {
+	if t == nil {
+		return
+	}
+	t.Checks = append(t.Checks, CheckResult{Name: name, Error: err})
+}
+
+// Skip records the named check as not run.
+func (t *CheckTrace) Skip(name string) {
+	if t == nil {
+		return
+	}
+	t.Checks = append(t.Checks, CheckResult{Name: name, Skipped: true})
+}
+
+// Ignore marks the latest result of the named check as not rejecting the
+// transaction.
+func (t *CheckTrace) Ignore(name string) {
+	if t == nil {
+		return
+	}
+	for i := len(t.Checks) - 1; i >= 0; i-- {
+		if t.Checks[i].Name == name {
+			t.Checks[i].Ignored = true
+			return
+		}
+	}
+}
+
+// Lookup records a lookup made by a check.
+func (t *CheckTrace) Lookup(name, key, result string) {
+	if t == nil {
+		return
+	}
+	t.Lookups = append(t.Lookups, CheckLookup{
+		Name:   name,
+		Key:    key,
+		Result: result,
+	})
+}
+
+// Err returns the error of the first failed check not ignored, or nil if all
+// checks run have passed or their errors are ignored.
+func (t *CheckTrace) Err() elaerr.ELAError {
+	if t == nil {
+		return nil
+	}
+	for i := range t.Checks {
+		if t.Checks[i].Error != nil && !t.Checks[i].Ignored {
+			return t.Checks[i].Error
+		}
+	}
+	return nil
+}
@@ -19,6 +19,10 @@ type TransactionChecker interface {
 
 	SetParameters(p interface{}) elaerr.ELAError
 
+	// TraceCheck runs every sanity and context check without stopping at
+	// the first failure and returns the result of each check.
+	TraceCheck(p Parameters) *CheckTrace
+
 	SetReferences(ref map[*common2.Input]common2.Output)
 }
 
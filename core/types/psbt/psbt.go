@@ -0,0 +1,479 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package psbt
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// Version is the latest version of partially signed transaction format.
+const Version byte = 1
+
+const (
+	// publicKeyLength is the length of a compressed public key.
+	publicKeyLength = 33
+
+	// maxKeyDataLength is the max length of a signature or a nonce.
+	maxKeyDataLength = 72
+
+	// maxProgramKeys is the max count of public keys of a program, which is
+	// limited by the max length of a multi-sign redeem script.
+	maxProgramKeys = crypto.MaxMultiSignCodeLength / publicKeyLength
+)
+
+// Magic is the leading bytes of a serialized partially signed transaction,
+// which never starts a serialized transaction.
+var Magic = [5]byte{'p', 's', 'b', 't', 0xff}
+
+// KeyData is the data provided by the owner of the public key, such as
+// a signature or a nonce.
+type KeyData struct {
+	PublicKey []byte
+	Data      []byte
+}
+
+// Input is the information of the transaction input needed by signers.
+type Input struct {
+	// PreviousOutput is the output referenced by the input, nil if unknown.
+	PreviousOutput *common2.Output
+
+	// RedeemScript is the code of the program to unlock the input, nil if
+	// unknown.
+	RedeemScript []byte
+}
+
+// Output is the information of the transaction output.
+type Output struct {
+	// RedeemScript is the code of the output address if it is known by the
+	// creator, such as the change address.
+	RedeemScript []byte
+}
+
+// Program collects the signatures of a program of the transaction.
+type Program struct {
+	Code []byte
+
+	// PublicKeys is the public keys aggregated into the public key of a
+	// Schnorr program.
+	PublicKeys [][]byte
+
+	// Signatures is the signatures of a standard or multi-sign program.
+	Signatures []*KeyData
+
+	// Nonces and PartialSignatures is the public nonces and the partial
+	// signatures of the signers of a Schnorr program.
+	Nonces            []*KeyData
+	PartialSignatures []*KeyData
+}
+
+// Packet is a partially signed transaction.
+type Packet struct {
+	Transaction interfaces.Transaction
+	Inputs      []*Input
+	Outputs     []*Output
+	Programs    []*Program
+}
+
+// New creates a partially signed transaction of the transaction, the
+// previous outputs are in the order of the inputs and can be nil if unknown.
+// The codes and signatures of the transaction programs are kept, while the
+// programs are removed from the transaction.
+func New(txn interfaces.Transaction,
+	previousOutputs []*common2.Output) (*Packet, error) {
+	if len(previousOutputs) != len(txn.Inputs()) {
+		return nil, errors.New("previous outputs and inputs not match")
+	}
+
+	p := &Packet{
+		Transaction: txn,
+		Inputs:      make([]*Input, 0, len(txn.Inputs())),
+		Outputs:     make([]*Output, 0, len(txn.Outputs())),
+	}
+	for _, output := range previousOutputs {
+		p.Inputs = append(p.Inputs, &Input{PreviousOutput: output})
+	}
+	for range txn.Outputs() {
+		p.Outputs = append(p.Outputs, &Output{})
+	}
+
+	programs := txn.Programs()
+	txn.SetPrograms(nil)
+	for _, program := range programs {
+		pg, err := p.AddRedeemScript(program.Code)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.importSignatures(pg, program.Parameter); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// AddRedeemScript sets the redeem script of the inputs referencing the
+// address of the code, and adds the program of the code.
+func (p *Packet) AddRedeemScript(code []byte) (*Program, error) {
+	programHash, err := toProgramHash(code)
+	if err != nil {
+		return nil, err
+	}
+	var found bool
+	for _, input := range p.Inputs {
+		if input.PreviousOutput == nil ||
+			!input.PreviousOutput.ProgramHash.IsEqual(*programHash) {
+			continue
+		}
+		input.RedeemScript = code
+		found = true
+	}
+	// Without previous outputs, the code is trusted to unlock the inputs
+	// as the transaction programs do.
+	if !found && !p.previousOutputsKnown() {
+		found = true
+	}
+	if !found {
+		address, _ := programHash.ToAddress()
+		return nil, errors.New("no input of address " + address)
+	}
+
+	if pg := p.Program(code); pg != nil {
+		return pg, nil
+	}
+	pg := &Program{Code: code}
+	p.Programs = append(p.Programs, pg)
+	return pg, nil
+}
+
+// SetOutputRedeemScript sets the redeem script of the outputs paying to the
+// address of the code, returns the count of outputs set.
+func (p *Packet) SetOutputRedeemScript(code []byte) (int, error) {
+	programHash, err := toProgramHash(code)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	for i, output := range p.Transaction.Outputs() {
+		if output.ProgramHash.IsEqual(*programHash) {
+			p.Outputs[i].RedeemScript = code
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Program returns the program of the code, nil if not found.
+func (p *Packet) Program(code []byte) *Program {
+	for _, pg := range p.Programs {
+		if bytes.Equal(pg.Code, code) {
+			return pg
+		}
+	}
+	return nil
+}
+
+// Fee returns the fee of the transaction, the previous outputs of all inputs
+// must be known.
+func (p *Packet) Fee() (common.Fixed64, error) {
+	var fee common.Fixed64
+	for i, input := range p.Inputs {
+		if input.PreviousOutput == nil {
+			return 0, fmt.Errorf("previous output of input %d is unknown", i)
+		}
+		fee += input.PreviousOutput.Value
+	}
+	for _, output := range p.Transaction.Outputs() {
+		fee -= output.Value
+	}
+	return fee, nil
+}
+
+func (p *Packet) previousOutputsKnown() bool {
+	for _, input := range p.Inputs {
+		if input.PreviousOutput == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Packet) unsignedData() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := p.Transaction.SerializeUnsigned(buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// toProgramHash returns the address of the standard, multi-sign or Schnorr
+// code.
+func toProgramHash(code []byte) (*common.Uint168, error) {
+	switch contract.GetCodeType(code) {
+	case contract.Signature, contract.Schnorr:
+		return common.ToProgramHash(byte(contract.PrefixStandard), code), nil
+	case contract.MultiSig:
+		return common.ToProgramHash(byte(contract.PrefixMultiSig), code), nil
+	}
+	return nil, errors.New("unsupported redeem script " +
+		common.BytesToHexString(code))
+}
+
+// IsPacket returns if the data is a serialized partially signed transaction.
+func IsPacket(data []byte) bool {
+	return bytes.HasPrefix(data, Magic[:])
+}
+
+func (p *Packet) Serialize(w io.Writer) error {
+	if _, err := w.Write(Magic[:]); err != nil {
+		return err
+	}
+	if err := common.WriteUint8(w, Version); err != nil {
+		return err
+	}
+	if err := p.Transaction.SerializeUnsigned(w); err != nil {
+		return err
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(p.Inputs))); err != nil {
+		return err
+	}
+	for _, input := range p.Inputs {
+		if input.PreviousOutput == nil {
+			if err := common.WriteUint8(w, 0); err != nil {
+				return err
+			}
+		} else {
+			if err := common.WriteUint8(w, 1); err != nil {
+				return err
+			}
+			// The previous output is always serialized with output type and
+			// payload, regardless of the version of its transaction.
+			output := *input.PreviousOutput
+			if output.Payload == nil {
+				output.Type = common2.OTNone
+				output.Payload = &outputpayload.DefaultOutput{}
+			}
+			if err := output.Serialize(w, common2.TxVersion09); err != nil {
+				return err
+			}
+		}
+		if err := common.WriteVarBytes(w, input.RedeemScript); err != nil {
+			return err
+		}
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(p.Outputs))); err != nil {
+		return err
+	}
+	for _, output := range p.Outputs {
+		if err := common.WriteVarBytes(w, output.RedeemScript); err != nil {
+			return err
+		}
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(p.Programs))); err != nil {
+		return err
+	}
+	for _, pg := range p.Programs {
+		if err := pg.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Packet) Deserialize(r io.Reader) error {
+	var magic [5]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return err
+	}
+	if magic != Magic {
+		return errors.New("not a partially signed transaction")
+	}
+	version, err := common.ReadUint8(r)
+	if err != nil {
+		return err
+	}
+	if version != Version {
+		return fmt.Errorf("unsupported partially signed transaction "+
+			"version %d", version)
+	}
+	p.Transaction, err = functions.GetTransactionByBytes(r)
+	if err != nil {
+		return err
+	}
+	if err := p.Transaction.DeserializeUnsigned(r); err != nil {
+		return err
+	}
+
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count != uint64(len(p.Transaction.Inputs())) {
+		return errors.New("inputs count not match")
+	}
+	p.Inputs = make([]*Input, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var input Input
+		known, err := common.ReadUint8(r)
+		if err != nil {
+			return err
+		}
+		if known != 0 {
+			input.PreviousOutput = new(common2.Output)
+			if err := input.PreviousOutput.Deserialize(r,
+				common2.TxVersion09); err != nil {
+				return err
+			}
+		}
+		input.RedeemScript, err = common.ReadVarBytes(r,
+			crypto.MaxMultiSignCodeLength, "redeem script")
+		if err != nil {
+			return err
+		}
+		p.Inputs = append(p.Inputs, &input)
+	}
+
+	count, err = common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count != uint64(len(p.Transaction.Outputs())) {
+		return errors.New("outputs count not match")
+	}
+	p.Outputs = make([]*Output, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var output Output
+		output.RedeemScript, err = common.ReadVarBytes(r,
+			crypto.MaxMultiSignCodeLength, "redeem script")
+		if err != nil {
+			return err
+		}
+		p.Outputs = append(p.Outputs, &output)
+	}
+
+	count, err = common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count > uint64(len(p.Transaction.Inputs())) {
+		return errors.New("programs count exceeds inputs count")
+	}
+	p.Programs = make([]*Program, 0)
+	for i := uint64(0); i < count; i++ {
+		var pg Program
+		if err := pg.Deserialize(r); err != nil {
+			return err
+		}
+		p.Programs = append(p.Programs, &pg)
+	}
+	return nil
+}
+
+func (pr *Program) Serialize(w io.Writer) error {
+	if err := common.WriteVarBytes(w, pr.Code); err != nil {
+		return err
+	}
+	if err := common.WriteVarUint(w, uint64(len(pr.PublicKeys))); err != nil {
+		return err
+	}
+	for _, pk := range pr.PublicKeys {
+		if err := common.WriteVarBytes(w, pk); err != nil {
+			return err
+		}
+	}
+	if err := serializeKeyData(w, pr.Signatures); err != nil {
+		return err
+	}
+	if err := serializeKeyData(w, pr.Nonces); err != nil {
+		return err
+	}
+	return serializeKeyData(w, pr.PartialSignatures)
+}
+
+func (pr *Program) Deserialize(r io.Reader) error {
+	var err error
+	pr.Code, err = common.ReadVarBytes(r, crypto.MaxMultiSignCodeLength,
+		"program code")
+	if err != nil {
+		return err
+	}
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count > maxProgramKeys {
+		return fmt.Errorf("public keys count %d exceeds %d", count,
+			maxProgramKeys)
+	}
+	for i := uint64(0); i < count; i++ {
+		pk, err := common.ReadVarBytes(r, publicKeyLength,
+			"public key")
+		if err != nil {
+			return err
+		}
+		pr.PublicKeys = append(pr.PublicKeys, pk)
+	}
+	if pr.Signatures, err = deserializeKeyData(r); err != nil {
+		return err
+	}
+	if pr.Nonces, err = deserializeKeyData(r); err != nil {
+		return err
+	}
+	pr.PartialSignatures, err = deserializeKeyData(r)
+	return err
+}
+
+func serializeKeyData(w io.Writer, data []*KeyData) error {
+	if err := common.WriteVarUint(w, uint64(len(data))); err != nil {
+		return err
+	}
+	for _, d := range data {
+		if err := common.WriteVarBytes(w, d.PublicKey); err != nil {
+			return err
+		}
+		if err := common.WriteVarBytes(w, d.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deserializeKeyData(r io.Reader) ([]*KeyData, error) {
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if count > maxProgramKeys {
+		return nil, fmt.Errorf("key data count %d exceeds %d", count,
+			maxProgramKeys)
+	}
+	var data []*KeyData
+	for i := uint64(0); i < count; i++ {
+		var d KeyData
+		if d.PublicKey, err = common.ReadVarBytes(r,
+			publicKeyLength, "public key"); err != nil {
+			return nil, err
+		}
+		if d.Data, err = common.ReadVarBytes(r, maxKeyDataLength,
+			"key data"); err != nil {
+			return nil, err
+		}
+		data = append(data, &d)
+	}
+	return data, nil
+}
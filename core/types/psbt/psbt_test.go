@@ -0,0 +1,333 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package psbt
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/transaction"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/crypto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	functions.GetTransactionByTxType = transaction.GetTransaction
+	functions.GetTransactionByBytes = transaction.GetTransactionByBytes
+	functions.CreateTransaction = transaction.CreateTransaction
+}
+
+type testKey struct {
+	privateKey []byte
+	publicKey  *crypto.PublicKey
+	encoded    []byte
+}
+
+func newTestKeys(t *testing.T, count int) []*testKey {
+	keys := make([]*testKey, 0, count)
+	for i := 0; i < count; i++ {
+		privateKey, publicKey, err := crypto.GenerateKeyPair()
+		assert.NoError(t, err)
+		encoded, err := publicKey.EncodePoint(true)
+		assert.NoError(t, err)
+		keys = append(keys, &testKey{privateKey, publicKey, encoded})
+	}
+	return keys
+}
+
+func roundTrip(t *testing.T, p *Packet) *Packet {
+	buf := new(bytes.Buffer)
+	assert.NoError(t, p.Serialize(buf))
+	assert.True(t, IsPacket(buf.Bytes()))
+
+	var packet Packet
+	assert.NoError(t, packet.Deserialize(buf))
+	assert.Equal(t, 0, buf.Len())
+	return &packet
+}
+
+func TestPacket(t *testing.T) {
+	keys := newTestKeys(t, 6)
+
+	standard, err := contract.CreateStandardRedeemScript(keys[0].publicKey)
+	assert.NoError(t, err)
+	multiSig, err := contract.CreateMultiSigRedeemScript(2, []*crypto.PublicKey{
+		keys[1].publicKey, keys[2].publicKey, keys[3].publicKey})
+	assert.NoError(t, err)
+	aggregated, err := crypto.AggregatePublickeys([][]byte{
+		keys[4].encoded, keys[5].encoded})
+	assert.NoError(t, err)
+	aggregatedKey, err := crypto.DecodePoint(aggregated)
+	assert.NoError(t, err)
+	schnorr, err := contract.CreateSchnorrRedeemScript(aggregatedKey)
+	assert.NoError(t, err)
+
+	codes := [][]byte{standard, multiSig, schnorr}
+	inputs := make([]*common2.Input, 0, len(codes))
+	previousOutputs := make([]*common2.Output, 0, len(codes))
+	for i, code := range codes {
+		programHash, err := toProgramHash(code)
+		assert.NoError(t, err)
+		inputs = append(inputs, &common2.Input{
+			Previous: common2.OutPoint{TxID: common.Uint256{byte(i + 1)}},
+		})
+		previousOutputs = append(previousOutputs, &common2.Output{
+			AssetID:     core.ELAAssetID,
+			Value:       100,
+			ProgramHash: *programHash,
+		})
+	}
+	changeHash, _ := toProgramHash(standard)
+	txn := functions.CreateTransaction(
+		common2.TxVersion09,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{},
+		inputs,
+		[]*common2.Output{{
+			AssetID:     core.ELAAssetID,
+			Value:       290,
+			ProgramHash: *changeHash,
+			Payload:     &outputpayload.DefaultOutput{},
+		}},
+		0,
+		[]*pg.Program{{Code: standard}, {Code: multiSig}},
+	)
+
+	p, err := New(txn, previousOutputs)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(txn.Programs()))
+	_, err = p.SetSchnorrPublicKeys([][]byte{keys[4].encoded, keys[5].encoded})
+	assert.NoError(t, err)
+	count, err := p.SetOutputRedeemScript(standard)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	fee, err := p.Fee()
+	assert.NoError(t, err)
+	assert.Equal(t, common.Fixed64(10), fee)
+	for i, code := range codes {
+		assert.Equal(t, code, p.Inputs[i].RedeemScript)
+	}
+
+	// The code of an address not referenced can not be added.
+	other, err := contract.CreateStandardRedeemScript(keys[1].publicKey)
+	assert.NoError(t, err)
+	_, err = p.AddRedeemScript(other)
+	assert.Error(t, err)
+
+	// Signers sign copies of the packet independently.
+	p = roundTrip(t, p)
+	signed := make([]*Packet, 0, 3)
+	for _, k := range keys[:3] {
+		packet := roundTrip(t, p)
+		count, err := packet.Sign(k.privateKey)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, count)
+		signed = append(signed, packet)
+	}
+
+	// The Schnorr signers share nonces first.
+	secrets := make([][]byte, 0, 2)
+	for _, k := range keys[4:] {
+		secret, nonce, err := crypto.SchnorrNonce()
+		assert.NoError(t, err)
+		assert.NoError(t, p.Program(schnorr).AddNonce(k.encoded, nonce))
+		secrets = append(secrets, secret)
+	}
+	_, err = p.Finalize()
+	assert.Error(t, err)
+	for i, k := range keys[4:] {
+		packet := roundTrip(t, p)
+		assert.NoError(t, packet.SignSchnorr(packet.Program(schnorr),
+			k.privateKey, secrets[i]))
+		signed = append(signed, packet)
+	}
+
+	for _, packet := range signed {
+		assert.NoError(t, p.Combine(packet))
+	}
+	assert.Equal(t, 2, len(p.Program(multiSig).Signatures))
+	assert.Equal(t, 0, len(p.Program(schnorr).Missing()))
+
+	// The packet can be combined again without changes.
+	assert.NoError(t, p.Combine(signed[0]))
+	assert.Equal(t, 1, len(p.Program(standard).Signatures))
+
+	final, err := roundTrip(t, p).Finalize()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(final.Programs()))
+	assert.Equal(t, txn.Hash(), final.Hash())
+	for _, program := range final.Programs() {
+		switch {
+		case bytes.Equal(program.Code, multiSig):
+			assert.Equal(t, 2*crypto.SignatureScriptLength,
+				len(program.Parameter))
+		case bytes.Equal(program.Code, schnorr):
+			assert.Equal(t, 64, len(program.Parameter))
+		}
+	}
+
+	// Signatures of the finalized transaction are imported.
+	imported, err := New(final, previousOutputs)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(imported.Program(standard).Signatures))
+	assert.Equal(t, 2, len(imported.Program(multiSig).Signatures))
+}
+
+func TestPacket_Combine(t *testing.T) {
+	keys := newTestKeys(t, 2)
+	aggregated, err := crypto.AggregatePublickeys([][]byte{
+		keys[0].encoded, keys[1].encoded})
+	assert.NoError(t, err)
+	aggregatedKey, err := crypto.DecodePoint(aggregated)
+	assert.NoError(t, err)
+	schnorr, err := contract.CreateSchnorrRedeemScript(aggregatedKey)
+	assert.NoError(t, err)
+	programHash, _ := toProgramHash(schnorr)
+
+	txn := functions.CreateTransaction(
+		common2.TxVersion09,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{},
+		[]*common2.Input{{}},
+		[]*common2.Output{{
+			AssetID:     core.ELAAssetID,
+			Value:       90,
+			ProgramHash: *programHash,
+			Payload:     &outputpayload.DefaultOutput{},
+		}},
+		0,
+		[]*pg.Program{},
+	)
+	p, err := New(txn, []*common2.Output{nil})
+	assert.NoError(t, err)
+	_, err = p.Fee()
+	assert.Error(t, err)
+	_, err = p.SetSchnorrPublicKeys([][]byte{keys[0].encoded, keys[1].encoded})
+	assert.NoError(t, err)
+
+	// Conflicting nonces of the same signer are refused.
+	first, second := roundTrip(t, p), roundTrip(t, p)
+	_, nonce, err := crypto.SchnorrNonce()
+	assert.NoError(t, err)
+	assert.NoError(t, first.Program(schnorr).AddNonce(keys[0].encoded, nonce))
+	assert.Error(t, first.Program(schnorr).AddNonce(keys[0].encoded, nonce))
+	_, nonce, err = crypto.SchnorrNonce()
+	assert.NoError(t, err)
+	assert.NoError(t, second.Program(schnorr).AddNonce(keys[0].encoded, nonce))
+	assert.Error(t, first.Combine(second))
+
+	// Packets of different transactions can not be combined.
+	changed := roundTrip(t, p)
+	changed.Transaction.Outputs()[0].Value = 80
+	assert.Error(t, p.Combine(changed))
+}
+
+// newTestPacketBytes returns a serialized packet of a transaction with one
+// input, of which the programs section is replaced by the given bytes.
+func newTestPacketBytes(t testing.TB, programs []byte) []byte {
+	txn := functions.CreateTransaction(
+		common2.TxVersion09,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{},
+		[]*common2.Input{{}},
+		[]*common2.Output{{
+			AssetID: core.ELAAssetID,
+			Value:   90,
+			Payload: &outputpayload.DefaultOutput{},
+		}},
+		0,
+		[]*pg.Program{},
+	)
+	p, err := New(txn, []*common2.Output{nil})
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, p.Serialize(buf))
+	data := buf.Bytes()
+	// The packet ends with a zero programs count.
+	return append(data[:len(data)-1], programs...)
+}
+
+func TestPacket_DeserializeInvalid(t *testing.T) {
+	varUint := func(v uint64) []byte {
+		buf := new(bytes.Buffer)
+		common.WriteVarUint(buf, v)
+		return buf.Bytes()
+	}
+	program := func(counts ...uint64) []byte {
+		buf := new(bytes.Buffer)
+		common.WriteVarBytes(buf, []byte{0x21})
+		for _, count := range counts {
+			buf.Write(varUint(count))
+		}
+		return buf.Bytes()
+	}
+	join := func(parts ...[]byte) []byte {
+		return bytes.Join(parts, nil)
+	}
+
+	tests := []struct {
+		name     string
+		programs []byte
+	}{
+		{"programs count", varUint(2)},
+		{"huge programs count", varUint(0xffffffffffffffff)},
+		{"huge public keys count", join(varUint(1),
+			program(0xffffffffffffffff))},
+		{"public keys count", join(varUint(1),
+			program(maxProgramKeys+1))},
+		{"huge signatures count", join(varUint(1),
+			program(0, 0xffffffffffffffff))},
+		{"huge nonces count", join(varUint(1),
+			program(0, 0, 0xffffffffffffffff))},
+		{"huge partial signatures count", join(varUint(1),
+			program(0, 0, 0, 0xffffffffffffffff))},
+		{"truncated public keys", join(varUint(1), program(3))},
+		{"truncated signatures", join(varUint(1), program(0, 1))},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var p Packet
+			err := p.Deserialize(bytes.NewReader(
+				newTestPacketBytes(t, test.programs)))
+			assert.Error(t, err)
+		})
+	}
+
+	// A valid program is accepted, and every truncation of it is refused.
+	data := newTestPacketBytes(t, join(varUint(1), program(0, 0, 0, 0)))
+	var p Packet
+	assert.NoError(t, p.Deserialize(bytes.NewReader(data)))
+	for i := 0; i < len(data); i++ {
+		var p Packet
+		assert.Error(t, p.Deserialize(bytes.NewReader(data[:i])))
+	}
+}
+
+func FuzzPacket_Deserialize(f *testing.F) {
+	f.Add(newTestPacketBytes(f, []byte{0}))
+	f.Add(newTestPacketBytes(f, []byte{1, 1, 0x21, 0, 0, 0, 0}))
+	f.Add(newTestPacketBytes(f, []byte{1, 1, 0x21, 0, 1, 33}))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var p Packet
+		p.Deserialize(bytes.NewReader(data))
+	})
+}
@@ -0,0 +1,437 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package psbt
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	pg "github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/crypto"
+)
+
+// Signers returns the public keys of the signers of the program.
+func (pr *Program) Signers() ([][]byte, error) {
+	switch contract.GetCodeType(pr.Code) {
+	case contract.Signature:
+		return [][]byte{pr.Code[1 : len(pr.Code)-1]}, nil
+	case contract.MultiSig:
+		publicKeys, err := crypto.ParseMultisigScript(pr.Code)
+		if err != nil {
+			return nil, err
+		}
+		signers := make([][]byte, 0, len(publicKeys))
+		for _, pk := range publicKeys {
+			signers = append(signers, pk[1:])
+		}
+		return signers, nil
+	case contract.Schnorr:
+		return pr.PublicKeys, nil
+	}
+	return nil, errors.New("unsupported program code")
+}
+
+// Required returns the count of signatures the program requires.
+func (pr *Program) Required() (int, error) {
+	switch contract.GetCodeType(pr.Code) {
+	case contract.Signature:
+		return 1, nil
+	case contract.MultiSig:
+		m, err := crypto.GetM(pr.Code)
+		return int(m), err
+	case contract.Schnorr:
+		if len(pr.PublicKeys) == 0 {
+			return 0, errors.New("public keys of Schnorr program are unknown")
+		}
+		return len(pr.PublicKeys), nil
+	}
+	return 0, errors.New("unsupported program code")
+}
+
+// Signed returns the count of signatures collected.
+func (pr *Program) Signed() int {
+	if contract.IsSchnorr(pr.Code) {
+		return len(pr.PartialSignatures)
+	}
+	return len(pr.Signatures)
+}
+
+// Missing returns the public keys of the signers not signed yet.
+func (pr *Program) Missing() [][]byte {
+	signers, _ := pr.Signers()
+	data := pr.Signatures
+	if contract.IsSchnorr(pr.Code) {
+		data = pr.PartialSignatures
+	}
+	missing := make([][]byte, 0)
+	for _, pk := range signers {
+		if findKeyData(data, pk) == nil {
+			missing = append(missing, pk)
+		}
+	}
+	return missing
+}
+
+// IsSigner returns if the public key is one of the signers of the program.
+func (pr *Program) IsSigner(publicKey []byte) bool {
+	signers, _ := pr.Signers()
+	for _, pk := range signers {
+		if bytes.Equal(pk, publicKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSigned returns if the signature or partial signature of the signer is
+// collected.
+func (pr *Program) IsSigned(publicKey []byte) bool {
+	if contract.IsSchnorr(pr.Code) {
+		return findKeyData(pr.PartialSignatures, publicKey) != nil
+	}
+	return findKeyData(pr.Signatures, publicKey) != nil
+}
+
+// Nonce returns the public nonce of the signer, nil if not provided.
+func (pr *Program) Nonce(publicKey []byte) []byte {
+	if d := findKeyData(pr.Nonces, publicKey); d != nil {
+		return d.Data
+	}
+	return nil
+}
+
+// AddNonce adds the public nonce of the signer of Schnorr program.  The
+// nonces can not be changed after any partial signature is made.
+func (pr *Program) AddNonce(publicKey, nonce []byte) error {
+	if !contract.IsSchnorr(pr.Code) || !pr.IsSigner(publicKey) {
+		return errors.New("not a signer of Schnorr program")
+	}
+	if pr.Nonce(publicKey) != nil {
+		return errors.New("nonce already provided")
+	}
+	if len(pr.PartialSignatures) != 0 {
+		return errors.New("partial signatures already made")
+	}
+	pr.Nonces = append(pr.Nonces, &KeyData{PublicKey: publicKey, Data: nonce})
+	return nil
+}
+
+// SetSchnorrPublicKeys sets the public keys aggregated into the Schnorr
+// program, which is added if not exists.
+func (p *Packet) SetSchnorrPublicKeys(publicKeys [][]byte) (*Program, error) {
+	aggregated, err := crypto.AggregatePublickeys(publicKeys)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := crypto.DecodePoint(aggregated)
+	if err != nil {
+		return nil, err
+	}
+	code, err := contract.CreateSchnorrRedeemScript(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	program, err := p.AddRedeemScript(code)
+	if err != nil {
+		return nil, err
+	}
+	program.PublicKeys = publicKeys
+	return program, nil
+}
+
+// Sign adds the signatures of the private key to the standard and multi-sign
+// programs it is a signer of, returns the count of signatures added.
+func (p *Packet) Sign(privateKey []byte) (int, error) {
+	publicKey, err := crypto.NewPubKey(privateKey).EncodePoint(true)
+	if err != nil {
+		return 0, err
+	}
+	data, err := p.unsignedData()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	for _, program := range p.Programs {
+		if contract.IsSchnorr(program.Code) || !program.IsSigner(publicKey) ||
+			program.IsSigned(publicKey) {
+			continue
+		}
+		required, err := program.Required()
+		if err != nil {
+			return count, err
+		}
+		if len(program.Signatures) >= required {
+			continue
+		}
+		signature, err := crypto.Sign(privateKey, data)
+		if err != nil {
+			return count, err
+		}
+		program.Signatures = append(program.Signatures,
+			&KeyData{PublicKey: publicKey, Data: signature})
+		count++
+	}
+	return count, nil
+}
+
+// SignSchnorr makes the partial signature of the Schnorr program by the
+// private key and the secret of its nonce, the nonces of all signers must be
+// provided.  The secret must not be used again.
+func (p *Packet) SignSchnorr(program *Program, privateKey,
+	secret []byte) error {
+	publicKey, err := crypto.NewPubKey(privateKey).EncodePoint(true)
+	if err != nil {
+		return err
+	}
+	if !contract.IsSchnorr(program.Code) || !program.IsSigner(publicKey) {
+		return errors.New("not a signer of Schnorr program")
+	}
+	if program.IsSigned(publicKey) {
+		return errors.New("partial signature already made")
+	}
+	nonces, err := program.orderedNonces()
+	if err != nil {
+		return err
+	}
+	data, err := p.unsignedData()
+	if err != nil {
+		return err
+	}
+
+	partial, err := crypto.SchnorrPartialSign(privateKey, secret,
+		program.Code[2:], nonces, common.Sha256D(data))
+	if err != nil {
+		return err
+	}
+	program.PartialSignatures = append(program.PartialSignatures,
+		&KeyData{PublicKey: publicKey, Data: partial})
+	return nil
+}
+
+// orderedNonces returns the nonces in the order of the public keys.
+func (pr *Program) orderedNonces() ([][]byte, error) {
+	nonces := make([][]byte, 0, len(pr.PublicKeys))
+	for _, pk := range pr.PublicKeys {
+		nonce := pr.Nonce(pk)
+		if nonce == nil {
+			return nil, errors.New("nonce of " + common.BytesToHexString(pk) +
+				" not provided")
+		}
+		nonces = append(nonces, nonce)
+	}
+	return nonces, nil
+}
+
+// importSignatures adds the signatures in the parameter of a standard or
+// multi-sign program.
+func (p *Packet) importSignatures(program *Program, parameter []byte) error {
+	if len(parameter) == 0 || contract.IsSchnorr(program.Code) {
+		return nil
+	}
+	if len(parameter)%crypto.SignatureScriptLength != 0 {
+		return errors.New("invalid signatures, length not match")
+	}
+	data, err := p.unsignedData()
+	if err != nil {
+		return err
+	}
+	signers, err := program.Signers()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(parameter); i += crypto.SignatureScriptLength {
+		signature := parameter[i+1 : i+crypto.SignatureScriptLength]
+		for _, pk := range signers {
+			if findKeyData(program.Signatures, pk) != nil {
+				continue
+			}
+			publicKey, err := crypto.DecodePoint(pk)
+			if err != nil {
+				return err
+			}
+			if crypto.Verify(*publicKey, data, signature) == nil {
+				program.Signatures = append(program.Signatures,
+					&KeyData{PublicKey: pk, Data: signature})
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Combine merges the information and signatures of the other partially
+// signed transaction of the same transaction.
+func (p *Packet) Combine(other *Packet) error {
+	data, err := p.unsignedData()
+	if err != nil {
+		return err
+	}
+	otherData, err := other.unsignedData()
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, otherData) {
+		return errors.New("transactions not match")
+	}
+	for i, input := range other.Inputs {
+		if p.Inputs[i].PreviousOutput == nil {
+			p.Inputs[i].PreviousOutput = input.PreviousOutput
+		}
+		if len(p.Inputs[i].RedeemScript) == 0 {
+			p.Inputs[i].RedeemScript = input.RedeemScript
+		}
+	}
+	for i, output := range other.Outputs {
+		if len(p.Outputs[i].RedeemScript) == 0 {
+			p.Outputs[i].RedeemScript = output.RedeemScript
+		}
+	}
+
+	for _, o := range other.Programs {
+		program := p.Program(o.Code)
+		if program == nil {
+			p.Programs = append(p.Programs, o)
+			continue
+		}
+		if len(program.PublicKeys) == 0 {
+			program.PublicKeys = o.PublicKeys
+		}
+		program.Signatures = mergeKeyData(program.Signatures, o.Signatures)
+		for _, n := range o.Nonces {
+			nonce := program.Nonce(n.PublicKey)
+			if nonce != nil && !bytes.Equal(nonce, n.Data) {
+				return errors.New("conflicting nonces of " +
+					common.BytesToHexString(n.PublicKey))
+			}
+		}
+		program.Nonces = mergeKeyData(program.Nonces, o.Nonces)
+		program.PartialSignatures = mergeKeyData(program.PartialSignatures,
+			o.PartialSignatures)
+	}
+	return nil
+}
+
+// Finalize builds the programs of the transaction from the signatures, and
+// returns the transaction if the signatures of all programs are complete and
+// valid.
+func (p *Packet) Finalize() (interfaces.Transaction, error) {
+	data, err := p.unsignedData()
+	if err != nil {
+		return nil, err
+	}
+	if len(p.Programs) == 0 {
+		return nil, errors.New("no program found in transaction")
+	}
+
+	programs := make([]*pg.Program, 0, len(p.Programs))
+	for _, program := range p.Programs {
+		required, err := program.Required()
+		if err != nil {
+			return nil, err
+		}
+		if program.Signed() < required {
+			address, _ := program.Address()
+			return nil, errors.New("signatures of " + address +
+				" not complete")
+		}
+
+		var parameter []byte
+		switch contract.GetCodeType(program.Code) {
+		case contract.Signature, contract.MultiSig:
+			buf := new(bytes.Buffer)
+			for _, s := range program.Signatures[:required] {
+				buf.WriteByte(byte(len(s.Data)))
+				buf.Write(s.Data)
+			}
+			parameter = buf.Bytes()
+			err = verifySignatures(program.Code, parameter, data)
+		case contract.Schnorr:
+			var signature [64]byte
+			signature, err = program.aggregateSignature(data)
+			parameter = signature[:]
+		}
+		if err != nil {
+			return nil, err
+		}
+		programs = append(programs, &pg.Program{
+			Code:      program.Code,
+			Parameter: parameter,
+		})
+	}
+	p.Transaction.SetPrograms(programs)
+	return p.Transaction, nil
+}
+
+func (pr *Program) aggregateSignature(data []byte) ([64]byte, error) {
+	nonces, err := pr.orderedNonces()
+	if err != nil {
+		return [64]byte{}, err
+	}
+	partials := make([][]byte, 0, len(pr.PublicKeys))
+	for _, pk := range pr.PublicKeys {
+		partials = append(partials, findKeyData(pr.PartialSignatures, pk).Data)
+	}
+	signature, err := crypto.AggregatePartialSignatures(nonces, partials)
+	if err != nil {
+		return signature, err
+	}
+
+	var publicKey [33]byte
+	copy(publicKey[:], pr.Code[2:])
+	ok, err := crypto.SchnorrVerify(publicKey, common.Sha256D(data), signature)
+	if !ok {
+		if err == nil {
+			err = errors.New("invalid aggregated signature")
+		}
+		return signature, err
+	}
+	return signature, nil
+}
+
+func verifySignatures(code, parameter, data []byte) error {
+	if contract.IsStandard(code) {
+		publicKey, err := crypto.DecodePoint(code[1 : len(code)-1])
+		if err != nil {
+			return err
+		}
+		return crypto.Verify(*publicKey, data, parameter[1:])
+	}
+	return crypto.CheckMultiSigSignatures(pg.Program{
+		Code:      code,
+		Parameter: parameter,
+	}, data)
+}
+
+// Address returns the address of the program.
+func (pr *Program) Address() (string, error) {
+	programHash, err := toProgramHash(pr.Code)
+	if err != nil {
+		return "", err
+	}
+	return programHash.ToAddress()
+}
+
+func findKeyData(data []*KeyData, publicKey []byte) *KeyData {
+	for _, d := range data {
+		if bytes.Equal(d.PublicKey, publicKey) {
+			return d
+		}
+	}
+	return nil
+}
+
+func mergeKeyData(data, other []*KeyData) []*KeyData {
+	for _, d := range other {
+		if findKeyData(data, d.PublicKey) == nil {
+			data = append(data, d)
+		}
+	}
+	return data
+}
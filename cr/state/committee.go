@@ -426,7 +426,7 @@ func (c *Committee) updateCandidatesDepositCoin(height uint32) {
 
 func (c *Committee) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 	c.mtx.Lock()
-	if block.Height < c.Params.CRConfiguration.CRVotingStartHeight {
+	if !c.Params.IsActive(config.DeploymentCRVoting, block.Height) {
 		c.mtx.Unlock()
 		return
 	}
@@ -446,7 +446,7 @@ func (c *Committee) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 	c.updateProposals(block.Height, inElectionPeriod)
 	c.updateCirculationAmount(c.committeeHistory, block.Height)
 
-	if block.Height >= c.Params.DPoSV2StartHeight {
+	if c.Params.IsActive(config.DeploymentDPoSV2, block.Height) {
 		if c.shouldEndVoting(block.Height) {
 			c.tryEndVoting(block.Height)
 		}
@@ -461,7 +461,7 @@ func (c *Committee) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 	c.updateCRInactiveStatus(c.inactiveCRHistory, block.Height)
 	c.inactiveCRHistory.Commit(block.Height)
 
-	if block.Height >= c.Params.CRConfiguration.CRCProposalWithdrawPayloadV1Height &&
+	if c.Params.IsActive(config.DeploymentCRCProposalWithdrawPayloadV1, block.Height) &&
 		len(c.manager.WithdrawableTxInfo) != 0 {
 		c.createRealWithdrawTransaction(block.Height)
 	}
@@ -476,7 +476,7 @@ func (c *Committee) ProcessBlock(block *types.Block, confirm *payload.Confirm) {
 	} else {
 		if c.CRAssetsAddressUTXOCount >=
 			c.Params.CRConfiguration.MaxCRAssetsAddressUTXOCount+c.Params.PowConfiguration.CoinbaseMaturity &&
-			block.Height >= c.Params.CRConfiguration.CRAssetsRectifyTransactionHeight {
+			c.Params.IsActive(config.DeploymentCRAssetsRectify, block.Height) {
 			c.createRectifyCRAssetsTransaction(block.Height)
 		}
 	}
@@ -506,12 +506,12 @@ func (c *Committee) checkAndSetMemberToInactive(history *utils.History, height u
 		if len(m.DPOSPublicKey) == 0 && m.MemberState == MemberElected {
 			history.Append(height, func() {
 				m.MemberState = MemberInactive
-				if height >= c.Params.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if c.Params.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					c.state.UpdateCRInactivePenalty(m.Info.CID, height)
 				}
 			}, func() {
 				m.MemberState = MemberElected
-				if height >= c.Params.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if c.Params.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					c.state.RevertUpdateCRInactivePenalty(m.Info.CID, height)
 				}
 			})
@@ -532,7 +532,9 @@ func (c *Committee) updateCRInactiveStatus(history *utils.History, height uint32
 	if c.state.CurrentSession == 0 {
 		return
 	} else if c.state.CurrentSession == 1 {
-		if height < c.Params.CRConfiguration.CRClaimDPOSNodeStartHeight+c.Params.CRConfiguration.CRClaimDPOSNodePeriod {
+		period := c.Params.CRConfiguration.CRClaimDPOSNodePeriod
+		if height < period ||
+			!c.Params.IsActive(config.DeploymentCRClaimDPOSNode, height-period) {
 			return
 		}
 		c.checkAndSetMemberToInactive(history, height)
@@ -910,7 +912,7 @@ func (c *Committee) recordCRCRelatedAddressOutputs(block *types.Block) {
 }
 
 func (c *Committee) updateCirculationAmount(history *utils.History, height uint32) {
-	if height < c.Params.DPoSV2StartHeight {
+	if !c.Params.IsActive(config.DeploymentDPoSV2, height) {
 		circulationAmount := common.Fixed64(config.OriginIssuanceAmount) +
 			common.Fixed64(height)*c.Params.GetBlockReward(height) -
 			c.CRCFoundationBalance - c.CRCCommitteeBalance - c.DestroyedAmount
@@ -939,7 +941,7 @@ func (c *Committee) calculateTotalELAByHeight(currentHeight uint32) common.Fixed
 	height2 := c.Params.HalvingRewardInterval
 
 	heights := make([]uint32, 0)
-	if currentHeight >= height0 {
+	if c.Params.IsActive(config.DeploymentNewELAIssuance, currentHeight) {
 		heights = append(heights, height0)
 	}
 	for i := uint32(0); ; i++ {
@@ -976,7 +978,7 @@ func (c *Committee) recordLastVotingStartHeight(height uint32) {
 }
 
 func (c *Committee) getNextVotingStartHeight(height uint32) uint32 {
-	if height >= c.Params.DPoSV2StartHeight {
+	if c.Params.IsActive(config.DeploymentDPoSV2, height) {
 		return c.LastCommitteeHeight + c.Params.CRConfiguration.DutyPeriod -
 			c.Params.CRConfiguration.VotingPeriod - c.Params.CRConfiguration.CRClaimPeriod - 1
 	}
@@ -1234,7 +1236,7 @@ func (c *Committee) processCRCouncilMemberClaimNode(tx interfaces.Transaction,
 	height uint32, history *utils.History) {
 	claimNodePayload := tx.Payload().(*payload.CRCouncilMemberClaimNode)
 	var cr *CRMember
-	if height >= c.Params.DPoSV2StartHeight {
+	if c.Params.IsActive(config.DeploymentDPoSV2, height) {
 		switch tx.PayloadVersion() {
 		case payload.CurrentCRClaimDPoSNodeVersion:
 			cr = c.getMember(claimNodePayload.CRCouncilCommitteeDID)
@@ -1381,7 +1383,7 @@ func (c *Committee) shouldEndVoting(height uint32) bool {
 
 func (c *Committee) shouldChangeCommittee(height uint32) bool {
 	if c.LastCommitteeHeight == 0 {
-		if height < c.Params.CRConfiguration.CRCommitteeStartHeight {
+		if !c.Params.IsActive(config.DeploymentCRCommittee, height) {
 			return false
 		} else if height == c.Params.CRConfiguration.CRCommitteeStartHeight {
 			return true
@@ -1392,7 +1394,7 @@ func (c *Committee) shouldChangeCommittee(height uint32) bool {
 		return height == c.LastCommitteeHeight+c.Params.CRConfiguration.DutyPeriod
 	}
 
-	if height >= c.Params.DPoSV2StartHeight {
+	if c.Params.IsActive(config.DeploymentDPoSV2, height) {
 		return height == c.LastCommitteeHeight+c.Params.CRConfiguration.DutyPeriod ||
 			height == c.LastVotingStartHeight+c.Params.CRConfiguration.VotingPeriod+c.Params.CRConfiguration.CRClaimPeriod
 	}
@@ -1401,7 +1403,7 @@ func (c *Committee) shouldChangeCommittee(height uint32) bool {
 }
 
 func (c *Committee) shouldCleanHistory(height uint32) bool {
-	if height >= c.Params.DPoSV2StartHeight {
+	if c.Params.IsActive(config.DeploymentDPoSV2, height) {
 		return c.LastVotingStartHeight == c.LastCommitteeHeight+
 			c.Params.CRConfiguration.DutyPeriod-c.Params.CRConfiguration.VotingPeriod-c.Params.CRConfiguration.CRClaimPeriod
 	}
@@ -1413,17 +1415,17 @@ func (c *Committee) shouldCleanHistory(height uint32) bool {
 func (c *Committee) isInVotingPeriod(height uint32) bool {
 	//todo consider emergency election later
 	inVotingPeriod := func(committeeUpdateHeight uint32) bool {
-		if height >= c.Params.DPoSV2StartHeight {
+		if c.Params.IsActive(config.DeploymentDPoSV2, height) {
 			return height >= c.LastVotingStartHeight &&
 				height < c.LastVotingStartHeight+c.Params.CRConfiguration.VotingPeriod
 		}
 		return height >= committeeUpdateHeight-c.Params.CRConfiguration.VotingPeriod &&
 			height < committeeUpdateHeight
 	}
-	if c.LastCommitteeHeight < c.Params.CRConfiguration.CRCommitteeStartHeight &&
-		height <= c.Params.CRConfiguration.CRCommitteeStartHeight {
-		return height >= c.Params.CRConfiguration.CRVotingStartHeight &&
-			height < c.Params.CRConfiguration.CRCommitteeStartHeight
+	if !c.Params.IsActive(config.DeploymentCRCommittee, c.LastCommitteeHeight) &&
+		!c.Params.IsActiveAfter(config.DeploymentCRCommittee, height) {
+		return c.Params.IsActive(config.DeploymentCRVoting, height) &&
+			!c.Params.IsActive(config.DeploymentCRCommittee, height)
 	} else {
 		if !c.InElectionPeriod {
 			if c.LastVotingStartHeight == 0 {
@@ -1436,7 +1438,7 @@ func (c *Committee) isInVotingPeriod(height uint32) bool {
 }
 
 func (c *Committee) isInClaimPeriod(height uint32) bool {
-	if height >= c.Params.DPoSV2StartHeight {
+	if c.Params.IsActive(config.DeploymentDPoSV2, height) {
 		return height >= c.LastVotingStartHeight+c.Params.CRConfiguration.VotingPeriod &&
 			height <= c.LastVotingStartHeight+c.Params.CRConfiguration.VotingPeriod+c.Params.CRConfiguration.CRClaimPeriod
 	}
@@ -1480,7 +1482,7 @@ func (c *Committee) changeCommitteeMembers(height uint32) error {
 		c.processCurrentMembersDepositInfo(height)
 	}
 
-	if height >= c.Params.DPoSV2StartHeight {
+	if c.Params.IsActive(config.DeploymentDPoSV2, height) {
 
 		// if no next CR members, need to change InElectionPeriod to false
 		if len(c.NextMembers) == 0 {
@@ -2043,7 +2045,7 @@ func (c *Committee) TryUpdateCRMemberInactivity(did common.Uint168,
 		return
 	}
 
-	if height < c.Params.CRConfiguration.ChangeCommitteeNewCRHeight {
+	if !c.Params.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 		if needReset {
 			crMember.InactiveCountingHeight = 0
 			return
@@ -2073,7 +2075,7 @@ func (c *Committee) TryUpdateCRMemberInactivity(did common.Uint168,
 				"changed to inactive", "InactiveCount:", crMember.InactiveCount,
 				"MaxInactiveRounds:", c.Params.DPoSConfiguration.MaxInactiveRounds)
 			crMember.MemberState = MemberInactive
-			if height >= c.Params.CRConfiguration.ChangeCommitteeNewCRHeight {
+			if c.Params.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 				c.state.UpdateCRInactivePenalty(crMember.Info.CID, height)
 			}
 			crMember.InactiveCount = 0
@@ -2103,7 +2105,7 @@ func (c *Committee) TryRevertCRMemberInactivity(did common.Uint168,
 		return
 	}
 
-	if height < c.Params.CRConfiguration.ChangeCommitteeNewCRHeight {
+	if !c.Params.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 		crMember.MemberState = oriState
 		crMember.InactiveCountingHeight = oriInactiveCount
 	} else {
@@ -12,6 +12,7 @@ import (
 	"sort"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
 	"github.com/elastos/Elastos.ELA/core/types/interfaces"
@@ -79,7 +80,7 @@ func SortTransactions(txs []interfaces.Transaction) {
 func (c *Committee) checkWithdrawAndInactiveCR(txs []interfaces.Transaction,
 	height uint32) {
 
-	if height < c.Params.CrossChainMonitorStartHeight {
+	if !c.Params.IsActive(config.DeploymentCrossChainMonitor, height) {
 		return
 	}
 
@@ -139,12 +140,12 @@ func (c *Committee) inactiveMembersByWithdrawKeys(height uint32,
 			c.state.History.Append(height, func() {
 				member.MemberState = MemberInactive
 				log.Infof("[checkWithdrawAndInactiveCR] Set %s to inactive", member.Info.NickName)
-				if height >= c.Params.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if c.Params.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					c.state.UpdateCRInactivePenalty(member.Info.CID, height)
 				}
 			}, func() {
 				member.MemberState = MemberElected
-				if height >= c.Params.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if c.Params.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					c.state.RevertUpdateCRInactivePenalty(member.Info.CID, height)
 				}
 			})
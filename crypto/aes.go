@@ -8,6 +8,7 @@ package crypto
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/rand"
 	"crypto/sha256"
 	"errors"
 )
@@ -55,3 +56,46 @@ func AesDecrypt(cipherText []byte, key []byte, iv []byte) ([]byte, error) {
 
 	return plaintext, nil
 }
+
+// AesGCMEncrypt encrypts the plaintext with AES-GCM, a random nonce is
+// generated and put before the cipher text.
+func AesGCMEncrypt(plaintext []byte, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.New("invalid encrypt key")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// AesGCMDecrypt decrypts and authenticates the data encrypted by
+// AesGCMEncrypt, an error is returned if the key is wrong or the data has
+// been modified.
+func AesGCMDecrypt(data []byte, key []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.New("invalid decrypt key")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, errors.New("cipherText too short")
+	}
+	nonce, cipherText := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, cipherText, nil)
+	if err != nil {
+		return nil, errors.New("decrypt failed, wrong key or corrupted data")
+	}
+	return plaintext, nil
+}
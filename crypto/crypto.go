@@ -72,6 +72,8 @@ func SignDigest(priKey []byte, digest []byte) ([]byte, error) {
 	privateKey.Curve = DefaultCurve
 	privateKey.D = big.NewInt(0)
 	privateKey.D.SetBytes(priKey)
+	privateKey.PublicKey.X, privateKey.PublicKey.Y =
+		DefaultCurve.ScalarBaseMult(priKey)
 
 	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest)
 	if err != nil {
@@ -117,6 +119,8 @@ func Sign(priKey []byte, data []byte) ([]byte, error) {
 	privateKey.Curve = DefaultCurve
 	privateKey.D = big.NewInt(0)
 	privateKey.D.SetBytes(priKey)
+	privateKey.PublicKey.X, privateKey.PublicKey.Y =
+		DefaultCurve.ScalarBaseMult(priKey)
 
 	r, s, err := ecdsa.Sign(rand.Reader, privateKey, digest[:])
 	if err != nil {
@@ -0,0 +1,159 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key.
+	HardenedKeyStart = uint32(0x80000000)
+
+	// CoinTypeELA is the registered BIP44 coin type of ELA.
+	CoinTypeELA = 2305
+
+	// DefaultAccountPath is the BIP44 path of the first ELA account, address
+	// keys are derived as its external chain children.
+	DefaultAccountPath = "m/44'/2305'/0'"
+)
+
+var (
+	masterKeySalt = []byte("Bitcoin seed")
+
+	// ErrInvalidChildKey is returned if the derived key is invalid, which
+	// is very unlikely, the next index should be used in this case.
+	ErrInvalidChildKey = errors.New("invalid child key, use next index")
+)
+
+// ExtendedKey is a private key together with its chain code, that child keys
+// can be derived from in the BIP32 way on the default curve.
+type ExtendedKey struct {
+	PrivateKey []byte
+	ChainCode  []byte
+}
+
+// NewMasterKey creates the master extended key from the seed.
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, errors.New("invalid seed length")
+	}
+
+	mac := hmac.New(sha512.New, masterKeySalt)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	key := new(big.Int).SetBytes(sum[:32])
+	if key.Sign() == 0 || key.Cmp(DefaultParams.N) >= 0 {
+		return nil, errors.New("invalid seed, unusable master key")
+	}
+	return &ExtendedKey{
+		PrivateKey: paddedKey(key),
+		ChainCode:  sum[32:],
+	}, nil
+}
+
+// Child derives the child extended key of the given index, index from
+// HardenedKeyStart derives a hardened child.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	if len(k.ChainCode) != 32 {
+		return nil, errors.New("invalid chain code, length not equal to 32")
+	}
+
+	var data []byte
+	if index >= HardenedKeyStart {
+		data = append([]byte{0}, paddedKey(new(big.Int).SetBytes(k.PrivateKey))...)
+	} else {
+		publicKey, err := NewPubKey(k.PrivateKey).EncodePoint(true)
+		if err != nil {
+			return nil, err
+		}
+		data = publicKey
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+
+	mac := hmac.New(sha512.New, k.ChainCode)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(DefaultParams.N) >= 0 {
+		return nil, ErrInvalidChildKey
+	}
+	key := new(big.Int).SetBytes(k.PrivateKey)
+	key.Add(key, tweak)
+	key.Mod(key, DefaultParams.N)
+	if key.Sign() == 0 {
+		return nil, ErrInvalidChildKey
+	}
+	return &ExtendedKey{
+		PrivateKey: paddedKey(key),
+		ChainCode:  sum[32:],
+	}, nil
+}
+
+// Derive derives the extended key of the path relative to this key, such as
+// "m/44'/2305'/0'/0/1", path components ending with ' or h are hardened.
+func (k *ExtendedKey) Derive(path string) (*ExtendedKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indexes {
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PublicKey returns the public key of the extended key.
+func (k *ExtendedKey) PublicKey() *PublicKey {
+	return NewPubKey(k.PrivateKey)
+}
+
+// ParseDerivationPath parses the path into child indexes.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	components := strings.Split(strings.TrimSpace(path), "/")
+	if len(components) == 0 || components[0] != "m" {
+		return nil, fmt.Errorf("invalid derivation path %s, must start"+
+			" with m", path)
+	}
+
+	indexes := make([]uint32, 0, len(components)-1)
+	for _, c := range components[1:] {
+		hardened := strings.HasSuffix(c, "'") || strings.HasSuffix(c, "h")
+		if hardened {
+			c = c[:len(c)-1]
+		}
+		index, err := strconv.ParseUint(c, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, fmt.Errorf("invalid derivation path %s", path)
+		}
+		if hardened {
+			index += uint64(HardenedKeyStart)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+func paddedKey(key *big.Int) []byte {
+	padded := make([]byte, 32)
+	b := key.Bytes()
+	copy(padded[32-len(b):], b)
+	return padded
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDerivationPath(t *testing.T) {
+	indexes, err := ParseDerivationPath("m/44'/2305'/0'/0/1")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{HardenedKeyStart + 44,
+		HardenedKeyStart + CoinTypeELA, HardenedKeyStart, 0, 1}, indexes)
+
+	indexes, err = ParseDerivationPath("m/1h/2")
+	assert.NoError(t, err)
+	assert.Equal(t, []uint32{HardenedKeyStart + 1, 2}, indexes)
+
+	indexes, err = ParseDerivationPath("m")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(indexes))
+
+	for _, path := range []string{"", "44'/0", "m/a", "m/2147483648", "m//1"} {
+		_, err = ParseDerivationPath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestExtendedKey_Derive(t *testing.T) {
+	seed, err := NewSeed("abandon abandon abandon abandon abandon abandon "+
+		"abandon abandon abandon abandon abandon about", "")
+	assert.NoError(t, err)
+	master, err := NewMasterKey(seed)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, len(master.PrivateKey))
+	assert.Equal(t, 32, len(master.ChainCode))
+
+	// derivation is deterministic
+	account, err := master.Derive(DefaultAccountPath)
+	assert.NoError(t, err)
+	key1, err := account.Derive("m/0/0")
+	assert.NoError(t, err)
+	key2, err := master.Derive(DefaultAccountPath + "/0/0")
+	assert.NoError(t, err)
+	assert.Equal(t, key1, key2)
+
+	// different indexes derive different keys
+	key3, err := account.Derive("m/0/1")
+	assert.NoError(t, err)
+	assert.NotEqual(t, key1.PrivateKey, key3.PrivateKey)
+	hardened, err := account.Child(HardenedKeyStart)
+	assert.NoError(t, err)
+	normal, err := account.Child(0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hardened.PrivateKey, normal.PrivateKey)
+
+	// public key matches the private key
+	x, y := DefaultCurve.ScalarBaseMult(key1.PrivateKey)
+	assert.Equal(t, &PublicKey{X: x, Y: y}, key1.PublicKey())
+
+	_, err = NewMasterKey([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
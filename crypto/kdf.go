@@ -0,0 +1,56 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package crypto
+
+import (
+	"crypto/rand"
+	"errors"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// KDFScrypt is the name of the scrypt key derivation function.
+	KDFScrypt = "scrypt"
+
+	// SaltLength is the length of the random salt used to derive keys.
+	SaltLength = 32
+)
+
+// ScryptParams is the parameters of scrypt key derivation.
+type ScryptParams struct {
+	N      int
+	R      int
+	P      int
+	KeyLen int
+}
+
+// DefaultScryptParams costs 64MB memory and about half a second to derive
+// a key, which makes brute forcing the password expensive.
+var DefaultScryptParams = ScryptParams{
+	N:      1 << 16,
+	R:      8,
+	P:      1,
+	KeyLen: 32,
+}
+
+// NewSalt generates a random salt for key derivation.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// ScryptKey derives the key from the password by scrypt.
+func ScryptKey(password, salt []byte, params ScryptParams) ([]byte, error) {
+	if len(salt) == 0 {
+		return nil, errors.New("empty salt")
+	}
+	return scrypt.Key(password, salt, params.N, params.R, params.P,
+		params.KeyLen)
+}
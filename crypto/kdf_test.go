@@ -0,0 +1,57 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScryptKey(t *testing.T) {
+	params := ScryptParams{N: 1 << 10, R: 8, P: 1, KeyLen: 32}
+	salt, err := NewSalt()
+	assert.NoError(t, err)
+	key1, err := ScryptKey([]byte("password"), salt, params)
+	assert.NoError(t, err)
+	assert.Equal(t, 32, len(key1))
+	key2, err := ScryptKey([]byte("password"), salt, params)
+	assert.NoError(t, err)
+	assert.Equal(t, key1, key2)
+
+	salt2, err := NewSalt()
+	assert.NoError(t, err)
+	key3, err := ScryptKey([]byte("password"), salt2, params)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key1, key3)
+
+	_, err = ScryptKey([]byte("password"), nil, params)
+	assert.Error(t, err)
+}
+
+func TestAesGCM(t *testing.T) {
+	key := make([]byte, 32)
+	plaintext := []byte("elastos keystore")
+	encrypted, err := AesGCMEncrypt(plaintext, key)
+	assert.NoError(t, err)
+	decrypted, err := AesGCMDecrypt(encrypted, key)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+
+	// wrong key
+	wrongKey := make([]byte, 32)
+	wrongKey[0] = 1
+	_, err = AesGCMDecrypt(encrypted, wrongKey)
+	assert.Error(t, err)
+
+	// modified data
+	encrypted[len(encrypted)-1] ^= 1
+	_, err = AesGCMDecrypt(encrypted, key)
+	assert.Error(t, err)
+
+	_, err = AesGCMDecrypt(encrypted[:10], key)
+	assert.Error(t, err)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// DefaultEntropyBits is the entropy size of a 12 words mnemonic.
+	DefaultEntropyBits = 128
+
+	// SeedLength is the length of the seed generated from a mnemonic.
+	SeedLength = 64
+
+	seedIterations = 2048
+)
+
+var wordIndexes = func() map[string]int {
+	indexes := make(map[string]int, len(englishWords))
+	for i, w := range englishWords {
+		indexes[w] = i
+	}
+	return indexes
+}()
+
+// NewEntropy generates random entropy for a mnemonic, the size in bits must
+// be a multiple of 32 between 128 and 256.
+func NewEntropy(bits int) ([]byte, error) {
+	if err := checkEntropyBits(bits); err != nil {
+		return nil, err
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+func checkEntropyBits(bits int) error {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return errors.New("entropy size must be a multiple of 32 between" +
+			" 128 and 256 bits")
+	}
+	return nil
+}
+
+// NewMnemonic encodes the entropy into a BIP39 mnemonic sentence.
+func NewMnemonic(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if err := checkEntropyBits(bits); err != nil {
+		return "", err
+	}
+
+	// Append the checksum, which is the first bits/32 bits of the hash.
+	checksumBits := uint(bits / 32)
+	hash := sha256.Sum256(entropy)
+	data := new(big.Int).SetBytes(entropy)
+	data.Lsh(data, checksumBits)
+	data.Or(data, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	count := (bits + int(checksumBits)) / 11
+	words := make([]string, count)
+	mask := big.NewInt(2047)
+	index := new(big.Int)
+	for i := count - 1; i >= 0; i-- {
+		index.And(data, mask)
+		words[i] = englishWords[index.Int64()]
+		data.Rsh(data, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes the mnemonic sentence and verifies its checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	count := len(words)
+	if count < 12 || count > 24 || count%3 != 0 {
+		return nil, errors.New("invalid mnemonic, word count must be one" +
+			" of 12, 15, 18, 21 and 24")
+	}
+
+	data := new(big.Int)
+	for _, w := range words {
+		index, ok := wordIndexes[w]
+		if !ok {
+			return nil, errors.New("invalid mnemonic, unknown word " + w)
+		}
+		data.Lsh(data, 11)
+		data.Or(data, big.NewInt(int64(index)))
+	}
+
+	checksumBits := uint(count / 3)
+	checksum := new(big.Int).And(data,
+		big.NewInt(int64(1<<checksumBits-1))).Int64()
+	data.Rsh(data, checksumBits)
+
+	entropy := make([]byte, (count*11-int(checksumBits))/8)
+	dataBytes := data.Bytes()
+	copy(entropy[len(entropy)-len(dataBytes):], dataBytes)
+
+	hash := sha256.Sum256(entropy)
+	if int64(hash[0]>>(8-checksumBits)) != checksum {
+		return nil, errors.New("invalid mnemonic, checksum mismatch")
+	}
+	return entropy, nil
+}
+
+// NewSeed generates the seed from the mnemonic sentence and the optional
+// passphrase, the mnemonic is validated before use.
+func NewSeed(mnemonic, passphrase string) ([]byte, error) {
+	if _, err := MnemonicToEntropy(mnemonic); err != nil {
+		return nil, err
+	}
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase),
+		seedIterations, SeedLength, sha512.New), nil
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package crypto
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMnemonic(t *testing.T) {
+	// test vectors from BIP39
+	vectors := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon " +
+				"abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e5349553" +
+				"1f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal " +
+				"winner thank yellow",
+			"",
+		},
+		{
+			"ffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+			"",
+		},
+	}
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		mnemonic, err := NewMnemonic(entropy)
+		assert.NoError(t, err)
+		assert.Equal(t, v.mnemonic, mnemonic)
+
+		decoded, err := MnemonicToEntropy(mnemonic)
+		assert.NoError(t, err)
+		assert.Equal(t, entropy, decoded)
+
+		if v.seed != "" {
+			seed, err := NewSeed(mnemonic, "TREZOR")
+			assert.NoError(t, err)
+			assert.Equal(t, v.seed, hex.EncodeToString(seed))
+		}
+	}
+}
+
+func TestMnemonic_Random(t *testing.T) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		entropy, err := NewEntropy(bits)
+		assert.NoError(t, err)
+		mnemonic, err := NewMnemonic(entropy)
+		assert.NoError(t, err)
+		assert.Equal(t, bits/32*3, len(strings.Fields(mnemonic)))
+
+		decoded, err := MnemonicToEntropy(mnemonic)
+		assert.NoError(t, err)
+		assert.True(t, bytes.Equal(entropy, decoded))
+	}
+
+	_, err := NewEntropy(100)
+	assert.Error(t, err)
+}
+
+func TestMnemonic_Invalid(t *testing.T) {
+	// wrong checksum
+	_, err := MnemonicToEntropy("abandon abandon abandon abandon abandon " +
+		"abandon abandon abandon abandon abandon abandon abandon")
+	assert.Error(t, err)
+
+	// unknown word
+	_, err = MnemonicToEntropy("abandon abandon abandon abandon abandon " +
+		"abandon abandon abandon abandon abandon abandon elastos")
+	assert.Error(t, err)
+
+	// wrong word count
+	_, err = MnemonicToEntropy("abandon abandon abandon about")
+	assert.Error(t, err)
+
+	_, err = NewSeed("zoo zoo zoo", "")
+	assert.Error(t, err)
+}
@@ -7,6 +7,7 @@ package crypto
 
 import (
 	"crypto/elliptic"
+	crand "crypto/rand"
 	"crypto/sha256"
 	"errors"
 	"math/big"
@@ -201,6 +202,88 @@ func AggregateSignatures(privateKeys []*big.Int, message [32]byte) ([64]byte, er
 	return sig, nil
 }
 
+// SchnorrNonce generates the secret nonce of a signer taking part in an
+// aggregated signature, and the public nonce to share with the other signers.
+// The secret nonce must be used to sign only once.
+func SchnorrNonce() (secret []byte, nonce []byte, err error) {
+	k := make([]byte, 32)
+	for {
+		if _, err := crand.Read(k); err != nil {
+			return nil, nil, err
+		}
+		k0 := new(big.Int).SetBytes(k)
+		if k0.Sign() > 0 && k0.Cmp(N) < 0 {
+			break
+		}
+	}
+	Rx, Ry := Curve.ScalarBaseMult(k)
+	return k, Marshal(Curve, Rx, Ry), nil
+}
+
+// sumPoints adds up the compressed points.
+func sumPoints(points [][]byte) (x, y *big.Int, err error) {
+	x, y = new(big.Int), new(big.Int)
+	for _, point := range points {
+		px, py := Unmarshal(Curve, point)
+		if px == nil || py == nil || !Curve.IsOnCurve(px, py) {
+			return nil, nil, errors.New("invalid point")
+		}
+		x, y = Curve.Add(x, y, px, py)
+	}
+	return x, y, nil
+}
+
+// SchnorrPartialSign signs the message with one of the private keys of the
+// aggregated public key, by the secret nonce of the signer and the public
+// nonces of all signers.  The partial signatures of all signers are combined
+// by AggregatePartialSignatures.
+func SchnorrPartialSign(privateKey, secret, publicKey []byte,
+	nonces [][]byte, message [32]byte) ([]byte, error) {
+	d := new(big.Int).SetBytes(privateKey)
+	if d.Sign() <= 0 || d.Cmp(N) >= 0 {
+		return nil, errors.New("the private key must be an integer in the range 1..n-1")
+	}
+	k0 := new(big.Int).SetBytes(secret)
+	if k0.Sign() <= 0 || k0.Cmp(N) >= 0 {
+		return nil, errors.New("the secret nonce must be an integer in the range 1..n-1")
+	}
+	Px, Py := Unmarshal(Curve, publicKey)
+	if Px == nil || Py == nil || !Curve.IsOnCurve(Px, Py) {
+		return nil, errors.New("invalid public key")
+	}
+	Rx, Ry, err := sumPoints(nonces)
+	if err != nil {
+		return nil, err
+	}
+
+	e := getE(Px, Py, intToByte(Rx), message)
+	s := getK(Ry, k0)
+	s.Add(s, new(big.Int).Mul(e, d))
+	return intToByte(s.Mod(s, N)), nil
+}
+
+// AggregatePartialSignatures combines the partial signatures made by
+// SchnorrPartialSign into one signature of the aggregated public key.
+func AggregatePartialSignatures(nonces [][]byte,
+	partials [][]byte) ([64]byte, error) {
+	sig := [64]byte{}
+	if len(nonces) == 0 || len(nonces) != len(partials) {
+		return sig, errors.New("nonces and partial signatures not match")
+	}
+	Rx, _, err := sumPoints(nonces)
+	if err != nil {
+		return sig, err
+	}
+	s := new(big.Int)
+	for _, partial := range partials {
+		s.Add(s, new(big.Int).SetBytes(partial))
+	}
+
+	copy(sig[:32], intToByte(Rx))
+	copy(sig[32:], intToByte(s.Mod(s, N)))
+	return sig, nil
+}
+
 func randomBytes(len int) []byte {
 	a := make([]byte, len)
 	rand.Read(a)
@@ -0,0 +1,69 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package crypto
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchnorrPartialSign(t *testing.T) {
+	message := sha256.Sum256([]byte("message"))
+	for count := 1; count <= 4; count++ {
+		privateKeys := make([][]byte, 0, count)
+		publicKeys := make([][]byte, 0, count)
+		for i := 0; i < count; i++ {
+			privateKey, publicKey, err := GenerateKeyPair()
+			assert.NoError(t, err)
+			pk, err := publicKey.EncodePoint(true)
+			assert.NoError(t, err)
+			privateKeys = append(privateKeys, privateKey)
+			publicKeys = append(publicKeys, pk)
+		}
+		publicKey, err := AggregatePublickeys(publicKeys)
+		assert.NoError(t, err)
+
+		// Round one, every signer shares a public nonce.
+		secrets := make([][]byte, 0, count)
+		nonces := make([][]byte, 0, count)
+		for i := 0; i < count; i++ {
+			secret, nonce, err := SchnorrNonce()
+			assert.NoError(t, err)
+			secrets = append(secrets, secret)
+			nonces = append(nonces, nonce)
+		}
+
+		// Round two, every signer makes a partial signature.
+		partials := make([][]byte, 0, count)
+		for i := 0; i < count; i++ {
+			partial, err := SchnorrPartialSign(privateKeys[i], secrets[i],
+				publicKey, nonces, message)
+			assert.NoError(t, err)
+			partials = append(partials, partial)
+		}
+
+		signature, err := AggregatePartialSignatures(nonces, partials)
+		assert.NoError(t, err)
+		pk := [33]byte{}
+		copy(pk[:], publicKey)
+		ok, err := SchnorrVerify(pk, message, signature)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		// A missing partial signature makes the signature invalid.
+		if count > 1 {
+			signature, err = AggregatePartialSignatures(nonces[1:], partials[1:])
+			assert.NoError(t, err)
+			ok, _ = SchnorrVerify(pk, message, signature)
+			assert.False(t, ok)
+		}
+	}
+
+	_, err := AggregatePartialSignatures(nil, nil)
+	assert.Error(t, err)
+}
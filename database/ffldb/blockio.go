@@ -16,6 +16,7 @@ import (
 	"fmt"
 	"hash/crc32"
 	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"sync"
@@ -172,6 +173,12 @@ type blockStore struct {
 	// new blocks are written to.
 	writeCursor *writeCursor
 
+	// firstFileNum is the number of the oldest flat block file which still
+	// exists on disk.  All files before it have been removed by pruning.
+	// It is protected by pruneMutex.
+	pruneMutex   sync.RWMutex
+	firstFileNum uint32
+
 	// These functions are set to openFile, openWriteFile, and deleteFile by
 	// default, but are exposed here to allow the whitebox tests to replace
 	// them when working with mock files.
@@ -187,6 +194,15 @@ type blockLocation struct {
 	blockLen     uint32
 }
 
+// prunedBlockLoc is the location stored for blocks whose data has never been
+// written to the flat files, such as the blocks before the height of a
+// snapshot the database was bootstrapped from.  They are treated as pruned.
+var prunedBlockLoc = blockLocation{
+	blockFileNum: math.MaxUint32,
+	fileOffset:   math.MaxUint32,
+	blockLen:     math.MaxUint32,
+}
+
 // deserializeBlockLoc deserializes the passed serialized block location
 // information.  This is data stored into the block index metadata for each
 // block.  The serialized data passed to this function MUST be at least
@@ -504,12 +520,19 @@ func (s *blockStore) writeBlock(rawBlock []byte) (blockLocation, error) {
 // and closing files as necessary to stay within the maximum allowed open files
 // limit.
 //
-// Returns ErrDriverSpecific if the data fails to read for any reason and
-// ErrCorruption if the checksum of the read data doesn't match the checksum
+// Returns ErrBlockPruned if the block file has been pruned, ErrDriverSpecific
+// if the data fails to read for any reason and ErrCorruption if the checksum of the read data doesn't match the checksum
 // read from the file.
 //
 // Format: <network><block length><serialized block><checksum>
 func (s *blockStore) readBlock(hash *common.Uint256, loc blockLocation) ([]byte, error) {
+	// Data which lives in a pruned block file is no longer available.
+	if s.isPruned(loc) {
+		str := fmt.Sprintf("block %s has been pruned from file %d", hash,
+			loc.blockFileNum)
+		return nil, makeDbErr(database.ErrBlockPruned, str, nil)
+	}
+
 	// Get the referenced block file handle opening the file as needed.  The
 	// function also handles closing files as needed to avoid going over the
 	// max allowed open files.
@@ -564,8 +587,16 @@ func (s *blockStore) readBlock(hash *common.Uint256, loc blockLocation) ([]byte,
 // closing files as necessary to stay within the maximum allowed open files
 // limit.
 //
-// Returns ErrDriverSpecific if the data fails to read for any reason.
+// Returns ErrBlockPruned if the block file has been pruned and
+// ErrDriverSpecific if the data fails to read for any reason.
 func (s *blockStore) readBlockRegion(loc blockLocation, offset, numBytes uint32) ([]byte, error) {
+	// Data which lives in a pruned block file is no longer available.
+	if s.isPruned(loc) {
+		str := fmt.Sprintf("block file %d has been pruned",
+			loc.blockFileNum)
+		return nil, makeDbErr(database.ErrBlockPruned, str, nil)
+	}
+
 	// Get the referenced block file handle opening the file as needed.  The
 	// function also handles closing files as needed to avoid going over the
 	// max allowed open files.
@@ -716,15 +747,155 @@ func (s *blockStore) handleRollback(oldBlockFileNum, oldBlockOffset uint32) {
 	}
 }
 
+// isPruned returns whether the data at the passed block location has been
+// removed from disk by pruning or has never been stored.
+func (s *blockStore) isPruned(loc blockLocation) bool {
+	if loc == prunedBlockLoc {
+		return true
+	}
+
+	s.pruneMutex.RLock()
+	defer s.pruneMutex.RUnlock()
+	return loc.blockFileNum < s.firstFileNum
+}
+
+// closeFile closes the read-only handle of the passed flat file number if it is
+// currently open and removes it from the least recently used tracking.
+func (s *blockStore) closeFile(fileNum uint32) {
+	s.obfMutex.Lock()
+	defer s.obfMutex.Unlock()
+
+	blockFile, ok := s.openBlockFiles[fileNum]
+	if !ok {
+		return
+	}
+
+	s.lruMutex.Lock()
+	s.openBlocksLRU.Remove(s.fileNumToLRUElem[fileNum])
+	delete(s.fileNumToLRUElem, fileNum)
+	s.lruMutex.Unlock()
+
+	// Close the file under the write lock for the file in case any readers
+	// are currently reading from it so it's not closed out from under them.
+	blockFile.Lock()
+	_ = blockFile.file.Close()
+	blockFile.Unlock()
+
+	delete(s.openBlockFiles, fileNum)
+}
+
+// filesSize returns the total size of the flat block files which currently
+// exist on disk.
+func (s *blockStore) filesSize() uint64 {
+	s.pruneMutex.RLock()
+	firstFileNum := s.firstFileNum
+	s.pruneMutex.RUnlock()
+
+	wc := s.writeCursor
+	wc.RLock()
+	curFileNum := wc.curFileNum
+	curOffset := wc.curOffset
+	wc.RUnlock()
+
+	totalSize := uint64(curOffset)
+	for fileNum := firstFileNum; fileNum < curFileNum; fileNum++ {
+		st, err := os.Stat(blockFilePath(s.basePath, fileNum))
+		if err != nil {
+			continue
+		}
+		totalSize += uint64(st.Size())
+	}
+	return totalSize
+}
+
+// pruneFiles removes the oldest flat block files from disk until the total size
+// of the remaining files no longer exceeds targetSize.  The file identified by
+// keepFileNum, any files after it and the current write file are never
+// removed.  It returns the number of bytes freed.
+//
+// NOTE: This function MUST be called with the database write lock held so the
+// write cursor can't be moved while files are being removed.
+func (s *blockStore) pruneFiles(targetSize uint64, keepFileNum uint32) (uint64, error) {
+	wc := s.writeCursor
+	wc.RLock()
+	if keepFileNum > wc.curFileNum {
+		keepFileNum = wc.curFileNum
+	}
+	wc.RUnlock()
+
+	totalSize := s.filesSize()
+	freed := uint64(0)
+	for totalSize > targetSize {
+		s.pruneMutex.Lock()
+		fileNum := s.firstFileNum
+		if fileNum >= keepFileNum {
+			s.pruneMutex.Unlock()
+			break
+		}
+
+		// Mark the file as pruned before removing it, so readers get
+		// ErrBlockPruned instead of a failure to open the file.
+		s.firstFileNum = fileNum + 1
+		s.pruneMutex.Unlock()
+
+		filePath := blockFilePath(s.basePath, fileNum)
+		fileSize := uint64(0)
+		if st, err := os.Stat(filePath); err == nil {
+			fileSize = uint64(st.Size())
+		}
+
+		s.closeFile(fileNum)
+		if err := s.deleteFileFunc(fileNum); err != nil {
+			return freed, err
+		}
+		log.Debugf("Pruned block file #%d with length %d", fileNum,
+			fileSize)
+
+		totalSize -= fileSize
+		freed += fileSize
+	}
+
+	return freed, nil
+}
+
+// scanFirstBlockFile searches the database directory for the oldest flat block
+// file.  Block files before it might have been removed by pruning, so it is the
+// file scanning for the current write cursor starts from.
+func scanFirstBlockFile(dbPath string) uint32 {
+	pattern := filepath.Join(dbPath, "*"+filepath.Ext(blockFilenameTemplate))
+	filePaths, err := filepath.Glob(pattern)
+	if err != nil {
+		return 0
+	}
+
+	firstFile := uint32(0)
+	found := false
+	for _, filePath := range filePaths {
+		var fileNum uint32
+		_, err := fmt.Sscanf(filepath.Base(filePath), blockFilenameTemplate,
+			&fileNum)
+		if err != nil {
+			continue
+		}
+		if !found || fileNum < firstFile {
+			firstFile = fileNum
+			found = true
+		}
+	}
+
+	return firstFile
+}
+
 // scanBlockFiles searches the database directory for all flat block files to
 // find the end of the most recent file.  This position is considered the
 // current write cursor which is also stored in the metadata.  Thus, it is used
 // to detect unexpected shutdowns in the middle of writes so the block files
-// can be reconciled.
-func scanBlockFiles(dbPath string) (int, uint32) {
+// can be reconciled.  The scan starts from the provided first file since any
+// files before it have been pruned.
+func scanBlockFiles(dbPath string, firstFile uint32) (int, uint32) {
 	lastFile := -1
 	fileLen := uint32(0)
-	for i := 0; ; i++ {
+	for i := int(firstFile); ; i++ {
 		filePath := blockFilePath(dbPath, uint32(i))
 		st, err := os.Stat(filePath)
 		if err != nil {
@@ -746,8 +917,10 @@ func newBlockStore(basePath string, network wire.BitcoinNet) *blockStore {
 	// Look for the end of the latest block to file to determine what the
 	// write cursor position is from the viewpoing of the block files on
 	// disk.
-	fileNum, fileOff := scanBlockFiles(basePath)
+	firstFile := scanFirstBlockFile(basePath)
+	fileNum, fileOff := scanBlockFiles(basePath, firstFile)
 	if fileNum == -1 {
+		firstFile = 0
 		fileNum = 0
 		fileOff = 0
 	}
@@ -759,6 +932,7 @@ func newBlockStore(basePath string, network wire.BitcoinNet) *blockStore {
 		openBlockFiles:   make(map[uint32]*lockableFile),
 		openBlocksLRU:    list.New(),
 		fileNumToLRUElem: make(map[uint32]*list.Element),
+		firstFileNum:     firstFile,
 
 		writeCursor: &writeCursor{
 			curFile:    &lockableFile{},
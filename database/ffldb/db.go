@@ -1727,8 +1727,9 @@ type db struct {
 	cache     *dbCache     // Cache layer which wraps underlying leveldb DB.
 }
 
-// Enforce db implements the database.DB interface.
+// Enforce db implements the database.DB and database.Pruner interfaces.
 var _ database.DB = (*db)(nil)
+var _ database.Pruner = (*db)(nil)
 
 // Type returns the database driver type the current database instance was
 // created with.
@@ -1934,6 +1935,66 @@ func (db *db) Close() error {
 	return closeErr
 }
 
+// PruneBlocks removes the oldest flat block files from disk until the total
+// size of the stored block data no longer exceeds targetSize bytes.  The file
+// which houses the block identified by keepHash and all files after it are never
+// removed.  The block index entries of pruned blocks are kept, so fetching their
+// data returns ErrBlockPruned.  It returns the number of bytes freed.
+//
+// This function is part of the database.Pruner interface implementation.
+func (db *db) PruneBlocks(targetSize uint64, keepHash *common.Uint256) (uint64, error) {
+	// The write lock of the managed transaction prevents blocks from being
+	// written or rolled back while the files are removed.
+	var freed uint64
+	err := db.Update(func(dbTx database.Tx) error {
+		blockRow, err := dbTx.(*transaction).fetchBlockRow(keepHash)
+		if err != nil {
+			return err
+		}
+		location := deserializeBlockLoc(blockRow)
+
+		// Nothing is stored before a block which has no data on disk.
+		if location == prunedBlockLoc {
+			return nil
+		}
+
+		freed, err = db.store.pruneFiles(targetSize, location.blockFileNum)
+		return err
+	})
+	return freed, err
+}
+
+// StorePrunedBlocks adds the blocks with the given hashes to the block index as
+// pruned blocks without storing any data, so HasBlock reports them as existing
+// and fetching their data returns ErrBlockPruned.  Hashes which already exist
+// are skipped.  It is used to bootstrap the database from a snapshot.
+//
+// This function is part of the database.Pruner interface implementation.
+func (db *db) StorePrunedBlocks(hashes []common.Uint256) error {
+	blockRow := serializeBlockLoc(prunedBlockLoc)
+	return db.Update(func(dbTx database.Tx) error {
+		tx := dbTx.(*transaction)
+		for i := range hashes {
+			if tx.hasBlock(hashes[i]) {
+				continue
+			}
+			err := tx.blockIdxBucket.Put(hashes[i][:], blockRow)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// BlocksSize returns the total size in bytes of the flat block files which
+// currently exist on disk.
+//
+// This function is part of the database.Pruner interface implementation.
+func (db *db) BlocksSize() uint64 {
+	return db.store.filesSize()
+}
+
 // filesExists reports whether the named file or directory exists.
 func fileExists(name string) bool {
 	if _, err := os.Stat(name); err != nil {
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/elastos/Elastos.ELA/common/metrics"
 	"github.com/elastos/Elastos.ELA/database/internal/treap"
 
 	"github.com/syndtr/goleveldb/leveldb"
@@ -20,6 +21,10 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
+// cacheFlushesCounter counts the flushes of database cache to leveldb.
+var cacheFlushesCounter = metrics.NewCounter("ela_ffldb_cache_flushes_total",
+	"Total number of database cache flushes to leveldb.")
+
 const (
 	// defaultCacheSize is the default size for the database cache.
 	defaultCacheSize = 20 * 1024 * 1024 // 20 MB
@@ -524,6 +529,7 @@ func (c *dbCache) flush() error {
 	c.cachedRemove = treap.NewImmutable()
 	c.cacheLock.Unlock()
 
+	cacheFlushesCounter.Inc()
 	return nil
 }
 
@@ -0,0 +1,135 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package ffldb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/database"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDB_PruneBlocks(t *testing.T) {
+	dbPath, err := ioutil.TempDir("", "ffldb-prune")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dbPath)
+
+	idb, err := openDB(dbPath, wire.MainNet, true)
+	assert.NoError(t, err)
+	pdb := idb.(*db)
+
+	// Each block record takes 1012 bytes, so every file houses two blocks.
+	pdb.store.maxBlockFileSize = 2500
+	hashes := make([]common.Uint256, 10)
+	for i := range hashes {
+		hashes[i] = common.Uint256{byte(i + 1)}
+		data := make([]byte, 1000)
+		data[0] = byte(i)
+		err := pdb.Update(func(tx database.Tx) error {
+			return tx.StoreBlock(hashes[i], data)
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, uint64(10*1012), pdb.BlocksSize())
+
+	// Nothing is pruned when the data fits in the target size.
+	freed, err := pdb.PruneBlocks(20000, &hashes[9])
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), freed)
+
+	// Blocks at or after the keep block must not be pruned.
+	freed, err = pdb.PruneBlocks(0, &hashes[4])
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2*2024), freed)
+	assert.Equal(t, uint64(6*1012), pdb.BlocksSize())
+
+	err = pdb.View(func(tx database.Tx) error {
+		exists, err := tx.HasBlock(hashes[0])
+		assert.NoError(t, err)
+		assert.True(t, exists)
+
+		_, err = tx.FetchBlock(&hashes[0])
+		assert.Equal(t, database.ErrBlockPruned,
+			err.(database.Error).ErrorCode)
+		_, err = tx.FetchBlockHeader(&hashes[3])
+		assert.Equal(t, database.ErrBlockPruned,
+			err.(database.Error).ErrorCode)
+
+		data, err := tx.FetchBlock(&hashes[4])
+		assert.NoError(t, err)
+		assert.Equal(t, byte(4), data[0])
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, pdb.Close())
+
+	// The write cursor must be recovered from the remaining files.
+	idb, err = openDB(dbPath, wire.MainNet, false)
+	assert.NoError(t, err)
+	pdb = idb.(*db)
+	assert.Equal(t, uint32(2), pdb.store.firstFileNum)
+	assert.Equal(t, uint64(6*1012), pdb.BlocksSize())
+	err = pdb.View(func(tx database.Tx) error {
+		_, err := tx.FetchBlock(&hashes[1])
+		assert.Equal(t, database.ErrBlockPruned,
+			err.(database.Error).ErrorCode)
+		_, err = tx.FetchBlock(&hashes[9])
+		return err
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, pdb.Close())
+}
+
+func TestDB_StorePrunedBlocks(t *testing.T) {
+	dbPath, err := ioutil.TempDir("", "ffldb-pruned")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dbPath)
+
+	idb, err := openDB(dbPath, wire.MainNet, true)
+	assert.NoError(t, err)
+	pdb := idb.(*db)
+	defer pdb.Close()
+
+	stored := common.Uint256{1}
+	err = pdb.Update(func(tx database.Tx) error {
+		return tx.StoreBlock(stored, make([]byte, 100))
+	})
+	assert.NoError(t, err)
+
+	// Existing blocks are left untouched.
+	pruned := []common.Uint256{{2}, {3}, stored}
+	assert.NoError(t, pdb.StorePrunedBlocks(pruned))
+
+	err = pdb.View(func(tx database.Tx) error {
+		for _, hash := range pruned[:2] {
+			exists, err := tx.HasBlock(hash)
+			assert.NoError(t, err)
+			assert.True(t, exists)
+
+			_, err = tx.FetchBlock(&hash)
+			assert.Equal(t, database.ErrBlockPruned,
+				err.(database.Error).ErrorCode)
+			_, err = tx.FetchBlockRegion(&database.BlockRegion{
+				Hash: &hash, Offset: 10, Len: 20})
+			assert.Equal(t, database.ErrBlockPruned,
+				err.(database.Error).ErrorCode)
+		}
+
+		_, err := tx.FetchBlock(&stored)
+		return err
+	})
+	assert.NoError(t, err)
+
+	// Nothing is pruned before a block without data.
+	freed, err := pdb.PruneBlocks(0, &pruned[0])
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), freed)
+}
@@ -468,3 +468,25 @@ type DB interface {
 	// back or committed).
 	Close() error
 }
+
+// Pruner is implemented by database backends which are able to remove the flat
+// block data of old blocks from disk while keeping their metadata.
+//
+// Once a block has been pruned, HasBlock still reports it as existing, but any
+// attempt to fetch its data returns ErrBlockPruned.
+type Pruner interface {
+	// PruneBlocks removes the oldest block data from disk until the total
+	// size of the stored block data no longer exceeds targetSize bytes.
+	// The data of the block identified by keepHash and all blocks stored
+	// after it are never removed.  It returns the number of bytes freed.
+	PruneBlocks(targetSize uint64, keepHash *common.Uint256) (uint64, error)
+
+	// BlocksSize returns the total size in bytes of the block data which
+	// is currently stored on disk.
+	BlocksSize() uint64
+
+	// StorePrunedBlocks adds the blocks with the given hashes as pruned
+	// blocks without storing their data, it is used to bootstrap the
+	// database from a snapshot.
+	StorePrunedBlocks(hashes []common.Uint256) error
+}
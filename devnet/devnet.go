@@ -0,0 +1,221 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package devnet
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/account"
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/contract/program"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/mempool"
+	"github.com/elastos/Elastos.ELA/pow"
+)
+
+const (
+	// confirmTimeout is the duration to wait for the arbiters to confirm
+	// a generated block before generating another one at the same height.
+	confirmTimeout = 10 * time.Second
+
+	// maxGenerateAttempts is the max times to generate a block at the same
+	// height before giving up.
+	maxGenerateAttempts = 3
+
+	// settleDelay is the duration to wait after a block confirmed by the
+	// arbiters, the arbiters process blocks and consensus messages in
+	// separate queues, and a new block arriving before the confirm of the
+	// previous one is processed would break the consensus.
+	settleDelay = 200 * time.Millisecond
+
+	// faucetFee is the fee of the transactions funding addresses.
+	faucetFee = common.Fixed64(10000)
+)
+
+// Config is the configuration of the devnet.
+type Config struct {
+	Chain       *blockchain.BlockChain
+	ChainParams *config.Configuration
+	TxMemPool   *mempool.TxPool
+	Pow         *pow.Service
+	Keys        *Keys
+}
+
+// DevNet generates blocks on demand for a local development network.  Blocks
+// are generated by the PoW service, and confirmed by the simulated arbiters
+// once the DPOS consensus started.
+type DevNet struct {
+	cfg  Config
+	mtx  sync.Mutex
+	quit chan struct{}
+}
+
+// Generate generates the given count of blocks and returns their hashes.
+func (d *DevNet) Generate(count uint32) ([]common.Uint256, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	hashes := make([]common.Uint256, 0, count)
+	for i := uint32(0); i < count; i++ {
+		hash, err := d.generateBlock()
+		if err != nil {
+			return hashes, err
+		}
+		hashes = append(hashes, hash)
+	}
+	return hashes, nil
+}
+
+// GenerateToHeight generates blocks until the best height reaches the given
+// height, and returns the count of generated blocks.
+func (d *DevNet) GenerateToHeight(height uint32) (uint32, error) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	var count uint32
+	for d.cfg.Chain.GetHeight() < height {
+		if _, err := d.generateBlock(); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// generateBlock generates a block on top of the best block, and waits until
+// the block is connected to the chain.
+func (d *DevNet) generateBlock() (common.Uint256, error) {
+	height := d.cfg.Chain.GetHeight()
+	for attempt := 0; attempt < maxGenerateAttempts; attempt++ {
+		if _, err := d.cfg.Pow.DiscreteMining(1); err != nil {
+			return common.EmptyHash, err
+		}
+
+		deadline := time.Now().Add(confirmTimeout)
+		for time.Now().Before(deadline) {
+			if d.cfg.Chain.GetHeight() > height {
+				if d.cfg.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS,
+					height+1) {
+					time.Sleep(settleDelay)
+				}
+				return *d.cfg.Chain.GetBestBlockHash(), nil
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		log.Warnf("[DevNet] block at height %d not confirmed, retry",
+			height+1)
+	}
+	return common.EmptyHash, fmt.Errorf("block at height %d not confirmed"+
+		" by arbiters", height+1)
+}
+
+// Fund sends the given amount from the faucet to the address, and generates
+// a block to confirm the transaction.
+func (d *DevNet) Fund(address common.Uint168,
+	amount common.Fixed64) (interfaces.Transaction, common.Uint256, error) {
+	if amount <= 0 {
+		return nil, common.EmptyHash, errors.New("invalid amount")
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	tx, err := CreateFundTransaction(d.cfg.Chain, d.cfg.TxMemPool,
+		d.cfg.Keys.Faucet, address, amount)
+	if err != nil {
+		return nil, common.EmptyHash, err
+	}
+
+	if err := d.cfg.TxMemPool.AppendToTxPool(tx); err != nil {
+		return nil, common.EmptyHash, err
+	}
+	hash, err := d.generateBlock()
+	if err != nil {
+		return nil, common.EmptyHash, err
+	}
+	return tx, hash, nil
+}
+
+// CreateFundTransaction creates a transaction sending the given amount from
+// the faucet to the address, the UTXOs spent by the transactions in the
+// transaction pool are skipped.
+func CreateFundTransaction(chain *blockchain.BlockChain, txPool *mempool.TxPool,
+	faucet *account.Account, address common.Uint168,
+	amount common.Fixed64) (interfaces.Transaction, error) {
+	exclude := make(map[common2.OutPoint]struct{})
+	for _, tx := range txPool.GetTxsInPool() {
+		for _, input := range tx.Inputs() {
+			exclude[input.Previous] = struct{}{}
+		}
+	}
+
+	tx, err := chain.CreateTransferTransaction(faucet.ProgramHash,
+		faucetFee, exclude, &common2.OutputInfo{
+			Recipient: address,
+			Amount:    amount,
+		})
+	if err != nil {
+		return nil, err
+	}
+	signature, err := account.SignBySigner(tx, faucet)
+	if err != nil {
+		return nil, err
+	}
+	parameter := new(bytes.Buffer)
+	parameter.WriteByte(byte(len(signature)))
+	parameter.Write(signature)
+	tx.SetPrograms([]*program.Program{{
+		Code:      faucet.RedeemScript,
+		Parameter: parameter.Bytes(),
+	}})
+	return tx, nil
+}
+
+// Start generates a block every interval until Stop is called.
+func (d *DevNet) Start(interval time.Duration) {
+	quit := make(chan struct{})
+	d.quit = quit
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := d.Generate(1); err != nil {
+					log.Warn("[DevNet] generate block failed, ", err)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops generating blocks started by Start.
+func (d *DevNet) Stop() {
+	if d.quit != nil {
+		close(d.quit)
+		d.quit = nil
+	}
+}
+
+// Keys returns the keys used by the devnet.
+func (d *DevNet) Keys() *Keys {
+	return d.cfg.Keys
+}
+
+// New creates a devnet with the given configuration.
+func New(cfg *Config) *DevNet {
+	return &DevNet{cfg: *cfg}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package devnet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys_SaveAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "devnet")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = GenerateKeys(0)
+	assert.Error(t, err)
+
+	keys, err := GenerateKeys(3)
+	assert.NoError(t, err)
+	path := filepath.Join(dir, "keys.json")
+	assert.NoError(t, keys.Save(path))
+
+	loaded, err := LoadKeys(path)
+	assert.NoError(t, err)
+	assert.Equal(t, keys.Faucet.Address, loaded.Faucet.Address)
+	assert.Equal(t, len(keys.Arbiters), len(loaded.Arbiters))
+	for i := range keys.Arbiters {
+		assert.Equal(t, keys.Arbiters[i].PrivateKey,
+			loaded.Arbiters[i].PrivateKey)
+	}
+
+	publicKeys, err := loaded.ArbiterPublicKeys()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(publicKeys))
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"faucet":"00",
+		"arbiters":["00"]}`), 0600))
+	_, err = LoadKeys(path)
+	assert.Error(t, err)
+}
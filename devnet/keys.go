@@ -0,0 +1,146 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package devnet
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+
+	"github.com/elastos/Elastos.ELA/account"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/core"
+)
+
+// privateKeyLength is the length of a private key in bytes.
+const privateKeyLength = 32
+
+// Keys holds the private keys used by the devnet, the faucet owns the
+// genesis funds and each arbiter signs proposals and votes as a CRC arbiter.
+type Keys struct {
+	Faucet   *account.Account
+	Arbiters []*account.Account
+}
+
+// keysFile is the JSON format of the keys file, private keys are stored in
+// hexadecimal format.
+type keysFile struct {
+	Faucet   string   `json:"faucet"`
+	Arbiters []string `json:"arbiters"`
+}
+
+// GenerateKeys generates a faucet key and the given count of arbiter keys.
+func GenerateKeys(arbiters int) (*Keys, error) {
+	if arbiters <= 0 {
+		return nil, errors.New("at least one arbiter is required")
+	}
+
+	faucet, err := account.NewAccount()
+	if err != nil {
+		return nil, err
+	}
+	keys := &Keys{Faucet: faucet}
+	for i := 0; i < arbiters; i++ {
+		arbiter, err := account.NewAccount()
+		if err != nil {
+			return nil, err
+		}
+		keys.Arbiters = append(keys.Arbiters, arbiter)
+	}
+	return keys, nil
+}
+
+// LoadKeys loads the keys from the keys file of the given path.
+func LoadKeys(path string) (*Keys, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file keysFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if len(file.Arbiters) == 0 {
+		return nil, errors.New("no arbiter keys in keys file")
+	}
+
+	keys := &Keys{}
+	keys.Faucet, err = accountFromHex(file.Faucet)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range file.Arbiters {
+		arbiter, err := accountFromHex(key)
+		if err != nil {
+			return nil, err
+		}
+		keys.Arbiters = append(keys.Arbiters, arbiter)
+	}
+	return keys, nil
+}
+
+// Save writes the keys into the keys file of the given path, the file is
+// readable by the owner only.
+func (k *Keys) Save(path string) error {
+	file := keysFile{Faucet: common.BytesToHexString(k.Faucet.PrivateKey)}
+	for _, arbiter := range k.Arbiters {
+		file.Arbiters = append(file.Arbiters,
+			common.BytesToHexString(arbiter.PrivateKey))
+	}
+	data, err := json.MarshalIndent(file, "", "\t")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, os.FileMode(0600))
+}
+
+// ArbiterPublicKeys returns the public keys of the arbiters in hexadecimal
+// format.
+func (k *Keys) ArbiterPublicKeys() ([]string, error) {
+	keys := make([]string, 0, len(k.Arbiters))
+	for _, arbiter := range k.Arbiters {
+		publicKey, err := arbiter.PublicKey.EncodePoint(true)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, common.BytesToHexString(publicKey))
+	}
+	return keys, nil
+}
+
+// Apply replaces the foundation and the CRC arbiters of the network
+// parameters with the keys.
+func (k *Keys) Apply(cfg *config.Configuration) error {
+	arbiters, err := k.ArbiterPublicKeys()
+	if err != nil {
+		return err
+	}
+	cfg.DPoSConfiguration.OriginArbiters = arbiters
+	cfg.DPoSConfiguration.CRCArbiters = arbiters
+	cfg.CRConfiguration.MemberCount = uint32(len(arbiters))
+
+	faucet := k.Faucet.ProgramHash
+	cfg.FoundationAddress = k.Faucet.Address
+	cfg.FoundationProgramHash = &faucet
+	cfg.GenesisBlock = core.GenesisBlock(faucet)
+	if cfg.PowConfiguration.PayToAddr == "" {
+		cfg.PowConfiguration.PayToAddr = k.Faucet.Address
+	}
+	return nil
+}
+
+func accountFromHex(key string) (*account.Account, error) {
+	privateKey, err := common.HexStringToBytes(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(privateKey) != privateKeyLength {
+		return nil, errors.New("invalid private key length")
+	}
+	return account.NewAccountWithPrivateKey(privateKey)
+}
@@ -9,6 +9,7 @@ import (
 	"bytes"
 
 	"github.com/elastos/Elastos.ELA/account"
+	"github.com/elastos/Elastos.ELA/account/signer"
 	"github.com/elastos/Elastos.ELA/core/types/interfaces"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	"github.com/elastos/Elastos.ELA/crypto"
@@ -17,31 +18,35 @@ import (
 type Account interface {
 	PublicKey() *crypto.PublicKey
 	PublicKeyBytes() []byte
-	SignProposal(proposal *payload.DPOSProposal) ([]byte, error)
-	SignVote(vote *payload.DPOSProposalVote) ([]byte, error)
+	SignProposal(proposal *payload.DPOSProposal, height uint32) ([]byte, error)
+	SignVote(vote *payload.DPOSProposalVote, height uint32,
+		viewOffset uint32) ([]byte, error)
 	Sign(data []byte) []byte
 	SignTx(tx interfaces.Transaction) ([]byte, error)
 	DecryptAddr(cipher []byte) (addr string, err error)
 }
 
 type dAccount struct {
-	*account.Account
-	pubKey []byte
+	signer    signer.Signer
+	publicKey *crypto.PublicKey
 }
 
 func (a *dAccount) PublicKey() *crypto.PublicKey {
-	return a.Account.PublicKey
+	return a.publicKey
 }
 
 func (a *dAccount) PublicKeyBytes() []byte {
-	return a.pubKey
+	return a.signer.PublicKey()
 }
 
-func (a *dAccount) SignProposal(proposal *payload.DPOSProposal) ([]byte,
-	error) {
-	privateKey := a.PrivKey()
-
-	signature, err := crypto.Sign(privateKey, proposal.Data())
+func (a *dAccount) SignProposal(proposal *payload.DPOSProposal,
+	height uint32) ([]byte, error) {
+	signature, err := a.signer.Sign(&signer.Request{
+		Type:       signer.Proposal,
+		Height:     height,
+		ViewOffset: proposal.ViewOffset,
+		Data:       proposal.Data(),
+	})
 	if err != nil {
 		return []byte{0}, err
 	}
@@ -49,10 +54,14 @@ func (a *dAccount) SignProposal(proposal *payload.DPOSProposal) ([]byte,
 	return signature, nil
 }
 
-func (a *dAccount) SignVote(vote *payload.DPOSProposalVote) ([]byte, error) {
-	privateKey := a.PrivKey()
-
-	signature, err := crypto.Sign(privateKey, vote.Data())
+func (a *dAccount) SignVote(vote *payload.DPOSProposalVote, height uint32,
+	viewOffset uint32) ([]byte, error) {
+	signature, err := a.signer.Sign(&signer.Request{
+		Type:       signer.Vote,
+		Height:     height,
+		ViewOffset: viewOffset,
+		Data:       vote.Data(),
+	})
 	if err != nil {
 		return []byte{0}, err
 	}
@@ -61,8 +70,10 @@ func (a *dAccount) SignVote(vote *payload.DPOSProposalVote) ([]byte, error) {
 }
 
 func (a *dAccount) Sign(data []byte) []byte {
-	privateKey := a.PrivKey()
-	sign, err := crypto.Sign(privateKey, data)
+	sign, err := a.signer.Sign(&signer.Request{
+		Type: signer.Message,
+		Data: data,
+	})
 	if err != nil {
 		return nil
 	}
@@ -75,30 +86,58 @@ func (a *dAccount) SignTx(tx interfaces.Transaction) ([]byte, error) {
 		return nil, err
 	}
 
-	privateKey := a.PrivKey()
-	return crypto.Sign(privateKey, buf.Bytes())
+	return a.signer.Sign(&signer.Request{
+		Type: signer.Transaction,
+		Data: buf.Bytes(),
+	})
 }
 
 func (a *dAccount) DecryptAddr(cipher []byte) (addr string, err error) {
-	data, err := crypto.Decrypt(a.PrivateKey, cipher)
+	data, err := a.signer.Decrypt(cipher)
 	return string(data), err
 }
 
-func Open(password []byte, walletPath string) (Account, error) {
-	client, err := account.Open(walletPath, password)
+// Open opens the keystore file and signs with its main account in the
+// process memory, the requests are checked by the policy.
+func Open(password []byte, walletPath string, policy *signer.Policy) (Account,
+	error) {
+	s, err := signer.OpenKeystore(walletPath, password)
 	if err != nil {
 		return nil, err
 	}
-	a := client.GetMainAccount()
-	pk, err := a.PublicKey.EncodePoint(true)
+	return guard(s, policy)
+}
+
+// Dial signs with the remote signer listening on the Unix socket, the
+// requests are checked by the policy before sent.
+func Dial(socket string, policy *signer.Policy) (Account, error) {
+	s, err := signer.Dial(socket)
 	if err != nil {
 		return nil, err
 	}
+	return guard(s, policy)
+}
 
-	return &dAccount{Account: a, pubKey: pk}, nil
+func guard(s signer.Signer, policy *signer.Policy) (Account, error) {
+	guarded, err := signer.Guard(s, policy)
+	if err != nil {
+		return nil, err
+	}
+	return NewWithSigner(guarded)
 }
 
+// New signs with the private key of the account without any policy, which
+// is used to simulate arbiters signing anything asked.
 func New(a *account.Account) Account {
-	pubKey, _ := a.PublicKey.EncodePoint(true)
-	return &dAccount{Account: a, pubKey: pubKey}
+	s, _ := signer.NewKeystore(a)
+	return &dAccount{signer: s, publicKey: a.PublicKey}
+}
+
+// NewWithSigner creates an account signing with the signer.
+func NewWithSigner(s signer.Signer) (Account, error) {
+	publicKey, err := crypto.DecodePoint(s.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+	return &dAccount{signer: s, publicKey: publicKey}, nil
 }
@@ -43,6 +43,7 @@ type Config struct {
 	AnnounceAddr   func()
 	NodeVersion    string
 	Addr           string
+	LocalNetwork   *dp2p.LocalNetwork
 }
 
 type Arbitrator struct {
@@ -201,12 +202,12 @@ func (a *Arbitrator) OnBlockReceived(b *types.Block, confirmed bool) {
 	if !a.cfg.Server.IsCurrent() {
 		return
 	}
-	if b.Height >= a.cfg.ChainParams.DPoSConfiguration.RevertToPOWStartHeight {
+	if a.cfg.ChainParams.IsActive(config.DeploymentRevertToPOW, b.Height) {
 		lastBlockTimestamp := int64(a.cfg.Arbitrators.GetLastBlockTimestamp())
 		localTimestamp := a.cfg.Chain.TimeSource.AdjustedTime().Unix()
 
 		var stopConfirmTime int64
-		if b.Height < a.cfg.ChainParams.DPoSConfiguration.ChangeViewV1Height {
+		if !a.cfg.ChainParams.IsActive(config.DeploymentChangeViewV1, b.Height) {
 			stopConfirmTime = a.cfg.ChainParams.DPoSConfiguration.StopConfirmBlockTime
 		} else {
 			stopConfirmTime = a.cfg.ChainParams.DPoSConfiguration.StopConfirmBlockTimeV1
@@ -260,12 +261,13 @@ func NewArbitrator(account account.Account, cfg Config) (*Arbitrator, error) {
 	})
 
 	network, err := NewDposNetwork(NetworkConfig{
-		ChainParams: cfg.ChainParams,
-		Account:     account,
-		MedianTime:  medianTime,
-		Listener:    dposManager,
-		NodeVersion: cfg.NodeVersion,
-		Addr:        cfg.Addr,
+		ChainParams:  cfg.ChainParams,
+		Account:      account,
+		MedianTime:   medianTime,
+		Listener:     dposManager,
+		NodeVersion:  cfg.NodeVersion,
+		Addr:         cfg.Addr,
+		LocalNetwork: cfg.LocalNetwork,
 	})
 	if err != nil {
 		log.Error("Init p2p network error")
@@ -21,6 +21,7 @@ var logger *elaLog.Logger
 func Init(dir string, level uint8, maxPerLogSizeMb, maxLogsSizeMb int64) {
 	path := filepath.Join(dir, dposLogPath)
 	logger = elaLog.NewLogger(path, level, maxPerLogSizeMb, maxLogsSizeMb)
+	elaLog.Register("dpos", logger)
 	p2p.UseLogger(logger)
 }
 
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/dpos/log"
 	"github.com/elastos/Elastos.ELA/dpos/p2p/msg"
@@ -116,7 +117,7 @@ func (c *Consensus) ProcessBlock(b *types.Block) {
 }
 
 func (c *Consensus) ChangeView() {
-	if c.currentHeight < c.manager.chainParams.DPoSConfiguration.ChangeViewV1Height {
+	if !c.manager.chainParams.IsActive(config.DeploymentChangeViewV1, c.currentHeight) {
 		c.currentView.ChangeView(&c.viewOffset, c.manager.timeSource.AdjustedTime())
 	} else {
 		c.currentView.ChangeViewV1(&c.viewOffset, c.manager.timeSource.AdjustedTime())
@@ -126,7 +127,7 @@ func (c *Consensus) ChangeView() {
 
 func (c *Consensus) TryChangeView() bool {
 	if c.IsRunning() {
-		if c.currentHeight < c.manager.chainParams.DPoSConfiguration.ChangeViewV1Height {
+		if !c.manager.chainParams.IsActive(config.DeploymentChangeViewV1, c.currentHeight) {
 			return c.currentView.TryChangeView(&c.viewOffset, c.manager.timeSource.AdjustedTime())
 		} else {
 			return c.currentView.TryChangeViewV1(&c.viewOffset, c.manager.timeSource.AdjustedTime())
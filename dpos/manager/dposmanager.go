@@ -252,7 +252,7 @@ func (d *DPOSManager) OnProposalReceived(id dpeer.PID, p *payload.DPOSProposal)
 			log.Info("[OnProposalReceived] has minority not handled" +
 				" proposals, need recover")
 
-			if d.consensus.finishedHeight < d.chainParams.DPoSConfiguration.ChangeViewV1Height {
+			if !d.chainParams.IsActive(config.DeploymentChangeViewV1, d.consensus.finishedHeight) {
 				if d.recoverAbnormalState() {
 					log.Info("[OnProposalReceived] recover start")
 				} else {
@@ -525,7 +525,7 @@ func (d *DPOSManager) OnChangeView() {
 		log.Info("[TryChangeView] succeed")
 	}
 
-	if d.consensus.currentHeight >= d.chainParams.DPoSConfiguration.ChangeViewV1Height {
+	if d.chainParams.IsActive(config.DeploymentChangeViewV1, d.consensus.currentHeight) {
 		return
 	}
 
@@ -737,7 +737,7 @@ func (d *DPOSManager) OnResponseRevertToDPOSTxReceived(
 }
 
 func (d *DPOSManager) OnResponseResetViewReceived(msg *dmsg.ResetView) {
-	if d.consensus.currentHeight >= d.chainParams.DPoSConfiguration.ChangeViewV1Height {
+	if d.chainParams.IsActive(config.DeploymentChangeViewV1, d.consensus.currentHeight) {
 		return
 	}
 
@@ -147,7 +147,7 @@ func (p *ProposalDispatcher) StartProposal(b *types.Block) {
 	proposal := &payload.DPOSProposal{Sponsor: p.cfg.Manager.GetPublicKey(),
 		BlockHash: b.Hash(), ViewOffset: p.cfg.Consensus.GetViewOffset()}
 	var err error
-	proposal.Sign, err = p.cfg.Account.SignProposal(proposal)
+	proposal.Sign, err = p.cfg.Account.SignProposal(proposal, b.Height)
 	if err != nil {
 		log.Error("[StartProposal] start proposal failed:", err.Error())
 		return
@@ -862,7 +862,8 @@ func (p *ProposalDispatcher) acceptProposal(d *payload.DPOSProposal) {
 	vote := &payload.DPOSProposalVote{ProposalHash: d.Hash(),
 		Signer: p.cfg.Manager.GetPublicKey(), Accept: true}
 	var err error
-	vote.Sign, err = p.cfg.Account.SignVote(vote)
+	vote.Sign, err = p.cfg.Account.SignVote(vote, p.consensusHeight(),
+		d.ViewOffset)
 	if err != nil {
 		log.Error("[acceptProposal] sign failed")
 		return
@@ -891,7 +892,8 @@ func (p *ProposalDispatcher) rejectProposal(d *payload.DPOSProposal) {
 	vote := &payload.DPOSProposalVote{ProposalHash: d.Hash(),
 		Signer: p.cfg.Manager.GetPublicKey(), Accept: false}
 	var err error
-	vote.Sign, err = p.cfg.Account.SignVote(vote)
+	vote.Sign, err = p.cfg.Account.SignVote(vote, p.consensusHeight(),
+		d.ViewOffset)
 	if err != nil {
 		log.Error("[rejectProposal] sign failed")
 		return
@@ -913,6 +915,14 @@ func (p *ProposalDispatcher) rejectProposal(d *payload.DPOSProposal) {
 	p.eventAnalyzer.AppendConsensusVote(vote)
 }
 
+// consensusHeight returns the height of the block in consensus.
+func (p *ProposalDispatcher) consensusHeight() uint32 {
+	if p.processingBlock != nil {
+		return p.processingBlock.Height
+	}
+	return blockchain.DefaultLedger.Blockchain.GetHeight() + 1
+}
+
 func (p *ProposalDispatcher) setProcessingProposal(d *payload.DPOSProposal) (finished bool) {
 	p.processingProposal = d
 
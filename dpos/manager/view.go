@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/metrics"
 	"github.com/elastos/Elastos.ELA/dpos/log"
 	"github.com/elastos/Elastos.ELA/dpos/state"
 )
@@ -20,6 +21,10 @@ const (
 	ChangeViewMulStep = uint32(20)
 )
 
+// viewChangesCounter counts the view changes of DPoS consensus.
+var viewChangesCounter = metrics.NewCounter("ela_dpos_view_changes_total",
+	"Total number of DPoS view changes.")
+
 type ViewListener interface {
 	OnViewChanged(isOnDuty bool)
 }
@@ -64,6 +69,7 @@ func (v *view) ChangeView(viewOffset *uint32, now time.Time) {
 		log.Info("current onduty arbiter:",
 			common.BytesToHexString(currentArbiter))
 
+		viewChangesCounter.Inc()
 		v.listener.OnViewChanged(v.isDposOnDuty)
 	}
 }
@@ -76,6 +82,7 @@ func (v *view) ChangeViewV1(viewOffset *uint32, now time.Time) bool {
 		return false
 	}
 	log.Info("ChangeView succeed, offset from:", *viewOffset, "to:", offset)
+	viewChangesCounter.Inc()
 
 	*viewOffset = offset
 	v.viewStartTime = now.Add(-offsetTime)
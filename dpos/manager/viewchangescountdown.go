@@ -8,6 +8,7 @@ package manager
 import (
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/dpos/state"
 )
 
@@ -49,8 +50,8 @@ func (c *ViewChangesCountDown) SetEliminated(hash common.Uint256) bool {
 }
 
 func (c *ViewChangesCountDown) IsTimeOut() bool {
-	if blockchain.DefaultLedger.Blockchain.GetHeight()+1 <
-		c.dispatcher.cfg.ChainParams.PublicDPOSHeight ||
+	if !c.dispatcher.cfg.ChainParams.IsActive(config.DeploymentPublicDPOS,
+		blockchain.DefaultLedger.Blockchain.GetHeight()+1) ||
 		c.arbitrators.IsInactiveMode() {
 		return false
 	}
@@ -38,6 +38,10 @@ type NetworkConfig struct {
 	Listener    manager.NetworkEventListener
 	NodeVersion string
 	Addr        string
+
+	// LocalNetwork, if set, connects the network to other arbiters in the
+	// same process instead of the peer-to-peer network.
+	LocalNetwork *p2p.LocalNetwork
 }
 
 type blockItem struct {
@@ -376,7 +380,7 @@ func NewDposNetwork(cfg NetworkConfig) (*network, error) {
 
 	var pid peer.PID
 	copy(pid[:], cfg.Account.PublicKeyBytes())
-	server, err := p2p.NewServer(&p2p.Config{
+	serverCfg := &p2p.Config{
 		DataDir:           dataPathDPoS,
 		PID:               pid,
 		EnableHub:         true,
@@ -394,7 +398,13 @@ func NewDposNetwork(cfg NetworkConfig) (*network, error) {
 		DPoSV2StartHeight: cfg.ChainParams.DPoSV2StartHeight,
 		NodeVersion:       cfg.NodeVersion,
 		Addr:              cfg.Addr,
-	})
+	}
+	if cfg.LocalNetwork != nil {
+		network.p2pServer = cfg.LocalNetwork.NewServer(serverCfg)
+		return network, nil
+	}
+
+	server, err := p2p.NewServer(serverCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,236 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package p2p
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/dpos/p2p/peer"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// Ensure localServer implement Server interface.
+var _ Server = (*localServer)(nil)
+
+// LocalNetwork connects servers running in the same process.  Messages are
+// handed over to the receiving server in memory instead of being sent
+// through network connections, so a group of arbiters can be simulated by a
+// single process.
+//
+// Messages are not copied, the receiving servers must treat them as read
+// only.
+type LocalNetwork struct {
+	mtx     sync.RWMutex
+	servers map[peer.PID]*localServer
+}
+
+// NewServer creates a server attached to the local network with the given
+// configuration.  Only PID, HandleMessage and StateNotifier of the
+// configuration are used.
+func (n *LocalNetwork) NewServer(cfg *Config) Server {
+	return &localServer{
+		cfg:       cfg,
+		network:   n,
+		connected: make(map[peer.PID]struct{}),
+	}
+}
+
+// attach makes the server reachable from other servers.
+func (n *LocalNetwork) attach(s *localServer) {
+	n.mtx.Lock()
+	n.servers[s.cfg.PID] = s
+	n.mtx.Unlock()
+}
+
+// detach makes the server unreachable from other servers.
+func (n *LocalNetwork) detach(s *localServer) {
+	n.mtx.Lock()
+	if n.servers[s.cfg.PID] == s {
+		delete(n.servers, s.cfg.PID)
+	}
+	n.mtx.Unlock()
+}
+
+// server returns the attached server with the given PID.
+func (n *LocalNetwork) server(pid peer.PID) (*localServer, bool) {
+	n.mtx.RLock()
+	s, ok := n.servers[pid]
+	n.mtx.RUnlock()
+	return s, ok
+}
+
+// NewLocalNetwork creates an empty local network.
+func NewLocalNetwork() *LocalNetwork {
+	return &LocalNetwork{servers: make(map[peer.PID]*localServer)}
+}
+
+// localPeer represent a server attached to the same local network.
+type localPeer peer.PID
+
+// PID returns the peer's public key id.
+func (p localPeer) PID() peer.PID {
+	return peer.PID(p)
+}
+
+// ToPeer returns nil, local peers have no real peer instance.
+func (p localPeer) ToPeer() *peer.Peer {
+	return nil
+}
+
+// localServer is a Server attached to a LocalNetwork.  A peer is regarded
+// as connected when it is in the peers passed by ConnectPeers and there is
+// a server with the same PID attached to the local network.
+type localServer struct {
+	cfg     *Config
+	network *LocalNetwork
+
+	mtx       sync.Mutex
+	current   map[peer.PID]struct{}
+	connected map[peer.PID]struct{}
+}
+
+// Start attaches the server to the local network.
+func (s *localServer) Start() {
+	s.network.attach(s)
+}
+
+// Stop detaches the server from the local network.
+func (s *localServer) Stop() error {
+	s.network.detach(s)
+	return nil
+}
+
+// AddAddr does nothing, local peers have no network address.
+func (s *localServer) AddAddr(pid peer.PID, addr string) {}
+
+// ConnectPeers let server connect the peers in the given list, and
+// disconnect peers that not in the list.
+func (s *localServer) ConnectPeers(currentPeers []peer.PID,
+	nextPeers []peer.PID) {
+	current := make(map[peer.PID]struct{})
+	for _, pid := range currentPeers {
+		current[pid] = struct{}{}
+	}
+	peers := make(map[peer.PID]struct{})
+	for _, list := range [][]peer.PID{currentPeers, nextPeers} {
+		for _, pid := range list {
+			if pid.Equal(s.cfg.PID) {
+				continue
+			}
+			peers[pid] = struct{}{}
+		}
+	}
+
+	s.mtx.Lock()
+	s.current = current
+	var newPeers, donePeers []peer.PID
+	for pid := range peers {
+		if _, ok := s.connected[pid]; !ok {
+			s.connected[pid] = struct{}{}
+			newPeers = append(newPeers, pid)
+		}
+	}
+	for pid := range s.connected {
+		if _, ok := peers[pid]; !ok {
+			delete(s.connected, pid)
+			donePeers = append(donePeers, pid)
+		}
+	}
+	s.mtx.Unlock()
+
+	if s.cfg.StateNotifier == nil {
+		return
+	}
+	list := make([]peer.PID, 0, len(peers))
+	for pid := range peers {
+		list = append(list, pid)
+	}
+	s.cfg.StateNotifier.OnConnectPeers(list)
+	for _, pid := range newPeers {
+		s.cfg.StateNotifier.OnNewPeer(pid)
+	}
+	for _, pid := range donePeers {
+		s.cfg.StateNotifier.OnDonePeer(pid)
+	}
+}
+
+// connectedPeers returns the connected peers matching the filter.
+func (s *localServer) connectedPeers(filter func(pid peer.PID) bool) []Peer {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	peers := make([]Peer, 0, len(s.connected))
+	for pid := range s.connected {
+		if _, ok := s.network.server(pid); !ok || !filter(pid) {
+			continue
+		}
+		peers = append(peers, localPeer(pid))
+	}
+	return peers
+}
+
+// SendMessageToPeer send a message to the peer with the given id, error
+// will be returned if there is no matches.
+func (s *localServer) SendMessageToPeer(pid peer.PID, msg p2p.Message) error {
+	s.mtx.Lock()
+	_, ok := s.connected[pid]
+	s.mtx.Unlock()
+	if ok {
+		if target, ok := s.network.server(pid); ok {
+			target.cfg.HandleMessage(s.cfg.PID, msg)
+			return nil
+		}
+	}
+	return fmt.Errorf("peer %s not connected", pid)
+}
+
+// BroadcastMessage sends msg to all peers currently connected to the server
+// except those in the passed peers to exclude.
+func (s *localServer) BroadcastMessage(msg p2p.Message,
+	exclPeers ...peer.PID) {
+	peers := s.connectedPeers(func(pid peer.PID) bool {
+		for _, excl := range exclPeers {
+			if pid.Equal(excl) {
+				return false
+			}
+		}
+		return true
+	})
+	for _, p := range peers {
+		if target, ok := s.network.server(p.PID()); ok {
+			target.cfg.HandleMessage(s.cfg.PID, msg)
+		}
+	}
+}
+
+// ConnectedPeers returns an array consisting of all connected peers.
+func (s *localServer) ConnectedPeers() []Peer {
+	return s.connectedPeers(func(pid peer.PID) bool { return true })
+}
+
+// ConnectedCurrentPeers returns an array consisting of all connected
+// current peers.
+func (s *localServer) ConnectedCurrentPeers() []Peer {
+	return s.connectedPeers(func(pid peer.PID) bool {
+		_, ok := s.current[pid]
+		return ok
+	})
+}
+
+// DumpPeersInfo returns a list of connect peers information.
+func (s *localServer) DumpPeersInfo() []*PeerInfo {
+	peers := s.ConnectedPeers()
+	infos := make([]*PeerInfo, 0, len(peers))
+	for _, p := range peers {
+		infos = append(infos, &PeerInfo{
+			PID:         p.PID(),
+			State:       CS2WayConnection,
+			NodeVersion: s.cfg.NodeVersion,
+		})
+	}
+	return infos
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package p2p
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/dpos/p2p/peer"
+	"github.com/elastos/Elastos.ELA/p2p"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalNetwork(t *testing.T) {
+	type received struct {
+		from peer.PID
+		to   peer.PID
+	}
+	var messages []received
+
+	network := NewLocalNetwork()
+	pids := make([]peer.PID, 3)
+	servers := make([]Server, len(pids))
+	for i := range pids {
+		pids[i][0] = byte(i + 1)
+		pid := pids[i]
+		servers[i] = network.NewServer(&Config{
+			PID: pid,
+			HandleMessage: func(from peer.PID, msg p2p.Message) {
+				messages = append(messages, received{from, pid})
+			},
+		})
+		servers[i].Start()
+	}
+
+	// Nothing is delivered before the peers are connected.
+	assert.Error(t, servers[0].SendMessageToPeer(pids[1], &message{}))
+	servers[0].BroadcastMessage(&message{})
+	assert.Len(t, messages, 0)
+
+	servers[0].ConnectPeers(pids[:2], pids[2:])
+	assert.Len(t, servers[0].ConnectedPeers(), 2)
+	assert.Len(t, servers[0].ConnectedCurrentPeers(), 1)
+	assert.Len(t, servers[0].DumpPeersInfo(), 2)
+
+	assert.NoError(t, servers[0].SendMessageToPeer(pids[1], &message{}))
+	assert.Equal(t, []received{{pids[0], pids[1]}}, messages)
+
+	messages = nil
+	servers[0].BroadcastMessage(&message{}, pids[1])
+	assert.Equal(t, []received{{pids[0], pids[2]}}, messages)
+
+	// Stopped servers are no longer reachable.
+	messages = nil
+	assert.NoError(t, servers[2].Stop())
+	assert.Len(t, servers[0].ConnectedPeers(), 1)
+	assert.Error(t, servers[0].SendMessageToPeer(pids[2], &message{}))
+	servers[0].BroadcastMessage(&message{})
+	assert.Equal(t, []received{{pids[0], pids[1]}}, messages)
+
+	// Peers not in the list are disconnected.
+	servers[0].ConnectPeers(nil, nil)
+	assert.Len(t, servers[0].ConnectedPeers(), 0)
+}
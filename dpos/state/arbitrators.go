@@ -395,19 +395,19 @@ func (a *Arbiters) RollbackTo(height uint32) error {
 
 func (a *Arbiters) GetDutyIndexByHeight(height uint32) (index int) {
 	a.mtx.Lock()
-	if height >= a.ChainParams.DPoSConfiguration.DPOSNodeCrossChainHeight {
+	if a.ChainParams.IsActive(config.DeploymentDPOSNodeCrossChain, height) {
 		if len(a.CurrentArbitrators) == 0 {
 			index = 0
 		} else {
 			index = a.DutyIndex % len(a.CurrentArbitrators)
 		}
-	} else if height >= a.ChainParams.CRConfiguration.CRClaimDPOSNodeStartHeight {
+	} else if a.ChainParams.IsActive(config.DeploymentCRClaimDPOSNode, height) {
 		if len(a.CurrentCRCArbitersMap) == 0 {
 			index = 0
 		} else {
 			index = a.DutyIndex % len(a.CurrentCRCArbitersMap)
 		}
-	} else if height >= a.ChainParams.CRCOnlyDPOSHeight-1 {
+	} else if a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS, height+1) {
 		if len(a.CurrentCRCArbitersMap) == 0 {
 			index = 0
 		} else {
@@ -535,7 +535,7 @@ func (a *Arbiters) normalChange(height uint32) error {
 }
 
 func (a *Arbiters) notifyNextTurnDPOSInfoTx(blockHeight, versionHeight uint32, forceChange bool) {
-	if blockHeight > a.ChainParams.DPoSConfiguration.DexStartHeight {
+	if a.ChainParams.IsActiveAfter(config.DeploymentDex, blockHeight) {
 		nextTurnDPOSInfoTx := a.createNextTurnDPOSInfoTransactionV2(blockHeight, forceChange)
 		go events.Notify(events.ETAppendTxToTxPool, nextTurnDPOSInfoTx)
 
@@ -802,7 +802,7 @@ func (a *Arbiters) getDPoSV2RewardsV2(dposReward common.Fixed64, sponsor []byte,
 }
 
 func (a *Arbiters) accumulateReward(block *types.Block, confirm *payload.Confirm) {
-	if block.Height < a.ChainParams.PublicDPOSHeight {
+	if !a.ChainParams.IsActive(config.DeploymentPublicDPOS, block.Height) {
 		oriDutyIndex := a.DutyIndex
 		a.History.Append(block.Height, func() {
 			a.DutyIndex = oriDutyIndex + 1
@@ -815,7 +815,7 @@ func (a *Arbiters) accumulateReward(block *types.Block, confirm *payload.Confirm
 	var accumulative common.Fixed64
 	accumulative = a.accumulativeReward
 	var dposReward common.Fixed64
-	if block.Height < a.ChainParams.CRConfiguration.CRVotingStartHeight || !a.forceChanged {
+	if !a.ChainParams.IsActive(config.DeploymentCRVoting, block.Height) || !a.forceChanged {
 		dposReward = a.getBlockDPOSReward(block)
 		accumulative += dposReward
 	}
@@ -828,7 +828,7 @@ func (a *Arbiters) accumulateReward(block *types.Block, confirm *payload.Confirm
 		var rewards map[string]common.Fixed64
 
 		// need record rewards after RecordSponsorStartHeight, real reward at next block.
-		if block.Height >= a.ChainParams.DPoSConfiguration.RecordSponsorStartHeight {
+		if a.ChainParams.IsActive(config.DeploymentRecordSponsor, block.Height) {
 			possibleRewards := make(map[string]map[string]common.Fixed64)
 			if confirm != nil {
 				for _, arb := range a.CurrentArbitrators {
@@ -906,7 +906,7 @@ func (a *Arbiters) accumulateReward(block *types.Block, confirm *payload.Confirm
 
 func (a *Arbiters) clearingDPOSReward(block *types.Block, historyHeight uint32,
 	smoothClearing bool) (err error) {
-	if block.Height < a.ChainParams.PublicDPOSHeight ||
+	if !a.ChainParams.IsActive(config.DeploymentPublicDPOS, block.Height) ||
 		block.Height == a.clearingHeight {
 		return nil
 	}
@@ -948,11 +948,17 @@ func (a *Arbiters) distributeDPOSReward(height uint32,
 	reward common.Fixed64) (roundReward map[common.Uint168]common.Fixed64,
 	change common.Fixed64, err error) {
 	var realDPOSReward common.Fixed64
-	if height >= a.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight+2*uint32(len(a.CurrentArbitrators)) {
+	// The rules of distributing reward change two rounds after the activation
+	// of the deployments.
+	rounds := 2 * uint32(len(a.CurrentArbitrators))
+	isActiveAfterRounds := func(feature config.Deployment) bool {
+		return height >= rounds && a.ChainParams.IsActive(feature, height-rounds)
+	}
+	if isActiveAfterRounds(config.DeploymentChangeCommitteeNewCR) {
 		roundReward, realDPOSReward, err = a.distributeWithNormalArbitratorsV3(height, reward)
-	} else if height >= a.ChainParams.CRConfiguration.CRClaimDPOSNodeStartHeight+2*uint32(len(a.CurrentArbitrators)) {
+	} else if isActiveAfterRounds(config.DeploymentCRClaimDPOSNode) {
 		roundReward, realDPOSReward, err = a.distributeWithNormalArbitratorsV2(height, reward)
-	} else if height >= a.ChainParams.CRConfiguration.CRCommitteeStartHeight+2*uint32(len(a.CurrentArbitrators)) {
+	} else if isActiveAfterRounds(config.DeploymentCRCommittee) {
 		roundReward, realDPOSReward, err = a.distributeWithNormalArbitratorsV1(height, reward)
 	} else {
 		roundReward, realDPOSReward, err = a.distributeWithNormalArbitratorsV0(height, reward)
@@ -1200,7 +1206,8 @@ func (a *Arbiters) GetCurrentNeedConnectArbiters() []peer.PID {
 
 func (a *Arbiters) getCurrentNeedConnectArbiters() []peer.PID {
 	height := a.History.Height() + 1
-	if height < a.ChainParams.CRCOnlyDPOSHeight-a.ChainParams.DPoSConfiguration.PreConnectOffset {
+	if !a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS,
+		height+a.ChainParams.DPoSConfiguration.PreConnectOffset) {
 		return nil
 	}
 
@@ -1229,7 +1236,8 @@ func (a *Arbiters) GetNextNeedConnectArbiters() []peer.PID {
 
 func (a *Arbiters) getNextNeedConnectArbiters() []peer.PID {
 	height := a.History.Height() + 1
-	if height < a.ChainParams.CRCOnlyDPOSHeight-a.ChainParams.DPoSConfiguration.PreConnectOffset {
+	if !a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS,
+		height+a.ChainParams.DPoSConfiguration.PreConnectOffset) {
 		return nil
 	}
 
@@ -1258,7 +1266,8 @@ func (a *Arbiters) GetNeedConnectCRArbiters() []peer.PID {
 
 func (a *Arbiters) getNeedConnectCRArbiters() []peer.PID {
 	height := a.History.Height() + 1
-	if height < a.ChainParams.CRCOnlyDPOSHeight-a.ChainParams.DPoSConfiguration.PreConnectOffset {
+	if !a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS,
+		height+a.ChainParams.DPoSConfiguration.PreConnectOffset) {
 		return nil
 	}
 
@@ -1300,8 +1309,8 @@ func (a *Arbiters) GetNeedConnectArbiters() []peer.PID {
 
 func (a *Arbiters) getNeedConnectArbiters() []peer.PID {
 	height := a.History.Height() + 1
-	if height < a.ChainParams.CRCOnlyDPOSHeight-
-		a.ChainParams.DPoSConfiguration.PreConnectOffset {
+	if !a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS,
+		height+a.ChainParams.DPoSConfiguration.PreConnectOffset) {
 		return nil
 	}
 
@@ -1709,9 +1718,9 @@ func (a *Arbiters) GetNextOnDutyArbitrator(offset uint32) []byte {
 func (a *Arbiters) GetOnDutyCrossChainArbitrator() []byte {
 	var arbiter []byte
 	height := a.bestHeight()
-	if height < a.ChainParams.CRCOnlyDPOSHeight-1 {
+	if !a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS, height+1) {
 		arbiter = a.GetOnDutyArbitrator()
-	} else if height < a.ChainParams.CRConfiguration.CRClaimDPOSNodeStartHeight {
+	} else if !a.ChainParams.IsActive(config.DeploymentCRClaimDPOSNode, height) {
 		a.mtx.Lock()
 		crcArbiters := a.getCRCArbiters()
 		sort.Slice(crcArbiters, func(i, j int) bool {
@@ -1720,7 +1729,7 @@ func (a *Arbiters) GetOnDutyCrossChainArbitrator() []byte {
 		ondutyIndex := int(height-a.ChainParams.CRCOnlyDPOSHeight+1) % len(crcArbiters)
 		arbiter = crcArbiters[ondutyIndex].NodePublicKey
 		a.mtx.Unlock()
-	} else if height < a.ChainParams.DPoSConfiguration.DPOSNodeCrossChainHeight {
+	} else if !a.ChainParams.IsActive(config.DeploymentDPOSNodeCrossChain, height) {
 		a.mtx.Lock()
 		crcArbiters := a.getCRCArbiters()
 		sort.Slice(crcArbiters, func(i, j int) bool {
@@ -1749,10 +1758,10 @@ func (a *Arbiters) GetOnDutyCrossChainArbitrator() []byte {
 
 func (a *Arbiters) GetCrossChainArbiters() []*ArbiterInfo {
 	bestHeight := a.bestHeight()
-	if bestHeight < a.ChainParams.CRCOnlyDPOSHeight-1 {
+	if !a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS, bestHeight+1) {
 		return a.GetArbitrators()
 	}
-	if bestHeight < a.ChainParams.DPoSConfiguration.DPOSNodeCrossChainHeight {
+	if !a.ChainParams.IsActive(config.DeploymentDPOSNodeCrossChain, bestHeight) {
 		crcArbiters := a.GetCRCArbiters()
 		sort.Slice(crcArbiters, func(i, j int) bool {
 			return bytes.Compare(crcArbiters[i].NodePublicKey, crcArbiters[j].NodePublicKey) < 0
@@ -1764,7 +1773,7 @@ func (a *Arbiters) GetCrossChainArbiters() []*ArbiterInfo {
 }
 
 func (a *Arbiters) GetCrossChainArbitersCount() int {
-	if a.bestHeight() < a.ChainParams.CRCOnlyDPOSHeight-1 {
+	if !a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS, a.bestHeight()+1) {
 		return len(a.ChainParams.DPoSConfiguration.OriginArbiters)
 	}
 
@@ -1779,7 +1788,7 @@ func (a *Arbiters) GetCrossChainArbitersMajorityCount() int {
 
 func (a *Arbiters) getNextOnDutyArbitratorV(height, offset uint32) ArbiterMember {
 	// main version is >= H1
-	if height >= a.ChainParams.CRCOnlyDPOSHeight {
+	if a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS, height) {
 		arbitrators := a.CurrentArbitrators
 		if len(arbitrators) == 0 {
 			return nil
@@ -1863,12 +1872,12 @@ func (a *Arbiters) getChangeType(height uint32) (ChangeType, uint32) {
 	}
 
 	// main version >= H2
-	if height > a.ChainParams.PublicDPOSHeight &&
+	if a.ChainParams.IsActiveAfter(config.DeploymentPublicDPOS, height) &&
 		a.DutyIndex == len(a.CurrentArbitrators)-1 {
 		return normalChange, height
 	}
 
-	if height > a.ChainParams.DPoSConfiguration.RevertToPOWStartHeight &&
+	if a.ChainParams.IsActiveAfter(config.DeploymentRevertToPOW, height) &&
 		a.DutyIndex == len(a.ChainParams.DPoSConfiguration.CRCArbiters)+a.ChainParams.DPoSConfiguration.NormalArbitratorsCount-1 {
 		return normalChange, height
 	}
@@ -2101,7 +2110,7 @@ func (a *Arbiters) updateNextTurnInfo(height uint32, producers []ArbiterMember,
 	sort.Slice(a.nextArbitrators, func(i, j int) bool {
 		return bytes.Compare(a.nextArbitrators[i].GetNodePublicKey(), a.nextArbitrators[j].GetNodePublicKey()) < 0
 	})
-	if height >= a.ChainParams.CRConfiguration.CRClaimDPOSNodeStartHeight {
+	if a.ChainParams.IsActive(config.DeploymentCRClaimDPOSNode, height) {
 		//need sent a NextTurnDPOSInfo tx into mempool
 		sort.Slice(nextCRCArbiters, func(i, j int) bool {
 			return bytes.Compare(nextCRCArbiters[i].GetNodePublicKey(), nextCRCArbiters[j].GetNodePublicKey()) < 0
@@ -2149,7 +2158,7 @@ func (a *Arbiters) getSortedProducersDposV2() []*Producer {
 func (a *Arbiters) getSortedProducersWithRandom(height uint32, unclaimedCount int) ([]*Producer, error) {
 
 	votedProducers := a.getSortedProducers()
-	if height < a.ChainParams.DPoSConfiguration.NoCRCDPOSNodeHeight {
+	if !a.ChainParams.IsActive(config.DeploymentNoCRCDPOSNode, height) {
 		return votedProducers, nil
 	}
 
@@ -2281,7 +2290,7 @@ func (a *Arbiters) isDposV2Active() bool {
 
 func (a *Arbiters) UpdateNextArbitrators(versionHeight, height uint32) error {
 
-	if height >= a.ChainParams.CRConfiguration.CRClaimDPOSNodeStartHeight {
+	if a.ChainParams.IsActive(config.DeploymentCRClaimDPOSNode, height) {
 		oriNeedNextTurnDPOSInfo := a.NeedNextTurnDPOSInfo
 		a.History.Append(height, func() {
 			a.NeedNextTurnDPOSInfo = true
@@ -2336,7 +2345,7 @@ func (a *Arbiters) UpdateNextArbitrators(versionHeight, height uint32) error {
 				votedProducers, unclaimed)
 		}
 		if err != nil {
-			if height > a.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+			if a.ChainParams.IsActiveAfter(config.DeploymentChangeCommitteeNewCR, height) {
 				return err
 			}
 			if err := a.tryHandleError(versionHeight, err); err != nil {
@@ -2355,7 +2364,7 @@ func (a *Arbiters) UpdateNextArbitrators(versionHeight, height uint32) error {
 			})
 		} else {
 			if !a.isDposV2Active() {
-				if height >= a.ChainParams.DPoSConfiguration.NoCRCDPOSNodeHeight {
+				if a.ChainParams.IsActive(config.DeploymentNoCRCDPOSNode, height) {
 					count := len(a.ChainParams.DPoSConfiguration.CRCArbiters) + a.ChainParams.DPoSConfiguration.NormalArbitratorsCount
 					var newSelected bool
 					for _, p := range votedProducers {
@@ -2441,9 +2450,9 @@ func (a *Arbiters) resetNextArbiterByCRC(versionHeight uint32, height uint32) (i
 	var needReset bool
 	crcArbiters := map[common.Uint168]ArbiterMember{}
 	if a.CRCommittee != nil && a.CRCommittee.IsInElectionPeriod() {
-		if versionHeight >= a.ChainParams.CRConfiguration.CRClaimDPOSNodeStartHeight {
+		if a.ChainParams.IsActive(config.DeploymentCRClaimDPOSNode, versionHeight) {
 			var err error
-			if versionHeight < a.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+			if !a.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, versionHeight) {
 				if crcArbiters, err = a.getCRCArbitersV1(height); err != nil {
 					return unclaimed, nil, err
 				}
@@ -2459,7 +2468,7 @@ func (a *Arbiters) resetNextArbiterByCRC(versionHeight uint32, height uint32) (i
 			}
 		}
 		needReset = true
-	} else if versionHeight >= a.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+	} else if a.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, versionHeight) {
 		var votedProducers []*Producer
 		if a.isDposV2Active() {
 			votedProducers = a.State.GetDposV2ActiveProducers()
@@ -2500,7 +2509,7 @@ func (a *Arbiters) resetNextArbiterByCRC(versionHeight uint32, height uint32) (i
 		unclaimed = len(a.ChainParams.DPoSConfiguration.CRCArbiters)
 		needReset = true
 
-	} else if versionHeight >= a.ChainParams.CRConfiguration.CRCommitteeStartHeight {
+	} else if a.ChainParams.IsActive(config.DeploymentCRCommittee, versionHeight) {
 		for _, pk := range a.ChainParams.DPoSConfiguration.CRCArbiters {
 			pubKey, err := hex.DecodeString(pk)
 			if err != nil {
@@ -2587,11 +2596,10 @@ func (a *Arbiters) getCRCArbitersV2(height uint32) (map[common.Uint168]ArbiterMe
 	}
 	var unclaimedCount int
 	crcArbiters := map[common.Uint168]ArbiterMember{}
-	claimHeight := a.ChainParams.CRConfiguration.CRClaimDPOSNodeStartHeight
 	for _, cr := range crMembers {
 		var pk []byte
 		if len(cr.DPOSPublicKey) == 0 {
-			if height >= a.ChainParams.DPoSConfiguration.CRDPoSNodeHotFixHeight {
+			if a.ChainParams.IsActive(config.DeploymentCRDPoSNodeHotFix, height) {
 				//if cr.MemberState != state.MemberElected {
 				var err error
 				pk, err = common.HexStringToBytes(unclaimedArbiterKeys[0])
@@ -2621,7 +2629,8 @@ func (a *Arbiters) getCRCArbitersV2(height uint32) (map[common.Uint168]ArbiterMe
 		}
 		crPublicKey := cr.Info.Code[1 : len(cr.Info.Code)-1]
 		isNormal := true
-		if height >= claimHeight && cr.MemberState != state.MemberElected {
+		if a.ChainParams.IsActive(config.DeploymentCRClaimDPOSNode, height) &&
+			cr.MemberState != state.MemberElected {
 			isNormal = false
 		}
 		ar, err := NewCRCArbiter(pk, crPublicKey, cr, isNormal)
@@ -2663,7 +2672,6 @@ func (a *Arbiters) getCRCArbitersV1(height uint32) (map[common.Uint168]ArbiterMe
 		return strings.Compare(unclaimedArbiterKeys[i], unclaimedArbiterKeys[j]) < 0
 	})
 	crcArbiters := map[common.Uint168]ArbiterMember{}
-	claimHeight := a.ChainParams.CRConfiguration.CRClaimDPOSNodeStartHeight
 	for _, cr := range crMembers {
 		var pk []byte
 		if len(cr.DPOSPublicKey) == 0 {
@@ -2678,7 +2686,8 @@ func (a *Arbiters) getCRCArbitersV1(height uint32) (map[common.Uint168]ArbiterMe
 		}
 		crPublicKey := cr.Info.Code[1 : len(cr.Info.Code)-1]
 		isNormal := true
-		if height >= claimHeight && cr.MemberState != state.MemberElected {
+		if a.ChainParams.IsActive(config.DeploymentCRClaimDPOSNode, height) &&
+			cr.MemberState != state.MemberElected {
 			isNormal = false
 		}
 		ar, err := NewCRCArbiter(pk, crPublicKey, cr, isNormal)
@@ -2716,7 +2725,7 @@ func (a *Arbiters) getCRCArbitersV0() (map[common.Uint168]ArbiterMember, error)
 func (a *Arbiters) GetCandidatesDesc(height uint32, startIndex int,
 	producers []*Producer) ([]ArbiterMember, error) {
 	// main version >= H2
-	if height >= a.ChainParams.PublicDPOSHeight {
+	if a.ChainParams.IsActive(config.DeploymentPublicDPOS, height) {
 		if len(producers) < startIndex {
 			return make([]ArbiterMember, 0), nil
 		}
@@ -2772,12 +2781,12 @@ func (a *Arbiters) GetNormalArbitratorsDesc(height uint32,
 	arbitratorsCount int, producers []*Producer, start int) ([]ArbiterMember, error) {
 
 	// main version >= H2
-	if height >= a.ChainParams.PublicDPOSHeight {
+	if a.ChainParams.IsActive(config.DeploymentPublicDPOS, height) {
 		return a.getNormalArbitratorsDescV2(arbitratorsCount, producers, start)
 	}
 
 	// version [H1, H2)
-	if height >= a.ChainParams.CRCOnlyDPOSHeight {
+	if a.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS, height) {
 		return a.getNormalArbitratorsDescV1()
 	}
 
@@ -2805,7 +2814,7 @@ func (a *Arbiters) snapshotVotesStates(height uint32) error {
 	nextReward.OwnerVotesInRound = make(map[common.Uint168]common.Fixed64, 0)
 	nextReward.TotalVotesInRound = 0
 	for _, ar := range a.nextArbitrators {
-		if height > a.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+		if a.ChainParams.IsActiveAfter(config.DeploymentChangeCommitteeNewCR, height) {
 			if ar.GetType() == CRC && (!ar.IsNormal() ||
 				(len(ar.(*crcArbiter).crMember.DPOSPublicKey) != 0 && ar.IsNormal())) {
 				continue
@@ -1294,9 +1294,9 @@ func (s *State) ProcessBlock(block *types.Block, sponsor []byte, dutyIndex int)
 		if block.Height > s.DPoSV2ActiveHeight {
 			// tpdp get sponsor from cache first
 			s.countArbitratorsInactivityV3(block.Height, sponsor, dutyIndex)
-		} else if block.Height >= s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+		} else if s.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, block.Height) {
 			s.countArbitratorsInactivityV2(block.Height, sponsor)
-		} else if block.Height >= s.ChainParams.CRConfiguration.CRClaimDPOSNodeStartHeight {
+		} else if s.ChainParams.IsActive(config.DeploymentCRClaimDPOSNode, block.Height) {
 			s.countArbitratorsInactivityV1(block.Height, sponsor)
 		} else {
 			s.countArbitratorsInactivityV0(block.Height, sponsor)
@@ -1311,12 +1311,12 @@ func (s *State) ProcessBlock(block *types.Block, sponsor []byte, dutyIndex int)
 	// Commit changes here if no errors found.
 	s.History.Commit(block.Height)
 
-	if block.Height >= s.ChainParams.DPoSV2StartHeight &&
+	if s.ChainParams.IsActive(config.DeploymentDPoSV2, block.Height) &&
 		len(s.WithdrawableTxInfo) != 0 {
 		s.createDposV2ClaimRewardRealWithdrawTransaction(block.Height)
 	}
 
-	if block.Height >= s.ChainParams.DPoSV2StartHeight &&
+	if s.ChainParams.IsActive(config.DeploymentDPoSV2, block.Height) &&
 		len(s.VotesWithdrawableTxInfo) != 0 {
 		s.createRealWithdrawTransaction(block.Height)
 	}
@@ -1718,7 +1718,7 @@ func (s *State) processTransactions(txs []interfaces.Transaction, height uint32)
 		}
 	}
 
-	if height >= s.ChainParams.EnableActivateIllegalHeight &&
+	if s.ChainParams.IsActive(config.DeploymentEnableActivateIllegal, height) &&
 		len(s.IllegalProducers) > 0 {
 		for key, producer := range s.IllegalProducers {
 			if height > producer.activateRequestHeight &&
@@ -2847,7 +2847,7 @@ func (s *State) getIllegalPenaltyByHeight(height uint32) common.Fixed64 {
 	var illegalPenalty common.Fixed64
 	if height >= s.DPoSV2ActiveHeight {
 		illegalPenalty = s.ChainParams.DPoSConfiguration.DPoSV2IllegalPenalty
-	} else if height >= s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+	} else if s.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 		illegalPenalty = s.ChainParams.DPoSConfiguration.IllegalPenalty
 	}
 
@@ -3129,7 +3129,7 @@ func (s *State) processIllegalEvidence(payloadData interfaces.Payload,
 				producer.illegalHeight = height
 				s.IllegalProducers[key] = producer
 				producer.activateRequestHeight = math.MaxUint32
-				if height >= s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if s.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					producer.penalty += illegalPenalty
 				}
 				delete(s.ActivityProducers, key)
@@ -3153,7 +3153,7 @@ func (s *State) processIllegalEvidence(payloadData interfaces.Payload,
 				producer.illegalHeight = height
 				s.IllegalProducers[key] = producer
 				producer.activateRequestHeight = math.MaxUint32
-				if height >= s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if s.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					producer.penalty += illegalPenalty
 				}
 				delete(s.InactiveProducers, key)
@@ -3174,7 +3174,7 @@ func (s *State) processIllegalEvidence(payloadData interfaces.Payload,
 			s.History.Append(height, func() {
 				producer.illegalHeight = height
 				producer.activateRequestHeight = math.MaxUint32
-				if height >= s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if s.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					producer.penalty += illegalPenalty
 				}
 			}, func() {
@@ -3192,7 +3192,7 @@ func (s *State) processIllegalEvidence(payloadData interfaces.Payload,
 				producer.state = Illegal
 				producer.illegalHeight = height
 				s.IllegalProducers[key] = producer
-				if height >= s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if s.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					producer.penalty += illegalPenalty
 				}
 				delete(s.CanceledProducers, key)
@@ -3238,7 +3238,7 @@ func (s *State) setInactiveProducer(producer *Producer, key string,
 	var penalty = s.ChainParams.DPoSConfiguration.InactivePenalty
 	if height < s.VersionStartHeight || height >= s.VersionEndHeight {
 		if !emergency {
-			if height >= s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+			if s.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 				producer.penalty += penalty
 			}
 		} else {
@@ -3276,13 +3276,13 @@ func (s *State) revertSettingInactiveProducer(producer *Producer, key string,
 func (s *State) countArbitratorsInactivityV3(height uint32,
 	sponsor []byte, dutyIndex int) {
 	// check inactive Arbiters after producers has participated in
-	if height < s.ChainParams.DPoSV2StartHeight {
+	if !s.ChainParams.IsActive(config.DeploymentDPoSV2, height) {
 		return
 	}
 
 	lastPosition := dutyIndex == s.ChainParams.DPoSConfiguration.NormalArbitratorsCount+len(s.ChainParams.DPoSConfiguration.CRCArbiters)-1
 
-	isDPOSAsCR := height > s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight
+	isDPOSAsCR := s.ChainParams.IsActiveAfter(config.DeploymentChangeCommitteeNewCR, height)
 
 	// changingArbiters indicates the arbiters that should reset inactive
 	// counting state. With the value of true means the producer is on duty or
@@ -3386,7 +3386,7 @@ func (s *State) updateCRMemberInactiveCountV2(lastPosition, needReset, workedInR
 			member.InactiveCountV2 += 1
 			if member.InactiveCountV2 >= 3 {
 				member.MemberState = state.MemberInactive
-				if height >= s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if s.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					s.updateCRInactivePenalty(member.Info.CID, height)
 				}
 				member.InactiveCountV2 = 0
@@ -3394,7 +3394,7 @@ func (s *State) updateCRMemberInactiveCountV2(lastPosition, needReset, workedInR
 		}, func() {
 			if member.MemberState == state.MemberInactive && member.InactiveCountV2 >= 3 {
 				member.MemberState = state.MemberElected
-				if height >= s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight {
+				if s.ChainParams.IsActive(config.DeploymentChangeCommitteeNewCR, height) {
 					s.revertUpdateCRInactivePenalty(member.Info.CID, height)
 				}
 			}
@@ -3441,11 +3441,11 @@ func (s *State) updateInactiveCountV2(lastPosition, needReset, workedInRound boo
 func (s *State) countArbitratorsInactivityV2(height uint32,
 	sponsor []byte) {
 	// check inactive Arbiters after producers has participated in
-	if height < s.ChainParams.PublicDPOSHeight {
+	if !s.ChainParams.IsActive(config.DeploymentPublicDPOS, height) {
 		return
 	}
 
-	isDPOSAsCR := height > s.ChainParams.CRConfiguration.ChangeCommitteeNewCRHeight
+	isDPOSAsCR := s.ChainParams.IsActiveAfter(config.DeploymentChangeCommitteeNewCR, height)
 
 	// changingArbiters indicates the arbiters that should reset inactive
 	// counting state. With the value of true means the producer is on duty or
@@ -3549,7 +3549,7 @@ func (s *State) CountArbitratorsInactivityV1(height uint32,
 func (s *State) countArbitratorsInactivityV1(height uint32,
 	sponsor []byte) {
 	// check inactive Arbiters after producers has participated in
-	if height < s.ChainParams.PublicDPOSHeight {
+	if !s.ChainParams.IsActive(config.DeploymentPublicDPOS, height) {
 		return
 	}
 	// changingArbiters indicates the arbiters that should reset inactive
@@ -3611,7 +3611,7 @@ func (s *State) countArbitratorsInactivityV1(height uint32,
 func (s *State) countArbitratorsInactivityV0(height uint32,
 	sponsor []byte) {
 	// check inactive Arbiters after producers has participated in
-	if height < s.ChainParams.PublicDPOSHeight {
+	if !s.ChainParams.IsActive(config.DeploymentPublicDPOS, height) {
 		return
 	}
 
@@ -3768,7 +3768,7 @@ func (s *State) GetLastIrreversibleHeight() uint32 {
 }
 
 func (s *State) tryUpdateLastIrreversibleHeight(height uint32) {
-	if height < s.ChainParams.DPoSConfiguration.RevertToPOWStartHeight {
+	if !s.ChainParams.IsActive(config.DeploymentRevertToPOW, height) {
 		return
 	}
 
@@ -3821,7 +3821,7 @@ func (s *State) IsIrreversible(curBlockHeight uint32, detachNodesLen int) bool {
 	s.mtx.RLock()
 	defer s.mtx.RUnlock()
 
-	if curBlockHeight <= s.ChainParams.CRCOnlyDPOSHeight {
+	if !s.ChainParams.IsActiveAfter(config.DeploymentCRCOnlyDPOS, curBlockHeight) {
 		return false
 	}
 
@@ -3834,7 +3834,7 @@ func (s *State) IsIrreversible(curBlockHeight uint32, detachNodesLen int) bool {
 	log.Debug("IsIrreversible curBlockHeight:", curBlockHeight,
 		"RevertToPOWStartHeight:", s.ChainParams.DPoSConfiguration.RevertToPOWStartHeight,
 		"detachNodesLen", detachNodesLen, "ConsensusAlgorithm:", s.ConsensusAlgorithm.String())
-	if curBlockHeight >= s.ChainParams.DPoSConfiguration.RevertToPOWStartHeight {
+	if s.ChainParams.IsActive(config.DeploymentRevertToPOW, curBlockHeight) {
 		if s.ConsensusAlgorithm == DPOS {
 			if detachNodesLen >= IrreversibleHeight {
 				return true
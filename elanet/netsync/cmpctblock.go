@@ -0,0 +1,279 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package netsync
+
+import (
+	"errors"
+	"sync/atomic"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/crypto"
+	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/elanet/peer"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+)
+
+// cmpctBlockMsg packages a cmpctblock message and the peer it came from
+// together so the block handler has access to that information.
+type cmpctBlockMsg struct {
+	block *msg.CmpctBlock
+	peer  *peer.Peer
+	reply chan struct{}
+}
+
+// blockTxnMsg packages a blocktxn message and the peer it came from together
+// so the block handler has access to that information.
+type blockTxnMsg struct {
+	blockTxn *msg.BlockTxn
+	peer     *peer.Peer
+	reply    chan struct{}
+}
+
+// partialBlock is a compact block waiting for the transactions missing from
+// the transaction pool, which are requested from the peer sending it.
+type partialBlock struct {
+	peer    *peer.Peer
+	block   *types.DposBlock
+	missing []uint32
+}
+
+// supportsCmpctBlocks returns whether or not the peer relays new blocks by
+// compact blocks.
+func supportsCmpctBlocks(peer *peer.Peer) bool {
+	return peer.Services()&pact.SFNodeCompactBlocks == pact.SFNodeCompactBlocks
+}
+
+// blockInvVect returns the inventory vector to request the announced block
+// from the peer.  A compact block is requested if the peer supports it and we
+// are current, otherwise the transactions are unlikely in our pool.
+func (sm *SyncManager) blockInvVect(peer *peer.Peer, iv *msg.InvVect) *msg.InvVect {
+	if !supportsCmpctBlocks(peer) || !sm.current() {
+		return iv
+	}
+	switch iv.Type {
+	case msg.InvTypeBlock:
+		return msg.NewInvVect(msg.InvTypeCmpctBlock, &iv.Hash)
+	case msg.InvTypeConfirmedBlock:
+		return msg.NewInvVect(msg.InvTypeCmpctConfirmedBlock, &iv.Hash)
+	}
+	return iv
+}
+
+// requestFullBlock requests the full block from the peer, which is used when
+// the compact block can not be reconstructed.  The block is still in the
+// request maps, so it will be accepted when received.
+func (sm *SyncManager) requestFullBlock(peer *peer.Peer, blockHash common.Uint256,
+	haveConfirm bool) {
+	delete(sm.partialBlocks, blockHash)
+
+	invType := msg.InvTypeBlock
+	if haveConfirm {
+		invType = msg.InvTypeConfirmedBlock
+	}
+	gdmsg := msg.NewGetData()
+	gdmsg.AddInvVect(msg.NewInvVect(invType, &blockHash))
+	peer.QueueMessage(gdmsg, nil)
+}
+
+// reconstructBlock fills the transactions of the compact block from the
+// prefilled transactions and the transaction pool, and returns the indexes of
+// the transactions not found.  Transactions whose short ids collide are
+// treated as not found.
+func (sm *SyncManager) reconstructBlock(cmpct *msg.CmpctBlock) (
+	*types.DposBlock, []uint32, error) {
+	txs := make([]interfaces.Transaction, cmpct.TxCount())
+	for _, prefilled := range cmpct.PrefilledTxs {
+		if int(prefilled.Index) >= len(txs) {
+			return nil, nil, errors.New("prefilled transaction index " +
+				"out of range")
+		}
+		txs[prefilled.Index] = prefilled.Tx
+	}
+
+	// Assign the short ids to the positions not prefilled in order.
+	positions := make(map[uint64]int, len(cmpct.ShortIDs))
+	next := 0
+	for i := range txs {
+		if txs[i] != nil {
+			continue
+		}
+		if next >= len(cmpct.ShortIDs) {
+			return nil, nil, errors.New("duplicate prefilled transaction")
+		}
+		id := cmpct.ShortIDs[next]
+		if _, exists := positions[id]; exists {
+			return nil, nil, errors.New("duplicate short transaction id")
+		}
+		positions[id] = i
+		next++
+	}
+
+	k0, k1 := cmpct.ShortIDKeys()
+	collided := make(map[int]struct{})
+	for _, tx := range sm.txMemPool.GetTxsInPool() {
+		i, exists := positions[msg.ShortTxID(k0, k1, tx.Hash())]
+		if !exists {
+			continue
+		}
+		if txs[i] != nil {
+			txs[i] = nil
+			collided[i] = struct{}{}
+			continue
+		}
+		if _, exists := collided[i]; !exists {
+			txs[i] = tx
+		}
+	}
+
+	var missing []uint32
+	for i, tx := range txs {
+		if tx == nil {
+			missing = append(missing, uint32(i))
+		}
+	}
+
+	block := &types.DposBlock{
+		Block: &types.Block{
+			Header:       cmpct.Header,
+			Transactions: txs,
+		},
+		HaveConfirm: cmpct.HaveConfirm,
+		Confirm:     cmpct.Confirm,
+	}
+	return block, missing, nil
+}
+
+// processCmpctBlock processes the reconstructed block like a block received
+// in full, or requests the full block if the transactions do not match the
+// merkle root because of short id collisions.
+func (sm *SyncManager) processCmpctBlock(peer *peer.Peer, block *types.DposBlock) {
+	blockHash := block.Hash()
+	delete(sm.partialBlocks, blockHash)
+
+	hashes := make([]common.Uint256, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		hashes = append(hashes, tx.Hash())
+	}
+	root, err := crypto.ComputeRoot(hashes)
+	if err != nil || !root.IsEqual(block.Header.MerkleRoot) {
+		log.Debugf("Failed to reconstruct compact block %s from %s, "+
+			"requesting full block", blockHash, peer)
+		sm.requestFullBlock(peer, blockHash, block.HaveConfirm)
+		return
+	}
+
+	sm.handleBlockMsg(&blockMsg{block: block, peer: peer})
+}
+
+// handleCmpctBlockMsg handles cmpctblock messages from all peers.  The block
+// is reconstructed from the transaction pool, and the missing transactions
+// are requested by a getblocktxn message.
+func (sm *SyncManager) handleCmpctBlockMsg(cmsg *cmpctBlockMsg) {
+	peer := cmsg.peer
+	state, exists := sm.peerStates[peer]
+	if !exists {
+		log.Warnf("Received compact block message from unknown peer %s",
+			peer)
+		return
+	}
+
+	// If we didn't ask for this block then the peer is misbehaving.
+	blockHash := cmsg.block.Header.Hash()
+	_, blockExist := state.requestedBlocks[blockHash]
+	_, confirmedBlockExist := state.requestedConfirmedBlocks[blockHash]
+	if !blockExist && !confirmedBlockExist {
+		log.Warnf("Got unrequested compact block %v from %s -- "+
+			"disconnecting", blockHash, peer)
+		peer.Disconnect()
+		return
+	}
+
+	block, missing, err := sm.reconstructBlock(cmsg.block)
+	if err != nil {
+		log.Debugf("Invalid compact block %s from %s: %v", blockHash,
+			peer, err)
+		sm.requestFullBlock(peer, blockHash, cmsg.block.HaveConfirm)
+		return
+	}
+	if len(missing) == 0 {
+		sm.processCmpctBlock(peer, block)
+		return
+	}
+
+	log.Debugf("Requesting %d of %d transactions of compact block %s "+
+		"from %s", len(missing), len(block.Transactions), blockHash, peer)
+	sm.partialBlocks[blockHash] = &partialBlock{
+		peer:    peer,
+		block:   block,
+		missing: missing,
+	}
+	peer.QueueMessage(msg.NewGetBlockTxn(blockHash, missing), nil)
+}
+
+// handleBlockTxnMsg handles blocktxn messages from all peers.  The
+// transactions are filled into the compact block waiting for them.
+func (sm *SyncManager) handleBlockTxnMsg(bmsg *blockTxnMsg) {
+	peer := bmsg.peer
+	blockHash := bmsg.blockTxn.BlockHash
+	partial, exists := sm.partialBlocks[blockHash]
+	if !exists || partial.peer != peer {
+		log.Debugf("Ignoring unrequested block transactions of %s "+
+			"from %s", blockHash, peer)
+		return
+	}
+
+	if len(bmsg.blockTxn.Txs) != len(partial.missing) {
+		log.Debugf("Got %d of %d requested transactions of compact "+
+			"block %s from %s, requesting full block",
+			len(bmsg.blockTxn.Txs), len(partial.missing), blockHash, peer)
+		sm.requestFullBlock(peer, blockHash, partial.block.HaveConfirm)
+		return
+	}
+	for i, index := range partial.missing {
+		partial.block.Transactions[index] = bmsg.blockTxn.Txs[i]
+	}
+	sm.processCmpctBlock(peer, partial.block)
+}
+
+// releasePartialBlocks removes the compact blocks waiting for transactions
+// from the peer.
+func (sm *SyncManager) releasePartialBlocks(peer *peer.Peer) {
+	for hash, partial := range sm.partialBlocks {
+		if partial.peer == peer {
+			delete(sm.partialBlocks, hash)
+		}
+	}
+}
+
+// QueueCmpctBlock adds the passed compact block message and peer to the block
+// handling queue. Responds to the done channel argument after the compact
+// block message is processed.
+func (sm *SyncManager) QueueCmpctBlock(block *msg.CmpctBlock, peer *peer.Peer,
+	done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+
+	sm.msgChan <- &cmpctBlockMsg{block: block, peer: peer, reply: done}
+}
+
+// QueueBlockTxn adds the passed blocktxn message and peer to the block
+// handling queue. Responds to the done channel argument after the message is
+// processed.
+func (sm *SyncManager) QueueBlockTxn(blockTxn *msg.BlockTxn, peer *peer.Peer,
+	done chan struct{}) {
+	// Don't accept more blocks if we're shutting down.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		done <- struct{}{}
+		return
+	}
+
+	sm.msgChan <- &blockTxnMsg{blockTxn: blockTxn, peer: peer, reply: done}
+}
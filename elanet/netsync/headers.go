@@ -0,0 +1,400 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package netsync
+
+import (
+	"container/list"
+	"fmt"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/elanet/peer"
+	"github.com/elastos/Elastos.ELA/errors"
+	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/p2p/msg"
+)
+
+const (
+	// maxPendingHeaders is the maximum number of validated headers whose
+	// blocks are not connected yet, no more headers are requested until
+	// the blocks catch up.
+	maxPendingHeaders = msg.MaxHeadersPerMsg * 10
+
+	// blockDownloadWindow is the number of blocks after the last connected
+	// block which can be requested, it limits the blocks received out of
+	// order and waiting for their parents.
+	blockDownloadWindow = 1024
+
+	// maxInFlightPerPeer is the maximum number of blocks requested from a
+	// peer at the same time.
+	maxInFlightPerPeer = 16
+
+	// blockStallTimeout is the maximum allowable interval since a block is
+	// requested from a peer, the peer is disconnected if it stalls and the
+	// blocks requested from it are fetched from other peers.
+	blockStallTimeout = time.Minute * 2
+
+	// stallSampleInterval is the interval of time between each check for
+	// stalled block requests.
+	stallSampleInterval = time.Second * 30
+)
+
+// headersMsg packages a headers message and the peer it came from together
+// so the block handler has access to that information.
+type headersMsg struct {
+	headers *msg.Headers
+	peer    *peer.Peer
+}
+
+// headerNode is a validated header of the headers-first mode, the block of it
+// is requested from a peer and then connected in the order of headers.
+type headerNode struct {
+	node      *blockchain.BlockNode
+	peer      *peer.Peer
+	requested time.Time
+	block     *types.DposBlock
+}
+
+// supportsHeaders returns whether or not the peer is a full node which
+// responds to getheaders messages.
+func supportsHeaders(peer *peer.Peer) bool {
+	services := pact.SFNodeNetwork | pact.SFNodeHeaders
+	return peer.Services()&services == services
+}
+
+// startHeadersFirst switches to the headers-first mode, the header chain
+// starts from the best block of the chain.
+func (sm *SyncManager) startHeadersFirst() {
+	sm.headersFirstMode = true
+	sm.headerList = list.New()
+	sm.headerIndex = make(map[common.Uint256]*list.Element)
+	sm.headerTip = sm.chain.BestChain
+	sm.headersRequested = false
+	sm.headersSynced = false
+}
+
+// resetHeadersFirst leaves the headers-first mode, the blocks requested by
+// header nodes are removed from request maps so they can be fetched again.
+func (sm *SyncManager) resetHeadersFirst() {
+	for e := sm.headerList.Front(); e != nil; e = e.Next() {
+		sm.releaseHeaderNode(e.Value.(*headerNode))
+	}
+	sm.headersFirstMode = false
+	sm.headerList = nil
+	sm.headerIndex = nil
+	sm.headerTip = nil
+	sm.headersRequested = false
+	sm.headersSynced = false
+}
+
+// finishHeadersFirst leaves the headers-first mode if all headers are received
+// and their blocks are connected.  If the sync peer is lost before getting all
+// headers, syncing is restarted from other peers.
+func (sm *SyncManager) finishHeadersFirst() {
+	if !sm.headersFirstMode || sm.headerList.Len() > 0 {
+		return
+	}
+	if !sm.headersSynced && sm.syncPeer != nil {
+		return
+	}
+
+	restart := !sm.headersSynced
+	log.Infof("Headers-first sync finished at height %d",
+		sm.chain.GetHeight())
+	sm.resetHeadersFirst()
+	sm.syncPeer = nil
+	if restart {
+		sm.startSync()
+	}
+}
+
+// requestHeaders requests the headers after the header chain tip from the
+// sync peer if the pending headers are not too many.
+func (sm *SyncManager) requestHeaders() {
+	if sm.syncPeer == nil || sm.headersRequested || sm.headersSynced ||
+		sm.headerList.Len() >= maxPendingHeaders {
+		return
+	}
+
+	// The tip of header chain may be unknown by the sync peer, so append
+	// the locator of the best chain to find the fork point.
+	locator := []*common.Uint256{sm.headerTip.Hash}
+	chainLocator, err := sm.chain.LatestBlockLocator()
+	if err != nil {
+		log.Warnf("Failed to get block locator for the latest block: %v",
+			err)
+	}
+	locator = append(locator, chainLocator...)
+	if len(locator) > msg.MaxBlockLocatorsPerMsg {
+		locator = locator[:msg.MaxBlockLocatorsPerMsg]
+	}
+
+	sm.headersRequested = true
+	sm.syncPeer.PushGetHeadersMsg(locator, &zeroHash)
+}
+
+// handleHeadersMsg handles headers messages from the sync peer.  The headers
+// are validated and appended to the header chain, then the blocks of them are
+// requested from peers.
+func (sm *SyncManager) handleHeadersMsg(hmsg *headersMsg) {
+	peer := hmsg.peer
+	if _, exists := sm.peerStates[peer]; !exists {
+		log.Warnf("Received headers message from unknown peer %s", peer)
+		return
+	}
+
+	if !sm.headersFirstMode || peer != sm.syncPeer || !sm.headersRequested {
+		log.Debugf("Ignoring unrequested headers from %s", peer)
+		return
+	}
+	sm.headersRequested = false
+
+	headers := hmsg.headers.Headers
+	for _, header := range headers {
+		hash := header.Hash()
+		prevNode := sm.headerTip
+		if !header.Previous.IsEqual(*prevNode.Hash) {
+			// The sync peer is on a fork of our best chain, leave the
+			// headers-first mode and let the chain select the best
+			// chain from the blocks.
+			_, known := sm.chain.LookupNodeInIndex(&header.Previous)
+			if known && sm.headerList.Len() == 0 {
+				log.Infof("Headers from %s fork from the best chain, "+
+					"switch to blocks syncing", peer)
+				sm.resetHeadersFirst()
+				sm.pushGetBlocks(peer)
+				return
+			}
+
+			log.Warnf("Received block header %v from %s which does not "+
+				"connect to the header chain -- disconnecting", hash, peer)
+			peer.Disconnect()
+			return
+		}
+
+		if err := sm.checkHeader(header, prevNode); err != nil {
+			log.Warnf("Received invalid block header %v from %s: %v "+
+				"-- disconnecting", hash, peer, err)
+			peer.Disconnect()
+			return
+		}
+
+		node := blockchain.NewBlockNode(header, &hash)
+		node.Parent = prevNode
+		sm.headerIndex[hash] = sm.headerList.PushBack(&headerNode{node: node})
+		sm.headerTip = node
+	}
+
+	sm.syncStartTime = time.Now()
+	if len(headers) < msg.MaxHeadersPerMsg {
+		sm.headersSynced = true
+	}
+	if len(headers) > 0 {
+		log.Infof("Received %d headers from %s, header chain height %d",
+			len(headers), peer, sm.headerTip.Height)
+	}
+
+	sm.requestHeaders()
+	sm.fetchHeaderBlocks()
+	sm.finishHeadersFirst()
+}
+
+// checkHeader validates the header against the previous node of the header
+// chain.
+func (sm *SyncManager) checkHeader(header *common2.Header,
+	prevNode *blockchain.BlockNode) error {
+	if header.Height != prevNode.Height+1 {
+		return fmt.Errorf("header height %d does not follow %d",
+			header.Height, prevNode.Height)
+	}
+	if err := sm.chain.CheckHeaderSanity(header); err != nil {
+		return err
+	}
+	return sm.chain.CheckHeaderContext(header, prevNode)
+}
+
+// fetchHeaderBlocks requests the blocks of header nodes in the download
+// window from the peers with the least blocks in flight.
+func (sm *SyncManager) fetchHeaderBlocks() {
+	if !sm.headersFirstMode {
+		return
+	}
+
+	now := time.Now()
+	requests := make(map[*peer.Peer]*msg.GetData)
+	e := sm.headerList.Front()
+	for i := 0; e != nil && i < blockDownloadWindow; i++ {
+		hn := e.Value.(*headerNode)
+		e = e.Next()
+		if hn.peer != nil {
+			continue
+		}
+
+		peer, state := sm.blockPeer(hn.node.Height)
+		if peer == nil {
+			break
+		}
+
+		hash := *hn.node.Hash
+		sm.requestedConfirmedBlocks[hash] = struct{}{}
+		sm.limitMap(sm.requestedConfirmedBlocks, maxRequestedBlocks)
+		state.requestedConfirmedBlocks[hash] = struct{}{}
+		hn.peer = peer
+		hn.requested = now
+
+		gdmsg, ok := requests[peer]
+		if !ok {
+			gdmsg = msg.NewGetData()
+			requests[peer] = gdmsg
+		}
+		gdmsg.AddInvVect(msg.NewInvVect(msg.InvTypeConfirmedBlock, &hash))
+	}
+
+	for peer, gdmsg := range requests {
+		peer.QueueMessage(gdmsg, nil)
+	}
+}
+
+// blockPeer returns the full node peer with the least blocks in flight which
+// has the block of the height.
+func (sm *SyncManager) blockPeer(height uint32) (*peer.Peer, *peerSyncState) {
+	var bestPeer *peer.Peer
+	var bestState *peerSyncState
+	for peer, state := range sm.peerStates {
+		if !sm.isSyncCandidate(peer) || !peer.Connected() ||
+			peer.Height() < height {
+			continue
+		}
+
+		inFlight := len(state.requestedConfirmedBlocks)
+		if inFlight >= maxInFlightPerPeer {
+			continue
+		}
+		if bestState == nil ||
+			inFlight < len(bestState.requestedConfirmedBlocks) {
+			bestPeer, bestState = peer, state
+		}
+	}
+	return bestPeer, bestState
+}
+
+// handleHeaderBlock stores the block of the header node, then connects the
+// received blocks in the order of the header chain.
+func (sm *SyncManager) handleHeaderBlock(hn *headerNode, bmsg *blockMsg) {
+	hn.block = bmsg.block
+	hn.peer = bmsg.peer
+
+	for e := sm.headerList.Front(); e != nil; e = sm.headerList.Front() {
+		hn := e.Value.(*headerNode)
+		if hn.block == nil {
+			break
+		}
+		sm.headerList.Remove(e)
+		delete(sm.headerIndex, *hn.node.Hash)
+
+		blockHash := *hn.node.Hash
+		log.Debugf("Receive block %s at height %d", blockHash,
+			hn.node.Height)
+		_, _, err := sm.blockMemPool.AddDposBlock(hn.block)
+		if err != nil {
+			log.Warn("add block error:", err)
+			elaErr := errors.SimpleWithMessage(errors.ErrP2pReject, err,
+				fmt.Sprintf("Rejected block %v from %s", blockHash, hn.peer))
+			hn.peer.PushRejectMsg(p2p.CmdBlock, elaErr, &blockHash, false)
+
+			// The header chain can not be trusted any more, start
+			// over from the best chain.
+			hn.peer.Disconnect()
+			sm.resetHeadersFirst()
+			sm.syncPeer = nil
+			sm.startSync()
+			return
+		}
+		sm.syncStartTime = time.Now()
+
+		// Link the next header node to the connected block node, so
+		// the header nodes connected before can be released.
+		if next := sm.headerList.Front(); next != nil {
+			if node, ok := sm.chain.LookupNodeInIndex(&blockHash); ok {
+				next.Value.(*headerNode).node.Parent = node
+			}
+		}
+	}
+
+	sm.requestHeaders()
+	sm.fetchHeaderBlocks()
+	sm.finishHeadersFirst()
+}
+
+// releaseHeaderNode removes the block request of the header node if it is
+// not received yet.
+func (sm *SyncManager) releaseHeaderNode(hn *headerNode) {
+	if hn.peer == nil || hn.block != nil {
+		return
+	}
+
+	hash := *hn.node.Hash
+	delete(sm.requestedConfirmedBlocks, hash)
+	if state, exists := sm.peerStates[hn.peer]; exists {
+		delete(state.requestedConfirmedBlocks, hash)
+	}
+	hn.peer = nil
+}
+
+// releasePeerBlocks removes the block requests of header nodes sent to the
+// peer, so they will be requested from other peers.
+func (sm *SyncManager) releasePeerBlocks(peer *peer.Peer) {
+	e := sm.headerList.Front()
+	for i := 0; e != nil && i < blockDownloadWindow; i++ {
+		if hn := e.Value.(*headerNode); hn.peer == peer {
+			sm.releaseHeaderNode(hn)
+		}
+		e = e.Next()
+	}
+}
+
+// handleStallSample disconnects the peers which have not sent the requested
+// blocks in time, and requests the blocks from other peers.
+func (sm *SyncManager) handleStallSample() {
+	if !sm.headersFirstMode {
+		return
+	}
+
+	now := time.Now()
+	e := sm.headerList.Front()
+	for i := 0; e != nil && i < blockDownloadWindow; i++ {
+		hn := e.Value.(*headerNode)
+		e = e.Next()
+		if hn.peer == nil || hn.block != nil ||
+			now.Sub(hn.requested) < blockStallTimeout {
+			continue
+		}
+
+		stalled := hn.peer
+		log.Warnf("Peer %s has not sent block %v for more than %v "+
+			"-- disconnecting", stalled, hn.node.Hash, blockStallTimeout)
+		sm.releasePeerBlocks(stalled)
+		stalled.Disconnect()
+	}
+
+	sm.fetchHeaderBlocks()
+}
+
+// pushGetBlocks requests the inventory of blocks after the best chain from the
+// peer.
+func (sm *SyncManager) pushGetBlocks(peer *peer.Peer) {
+	locator, err := sm.chain.LatestBlockLocator()
+	if err != nil {
+		log.Errorf("Failed to get block locator for the "+
+			"latest block: %v", err)
+		return
+	}
+	peer.PushGetBlocksMsg(locator, &zeroHash)
+}
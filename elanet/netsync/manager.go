@@ -6,6 +6,7 @@
 package netsync
 
 import (
+	"container/list"
 	"fmt"
 	"sync"
 	"sync/atomic"
@@ -23,6 +24,7 @@ import (
 	"github.com/elastos/Elastos.ELA/mempool"
 	"github.com/elastos/Elastos.ELA/p2p"
 	"github.com/elastos/Elastos.ELA/p2p/msg"
+	"github.com/elastos/Elastos.ELA/utils/elalog"
 )
 
 const (
@@ -136,6 +138,17 @@ type SyncManager struct {
 	syncStartTime            time.Time
 	syncHeight               uint32
 	peerStates               map[*peer.Peer]*peerSyncState
+
+	// The following fields are used for headers-first mode.
+	headersFirstMode bool
+	headerList       *list.List
+	headerIndex      map[common.Uint256]*list.Element
+	headerTip        *blockchain.BlockNode
+	headersRequested bool
+	headersSynced    bool
+
+	// partialBlocks are the compact blocks waiting for transactions.
+	partialBlocks map[common.Uint256]*partialBlock
 }
 
 // startSync will choose the best peer among the available candidate peers to
@@ -149,9 +162,12 @@ func (sm *SyncManager) startSync() {
 	}
 
 	bestHeight := sm.chain.GetHeight()
+	if sm.headersFirstMode {
+		bestHeight = sm.headerTip.Height
+	}
 	var bestPeer *peer.Peer
 	for peer, state := range sm.peerStates {
-		if !state.syncCandidate {
+		if !state.syncCandidate || !peer.Connected() {
 			continue
 		}
 
@@ -166,9 +182,16 @@ func (sm *SyncManager) startSync() {
 			continue
 		}
 
-		// Just pick the first available candidate.
-		bestPeer = peer
-		break
+		// Pick the first available candidate, prefer the peers
+		// supporting headers-first mode.  Only these peers can continue
+		// the header chain in headers-first mode.
+		if supportsHeaders(peer) {
+			bestPeer = peer
+			break
+		}
+		if bestPeer == nil && !sm.headersFirstMode {
+			bestPeer = peer
+		}
 	}
 
 	// Start syncing from the best peer if one was selected.
@@ -178,12 +201,37 @@ func (sm *SyncManager) startSync() {
 			return
 		}
 
+		// Continue the header chain from the new sync peer, the blocks
+		// already requested from other peers are still in flight.
+		if sm.headersFirstMode {
+			log.Infof("Syncing headers from height %d from peer %v",
+				bestHeight, bestPeer.Addr())
+			sm.syncPeer = bestPeer
+			sm.syncHeight = bestPeer.Height()
+			sm.syncStartTime = time.Now()
+			sm.requestHeaders()
+			return
+		}
+
 		// Clear the requestedBlocks if the sync peer changes, otherwise
 		// we may ignore blocks we need that the last sync peer failed
 		// to send.
 		sm.requestedBlocks = make(map[common.Uint256]struct{})
 		sm.requestedConfirmedBlocks = make(map[common.Uint256]struct{})
 
+		// Download and validate the header chain ahead of the blocks,
+		// then the blocks are fetched from all full node peers.
+		if supportsHeaders(bestPeer) && sm.chain.BestChain != nil {
+			log.Infof("Syncing to block height %d from peer %v in "+
+				"headers-first mode", bestPeer.Height(), bestPeer.Addr())
+			sm.syncPeer = bestPeer
+			sm.syncHeight = bestPeer.Height()
+			sm.syncStartTime = time.Now()
+			sm.startHeadersFirst()
+			sm.requestHeaders()
+			return
+		}
+
 		locator, err := sm.chain.LatestBlockLocator()
 		if err != nil {
 			log.Errorf("Failed to get block locator for the "+
@@ -211,6 +259,11 @@ func (sm *SyncManager) isSyncCandidate(peer *peer.Peer) bool {
 		return false
 	}
 
+	// The peer is not a candidate for sync if it has pruned old blocks.
+	if peer.Services()&pact.SFNodePruned == pact.SFNodePruned {
+		return false
+	}
+
 	// Candidate if all checks passed.
 	return true
 }
@@ -239,6 +292,11 @@ func (sm *SyncManager) handleNewPeerMsg(peer *peer.Peer) {
 	if isSyncCandidate && sm.syncPeer == nil {
 		sm.startSync()
 	}
+
+	// Fetch blocks of the header chain from the new peer too.
+	if isSyncCandidate && sm.headersFirstMode {
+		sm.fetchHeaderBlocks()
+	}
 }
 
 // handleDonePeerMsg deals with peers that have signalled they are done.  It
@@ -273,13 +331,29 @@ func (sm *SyncManager) handleDonePeerMsg(peer *peer.Peer) {
 	for blockHash := range state.requestedConfirmedBlocks {
 		delete(sm.requestedConfirmedBlocks, blockHash)
 	}
+
+	// Compact blocks waiting for the transactions from the peer will be
+	// fetched from elsewhere next time we get an inv.
+	sm.releasePartialBlocks(peer)
+
+	// Release the blocks requested from the peer in headers-first mode so
+	// they will be requested from other peers.
+	if sm.headersFirstMode {
+		sm.releasePeerBlocks(peer)
+	}
+
 	// Attempt to find a new peer to sync from if the quitting peer is the
-	// sync peer.  Also, reset the headers-first state if in headers-first
-	// mode so
+	// sync peer.  In headers-first mode the header chain is continued by
+	// the new sync peer.
 	if sm.syncPeer == peer {
 		sm.syncPeer = nil
 		sm.startSync()
 	}
+
+	if sm.headersFirstMode {
+		sm.fetchHeaderBlocks()
+		sm.finishHeadersFirst()
+	}
 }
 
 // handleTxMsg handles transaction messages from all peers.
@@ -368,7 +442,7 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 	// Remove block from request maps. Either chain will know about it and
 	// so we shouldn't have any more instances of trying to fetch it, or we
 	// will fail the insert and thus we'll retry next time we get an inv.
-	if bmsg.block.Block.Height < sm.chainParams.CRCOnlyDPOSHeight {
+	if !sm.chainParams.IsActive(config.DeploymentCRCOnlyDPOS, bmsg.block.Block.Height) {
 		_, confirmedBlockExist := state.requestedConfirmedBlocks[blockHash]
 		if confirmedBlockExist {
 			delete(state.requestedConfirmedBlocks, blockHash)
@@ -413,13 +487,26 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		}
 	}
 
+	// The blocks of the header chain are connected in order in
+	// headers-first mode.
+	if sm.headersFirstMode {
+		if e, exists := sm.headerIndex[blockHash]; exists {
+			sm.handleHeaderBlock(e.Value.(*headerNode), bmsg)
+			return
+		}
+	}
+
 	// GetProcessor the block to include validation, best chain selection, orphan
 	// handling, etc.
-	log.Debugf("Receive block %s at height %d", blockHash,
-		bmsg.block.Block.Height)
+	fields := elalog.Fields{
+		"height": bmsg.block.Block.Height,
+		"hash":   blockHash.String(),
+		"peer":   peer.String(),
+	}
+	log.Debug("Receive block", fields)
 	_, isOrphan, err := sm.blockMemPool.AddDposBlock(bmsg.block)
 	if err != nil {
-		log.Warn("add block error:", err)
+		log.Warn("add block error:", err, fields)
 		elaErr := errors.SimpleWithMessage(errors.ErrP2pReject, err,
 			fmt.Sprintf("Rejected block %v from %s", blockHash, peer))
 
@@ -439,7 +526,8 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 		"sm.syncHeight:", sm.syncHeight, "isOrphan:", isOrphan)
 
 	// Request the parents for the orphan block from the peer that sent it.
-	if isOrphan {
+	// The parents are in the header chain in headers-first mode.
+	if isOrphan && !sm.headersFirstMode {
 		orphanRoot := sm.chain.GetOrphanRoot(&blockHash)
 		locator, err := sm.chain.LatestBlockLocator()
 		if err != nil {
@@ -455,7 +543,7 @@ func (sm *SyncManager) handleBlockMsg(bmsg *blockMsg) {
 				peer.PushGetBlocksMsg(locator, orphanRoot)
 			}
 		}
-	} else {
+	} else if !isOrphan {
 		// Clear the rejected transactions.
 		sm.rejectedTxns = make(map[common.Uint256]struct{})
 	}
@@ -517,7 +605,8 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 	// not be one.
 	invVects := imsg.inv.InvList
 
-	if sm.syncPeer != nil && time.Now().After(sm.syncStartTime.Add(syncTimeout)) {
+	if sm.syncPeer != nil && !sm.headersFirstMode &&
+		time.Now().After(sm.syncStartTime.Add(syncTimeout)) {
 		log.Warnf("sync peer %s has not received block for more than %d "+
 			"seconds, -- disconnecting", sm.syncPeer, syncTimeout)
 		sm.syncPeer.Disconnect()
@@ -548,6 +637,12 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 		// for the peer.
 		peer.AddKnownInventory(iv)
 
+		// Blocks are fetched by the header chain in headers-first
+		// mode.
+		if sm.headersFirstMode && iv.Type != msg.InvTypeTx {
+			continue
+		}
+
 		// Request the inventory if we don't already have it.
 		haveInv, err := sm.haveInventory(iv)
 		if err != nil {
@@ -622,7 +717,7 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 				sm.requestedBlocks[iv.Hash] = struct{}{}
 				sm.limitMap(sm.requestedBlocks, maxRequestedBlocks)
 				state.requestedBlocks[iv.Hash] = struct{}{}
-				gdmsg.AddInvVect(iv)
+				gdmsg.AddInvVect(sm.blockInvVect(peer, iv))
 				numRequested++
 			}
 		case msg.InvTypeConfirmedBlock:
@@ -632,7 +727,7 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 				sm.requestedConfirmedBlocks[iv.Hash] = struct{}{}
 				sm.limitMap(sm.requestedConfirmedBlocks, maxRequestedBlocks)
 				state.requestedConfirmedBlocks[iv.Hash] = struct{}{}
-				gdmsg.AddInvVect(iv)
+				gdmsg.AddInvVect(sm.blockInvVect(peer, iv))
 				numRequested++
 			}
 		case msg.InvTypeTx:
@@ -657,7 +752,7 @@ func (sm *SyncManager) handleInvMsg(imsg *invMsg) {
 	}
 
 	// maxBlockLocators = 500
-	if len(invVects) == 500 {
+	if len(invVects) == 500 && !sm.headersFirstMode {
 		locator := sm.chain.GetOrphanBlockLocator(invVects)
 		log.Info("PushGetBlocksMsg 2:", locator, "count:", len(gdmsg.InvList))
 		if err := peer.PushGetBlocksMsg(locator, &zeroHash); err != nil {
@@ -691,6 +786,9 @@ func (sm *SyncManager) limitMap(m map[common.Uint256]struct{}, limit int) {
 // important because the sync manager controls which blocks are needed and how
 // the fetching should proceed.
 func (sm *SyncManager) blockHandler() {
+	stallTicker := time.NewTicker(stallSampleInterval)
+	defer stallTicker.Stop()
+
 out:
 	for {
 		select {
@@ -707,9 +805,20 @@ out:
 				sm.handleBlockMsg(msg)
 				msg.reply <- struct{}{}
 
+			case *cmpctBlockMsg:
+				sm.handleCmpctBlockMsg(msg)
+				msg.reply <- struct{}{}
+
+			case *blockTxnMsg:
+				sm.handleBlockTxnMsg(msg)
+				msg.reply <- struct{}{}
+
 			case *invMsg:
 				sm.handleInvMsg(msg)
 
+			case *headersMsg:
+				sm.handleHeadersMsg(msg)
+
 			case *donePeerMsg:
 				sm.handleDonePeerMsg(msg.peer)
 
@@ -732,6 +841,9 @@ out:
 					"handler: %T", msg)
 			}
 
+		case <-stallTicker.C:
+			sm.handleStallSample()
+
 		case <-sm.quit:
 			break out
 		}
@@ -958,6 +1070,18 @@ func (sm *SyncManager) QueueInv(inv *msg.Inv, peer *peer.Peer) {
 	sm.msgChan <- &invMsg{inv: inv, peer: peer}
 }
 
+// QueueHeaders adds the passed headers message and peer to the block handling
+// queue.
+func (sm *SyncManager) QueueHeaders(headers *msg.Headers, peer *peer.Peer) {
+	// No channel handling here because peers do not need to block on
+	// headers messages.
+	if atomic.LoadInt32(&sm.shutdown) != 0 {
+		return
+	}
+
+	sm.msgChan <- &headersMsg{headers: headers, peer: peer}
+}
+
 // DonePeer informs the blockmanager that a peer has disconnected.
 func (sm *SyncManager) DonePeer(peer *peer.Peer) {
 	// Ignore if we are shutting down.
@@ -1033,6 +1157,7 @@ func New(config *Config) *SyncManager {
 		requestedBlocks:          make(map[common.Uint256]struct{}),
 		requestedConfirmedBlocks: make(map[common.Uint256]struct{}),
 		peerStates:               make(map[*peer.Peer]*peerSyncState),
+		partialBlocks:            make(map[common.Uint256]*partialBlock),
 		msgChan:                  make(chan interface{}, config.MaxPeers*3),
 		quit:                     make(chan struct{}),
 	}
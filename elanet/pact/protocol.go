@@ -55,13 +55,29 @@ const (
 
 	// SFNodeBloom is a flag used to indicate a peer supports bloom filtering.
 	SFNodeBloom
+
+	// SFNodeHeaders is a flag used to indicate a peer supports getheaders and
+	// headers messages, which are used by the headers-first block download.
+	SFNodeHeaders
+
+	// SFNodePruned is a flag used to indicate a peer has deleted the data of
+	// old blocks, so it is not able to serve the full block chain.
+	SFNodePruned
+
+	// SFNodeCompactBlocks is a flag used to indicate a peer supports
+	// cmpctblock, getblocktxn and blocktxn messages, which are used to relay
+	// new blocks by the short ids of their transactions.
+	SFNodeCompactBlocks
 )
 
 // Map of service flags back to their constant names for pretty printing.
 var sfStrings = map[ServiceFlag]string{
-	SFNodeNetwork: "SFNodeNetwork",
-	SFTxFiltering: "SFTxFiltering",
-	SFNodeBloom:   "SFNodeBloom",
+	SFNodeNetwork:       "SFNodeNetwork",
+	SFTxFiltering:       "SFTxFiltering",
+	SFNodeBloom:         "SFNodeBloom",
+	SFNodeHeaders:       "SFNodeHeaders",
+	SFNodePruned:        "SFNodePruned",
+	SFNodeCompactBlocks: "SFNodeCompactBlocks",
 }
 
 // orderedSFStrings is an ordered list of service flags from highest to
@@ -70,6 +86,9 @@ var orderedSFStrings = []ServiceFlag{
 	SFNodeNetwork,
 	SFTxFiltering,
 	SFNodeBloom,
+	SFNodeHeaders,
+	SFNodePruned,
+	SFNodeCompactBlocks,
 }
 
 // String returns the ServiceFlag in human-readable form.
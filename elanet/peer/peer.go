@@ -79,6 +79,23 @@ type Listeners struct {
 	// message.
 	OnGetBlocks func(p *Peer, msg *msg.GetBlocks)
 
+	// OnGetHeaders is invoked when a peer receives a getheaders
+	// message.
+	OnGetHeaders func(p *Peer, msg *msg.GetHeaders)
+
+	// OnHeaders is invoked when a peer receives a headers message.
+	OnHeaders func(p *Peer, msg *msg.Headers)
+
+	// OnCmpctBlock is invoked when a peer receives a cmpctblock message.
+	OnCmpctBlock func(p *Peer, msg *msg.CmpctBlock)
+
+	// OnGetBlockTxn is invoked when a peer receives a getblocktxn
+	// message.
+	OnGetBlockTxn func(p *Peer, msg *msg.GetBlockTxn)
+
+	// OnBlockTxn is invoked when a peer receives a blocktxn message.
+	OnBlockTxn func(p *Peer, msg *msg.BlockTxn)
+
 	// OnFilterAdd is invoked when a peer receives a filteradd message.
 	OnFilterAdd func(p *Peer, msg *msg.FilterAdd)
 
@@ -189,6 +206,14 @@ func (p *Peer) PushGetBlocksMsg(locator []*common.Uint256, stopHash *common.Uint
 	return nil
 }
 
+// PushGetHeadersMsg sends a getheaders message for the provided block locator
+// and stop hash.
+//
+// This function is safe for concurrent access.
+func (p *Peer) PushGetHeadersMsg(locator []*common.Uint256, stopHash *common.Uint256) {
+	p.QueueMessage(msg.NewGetHeaders(locator, *stopHash), nil)
+}
+
 // PushRejectMsg sends a reject message for the provided command, reject code,
 // reject reason, and hash.  The hash will only be used when the command is a tx
 // or block and should be nil in other cases.  The wait parameter will cause the
@@ -243,9 +268,19 @@ func (p *Peer) maybeAddDeadline(pendingResponses map[string]time.Time, msgCmd st
 		// Expects an inv message.
 		pendingResponses[p2p.CmdInv] = deadline
 
+	case p2p.CmdGetHeaders:
+		// Expects a headers message.
+		pendingResponses[p2p.CmdHeaders] = deadline
+
+	case p2p.CmdGetBlockTxn:
+		// Expects a blocktxn message.
+		pendingResponses[p2p.CmdBlockTxn] = deadline
+
 	case p2p.CmdGetData:
-		// Expects all block, merkleblock, tx, notfound or daddr message.
+		// Expects all block, cmpctblock, merkleblock, tx, notfound or daddr
+		// message.
 		pendingResponses[p2p.CmdBlock] = deadline
+		pendingResponses[p2p.CmdCmpctBlock] = deadline
 		pendingResponses[p2p.CmdMerkleBlock] = deadline
 		pendingResponses[p2p.CmdTx] = deadline
 		pendingResponses[p2p.CmdNotFound] = deadline
@@ -293,12 +328,15 @@ out:
 				switch msgCmd := msg.MSG.CMD(); msgCmd {
 				case p2p.CmdBlock:
 					fallthrough
+				case p2p.CmdCmpctBlock:
+					fallthrough
 				case p2p.CmdMerkleBlock:
 					fallthrough
 				case p2p.CmdTx:
 					fallthrough
 				case p2p.CmdNotFound:
 					delete(pendingResponses, p2p.CmdBlock)
+					delete(pendingResponses, p2p.CmdCmpctBlock)
 					delete(pendingResponses, p2p.CmdMerkleBlock)
 					delete(pendingResponses, p2p.CmdTx)
 					delete(pendingResponses, p2p.CmdNotFound)
@@ -536,6 +574,21 @@ func New(orgPeer server.IPeer, listeners *Listeners) *Peer {
 		case *msg.GetBlocks:
 			listeners.OnGetBlocks(p, m)
 
+		case *msg.GetHeaders:
+			listeners.OnGetHeaders(p, m)
+
+		case *msg.Headers:
+			listeners.OnHeaders(p, m)
+
+		case *msg.CmpctBlock:
+			listeners.OnCmpctBlock(p, m)
+
+		case *msg.GetBlockTxn:
+			listeners.OnGetBlockTxn(p, m)
+
+		case *msg.BlockTxn:
+			listeners.OnBlockTxn(p, m)
+
 		case *msg.FilterAdd:
 			listeners.OnFilterAdd(p, m)
 
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
 	"sync/atomic"
 	"time"
@@ -41,7 +42,8 @@ import (
 const (
 	// defaultServices describes the default services that are supported by
 	// the NetServer.
-	defaultServices = pact.SFNodeNetwork | pact.SFTxFiltering | pact.SFNodeBloom
+	defaultServices = pact.SFNodeNetwork | pact.SFTxFiltering | pact.SFNodeBloom |
+		pact.SFNodeHeaders | pact.SFNodeCompactBlocks
 
 	// maxNonNodePeers defines the maximum count of accepting non-node peers.
 	maxNonNodePeers = 100
@@ -288,6 +290,60 @@ func (sp *ServerPeer) OnBlock(_ *peer.Peer, msgBlock *msg.Block) {
 	<-sp.blockProcessed
 }
 
+// OnCmpctBlock is invoked when a peer receives a cmpctblock message.  It
+// blocks until the compact block has been processed, which may reconstruct
+// and process the block, or request its missing transactions.
+func (sp *ServerPeer) OnCmpctBlock(_ *peer.Peer, m *msg.CmpctBlock) {
+	blockHash := m.Header.Hash()
+	iv := msg.NewInvVect(msg.InvTypeBlock, &blockHash)
+	if m.HaveConfirm {
+		iv.Type = msg.InvTypeConfirmedBlock
+	}
+
+	// Add the block to the known inventory for the peer.
+	sp.AddKnownInventory(iv)
+
+	sp.server.SyncManager.QueueCmpctBlock(m, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
+// OnGetBlockTxn is invoked when a peer receives a getblocktxn message.  It
+// sends the requested transactions of the block, which the remote peer
+// failed to find in its transaction pool when reconstructing the compact
+// block.
+func (sp *ServerPeer) OnGetBlockTxn(_ *peer.Peer, m *msg.GetBlockTxn) {
+	block, _ := sp.server.blockMemPool.GetDposBlockByHash(m.BlockHash)
+	if block == nil {
+		block, _ = sp.server.chain.GetDposBlockByHash(m.BlockHash)
+		if block == nil {
+			log.Debugf("%s requested transactions of unknown block %s",
+				sp, m.BlockHash)
+			return
+		}
+	}
+
+	txs := make([]interfaces.Transaction, 0, len(m.Indexes))
+	for _, index := range m.Indexes {
+		if index >= uint32(len(block.Transactions)) {
+			log.Debugf("%s requested out of range transaction %d of "+
+				"block %s -- disconnecting", sp, index, m.BlockHash)
+			sp.AddBanScore(100, 0, m.CMD())
+			sp.Disconnect()
+			return
+		}
+		txs = append(txs, block.Transactions[index])
+	}
+	sp.QueueMessage(msg.NewBlockTxn(m.BlockHash, txs), nil)
+}
+
+// OnBlockTxn is invoked when a peer receives a blocktxn message.  It blocks
+// until the transactions have been filled into the compact block and the
+// block has been processed.
+func (sp *ServerPeer) OnBlockTxn(_ *peer.Peer, m *msg.BlockTxn) {
+	sp.server.SyncManager.QueueBlockTxn(m, sp.Peer, sp.blockProcessed)
+	<-sp.blockProcessed
+}
+
 // OnInv is invoked when a peer receives an inv message and is
 // used to examine the inventory being advertised by the remote peer and react
 // accordingly.  We pass the message down to blockmanager which will call
@@ -357,6 +413,10 @@ func (sp *ServerPeer) OnGetData(_ *peer.Peer, getData *msg.GetData) {
 			err = sp.server.pushBlockMsg(sp, &iv.Hash, c, waitChan)
 		case msg.InvTypeConfirmedBlock:
 			err = sp.server.pushConfirmedBlockMsg(sp, &iv.Hash, c, waitChan)
+		case msg.InvTypeCmpctBlock:
+			err = sp.server.pushCmpctBlockMsg(sp, &iv.Hash, false, c, waitChan)
+		case msg.InvTypeCmpctConfirmedBlock:
+			err = sp.server.pushCmpctBlockMsg(sp, &iv.Hash, true, c, waitChan)
 		case msg.InvTypeFilteredBlock:
 			err = sp.server.pushMerkleBlockMsg(sp, &iv.Hash, c, waitChan)
 		case msg.InvTypeAddress:
@@ -436,6 +496,26 @@ func (sp *ServerPeer) OnGetBlocks(_ *peer.Peer, m *msg.GetBlocks) {
 	}
 }
 
+// OnGetHeaders is invoked when a peer receives a getheaders message.
+func (sp *ServerPeer) OnGetHeaders(_ *peer.Peer, m *msg.GetHeaders) {
+	// Find the most recent known block in the best chain based on the block
+	// locator and fetch all of the headers after it until either
+	// msg.MaxHeadersPerMsg have been fetched or the provided stop hash is
+	// encountered.
+	//
+	// A headers message is always sent, even if it is empty, so the remote
+	// peer knows it has got all the headers we have.
+	headers := sp.server.chain.LocateHeaders(m.Locator, &m.HashStop,
+		msg.MaxHeadersPerMsg)
+	sp.QueueMessage(msg.NewHeaders(headers), nil)
+}
+
+// OnHeaders is invoked when a peer receives a headers message.  The message
+// is passed down to the sync manager.
+func (sp *ServerPeer) OnHeaders(_ *peer.Peer, m *msg.Headers) {
+	sp.server.SyncManager.QueueHeaders(m, sp.Peer)
+}
+
 // enforceTxFilterFlag disconnects the peer if the NetServer is not configured to
 // allow tx filters.  Additionally, if the peer has negotiated to a protocol
 // version  that is high enough to observe the bloom filter service support bit,
@@ -683,6 +763,36 @@ func (s *NetServer) pushConfirmedBlockMsg(sp *ServerPeer, hash *common.Uint256,
 	return nil
 }
 
+// pushCmpctBlockMsg sends a cmpctblock message for the provided block hash to
+// the connected peer, the confirm of the block is included if requested.  An
+// error is returned if the block hash is not known.
+func (s *NetServer) pushCmpctBlockMsg(sp *ServerPeer, hash *common.Uint256,
+	confirmed bool, doneChan chan<- struct{}, waitChan <-chan struct{}) error {
+
+	// Fetch the block from the block pool or the database.
+	block, _ := s.blockMemPool.GetDposBlockByHash(*hash)
+	if block == nil || (confirmed && !block.HaveConfirm) {
+		block, _ = s.chain.GetDposBlockByHash(*hash)
+	}
+	if block == nil || (confirmed && !block.HaveConfirm) {
+		if doneChan != nil {
+			doneChan <- struct{}{}
+		}
+		return errors.New("compact block not found")
+	}
+	if !confirmed {
+		block = &types.DposBlock{Block: block.Block}
+	}
+
+	// Once we have fetched data wait for any previous operation to finish.
+	if waitChan != nil {
+		<-waitChan
+	}
+
+	sp.QueueMessage(msg.NewCmpctBlock(block, rand.Uint64()), doneChan)
+	return nil
+}
+
 // pushMerkleBlockMsg sends a merkleblock message for the provided block hash to
 // the connected peer.  Since a merkle block requires the peer to have a filter
 // loaded, this call will simply be ignored if there is no filter loaded.  An
@@ -806,7 +916,7 @@ func (s *NetServer) handleRelayInvMsg(peers map[svr.IPeer]*ServerPeer, rmsg rela
 			// Compatible for old version SPV client.
 			if sp.filter.IsLoaded() {
 				// Do not send unconfirmed block to SPV client after H1.
-				if current >= s.ChainParams.CRCOnlyDPOSHeight-1 &&
+				if s.ChainParams.IsActive(config.DeploymentCRCOnlyDPOS, current+1) &&
 					s.chain.GetState().ConsensusAlgorithm != state.POW &&
 					rmsg.invVect.Type == msg.InvTypeBlock {
 					continue
@@ -919,6 +1029,11 @@ func (s *NetServer) HandlePeerMsg(peers map[svr.IPeer]*ServerPeer, p interface{}
 			OnNotFound:     sp.OnNotFound,
 			OnGetData:      sp.OnGetData,
 			OnGetBlocks:    sp.OnGetBlocks,
+			OnGetHeaders:   sp.OnGetHeaders,
+			OnHeaders:      sp.OnHeaders,
+			OnCmpctBlock:   sp.OnCmpctBlock,
+			OnGetBlockTxn:  sp.OnGetBlockTxn,
+			OnBlockTxn:     sp.OnBlockTxn,
 			OnFilterAdd:    sp.OnFilterAdd,
 			OnFilterClear:  sp.OnFilterClear,
 			OnFilterLoad:   sp.OnFilterLoad,
@@ -1003,6 +1118,12 @@ func NewServer(dataDir string, cfg *Config, nodeVersion string) (*NetServer, err
 		services &^= pact.SFNodeBloom
 		services &^= pact.SFTxFiltering
 	}
+	// Nodes bootstrapped from a snapshot lack the old blocks in the same way
+	// as pruned nodes.
+	_, fromSnapshot := cfg.Chain.SnapshotHeight()
+	if params.PruneTargetSize > 0 || fromSnapshot {
+		services |= pact.SFNodePruned
+	}
 
 	// If no listeners added, create default listener.
 	if len(params.ListenAddrs) == 0 {
@@ -1081,6 +1202,21 @@ func createMessage(hdr p2p.Header, r net.Conn) (p2p.Message, error) {
 	case p2p.CmdGetBlocks:
 		message = &msg.GetBlocks{}
 
+	case p2p.CmdGetHeaders:
+		message = &msg.GetHeaders{}
+
+	case p2p.CmdHeaders:
+		message = &msg.Headers{}
+
+	case p2p.CmdCmpctBlock:
+		message = &msg.CmpctBlock{}
+
+	case p2p.CmdGetBlockTxn:
+		message = &msg.GetBlockTxn{}
+
+	case p2p.CmdBlockTxn:
+		message = &msg.BlockTxn{}
+
 	case p2p.CmdFilterAdd:
 		message = &msg.FilterAdd{}
 
@@ -85,6 +85,7 @@ const (
 	ErrTxPoolDoubleSpend          ErrCode = -71005
 	ErrTxPoolTypeCastFailure      ErrCode = -71006
 	ErrTxPoolTxDuplicate          ErrCode = -71007
+	ErrTxPoolReplacementRejected  ErrCode = -71008
 )
 
 type SimpleErr struct {
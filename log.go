@@ -21,102 +21,17 @@ import (
 	"github.com/elastos/Elastos.ELA/elanet/netsync"
 	"github.com/elastos/Elastos.ELA/elanet/peer"
 	"github.com/elastos/Elastos.ELA/elanet/routes"
+	"github.com/elastos/Elastos.ELA/mempool"
 	"github.com/elastos/Elastos.ELA/p2p/addrmgr"
 	"github.com/elastos/Elastos.ELA/p2p/connmgr"
+	"github.com/elastos/Elastos.ELA/servers/httpjsonrpc"
+	"github.com/elastos/Elastos.ELA/servers/httprestful"
+	"github.com/elastos/Elastos.ELA/servers/httpwebsocket"
 	"github.com/elastos/Elastos.ELA/utils/elalog"
 
 	"gopkg.in/cheggaaa/pb.v1"
 )
 
-type logWrapper struct {
-	level  elalog.Level
-	logger elalog.Logger
-}
-
-func (l *logWrapper) Debug(a ...interface{}) {
-	if l.level > elalog.LevelDebug {
-		return
-	}
-	l.logger.Debug(a...)
-}
-
-func (l *logWrapper) Debugf(format string, a ...interface{}) {
-	if l.level > elalog.LevelDebug {
-		return
-	}
-	l.logger.Debugf(format, a...)
-}
-
-func (l *logWrapper) Info(a ...interface{}) {
-	if l.level > elalog.LevelInfo {
-		return
-	}
-	l.logger.Info(a...)
-}
-
-func (l *logWrapper) Infof(format string, a ...interface{}) {
-	if l.level > elalog.LevelInfo {
-		return
-	}
-	l.logger.Infof(format, a...)
-}
-
-func (l *logWrapper) Warn(a ...interface{}) {
-	if l.level > elalog.LevelWarn {
-		return
-	}
-	l.logger.Warn(a...)
-}
-
-func (l *logWrapper) Warnf(format string, a ...interface{}) {
-	if l.level > elalog.LevelWarn {
-		return
-	}
-	l.logger.Warnf(format, a...)
-}
-
-func (l *logWrapper) Error(a ...interface{}) {
-	if l.level > elalog.LevelError {
-		return
-	}
-	l.logger.Error(a...)
-}
-
-func (l *logWrapper) Errorf(format string, a ...interface{}) {
-	if l.level > elalog.LevelError {
-		return
-	}
-	l.logger.Errorf(format, a...)
-}
-
-func (l *logWrapper) Fatal(a ...interface{}) {
-	if l.level > elalog.LevelFatal {
-		return
-	}
-	l.logger.Fatal(a...)
-}
-
-func (l *logWrapper) Fatalf(format string, a ...interface{}) {
-	if l.level > elalog.LevelFatal {
-		return
-	}
-	l.logger.Fatalf(format, a...)
-}
-
-// Level returns the current logging level.
-func (l *logWrapper) Level() elalog.Level {
-	return l.level
-}
-
-// SetLevel changes the logging level to the passed level.
-func (l *logWrapper) SetLevel(level elalog.Level) {
-	l.level = level
-}
-
-func wrap(logger *log.Logger, level elalog.Level) *logWrapper {
-	return &logWrapper{logger: logger, level: level}
-}
-
 const (
 	// progressRefreshRate indicates the duration between refresh progress.
 	progressRefreshRate = time.Millisecond * 500
@@ -181,16 +96,19 @@ func setupLog(s *config.Configuration) {
 		s.MaxPerLogSize, s.MaxLogsSize)
 	pgBar = newProgress(logger.Writer())
 
-	admrlog := wrap(logger, elalog.LevelOff)
-	cmgrlog := wrap(logger, elalog.LevelOff)
-	hublog := wrap(logger, elalog.LevelOff)
-	synclog := wrap(logger, elalog.Level(s.PrintLevel))
-	peerlog := wrap(logger, elalog.Level(s.PrintLevel))
-	routlog := wrap(logger, elalog.Level(s.PrintLevel))
-	elanlog := wrap(logger, elalog.Level(s.PrintLevel))
-	statlog := wrap(logger, elalog.Level(s.PrintLevel))
-	crstatlog := wrap(logger, elalog.Level(s.PrintLevel))
-	txslog := wrap(logger, elalog.Level(s.PrintLevel))
+	level := elalog.Level(s.PrintLevel)
+	admrlog := logger.Subsystem("addrmgr", elalog.LevelOff)
+	cmgrlog := logger.Subsystem("connmgr", elalog.LevelOff)
+	hublog := logger.Subsystem("hub", elalog.LevelOff)
+	synclog := logger.Subsystem("netsync", level)
+	peerlog := logger.Subsystem("peer", level)
+	routlog := logger.Subsystem("routes", level)
+	elanlog := logger.Subsystem("elanet", level)
+	statlog := logger.Subsystem("dposstate", level)
+	crstatlog := logger.Subsystem("cr", level)
+	txslog := logger.Subsystem("transaction", level)
+	mempoollog := logger.Subsystem("mempool", level)
+	rpclog := logger.Subsystem("rpc", level)
 
 	addrmgr.UseLogger(admrlog)
 	connmgr.UseLogger(cmgrlog)
@@ -202,4 +120,20 @@ func setupLog(s *config.Configuration) {
 	crstate.UseLogger(crstatlog)
 	transaction.UseLogger(txslog)
 	hub.UseLogger(hublog)
+	mempool.UseLogger(mempoollog)
+	httpjsonrpc.UseLogger(rpclog)
+	httprestful.UseLogger(rpclog)
+	httpwebsocket.UseLogger(rpclog)
+
+	if err := log.SetFormat(s.LogFormat); err != nil {
+		printErrorAndExit(err)
+	}
+}
+
+// setupLogLevels applies the configured log levels of subsystems, which is
+// done after all subsystems are registered.
+func setupLogLevels(s *config.Configuration) {
+	if err := log.SetLevels(s.LogLevels); err != nil {
+		log.Warn("Invalid log levels,", err)
+	}
 }
@@ -17,26 +17,24 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/elastos/Elastos.ELA/account/signer"
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/common/config/settings"
 	"github.com/elastos/Elastos.ELA/common/log"
-	"github.com/elastos/Elastos.ELA/core/checkpoint"
 	"github.com/elastos/Elastos.ELA/core/types"
-	crstate "github.com/elastos/Elastos.ELA/cr/state"
 	"github.com/elastos/Elastos.ELA/dpos"
 	"github.com/elastos/Elastos.ELA/dpos/account"
 	dlog "github.com/elastos/Elastos.ELA/dpos/log"
 	msg2 "github.com/elastos/Elastos.ELA/dpos/p2p/msg"
-	"github.com/elastos/Elastos.ELA/dpos/state"
 	"github.com/elastos/Elastos.ELA/elanet"
 	"github.com/elastos/Elastos.ELA/elanet/routes"
-	"github.com/elastos/Elastos.ELA/mempool"
+	"github.com/elastos/Elastos.ELA/node"
 	"github.com/elastos/Elastos.ELA/p2p"
 	"github.com/elastos/Elastos.ELA/p2p/msg"
-	"github.com/elastos/Elastos.ELA/pow"
 	"github.com/elastos/Elastos.ELA/servers"
 	"github.com/elastos/Elastos.ELA/servers/httpjsonrpc"
+	"github.com/elastos/Elastos.ELA/servers/httpmetrics"
 	"github.com/elastos/Elastos.ELA/servers/httpnodeinfo"
 	"github.com/elastos/Elastos.ELA/servers/httprestful"
 	"github.com/elastos/Elastos.ELA/servers/httpwebsocket"
@@ -52,8 +50,9 @@ const (
 	// nodeLogPath indicates the path storing the node log.
 	nodeLogPath = "logs/node"
 
-	// checkpointPath indicates the path storing the checkpoint data.
-	checkpointPath = "checkpoints"
+	// protectionPath indicates the path storing the proposals and votes
+	// signed by the arbiter.
+	protectionPath = "protection"
 
 	// nodePrefix indicates the prefix of node version.
 	nodePrefix = "ela-"
@@ -107,11 +106,27 @@ func startNode(cfg *config.Configuration) {
 	}
 	dataDir := filepath.Join(flagDataDir, dataPath)
 
-	ckpManager := checkpoint.NewManager(cfg)
-	ckpManager.SetDataPath(filepath.Join(dataDir, checkpointPath))
-
 	var acc account.Account
+	var policy *signer.Policy
 	if cfg.DPoSConfiguration.EnableArbiter {
+		var err error
+		policy, err = signer.OpenPolicy(filepath.Join(dataDir, protectionPath))
+		if err != nil {
+			printErrorAndExit(err)
+		}
+		defer policy.Close()
+		policy.SetRefusal(cfg.DPoSConfiguration.RefuseSigning)
+		policy.Log = log.Warnf
+	}
+	if cfg.DPoSConfiguration.EnableArbiter &&
+		cfg.DPoSConfiguration.SignerSocket != "" {
+		// The arbiter key lives in a remote signer, no keystore is opened.
+		var err error
+		acc, err = account.Dial(cfg.DPoSConfiguration.SignerSocket, policy)
+		if err != nil {
+			printErrorAndExit(err)
+		}
+	} else if cfg.DPoSConfiguration.EnableArbiter {
 		var err error
 		var password []byte
 		if cfg.Password != "" {
@@ -122,66 +137,28 @@ func startNode(cfg *config.Configuration) {
 		if err != nil {
 			printErrorAndExit(err)
 		}
-		acc, err = account.Open(password, cfg.WalletPath)
+		acc, err = account.Open(password, cfg.WalletPath, policy)
 		if err != nil {
 			printErrorAndExit(err)
 		}
 	}
 	var interrupt = signal.NewInterrupt()
 
-	// fixme remove singleton Ledger
-	ledger := blockchain.Ledger{}
-
-	// Initializes the foundation address
-	blockchain.FoundationAddress = *cfg.FoundationProgramHash
-	chainStore, err := blockchain.NewChainStore(dataDir, cfg)
-	if err != nil {
-		printErrorAndExit(err)
-	}
-	defer chainStore.Close()
-	ledger.Store = chainStore // fixme
-
-	txMemPool := mempool.NewTxPool(cfg, ckpManager)
-	blockMemPool := mempool.NewBlockPool(cfg)
-	blockMemPool.Store = chainStore
-
-	blockchain.DefaultLedger = &ledger // fixme
-
-	committee := crstate.NewCommittee(cfg, ckpManager)
-	ledger.Committee = committee
-
-	arbiters, err := state.NewArbitrators(cfg, committee, ledger.GetAmount,
-		committee.TryUpdateCRMemberInactivity,
-		committee.TryRevertCRMemberInactivity,
-		committee.TryUpdateCRMemberIllegal,
-		committee.TryRevertCRMemberIllegal,
-		committee.UpdateCRInactivePenalty,
-		committee.RevertUpdateCRInactivePenalty,
-		ckpManager,
-	)
-	if err != nil {
-		printErrorAndExit(err)
-	}
-	ledger.Arbitrators = arbiters // fixme
-
-	chain, err := blockchain.New(chainStore, cfg,
-		arbiters.State, committee, ckpManager)
+	n, err := node.New(&node.Config{
+		ChainParams:  cfg,
+		DataDir:      dataDir,
+		SnapshotFile: cfg.SnapshotFile,
+		Interrupt:    interrupt.C,
+		BarStart:     pgBar.Start,
+		BarIncrease:  pgBar.Increase,
+	})
 	if err != nil {
 		printErrorAndExit(err)
 	}
-	if err = chain.Init(interrupt.C); err != nil {
-		printErrorAndExit(err)
-	}
-	if err = chain.MigrateOldDB(interrupt.C, pgBar.Start,
-		pgBar.Increase, dataDir, cfg); err != nil {
-		printErrorAndExit(err)
-	}
+	defer n.Close()
 	pgBar.Stop()
-
-	ledger.Blockchain = chain // fixme
-	blockMemPool.Chain = chain
-	arbiters.RegisterFunction(chain.GetHeight, chain.GetBestBlockHash,
-		chain.GetBlock, chain.UTXOCache.GetTxReference)
+	chain, chainStore := n.Chain, n.Store
+	txMemPool, blockMemPool, arbiters := n.TxPool, n.BlockPool, n.Arbiters
 
 	routesCfg := &routes.Config{TimeSource: chain.TimeSource}
 	if acc != nil {
@@ -205,17 +182,8 @@ func startNode(cfg *config.Configuration) {
 	}
 	routesCfg.IsCurrent = netServer.IsCurrent
 	routesCfg.RelayAddr = netServer.RelayInventory
-	blockMemPool.IsCurrent = netServer.IsCurrent
-
-	arbiters.State.RegisterFuncitons(&state.StateFuncsConfig{
-		GetHeight: chainStore.GetHeight,
-		IsCurrent: netServer.IsCurrent,
-		Broadcast: func(msg p2p.Message) {
-			netServer.BroadcastMessage(msg)
-		},
-		AppendToTxpool:                      txMemPool.AppendToTxPool,
-		CreateDposV2RealWithdrawTransaction: chain.CreateDposV2RealWithdrawTransaction,
-		CreateVotesRealWithdrawTransaction:  chain.CreateVotesRealWithdrawTransaction,
+	n.RegisterFunctions(netServer.IsCurrent, func(msg p2p.Message) {
+		netServer.BroadcastMessage(msg)
 	})
 
 	if acc != nil {
@@ -243,21 +211,7 @@ func startNode(cfg *config.Configuration) {
 		arbitrator.Start()
 		defer arbitrator.Stop()
 	}
-
-	committee.RegisterFuncitons(&crstate.CommitteeFuncsConfig{
-		GetTxReference:                   chain.UTXOCache.GetTxReference,
-		GetUTXO:                          chainStore.GetFFLDB().GetUTXO,
-		GetHeight:                        chainStore.GetHeight,
-		CreateCRAppropriationTransaction: chain.CreateCRCAppropriationTransaction,
-		CreateCRAssetsRectifyTransaction: chain.CreateCRAssetsRectifyTransaction,
-		CreateCRRealWithdrawTransaction:  chain.CreateCRRealWithdrawTransaction,
-		IsCurrent:                        netServer.IsCurrent,
-		Broadcast: func(msg p2p.Message) {
-			netServer.BroadcastMessage(msg)
-		},
-		AppendToTxpool:     txMemPool.AppendToTxPool,
-		GetCurrentArbiters: arbiters.GetCurrentArbitratorKeys,
-	})
+	setupLogLevels(cfg)
 
 	servers.Compile = Version
 	servers.ChainParams = cfg
@@ -266,28 +220,25 @@ func startNode(cfg *config.Configuration) {
 	servers.TxMemPool = txMemPool
 	servers.Server = netServer
 	servers.Arbiters = arbiters
-	servers.Pow = pow.NewService(&pow.Config{
-		PayToAddr:   cfg.PowConfiguration.PayToAddr,
-		MinerInfo:   cfg.PowConfiguration.MinerInfo,
-		Chain:       chain,
-		ChainParams: cfg,
-		TxMemPool:   txMemPool,
-		BlkMemPool:  blockMemPool,
-		BroadcastBlock: func(block *types.Block) {
-			hash := block.Hash()
-			netServer.RelayInventory(msg.NewInvVect(msg.InvTypeBlock, &hash), block)
-		},
-		Arbitrators: arbiters,
+	servers.Pow = n.NewPowService(func(block *types.Block) {
+		hash := block.Hash()
+		netServer.RelayInventory(msg.NewInvVect(msg.InvTypeBlock, &hash), block)
 	})
 
-	ckpManager.SetNeedSave(true)
 	// initialize producer state after arbiters has initialized.
-	if err = chain.InitCheckpoint(interrupt.C, pgBar.Start,
-		pgBar.Increase); err != nil {
+	if err = n.InitCheckpoint(pgBar.Start, pgBar.Increase); err != nil {
 		printErrorAndExit(err)
 	}
 	pgBar.Stop()
 
+	if cfg.SnapshotVerifyHeaders {
+		go func() {
+			if err := chain.VerifySnapshotHeaders(interrupt.C); err != nil {
+				log.Errorf("verify snapshot headers failed, %s", err)
+			}
+		}()
+	}
+
 	// todo remove me
 	if chain.GetHeight() > cfg.DPoSV2StartHeight {
 		msg2.SetPayloadVersion(msg2.DPoSV2Version)
@@ -305,6 +256,9 @@ func startNode(cfg *config.Configuration) {
 	netServer.Start()
 	defer netServer.Stop()
 
+	n.LoadMempool()
+	defer n.SaveMempool()
+
 	log.Info("Start services")
 	if cfg.EnableRPC {
 		go httpjsonrpc.StartRPCServer()
@@ -318,6 +272,9 @@ func startNode(cfg *config.Configuration) {
 	if cfg.HttpInfoStart {
 		go httpnodeinfo.StartServer()
 	}
+	if cfg.EnableMetrics {
+		go httpmetrics.StartServer()
+	}
 
 	go printSyncState(chain, netServer)
 
@@ -359,7 +316,11 @@ out:
 }
 
 func printSyncState(bc *blockchain.BlockChain, server elanet.Server) {
-	statlog := elalog.NewBackend(logger.Writer()).Logger("STAT",
+	var flags []uint32
+	if log.IsJSONFormat() {
+		flags = append(flags, elalog.LJSON)
+	}
+	statlog := elalog.NewBackend(logger.Writer(), flags...).Logger("STAT",
 		elalog.LevelInfo)
 
 	ticker := time.NewTicker(printStateInterval)
@@ -13,6 +13,7 @@ import (
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/core/contract/program"
 	"github.com/elastos/Elastos.ELA/core/types"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
@@ -67,7 +68,7 @@ func (bm *BlockPool) getConfirmSigners(confirm *payload.Confirm) ([][]byte, erro
 
 func (bm *BlockPool) CheckConfirmedBlockOnFork(height uint32, block *types.Block) error {
 	// main version >= H2
-	if height >= bm.chainParams.PublicDPOSHeight {
+	if bm.chainParams.IsActive(config.DeploymentPublicDPOS, height) {
 		blockNode := bm.Chain.GetBlockNode(block.Height)
 		if blockNode == nil {
 			return errors.New(fmt.Sprintf("no block at height %d exists", height))
@@ -12,7 +12,6 @@ import (
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/common/config"
-	"github.com/elastos/Elastos.ELA/common/log"
 	"github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
 	"github.com/elastos/Elastos.ELA/dpos/state"
@@ -50,7 +49,7 @@ func (bm *BlockPool) AddDposBlock(dposBlock *types.DposBlock) (bool, bool, error
 		return bm.Chain.ProcessBlock(dposBlock.Block, dposBlock.Confirm)
 	}
 
-	if dposBlock.Block.Height > bm.chainParams.DPoSConfiguration.RevertToPOWStartHeight && !dposBlock.HaveConfirm {
+	if bm.chainParams.IsActiveAfter(config.DeploymentRevertToPOW, dposBlock.Block.Height) && !dposBlock.HaveConfirm {
 		for _, tx := range dposBlock.Transactions {
 			if tx.IsRevertToPOW() {
 				return bm.Chain.ProcessBlock(dposBlock.Block, dposBlock.Confirm)
@@ -59,8 +58,8 @@ func (bm *BlockPool) AddDposBlock(dposBlock *types.DposBlock) (bool, bool, error
 	}
 
 	// main version >=H1
-	if dposBlock.Block.Height >= bm.chainParams.CRCOnlyDPOSHeight {
-		if dposBlock.Block.Height >= bm.chainParams.CRConfiguration.CRCommitteeStartHeight {
+	if bm.chainParams.IsActive(config.DeploymentCRCOnlyDPOS, dposBlock.Block.Height) {
+		if bm.chainParams.IsActive(config.DeploymentCRCommittee, dposBlock.Block.Height) {
 			if len(dposBlock.Block.Transactions) > 0 &&
 				len(dposBlock.Block.Transactions[0].Outputs()) >= 1 &&
 				dposBlock.Block.Transactions[0].Outputs()[0].ProgramHash.
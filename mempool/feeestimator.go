@@ -0,0 +1,490 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/checkpoint"
+	"github.com/elastos/Elastos.ELA/core/types"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/events"
+)
+
+const (
+	// feeEstimatorCheckpointKey defines key of fee estimator checkpoint.
+	feeEstimatorCheckpointKey = "cp_feeEstimator"
+
+	// feeEstimatorCheckpointExtension defines checkpoint file extension of
+	// fee estimator checkpoint.
+	feeEstimatorCheckpointExtension = ".fecp"
+
+	// feeEstimatorCheckpointHeight defines interval height between two
+	// neighbor fee estimator check points.
+	feeEstimatorCheckpointHeight = uint32(6)
+
+	// MaxConfirmTarget is the max number of blocks the fee estimator can
+	// estimate a fee rate for.
+	MaxConfirmTarget = 25
+
+	// minBucketFeeRate is the fee rate of the lowest bucket, the unit is
+	// sela per KB.
+	minBucketFeeRate = 1000
+
+	// maxBucketFeeRate is the upper bound of fee rate buckets, the unit is
+	// sela per KB.
+	maxBucketFeeRate = 1e7
+
+	// bucketFeeRateSpacing is the ratio of fee rates between two neighbor
+	// buckets.
+	bucketFeeRateSpacing = 1.2
+
+	// feeStatsDecay is the factor every statistic is multiplied with on
+	// each new block, so that old data counts less than new one.
+	feeStatsDecay = 0.998
+
+	// successThreshold is the ratio of transactions of a fee rate range
+	// that must have been confirmed within the target to pass the range.
+	successThreshold = 0.85
+
+	// sufficientTxs is the (decayed) count of transactions a fee rate range
+	// must contain before we trust the statistic of the range.
+	sufficientTxs = 2
+
+	// DefaultFeeRate is the fee rate returned when there is no enough data
+	// to estimate, the unit is sela per KB.
+	DefaultFeeRate = 10000
+)
+
+// bucketFeeRates defines lower bound of each fee rate bucket.
+var bucketFeeRates = func() []float64 {
+	rates := make([]float64, 0)
+	for rate := float64(minBucketFeeRate); rate < maxBucketFeeRate; rate *= bucketFeeRateSpacing {
+		rates = append(rates, rate)
+	}
+	return rates
+}()
+
+// feeRateBucket records how many transactions with fee rate within the bucket
+// have been seen and how fast they were confirmed.
+type feeRateBucket struct {
+	// confirmed records the count of transactions which were confirmed within
+	// i+1 blocks at index i.
+	confirmed [MaxConfirmTarget]float64
+
+	// total records the count of transactions which were confirmed or left
+	// the estimator unconfirmed.
+	total float64
+}
+
+// observedTx records a transaction waiting to be confirmed.
+type observedTx struct {
+	height uint32
+	bucket uint32
+}
+
+// FeeEstimation is the result of a fee rate estimation.
+type FeeEstimation struct {
+	// FeeRate is the estimated fee rate, the unit is sela per KB.
+	FeeRate common.Fixed64
+
+	// Confidence is the ratio of transactions with FeeRate which have been
+	// confirmed within the target, zero means there is no enough data and
+	// FeeRate is the default value.
+	Confidence float64
+}
+
+// FeeEstimator estimates the fee rate a transaction need to be confirmed
+// within a number of blocks, by tracking how many blocks transactions accepted
+// by the transaction pool took to confirm.
+type FeeEstimator struct {
+	mtx sync.Mutex
+
+	height     uint32
+	bestHeight uint32
+	buckets    []feeRateBucket
+	observed   map[common.Uint256]observedTx
+	lastBlock  map[common.Uint256]struct{}
+	removed    map[common.Uint256]struct{}
+}
+
+// bucketIndex returns the index of bucket the given fee rate belongs to.
+func bucketIndex(feeRate float64) uint32 {
+	index := sort.Search(len(bucketFeeRates), func(i int) bool {
+		return bucketFeeRates[i] > feeRate
+	})
+	if index > 0 {
+		index--
+	}
+	return uint32(index)
+}
+
+// txFeeRate returns the fee rate of a transaction in sela per KB.
+func txFeeRate(tx interfaces.Transaction) float64 {
+	size := tx.GetSize()
+	if size <= 0 {
+		return 0
+	}
+	return float64(tx.Fee()) * 1000 / float64(size)
+}
+
+// ObserveTransaction starts tracking a transaction accepted by the transaction
+// pool.
+func (f *FeeEstimator) ObserveTransaction(tx interfaces.Transaction) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	hash := tx.Hash()
+	if _, ok := f.observed[hash]; ok {
+		return
+	}
+	// The transaction accepted event is asynchronous, so the transaction may
+	// have been packed already.
+	if _, ok := f.lastBlock[hash]; ok {
+		return
+	}
+	if _, ok := f.removed[hash]; ok {
+		return
+	}
+
+	f.observed[hash] = observedTx{
+		height: f.bestHeight,
+		bucket: bucketIndex(txFeeRate(tx)),
+	}
+}
+
+// RemoveTransaction stops tracking a transaction which left the transaction
+// pool without being confirmed, such as one evicted by a replacement, so it
+// is not counted as failed to be confirmed.
+func (f *FeeEstimator) RemoveTransaction(hash common.Uint256) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	delete(f.observed, hash)
+	// The transaction may not have been observed yet.
+	f.removed[hash] = struct{}{}
+}
+
+// ProcessBlock records the confirmation of observed transactions packed in the
+// given block.
+func (f *FeeEstimator) ProcessBlock(block *types.Block) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	f.bestHeight = block.Height
+
+	for i := range f.buckets {
+		bucket := &f.buckets[i]
+		for j := range bucket.confirmed {
+			bucket.confirmed[j] *= feeStatsDecay
+		}
+		bucket.total *= feeStatsDecay
+	}
+
+	f.lastBlock = make(map[common.Uint256]struct{}, len(block.Transactions))
+	f.removed = make(map[common.Uint256]struct{})
+	for _, tx := range block.Transactions {
+		hash := tx.Hash()
+		f.lastBlock[hash] = struct{}{}
+		observed, ok := f.observed[hash]
+		if !ok {
+			continue
+		}
+		delete(f.observed, hash)
+
+		blocks := 1
+		if block.Height > observed.height {
+			blocks = int(block.Height - observed.height)
+		}
+		bucket := &f.buckets[observed.bucket]
+		for target := blocks; target <= MaxConfirmTarget; target++ {
+			bucket.confirmed[target-1]++
+		}
+		bucket.total++
+	}
+
+	// Transactions not confirmed within the max target are considered as
+	// failed.
+	for hash, observed := range f.observed {
+		if block.Height > observed.height &&
+			block.Height-observed.height > MaxConfirmTarget {
+			f.buckets[observed.bucket].total++
+			delete(f.observed, hash)
+		}
+	}
+}
+
+// RollbackBlock handles a block disconnected from the main chain.
+func (f *FeeEstimator) RollbackBlock(block *types.Block) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if block.Height > 0 && block.Height <= f.bestHeight {
+		f.bestHeight = block.Height - 1
+	}
+	f.lastBlock = make(map[common.Uint256]struct{})
+}
+
+// EstimateFee returns the lowest fee rate with which at least
+// successThreshold of transactions have been confirmed within the given
+// number of blocks.
+func (f *FeeEstimator) EstimateFee(target uint32) (*FeeEstimation, error) {
+	if target < 1 || target > MaxConfirmTarget {
+		return nil, fmt.Errorf("confirmation target must be between 1 "+
+			"and %d", MaxConfirmTarget)
+	}
+
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	best := -1
+	var confidence, groupConfirmed, groupTotal float64
+	for i := len(f.buckets) - 1; i >= 0; i-- {
+		groupConfirmed += f.buckets[i].confirmed[target-1]
+		groupTotal += f.buckets[i].total
+		if groupTotal < sufficientTxs {
+			continue
+		}
+
+		ratio := groupConfirmed / groupTotal
+		if ratio < successThreshold {
+			break
+		}
+		best = i
+		confidence = ratio
+		groupConfirmed, groupTotal = 0, 0
+	}
+
+	if best < 0 {
+		return &FeeEstimation{FeeRate: DefaultFeeRate}, nil
+	}
+	return &FeeEstimation{
+		FeeRate:    common.Fixed64(math.Ceil(bucketFeeRates[best])),
+		Confidence: confidence,
+	}, nil
+}
+
+func (f *FeeEstimator) handleEvents(e *events.Event) {
+	switch e.Type {
+	case events.ETTransactionAccepted:
+		if tx, ok := e.Data.(interfaces.Transaction); ok {
+			f.ObserveTransaction(tx)
+		}
+	case events.ETBlockConnected:
+		if block, ok := e.Data.(*types.Block); ok {
+			f.ProcessBlock(block)
+		}
+	case events.ETBlockDisconnected:
+		if block, ok := e.Data.(*types.Block); ok {
+			f.RollbackBlock(block)
+		}
+	}
+}
+
+func (f *FeeEstimator) OnBlockSaved(block *types.DposBlock) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	// Keep the best height up to date while replaying blocks on startup,
+	// when no block connected events are fired.
+	if block.Height > f.bestHeight {
+		f.bestHeight = block.Height
+	}
+}
+
+func (f *FeeEstimator) OnRollbackTo(uint32) error {
+	return nil
+}
+
+func (f *FeeEstimator) OnRollbackSeekTo(uint32) {
+}
+
+func (f *FeeEstimator) Key() string {
+	return feeEstimatorCheckpointKey
+}
+
+func (f *FeeEstimator) Snapshot() checkpoint.ICheckPoint {
+	buf := bytes.Buffer{}
+	if err := f.Serialize(&buf); err != nil {
+		f.LogError(err)
+		return nil
+	}
+	result := NewFeeEstimator()
+	if err := result.Deserialize(&buf); err != nil {
+		f.LogError(err)
+		return nil
+	}
+	return result
+}
+
+func (f *FeeEstimator) GetHeight() uint32 {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.height
+}
+
+func (f *FeeEstimator) SetHeight(height uint32) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.height = height
+}
+
+func (f *FeeEstimator) OnReset() error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.reset()
+	return nil
+}
+
+func (f *FeeEstimator) SavePeriod() uint32 {
+	return feeEstimatorCheckpointHeight
+}
+
+func (f *FeeEstimator) EffectivePeriod() uint32 {
+	return feeEstimatorCheckpointHeight
+}
+
+func (f *FeeEstimator) DataExtension() string {
+	return feeEstimatorCheckpointExtension
+}
+
+func (f *FeeEstimator) Generator() func(buf []byte) checkpoint.ICheckPoint {
+	return func(buf []byte) checkpoint.ICheckPoint {
+		stream := bytes.Buffer{}
+		stream.Write(buf)
+
+		result := NewFeeEstimator()
+		if err := result.Deserialize(&stream); err != nil {
+			f.LogError(err)
+			return nil
+		}
+		return result
+	}
+}
+
+func (f *FeeEstimator) LogError(err error) {
+	log.Warn("[FeeEstimator] ", err)
+}
+
+func (f *FeeEstimator) Priority() checkpoint.Priority {
+	return checkpoint.VeryLow
+}
+
+func (f *FeeEstimator) OnInit() {
+}
+
+func (f *FeeEstimator) SaveStartHeight() uint32 {
+	return uint32(1)
+}
+
+func (f *FeeEstimator) StartHeight() uint32 {
+	return uint32(1)
+}
+
+func (f *FeeEstimator) Serialize(w io.Writer) (err error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if err = common.WriteElements(w, f.height, f.bestHeight); err != nil {
+		return
+	}
+	if err = common.WriteVarUint(w, uint64(len(f.buckets))); err != nil {
+		return
+	}
+	for _, b := range f.buckets {
+		for _, c := range b.confirmed {
+			if err = common.WriteElement(w, c); err != nil {
+				return
+			}
+		}
+		if err = common.WriteElement(w, b.total); err != nil {
+			return
+		}
+	}
+
+	if err = common.WriteVarUint(w, uint64(len(f.observed))); err != nil {
+		return
+	}
+	for k, v := range f.observed {
+		if err = k.Serialize(w); err != nil {
+			return
+		}
+		if err = common.WriteElements(w, v.height, v.bucket); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (f *FeeEstimator) Deserialize(r io.Reader) (err error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	if err = common.ReadElements(r, &f.height, &f.bestHeight); err != nil {
+		return
+	}
+	var count uint64
+	if count, err = common.ReadVarUint(r, 0); err != nil {
+		return
+	}
+	if count != uint64(len(bucketFeeRates)) {
+		return errors.New("fee rate buckets mismatch")
+	}
+	f.buckets = make([]feeRateBucket, count)
+	for i := range f.buckets {
+		for j := range f.buckets[i].confirmed {
+			if err = common.ReadElement(r, &f.buckets[i].confirmed[j]); err != nil {
+				return
+			}
+		}
+		if err = common.ReadElement(r, &f.buckets[i].total); err != nil {
+			return
+		}
+	}
+
+	if count, err = common.ReadVarUint(r, 0); err != nil {
+		return
+	}
+	f.observed = make(map[common.Uint256]observedTx, count)
+	for i := uint64(0); i < count; i++ {
+		var hash common.Uint256
+		if err = hash.Deserialize(r); err != nil {
+			return
+		}
+		var tx observedTx
+		if err = common.ReadElements(r, &tx.height, &tx.bucket); err != nil {
+			return
+		}
+		if tx.bucket >= uint32(len(f.buckets)) {
+			return errors.New("invalid fee rate bucket")
+		}
+		f.observed[hash] = tx
+	}
+	return
+}
+
+func (f *FeeEstimator) reset() {
+	f.height = 0
+	f.bestHeight = 0
+	f.buckets = make([]feeRateBucket, len(bucketFeeRates))
+	f.observed = make(map[common.Uint256]observedTx)
+	f.lastBlock = make(map[common.Uint256]struct{})
+	f.removed = make(map[common.Uint256]struct{})
+}
+
+// NewFeeEstimator creates a fee estimator with empty statistics.
+func NewFeeEstimator() *FeeEstimator {
+	f := &FeeEstimator{}
+	f.reset()
+	return f
+}
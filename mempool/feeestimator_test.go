@@ -0,0 +1,175 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFeeEstimatorTestTx(feeRate common.Fixed64) interfaces.Transaction {
+	tx := functions.CreateTransaction(
+		0,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{{
+			Usage: common2.Nonce,
+			Data:  randomNonceData(),
+		}},
+		[]*common2.Input{},
+		[]*common2.Output{},
+		0,
+		[]*program.Program{},
+	)
+	tx.SetFee(feeRate * common.Fixed64(tx.GetSize()) / 1000)
+	return tx
+}
+
+func TestFeeEstimator_EstimateFee(t *testing.T) {
+	estimator := NewFeeEstimator()
+
+	// no enough data
+	estimation, err := estimator.EstimateFee(1)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Fixed64(DefaultFeeRate), estimation.FeeRate)
+	assert.Equal(t, float64(0), estimation.Confidence)
+
+	_, err = estimator.EstimateFee(0)
+	assert.Error(t, err)
+	_, err = estimator.EstimateFee(MaxConfirmTarget + 1)
+	assert.Error(t, err)
+
+	// transactions with high fee rate are packed in the next block, while
+	// transactions with low fee rate are packed after ten blocks.
+	height := uint32(100)
+	estimator.ProcessBlock(&types.Block{Header: common2.Header{Height: height}})
+	for round := 0; round < 5; round++ {
+		highTxs := make([]interfaces.Transaction, 0)
+		lowTxs := make([]interfaces.Transaction, 0)
+		for i := 0; i < 5; i++ {
+			highTx := newFeeEstimatorTestTx(50000)
+			lowTx := newFeeEstimatorTestTx(2000)
+			estimator.ObserveTransaction(highTx)
+			estimator.ObserveTransaction(lowTx)
+			highTxs = append(highTxs, highTx)
+			lowTxs = append(lowTxs, lowTx)
+		}
+
+		height++
+		estimator.ProcessBlock(&types.Block{
+			Header:       common2.Header{Height: height},
+			Transactions: highTxs,
+		})
+		for i := 0; i < 9; i++ {
+			height++
+			estimator.ProcessBlock(&types.Block{
+				Header: common2.Header{Height: height},
+			})
+		}
+		height++
+		estimator.ProcessBlock(&types.Block{
+			Header:       common2.Header{Height: height},
+			Transactions: lowTxs,
+		})
+	}
+	assert.Equal(t, 0, len(estimator.observed))
+
+	estimation, err = estimator.EstimateFee(1)
+	assert.NoError(t, err)
+	assert.True(t, estimation.FeeRate > 2000)
+	assert.True(t, estimation.FeeRate <= 50000)
+	assert.True(t, estimation.Confidence >= successThreshold)
+
+	estimation, err = estimator.EstimateFee(11)
+	assert.NoError(t, err)
+	assert.True(t, estimation.FeeRate <= 2000)
+	assert.True(t, estimation.Confidence >= successThreshold)
+}
+
+func TestFeeEstimator_Timeout(t *testing.T) {
+	estimator := NewFeeEstimator()
+	estimator.ProcessBlock(&types.Block{Header: common2.Header{Height: 1}})
+	for i := 0; i < 5; i++ {
+		estimator.ObserveTransaction(newFeeEstimatorTestTx(5000))
+	}
+	assert.Equal(t, 5, len(estimator.observed))
+
+	for height := uint32(2); height <= MaxConfirmTarget+2; height++ {
+		estimator.ProcessBlock(&types.Block{
+			Header: common2.Header{Height: height},
+		})
+	}
+	assert.Equal(t, 0, len(estimator.observed))
+
+	// unconfirmed transactions should not pass any target
+	estimation, err := estimator.EstimateFee(MaxConfirmTarget)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Fixed64(DefaultFeeRate), estimation.FeeRate)
+	assert.Equal(t, float64(0), estimation.Confidence)
+}
+
+func TestFeeEstimator_RemoveTransaction(t *testing.T) {
+	estimator := NewFeeEstimator()
+	estimator.ProcessBlock(&types.Block{Header: common2.Header{Height: 1}})
+	txs := make([]interfaces.Transaction, 0)
+	for i := 0; i < 5; i++ {
+		txs = append(txs, newFeeEstimatorTestTx(5000))
+	}
+	for _, tx := range txs[:4] {
+		estimator.ObserveTransaction(tx)
+	}
+
+	// replaced transactions are not tracked, even if they are observed
+	// after being removed
+	estimator.RemoveTransaction(txs[0].Hash())
+	estimator.RemoveTransaction(txs[4].Hash())
+	estimator.ObserveTransaction(txs[4])
+	assert.Equal(t, 3, len(estimator.observed))
+
+	for height := uint32(2); height <= MaxConfirmTarget+2; height++ {
+		estimator.ProcessBlock(&types.Block{
+			Header: common2.Header{Height: height},
+		})
+	}
+	assert.Equal(t, 0, len(estimator.observed))
+
+	// only the unconfirmed transactions left in pool are counted as failed
+	bucket := estimator.buckets[bucketIndex(txFeeRate(txs[0]))]
+	assert.True(t, bucket.total > 2.9 && bucket.total <= 3)
+}
+
+func TestFeeEstimator_Serialize(t *testing.T) {
+	estimator := NewFeeEstimator()
+	estimator.ProcessBlock(&types.Block{Header: common2.Header{Height: 10}})
+	tx := newFeeEstimatorTestTx(3000)
+	estimator.ObserveTransaction(tx)
+	estimator.ObserveTransaction(newFeeEstimatorTestTx(8000))
+	estimator.ProcessBlock(&types.Block{
+		Header:       common2.Header{Height: 11},
+		Transactions: []interfaces.Transaction{tx},
+	})
+	estimator.SetHeight(11)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, estimator.Serialize(buf))
+
+	restored := NewFeeEstimator()
+	assert.NoError(t, restored.Deserialize(buf))
+	assert.Equal(t, estimator.height, restored.height)
+	assert.Equal(t, estimator.bestHeight, restored.bestHeight)
+	assert.Equal(t, estimator.buckets, restored.buckets)
+	assert.Equal(t, estimator.observed, restored.observed)
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/events"
+)
+
+const (
+	// MempoolFileName is the name of the file the transaction pool is saved
+	// to within the data path.
+	MempoolFileName = "mempool.dat"
+
+	// mempoolFileVersion is the version of the mempool file format.
+	mempoolFileVersion = uint32(1)
+)
+
+// mempoolEntry is a transaction saved in the mempool file.
+type mempoolEntry struct {
+	tx   interfaces.Transaction
+	info TxReceivingInfo
+}
+
+func (e *mempoolEntry) Serialize(w io.Writer) error {
+	if err := common.WriteElements(w, e.info.Height,
+		e.info.Time.Unix()); err != nil {
+		return err
+	}
+	return e.tx.Serialize(w)
+}
+
+func (e *mempoolEntry) Deserialize(r io.Reader) error {
+	var unixTime int64
+	if err := common.ReadElements(r, &e.info.Height, &unixTime); err != nil {
+		return err
+	}
+	e.info.Time = time.Unix(unixTime, 0)
+
+	tx, err := functions.GetTransactionByBytes(r)
+	if err != nil {
+		return err
+	}
+	if err := tx.Deserialize(r); err != nil {
+		return err
+	}
+	e.tx = tx
+	return nil
+}
+
+// SetDataPath sets the directory the transaction pool is saved to.
+func (mp *TxPool) SetDataPath(path string) {
+	mp.dataPath = path
+}
+
+// MempoolFilePath returns the path of the file the transaction pool is saved
+// to.
+func (mp *TxPool) MempoolFilePath() string {
+	return filepath.Join(mp.dataPath, MempoolFileName)
+}
+
+// SaveMempool writes all transactions in pool together with their receiving
+// info to the mempool file, and returns the count of transactions saved.
+//
+// This function is safe for concurrent access.
+func (mp *TxPool) SaveMempool() (int, error) {
+	mp.RLock()
+	entries := make([]*mempoolEntry, 0, len(mp.txnList))
+	for hash, tx := range mp.txnList {
+		entries = append(entries, &mempoolEntry{
+			tx:   tx,
+			info: mp.txReceivingInfo[hash],
+		})
+	}
+	mp.RUnlock()
+
+	// Write to a temporary file first, so the mempool file will not be
+	// corrupted if the node stopped while saving.
+	path := mp.MempoolFilePath()
+	tmpPath := path + ".new"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriter(file)
+	err = func() error {
+		if err := common.WriteUint32(w, mempoolFileVersion); err != nil {
+			return err
+		}
+		if err := common.WriteVarUint(w, uint64(len(entries))); err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := e.Serialize(w); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	}()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return 0, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// LoadMempool reads transactions from the mempool file and adds them to the
+// pool after validating them again, transactions already in pool are skipped.
+// Accepted transactions are announced as if they were received just now.
+// Returns the count of transactions accepted and rejected, a missing mempool
+// file is not an error.
+func (mp *TxPool) LoadMempool() (accepted int, rejected int, err error) {
+	file, err := os.Open(mp.MempoolFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, 0, nil
+		}
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	version, err := common.ReadUint32(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	if version != mempoolFileVersion {
+		return 0, 0, fmt.Errorf("unknown mempool file version %d", version)
+	}
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	entries := make([]*mempoolEntry, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var e mempoolEntry
+		if err := e.Deserialize(r); err != nil {
+			return 0, 0, errors.New("invalid mempool file, " + err.Error())
+		}
+		entries = append(entries, &e)
+	}
+
+	for _, e := range entries {
+		if mp.HaveTransaction(e.tx.Hash()) {
+			continue
+		}
+		mp.Lock()
+		err := mp.appendToTxPool(e.tx)
+		if err == nil {
+			// Keep the original receiving info, so outdated transactions
+			// will be resent in time.
+			mp.txReceivingInfo[e.tx.Hash()] = e.info
+		}
+		mp.Unlock()
+		if err != nil {
+			log.Debugf("reload transaction %s failed, %s", e.tx.Hash(), err)
+			rejected++
+			continue
+		}
+		accepted++
+		go events.Notify(events.ETTransactionAccepted, e.tx)
+	}
+	return accepted, rejected, nil
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"fmt"
+
+	. "github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	elaerr "github.com/elastos/Elastos.ELA/errors"
+)
+
+// maxReplacementEvictions is the maximum number of transactions that can be
+// evicted from the transaction pool by one replacement transaction.
+const maxReplacementEvictions = 100
+
+// replacedTx records a transaction evicted by a replacement transaction, so
+// it can be put back if the replacement failed to be added.
+type replacedTx struct {
+	tx               interfaces.Transaction
+	receivingInfo    *TxReceivingInfo
+	crossChainHeight *uint32
+}
+
+// SignalsReplacement returns whether the transaction can be replaced by a
+// transaction spending the same inputs with higher fee.
+func SignalsReplacement(tx interfaces.Transaction) bool {
+	for _, input := range tx.Inputs() {
+		if input.SignalsReplacement() {
+			return true
+		}
+	}
+	return false
+}
+
+// replacedTransactions returns the transactions in pool spending the same
+// inputs as the given transaction, which will be evicted if it is accepted
+// as a replacement.  Transactions in pool can not spend outputs of each
+// other, so no descendants are evicted with them.  The replacement is
+// rejected if:
+//  1. any conflicting transaction did not signal replaceability;
+//  2. the fee rate is not higher than the ones of the conflicting transactions;
+//  3. more than maxReplacementEvictions transactions will be evicted;
+//  4. the fee is not higher than the total fee of the evicted transactions by
+//     at least MinTransactionFee, which pays for relaying the replacement.
+func (mp *TxPool) replacedTransactions(
+	tx interfaces.Transaction) ([]interfaces.Transaction, elaerr.ELAError) {
+	conflicts := make(map[Uint256]interfaces.Transaction)
+	for _, input := range tx.Inputs() {
+		if conflict := mp.getInputUTXOList(input); conflict != nil {
+			conflicts[conflict.Hash()] = conflict
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil, nil
+	}
+
+	txHash := tx.Hash()
+	feeRate := float64(tx.Fee()) / float64(tx.GetSize())
+	evicted := make(map[Uint256]interfaces.Transaction)
+	for hash, conflict := range conflicts {
+		if !SignalsReplacement(conflict) {
+			return nil, elaerr.SimpleWithMessage(elaerr.ErrTxPoolDoubleSpend,
+				nil, fmt.Sprintf("transaction %s spends inputs of transaction"+
+					" %s which is not replaceable", txHash, hash))
+		}
+		conflictFeeRate := float64(conflict.Fee()) / float64(conflict.GetSize())
+		if feeRate <= conflictFeeRate {
+			return nil, elaerr.SimpleWithMessage(
+				elaerr.ErrTxPoolReplacementRejected, nil, fmt.Sprintf(
+					"fee rate of transaction %s is not higher than %s",
+					txHash, hash))
+		}
+		evicted[hash] = conflict
+		if len(evicted) > maxReplacementEvictions {
+			return nil, elaerr.SimpleWithMessage(
+				elaerr.ErrTxPoolReplacementRejected, nil, fmt.Sprintf(
+					"transaction %s replaces more than %d transactions",
+					txHash, maxReplacementEvictions))
+		}
+	}
+
+	var evictedFees Fixed64
+	for _, e := range evicted {
+		evictedFees += e.Fee()
+	}
+	if tx.Fee() < evictedFees+mp.chainParams.MinTransactionFee {
+		return nil, elaerr.SimpleWithMessage(
+			elaerr.ErrTxPoolReplacementRejected, nil, fmt.Sprintf(
+				"fee of transaction %s is %s, need at least %s to replace",
+				txHash, tx.Fee(), evictedFees+mp.chainParams.MinTransactionFee))
+	}
+
+	replaced := make([]interfaces.Transaction, 0, len(evicted))
+	for _, e := range evicted {
+		replaced = append(replaced, e)
+	}
+	return replaced, nil
+}
+
+// evictTransactions removes the replaced transactions from the pool and
+// returns the records to restore them.
+func (mp *TxPool) evictTransactions(
+	txs []interfaces.Transaction) []*replacedTx {
+	records := make([]*replacedTx, 0, len(txs))
+	for _, tx := range txs {
+		hash := tx.Hash()
+		record := &replacedTx{tx: tx}
+		if info, ok := mp.txReceivingInfo[hash]; ok {
+			record.receivingInfo = &info
+		}
+		if height, ok := mp.crossChainHeightList[hash]; ok {
+			record.crossChainHeight = &height
+		}
+		records = append(records, record)
+		mp.doRemoveTransaction(tx)
+	}
+	return records
+}
+
+// restoreTransactions puts the evicted transactions back to the pool.
+func (mp *TxPool) restoreTransactions(records []*replacedTx) {
+	for _, r := range records {
+		if err := mp.AppendTx(r.tx); err != nil {
+			log.Warnf("restore replaced transaction %s failed, %s",
+				r.tx.Hash(), err)
+			continue
+		}
+		if err := mp.doAddTransaction(r.tx); err != nil {
+			log.Warnf("restore replaced transaction %s failed, %s",
+				r.tx.Hash(), err)
+			mp.removeTx(r.tx)
+			continue
+		}
+		hash := r.tx.Hash()
+		if r.receivingInfo != nil {
+			mp.txReceivingInfo[hash] = *r.receivingInfo
+		}
+		if r.crossChainHeight != nil {
+			mp.crossChainHeightList[hash] = *r.crossChainHeight
+		}
+	}
+}
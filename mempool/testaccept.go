@@ -0,0 +1,88 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package mempool
+
+import (
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	elaerr "github.com/elastos/Elastos.ELA/errors"
+)
+
+// TestAccept runs every check done by AppendToTxPool against the transaction
+// without adding it to the pool, and returns the result of each check.  The
+// transactions a replacement would evict are recorded as "Replaces" lookups.
+// Unlike AppendToTxPool, a side chain pow transaction conflicting with the
+// pool fails the conflict check instead of replacing the existing one.
+func (mp *TxPool) TestAccept(tx interfaces.Transaction) *interfaces.CheckTrace {
+	mp.Lock()
+	defer mp.Unlock()
+
+	trace := new(interfaces.CheckTrace)
+	if tx.IsRecordSponorTx() {
+		trace.Add("IsRecordSponsorTx", elaerr.Simple(elaerr.ErrTxValidation, nil))
+	} else {
+		trace.Add("IsRecordSponsorTx", nil)
+	}
+
+	_, exist := mp.txnList[tx.Hash()]
+	trace.Lookup("TxPool", tx.Hash().ReversedString(), existString(exist))
+	if exist {
+		trace.Add("TxPoolDuplicate", elaerr.Simple(elaerr.ErrTxDuplicate, nil))
+	} else {
+		trace.Add("TxPoolDuplicate", nil)
+	}
+
+	if tx.IsCoinBaseTx() {
+		trace.Add("IsCoinBaseTx", elaerr.Simple(elaerr.ErrBlockIneffectiveCoinbase, nil))
+	} else {
+		trace.Add("IsCoinBaseTx", nil)
+	}
+
+	chain := blockchain.DefaultLedger.Blockchain
+	chainTrace := chain.TraceTransaction(chain.GetHeight()+1, tx,
+		mp.proposalsUsedAmount)
+	trace.Checks = append(trace.Checks, chainTrace.Checks...)
+	trace.Lookups = append(trace.Lookups, chainTrace.Lookups...)
+
+	replaced, err := mp.replacedTransactions(tx)
+	trace.Add("ReplacedTransactions", err)
+	for _, r := range replaced {
+		trace.Lookup("Replaces", r.Hash().ReversedString(), "replaceable")
+	}
+
+	// The transactions to be replaced stay in the pool, so they are removed
+	// from the conflict slots only while verifying the transaction.
+	for _, r := range replaced {
+		mp.removeTx(r)
+	}
+	trace.Add("VerifyTransactionWithTxnPool", mp.VerifyTx(tx))
+	for _, r := range replaced {
+		if err := mp.AppendTx(r); err != nil {
+			log.Warnf("restore conflict slots of transaction %s failed, %s",
+				r.Hash(), err)
+		}
+	}
+
+	var replacedSize uint64
+	for _, r := range replaced {
+		replacedSize += uint64(r.GetSize())
+	}
+	size := uint64(tx.GetSize())
+	if size > replacedSize && mp.txFees.OverSize(size-replacedSize) {
+		trace.Add("TxPoolSize", elaerr.Simple(elaerr.ErrTxPoolOverCapacity, nil))
+	} else {
+		trace.Add("TxPoolSize", nil)
+	}
+
+	return trace
+}
+
+func existString(exist bool) string {
+	if exist {
+		return "exists"
+	}
+	return "not exists"
+}
@@ -10,11 +10,12 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/elastos/Elastos.ELA/blockchain"
 	. "github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/common/config"
-	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/common/metrics"
 	"github.com/elastos/Elastos.ELA/core/checkpoint"
 	. "github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/core/types/common"
@@ -27,6 +28,21 @@ import (
 
 const broadcastCrossChainTransactionInterval = 30
 
+const (
+	// evictionReasonFull is the reason of evictions of transactions with
+	// lowest fee rate when the pool is full.
+	evictionReasonFull = "full"
+
+	// evictionReasonReplaced is the reason of evictions of transactions
+	// replaced by fee.
+	evictionReasonReplaced = "replaced"
+)
+
+// evictionsCounter counts the transactions evicted from the pool.
+var evictionsCounter = metrics.NewCounter("ela_mempool_evictions_total",
+	"Total number of transactions evicted from the transaction pool.",
+	"reason")
+
 type TxPool struct {
 	conflictManager
 	*txPoolCheckpoint
@@ -35,7 +51,9 @@ type TxPool struct {
 	proposalsUsedAmount  Fixed64
 	crossChainHeightList map[Uint256]uint32
 	CkpManager           *checkpoint.Manager
+	FeeEstimator         *FeeEstimator
 	txReceivingInfo      map[Uint256]TxReceivingInfo
+	dataPath             string
 
 	sync.RWMutex
 }
@@ -43,6 +61,7 @@ type TxPool struct {
 // TxReceivingInfo record the tx receiving info detail, can expend it's field in the future need
 type TxReceivingInfo struct {
 	Height uint32
+	Time   time.Time
 }
 
 // append transaction to txnpool when check ok, and broadcast the transaction.
@@ -118,6 +137,51 @@ func (mp *TxPool) appendToTxPool(tx interfaces.Transaction) elaerr.ELAError {
 			err)
 		return err
 	}
+	// Evict the transactions replaced by this one, they will be put back
+	// if this transaction failed to be added.
+	replaced, err := mp.replacedTransactions(tx)
+	if err != nil {
+		log.Warnf("[TxPool replacedTransactions] failed, hash: %s, err: %s",
+			tx.Hash(), err)
+		return err
+	}
+	evicted := mp.evictTransactions(replaced)
+	if err := mp.addTransaction(tx); err != nil {
+		mp.restoreTransactions(evicted)
+		return err
+	}
+	if len(replaced) > 0 {
+		log.Infof("transaction %s replaced %d transactions in pool",
+			tx.Hash(), len(replaced))
+		evictionsCounter.Add(float64(len(replaced)), evictionReasonReplaced)
+		for _, r := range replaced {
+			mp.FeeEstimator.RemoveTransaction(r.Hash())
+		}
+	}
+
+	if mp.chainParams.IsActiveAfter(config.DeploymentNewCrossChain, bestHeight) &&
+		tx.IsTransferCrossChainAssetTx() &&
+		tx.IsSmallTransfer(mp.chainParams.SmallCrossTransferThreshold) {
+		err := blockchain.DefaultLedger.Store.SaveSmallCrossTransferTx(tx)
+		if err != nil {
+			log.Warnf("failed to save small cross chain transaction %s", tx.Hash())
+			return elaerr.Simple(elaerr.ErrTxValidation, nil)
+		}
+		mp.crossChainHeightList[tx.Hash()] = bestHeight
+	}
+
+	// record the tx receiving info
+	mp.txReceivingInfo[tx.Hash()] = TxReceivingInfo{
+		Height: bestHeight,
+		Time:   time.Now(),
+	}
+
+	return nil
+}
+
+// addTransaction verifies the transaction with transactions in pool and adds
+// it to the pool.
+func (mp *TxPool) addTransaction(tx interfaces.Transaction) elaerr.ELAError {
 	//verify transaction by pool with lock
 	if err := mp.verifyTransactionWithTxnPool(tx); err != nil {
 		log.Error("[TxPool verifyTransactionWithTxnPool] err", err)
@@ -140,23 +204,6 @@ func (mp *TxPool) appendToTxPool(tx interfaces.Transaction) elaerr.ELAError {
 		mp.removeTx(tx)
 		return err
 	}
-
-	if bestHeight > mp.chainParams.NewCrossChainStartHeight &&
-		tx.IsTransferCrossChainAssetTx() &&
-		tx.IsSmallTransfer(mp.chainParams.SmallCrossTransferThreshold) {
-		err := blockchain.DefaultLedger.Store.SaveSmallCrossTransferTx(tx)
-		if err != nil {
-			log.Warnf("failed to save small cross chain transaction %s", tx.Hash())
-			return elaerr.Simple(elaerr.ErrTxValidation, nil)
-		}
-		mp.crossChainHeightList[tx.Hash()] = bestHeight
-	}
-
-	// record the tx receiving info
-	mp.txReceivingInfo[tx.Hash()] = TxReceivingInfo{
-		Height: bestHeight,
-	}
-
 	return nil
 }
 
@@ -536,6 +583,13 @@ func (mp *TxPool) GetTransactionCount() int {
 	return len(mp.txnList)
 }
 
+// GetTransactionsSize returns the total size in bytes of transactions in pool.
+func (mp *TxPool) GetTransactionsSize() uint64 {
+	mp.RLock()
+	defer mp.RUnlock()
+	return mp.txFees.GetTotalSize()
+}
+
 func (mp *TxPool) getInputUTXOList(input *common.Input) interfaces.Transaction {
 	return mp.GetTx(input.ReferKey(), slotTxInputsReferKeys)
 }
@@ -629,7 +683,7 @@ func (mp *TxPool) onPopBack(hash Uint256) {
 	}
 	delete(mp.txnList, hash)
 	mp.dealDelProposalTx(tx)
-
+	evictionsCounter.Add(1, evictionReasonFull)
 }
 
 func NewTxPool(params *config.Configuration, ckpManager *checkpoint.Manager) *TxPool {
@@ -639,6 +693,7 @@ func NewTxPool(params *config.Configuration, ckpManager *checkpoint.Manager) *Tx
 		CkpManager:           ckpManager,
 		proposalsUsedAmount:  0,
 		crossChainHeightList: make(map[Uint256]uint32),
+		FeeEstimator:         NewFeeEstimator(),
 		txReceivingInfo:      make(map[Uint256]TxReceivingInfo),
 	}
 	rtn.txPoolCheckpoint = newTxPoolCheckpoint(
@@ -650,5 +705,7 @@ func NewTxPool(params *config.Configuration, ckpManager *checkpoint.Manager) *Tx
 			}
 		})
 	rtn.CkpManager.Register(rtn.txPoolCheckpoint)
+	rtn.CkpManager.Register(rtn.FeeEstimator)
+	events.Subscribe(rtn.FeeEstimator.handleEvents)
 	return rtn
 }
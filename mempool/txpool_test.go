@@ -11,14 +11,17 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"math"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/elastos/Elastos.ELA/auxpow"
 	"github.com/elastos/Elastos.ELA/blockchain"
 	"github.com/elastos/Elastos.ELA/common"
 	"github.com/elastos/Elastos.ELA/common/config"
-	"github.com/elastos/Elastos.ELA/common/log"
+	elaLog "github.com/elastos/Elastos.ELA/common/log"
 	"github.com/elastos/Elastos.ELA/core"
 	"github.com/elastos/Elastos.ELA/core/checkpoint"
 	"github.com/elastos/Elastos.ELA/core/contract"
@@ -80,7 +83,7 @@ func NewUtxoCacheDB() *UtxoCacheDB {
 }
 
 func TestTxPoolInit(t *testing.T) {
-	log.NewDefault(test.NodeLogPath, 0, 0, 0)
+	elaLog.NewDefault(test.NodeLogPath, 0, 0, 0)
 	dplog.Init("elastos", 0, 0, 0)
 
 	ckpManager := checkpoint.NewManager(config.GetDefaultParams())
@@ -1063,6 +1066,201 @@ func TestTxPool_CleanSubmittedTransactions(t *testing.T) {
 	}
 }
 
+func newReplacementTestTx(sequence uint32, fee common.Fixed64,
+	previous ...common2.OutPoint) interfaces.Transaction {
+	inputs := make([]*common2.Input, 0, len(previous))
+	for _, p := range previous {
+		inputs = append(inputs, &common2.Input{
+			Previous: p,
+			Sequence: sequence,
+		})
+	}
+	tx := functions.CreateTransaction(
+		0,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{{
+			Usage: common2.Nonce,
+			Data:  randomNonceData(),
+		}},
+		inputs,
+		[]*common2.Output{{}},
+		0,
+		[]*program.Program{},
+	)
+	tx.SetFee(fee)
+	return tx
+}
+
+// newReplacementTestOutPoint returns an outpoint referring to a confirmed
+// transaction.
+func newReplacementTestOutPoint() common2.OutPoint {
+	referTx := newReplacementTestTx(math.MaxUint32, 0)
+	utxoCacheDB.PutTransaction(referTx)
+	return common2.OutPoint{TxID: referTx.Hash(), Index: 0}
+}
+
+func newReplacementTestPool(t *testing.T,
+	txs ...interfaces.Transaction) *TxPool {
+	params := config.GetDefaultParams()
+	pool := NewTxPool(params, checkpoint.NewManager(params))
+	for _, tx := range txs {
+		assert.NoError(t, pool.AppendTx(tx))
+		assert.NoError(t, pool.doAddTransaction(tx))
+	}
+	return pool
+}
+
+func TestSignalsReplacement(t *testing.T) {
+	outPoint := common2.OutPoint{TxID: *randomHash(), Index: 0}
+	assert.True(t, SignalsReplacement(
+		newReplacementTestTx(common2.RBFSequence, 100, outPoint)))
+	assert.False(t, SignalsReplacement(newReplacementTestTx(0, 100, outPoint)))
+	assert.False(t, SignalsReplacement(newReplacementTestTx(
+		common2.LockTimeToSequence(false, 10), 100, outPoint)))
+	assert.False(t, SignalsReplacement(
+		newReplacementTestTx(math.MaxUint32, 100, outPoint)))
+	assert.False(t, SignalsReplacement(
+		newReplacementTestTx(math.MaxUint32-1, 100, outPoint)))
+}
+
+func TestTxPool_ReplacedTransactions(t *testing.T) {
+	outPoint1 := newReplacementTestOutPoint()
+	outPoint2 := newReplacementTestOutPoint()
+	original1 := newReplacementTestTx(common2.RBFSequence, 100, outPoint1)
+	original2 := newReplacementTestTx(common2.RBFSequence, 100, outPoint2)
+	pool := newReplacementTestPool(t, original1, original2)
+
+	// no conflicts
+	replaced, err := pool.replacedTransactions(newReplacementTestTx(
+		common2.RBFSequence, 100, newReplacementTestOutPoint()))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(replaced))
+
+	// fee rate not higher
+	_, err = pool.replacedTransactions(
+		newReplacementTestTx(math.MaxUint32, 100, outPoint1, outPoint2))
+	assert.Equal(t, elaerr.ErrTxPoolReplacementRejected, err.Code())
+
+	// fee not enough to pay for both of the conflicting transactions
+	_, err = pool.replacedTransactions(
+		newReplacementTestTx(math.MaxUint32, 250, outPoint1, outPoint2))
+	assert.Equal(t, elaerr.ErrTxPoolReplacementRejected, err.Code())
+
+	replacement := newReplacementTestTx(math.MaxUint32, 300,
+		outPoint1, outPoint2)
+	replaced, err = pool.replacedTransactions(replacement)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(replaced))
+
+	evicted := pool.evictTransactions(replaced)
+	assert.False(t, pool.HaveTransaction(original1.Hash()))
+	assert.False(t, pool.HaveTransaction(original2.Hash()))
+	assert.Equal(t, 0, pool.txFees.GetSize())
+
+	// put back the evicted transactions
+	pool.restoreTransactions(evicted)
+	assert.True(t, pool.HaveTransaction(original1.Hash()))
+	assert.True(t, pool.HaveTransaction(original2.Hash()))
+	assert.Equal(t, 2, pool.txFees.GetSize())
+}
+
+func TestTxPool_ReplacedTransactions_NotReplaceable(t *testing.T) {
+	outPoint := newReplacementTestOutPoint()
+	original := newReplacementTestTx(math.MaxUint32, 100, outPoint)
+	pool := newReplacementTestPool(t, original)
+
+	_, err := pool.replacedTransactions(
+		newReplacementTestTx(math.MaxUint32, 10000, outPoint))
+	assert.Equal(t, elaerr.ErrTxPoolDoubleSpend, err.Code())
+	assert.True(t, pool.HaveTransaction(original.Hash()))
+}
+
+func TestTxPool_TestAccept(t *testing.T) {
+	outPoint := newReplacementTestOutPoint()
+	original := newReplacementTestTx(common2.RBFSequence, 100, outPoint)
+	pool := newReplacementTestPool(t, original)
+
+	checks := func(trace *interfaces.CheckTrace) map[string]bool {
+		passed := make(map[string]bool)
+		for _, c := range trace.Checks {
+			passed[c.Name] = c.Passed()
+		}
+		return passed
+	}
+
+	// a transaction in pool
+	passed := checks(pool.TestAccept(original))
+	assert.False(t, passed["TxPoolDuplicate"])
+
+	// a replacement is verified without the transactions it replaces
+	replacement := newReplacementTestTx(math.MaxUint32, 10000, outPoint)
+	trace := pool.TestAccept(replacement)
+	passed = checks(trace)
+	assert.True(t, passed["TxPoolDuplicate"])
+	assert.True(t, passed["ReplacedTransactions"])
+	assert.True(t, passed["VerifyTransactionWithTxnPool"])
+	assert.Contains(t, trace.Lookups, interfaces.CheckLookup{
+		Name:   "Replaces",
+		Key:    original.Hash().ReversedString(),
+		Result: "replaceable",
+	})
+	assert.NotNil(t, trace.Err())
+
+	// the pool is not changed
+	assert.True(t, pool.HaveTransaction(original.Hash()))
+	assert.False(t, pool.HaveTransaction(replacement.Hash()))
+	assert.Error(t, pool.VerifyTx(
+		newReplacementTestTx(math.MaxUint32, 100, outPoint)))
+}
+
+func TestTxPool_SaveLoadMempool(t *testing.T) {
+	dataPath, err := ioutil.TempDir("", "mempool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dataPath)
+
+	tx1 := newReplacementTestTx(math.MaxUint32, 100,
+		newReplacementTestOutPoint())
+	tx2 := newReplacementTestTx(math.MaxUint32, 200,
+		newReplacementTestOutPoint())
+	pool := newReplacementTestPool(t, tx1, tx2)
+	pool.SetDataPath(dataPath)
+
+	// missing mempool file is not an error
+	accepted, rejected, err := pool.LoadMempool()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, accepted)
+	assert.Equal(t, 0, rejected)
+
+	count, err := pool.SaveMempool()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	_, err = os.Stat(filepath.Join(dataPath, MempoolFileName))
+	assert.NoError(t, err)
+
+	// transactions already in pool are skipped
+	accepted, rejected, err = pool.LoadMempool()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, accepted)
+	assert.Equal(t, 0, rejected)
+
+	// unsigned transactions are validated again and rejected
+	params := config.GetDefaultParams()
+	restarted := NewTxPool(params, checkpoint.NewManager(params))
+	restarted.SetDataPath(dataPath)
+	accepted, rejected, err = restarted.LoadMempool()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, accepted)
+	assert.Equal(t, 2, rejected)
+
+	// corrupted mempool file
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dataPath,
+		MempoolFileName), []byte{1, 0, 0, 0, 2, 1}, 0600))
+	_, _, err = restarted.LoadMempool()
+	assert.Error(t, err)
+}
+
 func TestTxPool_End(t *testing.T) {
 	blockchain.DefaultLedger.Store.Close()
 	blockchain.DefaultLedger = initialLedger
@@ -0,0 +1,246 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package node
+
+import (
+	"path/filepath"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/checkpoint"
+	"github.com/elastos/Elastos.ELA/core/types"
+	crstate "github.com/elastos/Elastos.ELA/cr/state"
+	"github.com/elastos/Elastos.ELA/dpos/state"
+	"github.com/elastos/Elastos.ELA/mempool"
+	"github.com/elastos/Elastos.ELA/p2p"
+	"github.com/elastos/Elastos.ELA/pow"
+)
+
+// checkpointPath indicates the path storing the checkpoint data in the data
+// directory of the chain.
+const checkpointPath = "checkpoints"
+
+// Config is the configuration to create the chain of a node.
+type Config struct {
+	// ChainParams is the parameters of the chain.
+	ChainParams *config.Configuration
+
+	// DataDir is the directory storing the chain data and checkpoints.
+	DataDir string
+
+	// SnapshotFile, if set, is the snapshot file to bootstrap an empty
+	// chain database from.
+	SnapshotFile string
+
+	// Interrupt stops the initialization of the chain when closed.
+	Interrupt <-chan struct{}
+
+	// BarStart and BarIncrease report the progress of migrating the old
+	// database, they can be nil.
+	BarStart    func(total uint32)
+	BarIncrease func()
+}
+
+// Node holds the blockchain of a node with the chain states and the pools.
+// The chain is wired in the same way for the full node, the devnet node, the
+// block importer and the scenario chain, and the functions depending on the
+// network are registered by RegisterFunctions.
+type Node struct {
+	ChainParams *config.Configuration
+	CkpManager  *checkpoint.Manager
+	Store       blockchain.IChainStore
+	Chain       *blockchain.BlockChain
+	TxPool      *mempool.TxPool
+	BlockPool   *mempool.BlockPool
+	Arbiters    *state.Arbiters
+	Committee   *crstate.Committee
+
+	interrupt <-chan struct{}
+}
+
+// RegisterFunctions registers the functions depending on the network to the
+// block pool and the chain states.
+func (n *Node) RegisterFunctions(isCurrent func() bool,
+	broadcast func(msg p2p.Message)) {
+	chain := n.Chain
+	n.BlockPool.IsCurrent = isCurrent
+	n.Arbiters.State.RegisterFuncitons(&state.StateFuncsConfig{
+		GetHeight:                           n.Store.GetHeight,
+		IsCurrent:                           isCurrent,
+		Broadcast:                           broadcast,
+		AppendToTxpool:                      n.TxPool.AppendToTxPool,
+		CreateDposV2RealWithdrawTransaction: chain.CreateDposV2RealWithdrawTransaction,
+		CreateVotesRealWithdrawTransaction:  chain.CreateVotesRealWithdrawTransaction,
+	})
+	n.Committee.RegisterFuncitons(&crstate.CommitteeFuncsConfig{
+		GetTxReference:                   chain.UTXOCache.GetTxReference,
+		GetUTXO:                          n.Store.GetFFLDB().GetUTXO,
+		GetHeight:                        n.Store.GetHeight,
+		CreateCRAppropriationTransaction: chain.CreateCRCAppropriationTransaction,
+		CreateCRAssetsRectifyTransaction: chain.CreateCRAssetsRectifyTransaction,
+		CreateCRRealWithdrawTransaction:  chain.CreateCRRealWithdrawTransaction,
+		IsCurrent:                        isCurrent,
+		Broadcast:                        broadcast,
+		AppendToTxpool:                   n.TxPool.AppendToTxPool,
+		GetCurrentArbiters:               n.Arbiters.GetCurrentArbitratorKeys,
+	})
+}
+
+// NewPowService creates the PoW service generating blocks on the chain, the
+// generated blocks are broadcast by broadcastBlock.
+func (n *Node) NewPowService(
+	broadcastBlock func(block *types.Block)) *pow.Service {
+	cfg := n.ChainParams
+	return pow.NewService(&pow.Config{
+		PayToAddr:      cfg.PowConfiguration.PayToAddr,
+		MinerInfo:      cfg.PowConfiguration.MinerInfo,
+		Chain:          n.Chain,
+		ChainParams:    cfg,
+		TxMemPool:      n.TxPool,
+		BlkMemPool:     n.BlockPool,
+		BroadcastBlock: broadcastBlock,
+		Arbitrators:    n.Arbiters,
+	})
+}
+
+// InitCheckpoint recovers the chain states from the checkpoints and the
+// blocks after them, and retains the transactions of the blocks which can be
+// pruned.  It must be called after the functions are registered.
+func (n *Node) InitCheckpoint(barStart func(total uint32),
+	barIncrease func()) error {
+	n.CkpManager.SetNeedSave(true)
+	err := n.Chain.InitCheckpoint(n.interrupt, barStart, barIncrease)
+	if err != nil {
+		return err
+	}
+	return n.Chain.InitPrune(n.interrupt)
+}
+
+// LoadMempool loads the transactions saved by SaveMempool into the
+// transaction pool if the mempool is persisted.
+func (n *Node) LoadMempool() {
+	if !n.ChainParams.PersistMempool {
+		return
+	}
+	accepted, rejected, err := n.TxPool.LoadMempool()
+	if err != nil {
+		log.Warn("Load mempool failed,", err)
+		return
+	}
+	log.Infof("Loaded %d transactions from mempool, %d rejected",
+		accepted, rejected)
+}
+
+// SaveMempool saves the transactions in the transaction pool if the mempool
+// is persisted.
+func (n *Node) SaveMempool() {
+	if !n.ChainParams.PersistMempool {
+		return
+	}
+	count, err := n.TxPool.SaveMempool()
+	if err != nil {
+		log.Warn("Save mempool failed,", err)
+		return
+	}
+	log.Infof("Saved %d transactions to mempool", count)
+}
+
+// Close closes the chain store with its level DB, so the data directory can
+// be opened again in the same process.
+func (n *Node) Close() {
+	n.Store.Close()
+	n.Store.CloseLeveldb()
+}
+
+// New creates the chain store in the data directory, and initializes the
+// blockchain with the chain states and the pools.
+func New(cfg *Config) (*Node, error) {
+	params := cfg.ChainParams
+	ckpManager := checkpoint.NewManager(params)
+	ckpManager.SetDataPath(filepath.Join(cfg.DataDir, checkpointPath))
+
+	// fixme remove singleton Ledger
+	ledger := blockchain.Ledger{}
+
+	// Initializes the foundation address
+	blockchain.FoundationAddress = *params.FoundationProgramHash
+	chainStore, err := blockchain.NewChainStore(cfg.DataDir, params)
+	if err != nil {
+		return nil, err
+	}
+	n := &Node{
+		ChainParams: params,
+		CkpManager:  ckpManager,
+		Store:       chainStore,
+		interrupt:   cfg.Interrupt,
+	}
+	if err := n.init(cfg, &ledger); err != nil {
+		chainStore.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+func (n *Node) init(cfg *Config, ledger *blockchain.Ledger) error {
+	params, ckpManager, chainStore := n.ChainParams, n.CkpManager, n.Store
+	ledger.Store = chainStore
+
+	txPool := mempool.NewTxPool(params, ckpManager)
+	txPool.SetDataPath(cfg.DataDir)
+	blockPool := mempool.NewBlockPool(params)
+	blockPool.Store = chainStore
+	blockchain.DefaultLedger = ledger
+
+	committee := crstate.NewCommittee(params, ckpManager)
+	ledger.Committee = committee
+	arbiters, err := state.NewArbitrators(params, committee, ledger.GetAmount,
+		committee.TryUpdateCRMemberInactivity,
+		committee.TryRevertCRMemberInactivity,
+		committee.TryUpdateCRMemberIllegal,
+		committee.TryRevertCRMemberIllegal,
+		committee.UpdateCRInactivePenalty,
+		committee.RevertUpdateCRInactivePenalty,
+		ckpManager,
+	)
+	if err != nil {
+		return err
+	}
+	ledger.Arbitrators = arbiters
+
+	if cfg.SnapshotFile != "" {
+		_, err = blockchain.LoadSnapshotFile(chainStore, params, ckpManager,
+			cfg.SnapshotFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	chain, err := blockchain.New(chainStore, params, arbiters.State,
+		committee, ckpManager)
+	if err != nil {
+		return err
+	}
+	if err = chain.Init(cfg.Interrupt); err != nil {
+		return err
+	}
+	if err = chain.MigrateOldDB(cfg.Interrupt, cfg.BarStart,
+		cfg.BarIncrease, cfg.DataDir, params); err != nil {
+		return err
+	}
+
+	ledger.Blockchain = chain
+	blockPool.Chain = chain
+	arbiters.RegisterFunction(chain.GetHeight, chain.GetBestBlockHash,
+		chain.GetBlock, chain.UTXOCache.GetTxReference)
+
+	n.Chain = chain
+	n.TxPool = txPool
+	n.BlockPool = blockPool
+	n.Arbiters = arbiters
+	n.Committee = committee
+	return nil
+}
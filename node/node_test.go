@@ -0,0 +1,75 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package node
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/transaction"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/mempool"
+	"github.com/elastos/Elastos.ELA/p2p"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	functions.GetTransactionByTxType = transaction.GetTransaction
+	functions.GetTransactionByBytes = transaction.GetTransactionByBytes
+	functions.CreateTransaction = transaction.CreateTransaction
+	functions.GetTransactionParameters = transaction.GetTransactionparameters
+}
+
+func TestNode_StartAndStop(t *testing.T) {
+	dir, err := ioutil.TempDir("", "node")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	log.NewDefault(filepath.Join(dir, "logs"), 0, 0, 0)
+
+	params := config.GetDefaultParams().Sterilize()
+	params.PersistMempool = true
+	genesis := params.GenesisBlock
+
+	start := func() *Node {
+		n, err := New(&Config{
+			ChainParams: params,
+			DataDir:     dir,
+			Interrupt:   make(chan struct{}),
+		})
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		n.RegisterFunctions(func() bool { return true },
+			func(msg p2p.Message) {})
+		assert.NotNil(t, n.NewPowService(nil))
+		assert.NoError(t, n.InitCheckpoint(nil, nil))
+		n.LoadMempool()
+
+		assert.Equal(t, uint32(0), n.Chain.GetHeight())
+		assert.Equal(t, genesis.Hash(), *n.Chain.BestChain.Hash)
+		assert.Equal(t, n.Chain, n.BlockPool.Chain)
+		assert.NotNil(t, n.Arbiters.State)
+		assert.NotNil(t, n.Committee)
+		return n
+	}
+
+	// Start a node on an empty data directory, the mempool is saved on
+	// shutdown.
+	n := start()
+	n.SaveMempool()
+	n.Close()
+	_, err = os.Stat(filepath.Join(dir, mempool.MempoolFileName))
+	assert.NoError(t, err)
+
+	// Restart the node on the same data directory.
+	n = start()
+	n.Close()
+}
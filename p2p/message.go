@@ -58,6 +58,11 @@ const (
 	CmdReject      = "reject"
 	CmdTxFilter    = "txfilter"
 	CmdDAddr       = "daddr"
+	CmdGetHeaders  = "getheaders"
+	CmdHeaders     = "headers"
+	CmdCmpctBlock  = "cmpctblock"
+	CmdGetBlockTxn = "getblocktxn"
+	CmdBlockTxn    = "blocktxn"
 )
 
 var (
@@ -0,0 +1,89 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package msg
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// Ensure BlockTxn implement p2p.Message interface.
+var _ p2p.Message = (*BlockTxn)(nil)
+
+// BlockTxn is the response of a getblocktxn message, it contains the requested
+// transactions of the block in the order of the requested indexes.
+type BlockTxn struct {
+	BlockHash common.Uint256
+	Txs       []interfaces.Transaction
+}
+
+func NewBlockTxn(blockHash common.Uint256, txs []interfaces.Transaction) *BlockTxn {
+	return &BlockTxn{BlockHash: blockHash, Txs: txs}
+}
+
+func (msg *BlockTxn) CMD() string {
+	return p2p.CmdBlockTxn
+}
+
+func (msg *BlockTxn) MaxLength() uint32 {
+	return pact.MaxBlockContextSize
+}
+
+func (msg *BlockTxn) Serialize(w io.Writer) error {
+	count := len(msg.Txs)
+	if count > int(pact.MaxTxPerBlock) {
+		str := fmt.Sprintf("too many transactions for message "+
+			"[count %v, max %v]", count, pact.MaxTxPerBlock)
+		return common.FuncError("BlockTxn.Serialize", str)
+	}
+
+	if err := msg.BlockHash.Serialize(w); err != nil {
+		return err
+	}
+	if err := common.WriteVarUint(w, uint64(count)); err != nil {
+		return err
+	}
+	for _, tx := range msg.Txs {
+		if err := tx.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (msg *BlockTxn) Deserialize(r io.Reader) error {
+	if err := msg.BlockHash.Deserialize(r); err != nil {
+		return err
+	}
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count > uint64(pact.MaxTxPerBlock) {
+		str := fmt.Sprintf("too many transactions for message "+
+			"[count %v, max %v]", count, pact.MaxTxPerBlock)
+		return common.FuncError("BlockTxn.Deserialize", str)
+	}
+
+	msg.Txs = make([]interfaces.Transaction, 0, count)
+	for i := uint64(0); i < count; i++ {
+		tx, err := functions.GetTransactionByBytes(r)
+		if err != nil {
+			return err
+		}
+		if err := tx.Deserialize(r); err != nil {
+			return err
+		}
+		msg.Txs = append(msg.Txs, tx)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package msg
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/types"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// ShortIDLength is the number of bytes of a short transaction id.
+const ShortIDLength = 6
+
+// Ensure CmpctBlock implement p2p.Message interface.
+var _ p2p.Message = (*CmpctBlock)(nil)
+
+// PrefilledTx is a transaction sent in full within a compact block, the
+// index is the position of the transaction in the block.
+type PrefilledTx struct {
+	Index uint32
+	Tx    interfaces.Transaction
+}
+
+// CmpctBlock is a block relayed by the short ids of its transactions, which
+// the receiver is expected to reconstruct from its transaction pool.  The
+// coinbase is always prefilled as it can not be in any pool.
+type CmpctBlock struct {
+	Header       common2.Header
+	Nonce        uint64
+	ShortIDs     []uint64
+	PrefilledTxs []*PrefilledTx
+	HaveConfirm  bool
+	Confirm      *payload.Confirm
+}
+
+// NewCmpctBlock creates a compact block of the block with the nonce used to
+// calculate the short transaction ids.
+func NewCmpctBlock(block *types.DposBlock, nonce uint64) *CmpctBlock {
+	msg := &CmpctBlock{
+		Header:      block.Header,
+		Nonce:       nonce,
+		ShortIDs:    make([]uint64, 0, len(block.Transactions)),
+		HaveConfirm: block.HaveConfirm,
+		Confirm:     block.Confirm,
+	}
+	k0, k1 := msg.ShortIDKeys()
+	for i, tx := range block.Transactions {
+		if tx.IsCoinBaseTx() {
+			msg.PrefilledTxs = append(msg.PrefilledTxs,
+				&PrefilledTx{Index: uint32(i), Tx: tx})
+			continue
+		}
+		msg.ShortIDs = append(msg.ShortIDs, ShortTxID(k0, k1, tx.Hash()))
+	}
+	return msg
+}
+
+// TxCount returns the number of transactions in the block.
+func (msg *CmpctBlock) TxCount() int {
+	return len(msg.ShortIDs) + len(msg.PrefilledTxs)
+}
+
+// ShortIDKeys returns the SipHash keys of the short transaction ids, which are
+// the first two little endian uint64 of the SHA256 of the block hash and the
+// nonce.
+func (msg *CmpctBlock) ShortIDKeys() (uint64, uint64) {
+	hash := msg.Header.Hash()
+	var buf [common.UINT256SIZE + 8]byte
+	copy(buf[:], hash[:])
+	binary.LittleEndian.PutUint64(buf[common.UINT256SIZE:], msg.Nonce)
+	sum := sha256.Sum256(buf[:])
+	return binary.LittleEndian.Uint64(sum[0:8]),
+		binary.LittleEndian.Uint64(sum[8:16])
+}
+
+func (msg *CmpctBlock) CMD() string {
+	return p2p.CmdCmpctBlock
+}
+
+func (msg *CmpctBlock) MaxLength() uint32 {
+	return (pact.MaxBlockContextSize + pact.MaxBlockHeaderSize) * 2
+}
+
+func (msg *CmpctBlock) Serialize(w io.Writer) error {
+	if msg.TxCount() > int(pact.MaxTxPerBlock) {
+		str := fmt.Sprintf("too many transactions for message "+
+			"[count %v, max %v]", msg.TxCount(), pact.MaxTxPerBlock)
+		return common.FuncError("CmpctBlock.Serialize", str)
+	}
+
+	if err := msg.Header.Serialize(w); err != nil {
+		return err
+	}
+	if err := common.WriteUint64(w, msg.Nonce); err != nil {
+		return err
+	}
+
+	if err := common.WriteVarUint(w, uint64(len(msg.ShortIDs))); err != nil {
+		return err
+	}
+	var shortID [8]byte
+	for _, id := range msg.ShortIDs {
+		binary.LittleEndian.PutUint64(shortID[:], id)
+		if _, err := w.Write(shortID[:ShortIDLength]); err != nil {
+			return err
+		}
+	}
+
+	// The indexes of prefilled transactions are differentially encoded.
+	err := common.WriteVarUint(w, uint64(len(msg.PrefilledTxs)))
+	if err != nil {
+		return err
+	}
+	lastIndex := -1
+	for _, prefilled := range msg.PrefilledTxs {
+		if int(prefilled.Index) <= lastIndex {
+			return common.FuncError("CmpctBlock.Serialize",
+				"prefilled transactions are not in order")
+		}
+		offset := uint64(int(prefilled.Index) - lastIndex - 1)
+		if err := common.WriteVarUint(w, offset); err != nil {
+			return err
+		}
+		if err := prefilled.Tx.Serialize(w); err != nil {
+			return err
+		}
+		lastIndex = int(prefilled.Index)
+	}
+
+	if err := common.WriteElement(w, msg.HaveConfirm); err != nil {
+		return err
+	}
+	if msg.HaveConfirm {
+		return msg.Confirm.Serialize(w)
+	}
+	return nil
+}
+
+func (msg *CmpctBlock) Deserialize(r io.Reader) error {
+	if err := msg.Header.Deserialize(r); err != nil {
+		return err
+	}
+	nonce, err := common.ReadUint64(r)
+	if err != nil {
+		return err
+	}
+	msg.Nonce = nonce
+
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count > uint64(pact.MaxTxPerBlock) {
+		str := fmt.Sprintf("too many short ids for message "+
+			"[count %v, max %v]", count, pact.MaxTxPerBlock)
+		return common.FuncError("CmpctBlock.Deserialize", str)
+	}
+	msg.ShortIDs = make([]uint64, 0, count)
+	var shortID [8]byte
+	for i := uint64(0); i < count; i++ {
+		if _, err := io.ReadFull(r, shortID[:ShortIDLength]); err != nil {
+			return err
+		}
+		msg.ShortIDs = append(msg.ShortIDs,
+			binary.LittleEndian.Uint64(shortID[:]))
+	}
+
+	prefilledCount, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count+prefilledCount > uint64(pact.MaxTxPerBlock) {
+		str := fmt.Sprintf("too many transactions for message "+
+			"[count %v, max %v]", count+prefilledCount, pact.MaxTxPerBlock)
+		return common.FuncError("CmpctBlock.Deserialize", str)
+	}
+	msg.PrefilledTxs = make([]*PrefilledTx, 0, prefilledCount)
+	lastIndex := -1
+	for i := uint64(0); i < prefilledCount; i++ {
+		offset, err := common.ReadVarUint(r, 0)
+		if err != nil {
+			return err
+		}
+		index := uint64(lastIndex+1) + offset
+		if index >= count+prefilledCount {
+			return common.FuncError("CmpctBlock.Deserialize",
+				"prefilled transaction index out of range")
+		}
+		tx, err := functions.GetTransactionByBytes(r)
+		if err != nil {
+			return err
+		}
+		if err := tx.Deserialize(r); err != nil {
+			return err
+		}
+		msg.PrefilledTxs = append(msg.PrefilledTxs,
+			&PrefilledTx{Index: uint32(index), Tx: tx})
+		lastIndex = int(index)
+	}
+
+	if err := common.ReadElement(r, &msg.HaveConfirm); err != nil {
+		return err
+	}
+	if msg.HaveConfirm {
+		msg.Confirm = new(payload.Confirm)
+		return msg.Confirm.Deserialize(r)
+	}
+	return nil
+}
+
+// ShortTxID returns the short id of the transaction hash, which is the lower
+// 6 bytes of the SipHash-2-4 of the hash with the given keys.
+func ShortTxID(k0, k1 uint64, txHash common.Uint256) uint64 {
+	return sipHash24(k0, k1, txHash[:]) & (1<<(ShortIDLength*8) - 1)
+}
+
+// sipHash24 returns the SipHash-2-4 of the data with the 128 bits key k0, k1.
+func sipHash24(k0, k1 uint64, data []byte) uint64 {
+	v0 := k0 ^ 0x736f6d6570736575
+	v1 := k1 ^ 0x646f72616e646f6d
+	v2 := k0 ^ 0x6c7967656e657261
+	v3 := k1 ^ 0x7465646279746573
+
+	round := func() {
+		v0 += v1
+		v1 = v1<<13 | v1>>51
+		v1 ^= v0
+		v0 = v0<<32 | v0>>32
+		v2 += v3
+		v3 = v3<<16 | v3>>48
+		v3 ^= v2
+		v0 += v3
+		v3 = v3<<21 | v3>>43
+		v3 ^= v0
+		v2 += v1
+		v1 = v1<<17 | v1>>47
+		v1 ^= v2
+		v2 = v2<<32 | v2>>32
+	}
+
+	length := len(data)
+	for ; len(data) >= 8; data = data[8:] {
+		m := binary.LittleEndian.Uint64(data)
+		v3 ^= m
+		round()
+		round()
+		v0 ^= m
+	}
+
+	// The last block holds the remaining bytes and the length.
+	var last [8]byte
+	copy(last[:], data)
+	last[7] = byte(length)
+	m := binary.LittleEndian.Uint64(last[:])
+	v3 ^= m
+	round()
+	round()
+	v0 ^= m
+
+	v2 ^= 0xff
+	round()
+	round()
+	round()
+	round()
+	return v0 ^ v1 ^ v2 ^ v3
+}
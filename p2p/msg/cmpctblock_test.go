@@ -0,0 +1,77 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package msg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSipHash24(t *testing.T) {
+	// Test vectors of the SipHash-2-4 reference implementation, the key is
+	// 00 01 02 ... 0f and the message is 00 01 02 ... of the length.
+	k0, k1 := uint64(0x0706050403020100), uint64(0x0f0e0d0c0b0a0908)
+	data := make([]byte, 15)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	assert.Equal(t, uint64(0x726fdb47dd0e0e31), sipHash24(k0, k1, data[:0]))
+	assert.Equal(t, uint64(0xa129ca6149be45e5), sipHash24(k0, k1, data))
+
+	id := ShortTxID(k0, k1, common.Uint256{1})
+	assert.Equal(t, uint64(0), id>>(ShortIDLength*8))
+}
+
+func TestCmpctBlock_Serialize(t *testing.T) {
+	block := &CmpctBlock{
+		Header:   common2.Header{Version: 1, Previous: common.Uint256{1}, Height: 10},
+		Nonce:    12345,
+		ShortIDs: []uint64{1, 0xffffffffffff, 0x123456789a},
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, block.Serialize(buf))
+	var decoded CmpctBlock
+	assert.NoError(t, decoded.Deserialize(buf))
+	assert.Equal(t, block.Header.Hash(), decoded.Header.Hash())
+	assert.Equal(t, block.Nonce, decoded.Nonce)
+	assert.Equal(t, block.ShortIDs, decoded.ShortIDs)
+	assert.Empty(t, decoded.PrefilledTxs)
+	assert.False(t, decoded.HaveConfirm)
+	assert.Equal(t, 3, decoded.TxCount())
+
+	// The short id keys depend on the block and the nonce.
+	k0, k1 := block.ShortIDKeys()
+	decoded.Nonce++
+	k2, k3 := decoded.ShortIDKeys()
+	assert.False(t, k0 == k2 && k1 == k3)
+}
+
+func TestGetBlockTxn_Serialize(t *testing.T) {
+	getBlockTxn := NewGetBlockTxn(common.Uint256{1}, []uint32{0, 2, 3, 300})
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, getBlockTxn.Serialize(buf))
+	var decoded GetBlockTxn
+	assert.NoError(t, decoded.Deserialize(buf))
+	assert.Equal(t, getBlockTxn, &decoded)
+
+	// The indexes must be in increasing order.
+	getBlockTxn.Indexes = []uint32{2, 1}
+	assert.Error(t, getBlockTxn.Serialize(new(bytes.Buffer)))
+
+	buf.Reset()
+	assert.NoError(t, NewBlockTxn(common.Uint256{2}, nil).Serialize(buf))
+	var blockTxn BlockTxn
+	assert.NoError(t, blockTxn.Deserialize(buf))
+	assert.Equal(t, common.Uint256{2}, blockTxn.BlockHash)
+	assert.Empty(t, blockTxn.Txs)
+}
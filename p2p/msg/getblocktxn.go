@@ -0,0 +1,100 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package msg
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// Ensure GetBlockTxn implement p2p.Message interface.
+var _ p2p.Message = (*GetBlockTxn)(nil)
+
+// GetBlockTxn requests the transactions of a compact block which are missing
+// from the transaction pool of the receiver, by their indexes in the block.
+type GetBlockTxn struct {
+	BlockHash common.Uint256
+	Indexes   []uint32
+}
+
+func NewGetBlockTxn(blockHash common.Uint256, indexes []uint32) *GetBlockTxn {
+	return &GetBlockTxn{BlockHash: blockHash, Indexes: indexes}
+}
+
+func (msg *GetBlockTxn) CMD() string {
+	return p2p.CmdGetBlockTxn
+}
+
+func (msg *GetBlockTxn) MaxLength() uint32 {
+	return common.UINT256SIZE + 9 + pact.MaxTxPerBlock*9
+}
+
+func (msg *GetBlockTxn) Serialize(w io.Writer) error {
+	count := len(msg.Indexes)
+	if count > int(pact.MaxTxPerBlock) {
+		str := fmt.Sprintf("too many transaction indexes for message "+
+			"[count %v, max %v]", count, pact.MaxTxPerBlock)
+		return common.FuncError("GetBlockTxn.Serialize", str)
+	}
+
+	if err := msg.BlockHash.Serialize(w); err != nil {
+		return err
+	}
+	if err := common.WriteVarUint(w, uint64(count)); err != nil {
+		return err
+	}
+
+	// The indexes are differentially encoded.
+	lastIndex := -1
+	for _, index := range msg.Indexes {
+		if int(index) <= lastIndex {
+			return common.FuncError("GetBlockTxn.Serialize",
+				"transaction indexes are not in order")
+		}
+		offset := uint64(int(index) - lastIndex - 1)
+		if err := common.WriteVarUint(w, offset); err != nil {
+			return err
+		}
+		lastIndex = int(index)
+	}
+	return nil
+}
+
+func (msg *GetBlockTxn) Deserialize(r io.Reader) error {
+	if err := msg.BlockHash.Deserialize(r); err != nil {
+		return err
+	}
+	count, err := common.ReadVarUint(r, 0)
+	if err != nil {
+		return err
+	}
+	if count > uint64(pact.MaxTxPerBlock) {
+		str := fmt.Sprintf("too many transaction indexes for message "+
+			"[count %v, max %v]", count, pact.MaxTxPerBlock)
+		return common.FuncError("GetBlockTxn.Deserialize", str)
+	}
+
+	msg.Indexes = make([]uint32, 0, count)
+	lastIndex := -1
+	for i := uint64(0); i < count; i++ {
+		offset, err := common.ReadVarUint(r, 0)
+		if err != nil {
+			return err
+		}
+		index := uint64(lastIndex+1) + offset
+		if index >= uint64(pact.MaxTxPerBlock) {
+			return common.FuncError("GetBlockTxn.Deserialize",
+				"transaction index out of range")
+		}
+		msg.Indexes = append(msg.Indexes, uint32(index))
+		lastIndex = int(index)
+	}
+	return nil
+}
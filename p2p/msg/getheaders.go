@@ -0,0 +1,85 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package msg
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// Ensure GetHeaders implement p2p.Message interface.
+var _ p2p.Message = (*GetHeaders)(nil)
+
+type GetHeaders struct {
+	Locator  []*common.Uint256
+	HashStop common.Uint256
+}
+
+func NewGetHeaders(locator []*common.Uint256, hashStop common.Uint256) *GetHeaders {
+	msg := new(GetHeaders)
+	msg.Locator = locator
+	msg.HashStop = hashStop
+	return msg
+}
+
+func (msg *GetHeaders) CMD() string {
+	return p2p.CmdGetHeaders
+}
+
+func (msg *GetHeaders) MaxLength() uint32 {
+	return 4 + (MaxBlockLocatorsPerMsg * common.UINT256SIZE) + common.UINT256SIZE
+}
+
+func (msg *GetHeaders) Serialize(w io.Writer) error {
+	count := len(msg.Locator)
+	if count > MaxBlockLocatorsPerMsg {
+		str := fmt.Sprintf("too many block locator hashes for message "+
+			"[count %v, max %v]", count, MaxBlockLocatorsPerMsg)
+		return common.FuncError("GetHeaders.Serialize", str)
+	}
+
+	err := common.WriteUint32(w, uint32(count))
+	if err != nil {
+		return err
+	}
+
+	for _, hash := range msg.Locator {
+		if err := hash.Serialize(w); err != nil {
+			return err
+		}
+	}
+
+	return msg.HashStop.Serialize(w)
+}
+
+func (msg *GetHeaders) Deserialize(reader io.Reader) error {
+	count, err := common.ReadUint32(reader)
+	if err != nil {
+		return err
+	}
+	if count > MaxBlockLocatorsPerMsg {
+		str := fmt.Sprintf("too many block locator hashes for message "+
+			"[count %v, max %v]", count, MaxBlockLocatorsPerMsg)
+		return common.FuncError("GetHeaders.Deserialize", str)
+	}
+
+	// Create a contiguous slice of hashes to deserialize into in order to
+	// reduce the number of allocations.
+	locator := make([]common.Uint256, count)
+	msg.Locator = make([]*common.Uint256, 0, count)
+	for i := uint32(0); i < count; i++ {
+		hash := &locator[i]
+		if err := hash.Deserialize(reader); err != nil {
+			return err
+		}
+		msg.Locator = append(msg.Locator, hash)
+	}
+
+	return msg.HashStop.Deserialize(reader)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package msg
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/elastos/Elastos.ELA/common"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/p2p"
+)
+
+// MaxHeadersPerMsg is the maximum number of block headers allowed per
+// message.
+const MaxHeadersPerMsg = 2000
+
+// Ensure Headers implement p2p.Message interface.
+var _ p2p.Message = (*Headers)(nil)
+
+// Headers is the response of a getheaders message, it contains the block
+// headers after the first known block of the locator.
+type Headers struct {
+	Headers []*common2.Header
+}
+
+func NewHeaders(headers []*common2.Header) *Headers {
+	return &Headers{Headers: headers}
+}
+
+func (msg *Headers) CMD() string {
+	return p2p.CmdHeaders
+}
+
+func (msg *Headers) MaxLength() uint32 {
+	return p2p.MaxMessagePayload
+}
+
+func (msg *Headers) Serialize(w io.Writer) error {
+	count := len(msg.Headers)
+	if count > MaxHeadersPerMsg {
+		str := fmt.Sprintf("too many block headers for message "+
+			"[count %v, max %v]", count, MaxHeadersPerMsg)
+		return common.FuncError("Headers.Serialize", str)
+	}
+
+	err := common.WriteUint32(w, uint32(count))
+	if err != nil {
+		return err
+	}
+
+	for _, header := range msg.Headers {
+		if err := header.Serialize(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (msg *Headers) Deserialize(r io.Reader) error {
+	count, err := common.ReadUint32(r)
+	if err != nil {
+		return err
+	}
+	if count > MaxHeadersPerMsg {
+		str := fmt.Sprintf("too many block headers for message "+
+			"[count %v, max %v]", count, MaxHeadersPerMsg)
+		return common.FuncError("Headers.Deserialize", str)
+	}
+
+	// Create a contiguous slice of headers to deserialize into in order to
+	// reduce the number of allocations.
+	headers := make([]common2.Header, count)
+	msg.Headers = make([]*common2.Header, 0, count)
+	for i := uint32(0); i < count; i++ {
+		header := &headers[i]
+		if err := header.Deserialize(r); err != nil {
+			return err
+		}
+		msg.Headers = append(msg.Headers, header)
+	}
+	return nil
+}
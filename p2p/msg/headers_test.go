@@ -0,0 +1,57 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package msg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHeaders_Serialize(t *testing.T) {
+	hash1 := common.Uint256{1}
+	hash2 := common.Uint256{2}
+	getHeaders := NewGetHeaders([]*common.Uint256{&hash1, &hash2},
+		common.Uint256{3})
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, getHeaders.Serialize(buf))
+	var decoded GetHeaders
+	assert.NoError(t, decoded.Deserialize(buf))
+	assert.Equal(t, getHeaders, &decoded)
+
+	getHeaders.Locator = make([]*common.Uint256, MaxBlockLocatorsPerMsg+1)
+	assert.Error(t, getHeaders.Serialize(new(bytes.Buffer)))
+}
+
+func TestHeaders_Serialize(t *testing.T) {
+	headers := NewHeaders([]*common2.Header{
+		{Version: 1, Previous: common.Uint256{1}, Height: 1, Bits: 2},
+		{Version: 1, Previous: common.Uint256{2}, Height: 2, Timestamp: 3},
+	})
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, headers.Serialize(buf))
+	var decoded Headers
+	assert.NoError(t, decoded.Deserialize(buf))
+	assert.Equal(t, len(headers.Headers), len(decoded.Headers))
+	for i, header := range headers.Headers {
+		assert.Equal(t, header.Hash(), decoded.Headers[i].Hash())
+	}
+
+	// An empty headers message is valid.
+	buf.Reset()
+	assert.NoError(t, NewHeaders(nil).Serialize(buf))
+	assert.NoError(t, decoded.Deserialize(buf))
+	assert.Empty(t, decoded.Headers)
+
+	headers.Headers = make([]*common2.Header, MaxHeadersPerMsg+1)
+	assert.Error(t, headers.Serialize(new(bytes.Buffer)))
+}
@@ -8,6 +8,7 @@ package pow
 import (
 	"time"
 
+	"github.com/elastos/Elastos.ELA/common/config"
 	"github.com/elastos/Elastos.ELA/common/log"
 	"github.com/elastos/Elastos.ELA/core/contract/program"
 	common2 "github.com/elastos/Elastos.ELA/core/types/common"
@@ -22,7 +23,7 @@ func (pow *Service) ListenForRevert() {
 		for {
 			time.Sleep(CheckRevertToPOWInterval)
 			currentHeight := pow.chain.BestChain.Height
-			if currentHeight < pow.chainParams.DPoSConfiguration.RevertToPOWStartHeight {
+			if !pow.chainParams.IsActive(config.DeploymentRevertToPOW, currentHeight) {
 				continue
 			}
 			if pow.arbiters.IsInPOWMode() {
@@ -31,7 +32,7 @@ func (pow *Service) ListenForRevert() {
 			lastBlockTimestamp := int64(pow.arbiters.GetLastBlockTimestamp())
 			localTimestamp := pow.chain.TimeSource.AdjustedTime().Unix()
 			var noBlockTime int64
-			if currentHeight < pow.chainParams.DPoSConfiguration.ChangeViewV1Height {
+			if !pow.chainParams.IsActive(config.DeploymentChangeViewV1, currentHeight) {
 				noBlockTime = pow.chainParams.DPoSConfiguration.RevertToPOWNoBlockTime
 			} else {
 				noBlockTime = pow.chainParams.DPoSConfiguration.RevertToPOWNoBlockTimeV1
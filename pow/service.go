@@ -108,7 +108,7 @@ type Service struct {
 func (pow *Service) GetDefaultTxVersion(height uint32) common2.TransactionVersion {
 	var v common2.TransactionVersion = 0
 	// when block height greater than H2 use the version TxVersion09
-	if height >= pow.chainParams.PublicDPOSHeight {
+	if pow.chainParams.IsActive(config.DeploymentPublicDPOS, height) {
 		v = common2.TxVersion09
 	}
 	return v
@@ -117,7 +117,7 @@ func (pow *Service) GetDefaultTxVersion(height uint32) common2.TransactionVersio
 func (pow *Service) CreateCoinbaseTx(minerAddr string, height uint32) (interfaces.Transaction, error) {
 
 	crRewardAddr := pow.chainParams.FoundationProgramHash
-	if height >= pow.chainParams.CRConfiguration.CRCommitteeStartHeight {
+	if pow.chainParams.IsActive(config.DeploymentCRCommittee, height) {
 		crRewardAddr = pow.chainParams.CRConfiguration.CRAssetsProgramHash
 	}
 
@@ -222,7 +222,7 @@ func (pow *Service) AssignCoinbaseTxRewards(block *types.Block, totalReward comm
 	}
 
 	// main version >= H2
-	if block.Height >= pow.chainParams.PublicDPOSHeight {
+	if pow.chainParams.IsActive(config.DeploymentPublicDPOS, block.Height) {
 		rewardCyberRepublic := common.Fixed64(math.Ceil(float64(totalReward) * 0.3))
 		rewardDposArbiter := common.Fixed64(math.Ceil(float64(totalReward) * 0.35))
 		rewardMergeMiner := common.Fixed64(totalReward) - rewardCyberRepublic - rewardDposArbiter
@@ -303,7 +303,7 @@ func (pow *Service) GenerateBlock(minerAddr string,
 	totalTxsSize := coinBaseTx.GetSize()
 	totalTxFee := common.Fixed64(0)
 
-	if bestChain.Height+1 >= pow.chainParams.DPoSConfiguration.RecordSponsorStartHeight {
+	if pow.chainParams.IsActive(config.DeploymentRecordSponsor, bestChain.Height+1) {
 		bestBlock, err := pow.chain.GetDposBlockByHash(*bestChain.Hash)
 		if err != nil {
 			return nil, err
@@ -342,6 +342,11 @@ func (pow *Service) GenerateBlock(minerAddr string,
 		return txs[i].FeePerKB() > txs[j].FeePerKB()
 	})
 
+	var medianTime time.Time
+	if pow.chainParams.IsActive(config.DeploymentTimeLock, nextBlockHeight) {
+		medianTime = pow.chain.PastMedianTime()
+	}
+
 	var proposalsUsedAmount common.Fixed64
 	for _, tx := range txs {
 		if tx.IsRecordSponorTx() {
@@ -358,7 +363,7 @@ func (pow *Service) GenerateBlock(minerAddr string,
 			break
 		}
 
-		if !blockchain.IsFinalizedTransaction(tx, nextBlockHeight) {
+		if !blockchain.IsFinalizedTransaction(tx, nextBlockHeight, medianTime) {
 			continue
 		}
 		_, errCode := pow.chain.CheckTransactionContext(nextBlockHeight, tx, proposalsUsedAmount, header.Timestamp)
@@ -527,6 +527,143 @@ type UTXOInfo struct {
 	Confirmations uint32 `json:"confirmations"`
 }
 
+type AddressHistoryInfo struct {
+	TxID          string `json:"txid"`
+	Height        uint32 `json:"height"`
+	Direction     string `json:"direction"`
+	Amount        string `json:"amount"`
+	Confirmations uint32 `json:"confirmations"`
+}
+
+type AddressHistoryResult struct {
+	Total   uint32               `json:"total"`
+	History []AddressHistoryInfo `json:"history"`
+}
+
+type EstimateFeeInfo struct {
+	FeeRate    int64   `json:"feerate"`
+	Confidence float64 `json:"confidence"`
+	Blocks     uint32  `json:"blocks"`
+}
+
+type SaveMempoolInfo struct {
+	Saved int    `json:"saved"`
+	File  string `json:"file"`
+}
+
+type LoadMempoolInfo struct {
+	Accepted int `json:"accepted"`
+	Rejected int `json:"rejected"`
+}
+
+type DumpSnapshotInfo struct {
+	Height      uint32 `json:"height"`
+	Hash        string `json:"hash"`
+	ContentHash string `json:"contenthash"`
+	Size        int64  `json:"size"`
+	Path        string `json:"path"`
+}
+
+type GenerateToHeightInfo struct {
+	Height    uint32 `json:"height"`
+	Hash      string `json:"hash"`
+	Generated uint32 `json:"generated"`
+}
+
+type FundAddressInfo struct {
+	TxID      string `json:"txid"`
+	BlockHash string `json:"blockhash"`
+}
+
+type DevNetInfo struct {
+	Faucet   string   `json:"faucet"`
+	Arbiters []string `json:"arbiters"`
+}
+
+type DeploymentInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Height      uint32 `json:"height"`
+	Active      bool   `json:"active"`
+}
+
+type DeploymentsInfo struct {
+	Height      uint32           `json:"height"`
+	Deployments []DeploymentInfo `json:"deployments"`
+}
+
+type UTXOLockInfo struct {
+	TxID            string `json:"txid"`
+	VOut            uint16 `json:"vout"`
+	OutputLock      uint32 `json:"outputlock"`
+	LockType        string `json:"locktype"`
+	Sequence        uint32 `json:"sequence"`
+	LockTime        uint32 `json:"locktime"`
+	SpendableHeight uint32 `json:"spendableheight"`
+	SpendableTime   int64  `json:"spendabletime"`
+	Spendable       bool   `json:"spendable"`
+}
+
+type CheckResultInfo struct {
+	Name   string `json:"name"`
+	Result string `json:"result"`
+	Code   int    `json:"code,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type CheckLookupInfo struct {
+	Name   string `json:"name"`
+	Key    string `json:"key"`
+	Result string `json:"result"`
+}
+
+type TestMempoolAcceptInfo struct {
+	TxID    string            `json:"txid"`
+	Allowed bool              `json:"allowed"`
+	Code    int               `json:"code,omitempty"`
+	Reason  string            `json:"reason,omitempty"`
+	Size    int               `json:"size"`
+	Fee     string            `json:"fee"`
+	Checks  []CheckResultInfo `json:"checks"`
+	Lookups []CheckLookupInfo `json:"lookups"`
+}
+
+type PSBTInputInfo struct {
+	TxID         string `json:"txid"`
+	VOut         uint16 `json:"vout"`
+	Address      string `json:"address,omitempty"`
+	Value        string `json:"value,omitempty"`
+	RedeemScript string `json:"redeemscript,omitempty"`
+}
+
+type PSBTOutputInfo struct {
+	Index        uint32 `json:"n"`
+	Address      string `json:"address"`
+	Value        string `json:"value"`
+	RedeemScript string `json:"redeemscript,omitempty"`
+}
+
+type PSBTProgramInfo struct {
+	Address    string   `json:"address"`
+	Type       string   `json:"type"`
+	Code       string   `json:"code"`
+	Signed     int      `json:"signed"`
+	Required   int      `json:"required"`
+	PublicKeys []string `json:"publickeys,omitempty"`
+	Missing    []string `json:"missing"`
+	Nonces     []string `json:"nonces,omitempty"`
+}
+
+type PartiallySignedTransactionInfo struct {
+	Version     byte              `json:"psbtversion"`
+	Transaction *TransactionInfo  `json:"tx"`
+	Inputs      []PSBTInputInfo   `json:"inputs"`
+	Outputs     []PSBTOutputInfo  `json:"outputs"`
+	Programs    []PSBTProgramInfo `json:"programs"`
+	Fee         string            `json:"fee,omitempty"`
+	Complete    bool              `json:"complete"`
+}
+
 type SidechainIllegalDataInfo struct {
 	IllegalType         uint8    `json:"illegaltype"`
 	Height              uint32   `json:"height"`
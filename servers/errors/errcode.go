@@ -36,6 +36,7 @@ const (
 	SessionExpired       ServerErrCode = 41001
 	IllegalDataFormat    ServerErrCode = 41003
 	PowServiceNotStarted ServerErrCode = 41004
+	DevNetNotEnabled     ServerErrCode = 41005
 	InvalidMethod        ServerErrCode = 42001
 	InvalidParams        ServerErrCode = 42002
 	InvalidToken         ServerErrCode = 42003
@@ -45,6 +46,7 @@ const (
 	UnknownAsset         ServerErrCode = 44002
 	UnknownBlock         ServerErrCode = 44003
 	UnknownConfirm       ServerErrCode = 44004
+	BlockPruned          ServerErrCode = 44005
 	InternalError        ServerErrCode = 45002
 )
 
@@ -54,6 +56,7 @@ var ErrMap = map[ServerErrCode]string{
 	SessionExpired:              "Session expired",
 	IllegalDataFormat:           "Illegal Dataformat",
 	PowServiceNotStarted:        "pow service not started",
+	DevNetNotEnabled:            "devnet not enabled",
 	InvalidMethod:               "Invalid method",
 	InvalidParams:               "Invalid Params",
 	InvalidToken:                "Verify token error",
@@ -63,6 +66,7 @@ var ErrMap = map[ServerErrCode]string{
 	UnknownAsset:                "Unknown asset",
 	UnknownBlock:                "Unknown Block",
 	UnknownConfirm:              "Unknown Confirm",
+	BlockPruned:                 "Block data has been pruned",
 	InternalError:               "Internal error",
 	ErrUTXOLocked:               "Error utxo locked",
 	ErrSideChainPowConsensus:    "Error sidechain pow consensus",
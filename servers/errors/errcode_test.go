@@ -32,6 +32,7 @@ func TestErrCode_Message(t *testing.T) {
 		SessionExpired,
 		IllegalDataFormat,
 		PowServiceNotStarted,
+		DevNetNotEnabled,
 		InvalidMethod,
 		InvalidParams,
 		InvalidToken,
@@ -40,6 +41,7 @@ func TestErrCode_Message(t *testing.T) {
 		UnknownTransaction,
 		UnknownAsset,
 		UnknownBlock,
+		BlockPruned,
 		InternalError,
 	}
 	for _, errorCode := range errorCodeArray {
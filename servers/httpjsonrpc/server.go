@@ -19,7 +19,7 @@ import (
 	"time"
 
 	"github.com/elastos/Elastos.ELA/common/config"
-	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/common/metrics"
 	. "github.com/elastos/Elastos.ELA/servers"
 	elaErr "github.com/elastos/Elastos.ELA/servers/errors"
 )
@@ -27,6 +27,10 @@ import (
 //an instance of the multiplexer
 var mainMux map[string]func(Params) map[string]interface{}
 
+// rpcDuration samples the handling time of RPC methods.
+var rpcDuration = metrics.NewHistogram("ela_rpc_duration_seconds",
+	"Latency of JSON-RPC requests by method in seconds.", nil, "method")
+
 const (
 	// JSON-RPC protocol error codes.
 	ParseError     = -32700
@@ -60,13 +64,17 @@ func StartRPCServer() {
 	mainMux["getneighbors"] = GetNeighbors
 	mainMux["getnodestate"] = GetNodeState
 	mainMux["sendrawtransaction"] = SendRawTransaction
+	mainMux["testmempoolaccept"] = TestMempoolAccept
 	mainMux["getarbitratorgroupbyheight"] = GetArbitratorGroupByHeight
 	mainMux["getbestblockhash"] = GetBestBlockHash
 	mainMux["getblockcount"] = GetBlockCount
 	mainMux["getblockbyheight"] = GetBlockByHeight
 	mainMux["getexistwithdrawtransactions"] = GetExistWithdrawTransactions
 	mainMux["getreceivedbyaddress"] = GetReceivedByAddress
+	mainMux["getaddresshistory"] = GetAddressHistory
+	mainMux["getaddresstxcount"] = GetAddressTxCount
 	mainMux["getexistreturndeposittransactions"] = GetExistSideChainReturnDepositTransactions
+	mainMux["getdeploymentinfo"] = GetDeploymentInfo
 
 	// register sidechain interfaces
 	mainMux["getregistertransactionsbyheight"] = Getregistertransactionsbyheight
@@ -76,6 +84,7 @@ func StartRPCServer() {
 	mainMux["getamountbyinputs"] = GetAmountByInputs
 	mainMux["getutxosbyamount"] = GetUTXOsByAmount
 	mainMux["listunspent"] = ListUnspent
+	mainMux["getutxolock"] = GetUTXOLock
 	mainMux["createrawtransaction"] = CreateRawTransaction
 	mainMux["decoderawtransaction"] = DecodeRawTransaction
 	mainMux["signrawtransactionwithkey"] = SignRawTransactionWithKey
@@ -87,6 +96,11 @@ func StartRPCServer() {
 	mainMux["getmininginfo"] = GetMiningInfo
 	mainMux["togglemining"] = ToggleMining
 	mainMux["discretemining"] = DiscreteMining
+	// devnet interfaces
+	mainMux["generateblocks"] = GenerateBlocks
+	mainMux["generatetoheight"] = GenerateToHeight
+	mainMux["fundaddress"] = FundAddress
+	mainMux["getdevnetinfo"] = GetDevNetInfo
 	// cr interfaces
 	mainMux["listcrcandidates"] = ListCRCandidates
 	mainMux["listcurrentcrs"] = ListCurrentCRs
@@ -114,6 +128,9 @@ func StartRPCServer() {
 	mainMux["getsmallcrosstransfertxs"] = GetSmallCrossTransferTxs
 
 	mainMux["estimatesmartfee"] = EstimateSmartFee
+	mainMux["savemempool"] = SaveMempool
+	mainMux["loadmempool"] = LoadMempool
+	mainMux["dumpsnapshot"] = DumpSnapshot
 	mainMux["getdepositcoin"] = GetDepositCoin
 	mainMux["getcrdepositcoin"] = GetCRDepositCoin
 	mainMux["getarbitersinfo"] = GetArbitersInfo
@@ -353,7 +370,9 @@ func getResponse(request Request) Response {
 	}
 	log.Debug("RPC method:", requestMethod)
 
+	start := time.Now()
 	response := method(params)
+	rpcDuration.Observe(time.Since(start).Seconds(), requestMethod)
 	if response["Error"] != elaErr.ServerErrCode(0) {
 		resp = Response{
 			JSONRPC: "2.0",
@@ -384,10 +403,12 @@ func convertParams(method string, params []interface{}) Params {
 		return FromArray(params, "blockhash", "auxpow")
 	case "getblockhash":
 		return FromArray(params, "height")
+	case "getdeploymentinfo":
+		return FromArray(params, "height")
 	case "getblock":
 		return FromArray(params, "blockhash", "verbosity")
 	case "setloglevel":
-		return FromArray(params, "level")
+		return FromArray(params, "level", "subsystem")
 	case "getrawtransaction":
 		return FromArray(params, "txid", "verbose")
 	case "getarbitratorgroupbyheight":
@@ -396,16 +417,30 @@ func convertParams(method string, params []interface{}) Params {
 		return FromArray(params, "mining")
 	case "discretemining":
 		return FromArray(params, "count")
+	case "generateblocks":
+		return FromArray(params, "count")
+	case "generatetoheight":
+		return FromArray(params, "height")
+	case "fundaddress":
+		return FromArray(params, "address", "amount")
 	case "sendrawtransaction":
 		return FromArray(params, "data")
+	case "testmempoolaccept":
+		return FromArray(params, "data")
 	case "listunspent":
 		return FromArray(params, "addresses")
+	case "getutxolock":
+		return FromArray(params, "txid", "vout")
 	case "getreceivedbyaddress":
 		return FromArray(params, "address")
+	case "getaddresshistory":
+		return FromArray(params, "address", "skip", "count", "reverse")
+	case "getaddresstxcount":
+		return FromArray(params, "address")
 	case "getblockbyheight":
 		return FromArray(params, "height")
 	case "estimatesmartfee":
-		return FromArray(params, "confirmations")
+		return FromArray(params, "confirmations", "verbose")
 	case "getrawmempool":
 		return FromArray(params, "state")
 	default:
@@ -0,0 +1,126 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httpmetrics
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/elastos/Elastos.ELA/blockchain"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/common/metrics"
+	crstate "github.com/elastos/Elastos.ELA/cr/state"
+	"github.com/elastos/Elastos.ELA/elanet/pact"
+	"github.com/elastos/Elastos.ELA/servers"
+)
+
+// proposalStatuses is the statuses of CR proposals to be exported, the
+// counts of all statuses are exported even if they are zero.
+var proposalStatuses = []crstate.ProposalStatus{
+	crstate.Registered,
+	crstate.CRAgreed,
+	crstate.VoterAgreed,
+	crstate.Finished,
+	crstate.CRCanceled,
+	crstate.VoterCanceled,
+	crstate.Terminated,
+	crstate.Aborted,
+}
+
+var (
+	bestHeight = metrics.NewGauge("ela_best_height",
+		"Height of the best block.")
+	peers = metrics.NewGauge("ela_peers",
+		"Number of connected peers.")
+	peersByService = metrics.NewGauge("ela_peers_by_service",
+		"Number of connected peers supporting the service.", "service")
+	mempoolTxs = metrics.NewGauge("ela_mempool_transactions",
+		"Number of transactions in the transaction pool.")
+	mempoolBytes = metrics.NewGauge("ela_mempool_bytes",
+		"Total size in bytes of transactions in the transaction pool.")
+	arbiters = metrics.NewGauge("ela_dpos_arbiters",
+		"Number of current DPoS arbiters.")
+	inactiveArbiters = metrics.NewGauge("ela_dpos_inactive_arbiters",
+		"Number of current DPoS arbiters which are inactive or illegal.")
+	onDutyArbiter = metrics.NewGauge("ela_dpos_on_duty_arbiter",
+		"The on duty DPoS arbiter is labeled by its public key with value 1.",
+		"arbiter")
+	crProposals = metrics.NewGauge("ela_cr_proposals",
+		"Number of CR proposals by status.", "status")
+)
+
+// collect samples the node state into gauges.
+func collect() {
+	if servers.Chain != nil {
+		bestHeight.Set(float64(servers.Chain.GetHeight()))
+	}
+
+	if servers.Server != nil {
+		connected := servers.Server.ConnectedPeers()
+		peers.Set(float64(len(connected)))
+		peersByService.Reset()
+		for _, p := range connected {
+			services := p.ToPeer().Services()
+			for i := uint(0); i < 64; i++ {
+				if flag := pact.ServiceFlag(1 << i); services&uint64(flag) != 0 {
+					peersByService.Add(1, flag.String())
+				}
+			}
+		}
+	}
+
+	if servers.TxMemPool != nil {
+		mempoolTxs.Set(float64(servers.TxMemPool.GetTransactionCount()))
+		mempoolBytes.Set(float64(servers.TxMemPool.GetTransactionsSize()))
+	}
+
+	if servers.Arbiters != nil {
+		current := servers.Arbiters.GetArbitrators()
+		var inactive int
+		for _, a := range current {
+			if !a.IsNormal {
+				inactive++
+			}
+		}
+		arbiters.Set(float64(len(current)))
+		inactiveArbiters.Set(float64(inactive))
+
+		onDutyArbiter.Reset()
+		if onDuty := servers.Arbiters.GetOnDutyArbitrator(); len(onDuty) > 0 {
+			onDutyArbiter.Set(1, common.BytesToHexString(onDuty))
+		}
+	}
+
+	if blockchain.DefaultLedger != nil && blockchain.DefaultLedger.Committee != nil {
+		counts := make(map[crstate.ProposalStatus]int)
+		for _, p := range blockchain.DefaultLedger.Committee.GetAllProposals() {
+			counts[p.Status]++
+		}
+		for _, status := range proposalStatuses {
+			crProposals.Set(float64(counts[status]), status.String())
+		}
+	}
+}
+
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := metrics.DefaultRegistry.Write(w); err != nil {
+		log.Warn("write metrics failed,", err)
+	}
+}
+
+func StartServer() {
+	metrics.RegisterCollector(collect)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", metricsHandler)
+	err := http.ListenAndServe(":"+strconv.Itoa(int(config.Parameters.MetricsPort)), mux)
+	if err != nil {
+		log.Error("metrics server stopped,", err)
+	}
+}
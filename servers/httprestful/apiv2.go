@@ -0,0 +1,477 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httprestful
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/servers"
+	. "github.com/elastos/Elastos.ELA/servers/errors"
+)
+
+const (
+	// ApiV2Prefix is the path prefix of the version 2 REST API.
+	ApiV2Prefix = "/api/v2"
+
+	// ApiV2OpenAPI is the path of the OpenAPI document of version 2 API.
+	ApiV2OpenAPI = ApiV2Prefix + "/openapi.json"
+)
+
+// Locations of parameters.
+const (
+	inPath  = "path"
+	inQuery = "query"
+	inBody  = "body"
+)
+
+// Types of parameters, they are converted to the types of JSON-RPC named
+// parameters before passing to the handlers.
+const (
+	typeString  = "string"
+	typeInteger = "integer"
+	typeBoolean = "boolean"
+	typeArray   = "array"
+)
+
+// apiParam describes a parameter of version 2 API.
+type apiParam struct {
+	// Name is the name of the parameter passed to the handler.
+	Name string
+	In   string
+	Type string
+
+	Required    bool
+	Description string
+}
+
+// apiRoute describes an API of version 2, the route is handled by the
+// JSON-RPC handler of the same name in servers package.
+type apiRoute struct {
+	Method string
+	// Path is the path relative to ApiV2Prefix, the path parameters are
+	// prefixed by ":".
+	Path    string
+	RPC     string
+	Handler func(servers.Params) map[string]interface{}
+	Tag     string
+	Summary string
+	Params  []apiParam
+	// Result is a value of the result type used to describe the result in
+	// the OpenAPI document, nil means any type.
+	Result interface{}
+}
+
+func pathParam(name, description string) apiParam {
+	return apiParam{Name: name, In: inPath, Type: typeString, Required: true,
+		Description: description}
+}
+
+func queryParam(name, typ, description string) apiParam {
+	return apiParam{Name: name, In: inQuery, Type: typ,
+		Description: description}
+}
+
+func bodyParam(name, description string) apiParam {
+	return apiParam{Name: name, In: inBody, Type: typeString, Required: true,
+		Description: description}
+}
+
+var (
+	startParam = queryParam("start", typeInteger,
+		"index of the first item to return")
+	limitParam = queryParam("limit", typeInteger,
+		"maximum count of items to return, all items by default")
+	verbosityParam = queryParam("verbosity", typeInteger,
+		"0 for raw data, 1 for details, 2 for details with transactions")
+)
+
+// apiV2Routes is the routes of version 2 API, routes are matched in order so
+// static paths should be put before paths with parameters of same prefix.
+var apiV2Routes = []apiRoute{
+	// node
+	{Method: http.MethodGet, Path: "/node/info", RPC: "getinfo",
+		Handler: servers.GetInfo, Tag: "node",
+		Summary: "Returns the information of the node."},
+	{Method: http.MethodGet, Path: "/node/state", RPC: "getnodestate",
+		Handler: servers.GetNodeState, Tag: "node",
+		Summary: "Returns the state of the node and its peers.",
+		Result:  servers.ServerInfo{}},
+	{Method: http.MethodGet, Path: "/node/connectioncount",
+		RPC: "getconnectioncount", Handler: servers.GetConnectionCount,
+		Tag: "node", Summary: "Returns the count of connected peers.",
+		Result: 0},
+	{Method: http.MethodGet, Path: "/node/neighbors", RPC: "getneighbors",
+		Handler: servers.GetNeighbors, Tag: "node",
+		Summary: "Returns the addresses of connected peers.",
+		Result:  []string{}},
+	{Method: http.MethodGet, Path: "/node/mininginfo", RPC: "getmininginfo",
+		Handler: servers.GetMiningInfo, Tag: "node",
+		Summary: "Returns the mining information."},
+	{Method: http.MethodGet, Path: "/node/deployments",
+		RPC: "getdeploymentinfo", Handler: servers.GetDeploymentInfo,
+		Tag: "node", Summary: "Returns the activation status of deployments.",
+		Params: []apiParam{queryParam("height", typeInteger,
+			"height to evaluate the status at, default is the best height")},
+		Result: servers.DeploymentsInfo{}},
+
+	// blocks
+	{Method: http.MethodGet, Path: "/blocks/height", RPC: "getcurrentheight",
+		Handler: servers.GetBlockHeight, Tag: "blocks",
+		Summary: "Returns the height of the best block.", Result: uint32(0)},
+	{Method: http.MethodGet, Path: "/blocks/count", RPC: "getblockcount",
+		Handler: servers.GetBlockCount, Tag: "blocks",
+		Summary: "Returns the count of blocks in the best chain.",
+		Result:  uint32(0)},
+	{Method: http.MethodGet, Path: "/blocks/besthash", RPC: "getbestblockhash",
+		Handler: servers.GetBestBlockHash, Tag: "blocks",
+		Summary: "Returns the hash of the best block.", Result: ""},
+	{Method: http.MethodGet, Path: "/blocks/height/:height",
+		RPC: "getblockbyheight", Handler: servers.GetBlockByHeight,
+		Tag: "blocks", Summary: "Returns the block of the height.",
+		Params: []apiParam{pathParam("height", "height of the block")},
+		Result: servers.BlockInfo{}},
+	{Method: http.MethodGet, Path: "/blocks/height/:height/hash",
+		RPC: "getblockhash", Handler: servers.GetBlockHash, Tag: "blocks",
+		Summary: "Returns the hash of the block of the height.",
+		Params:  []apiParam{pathParam("height", "height of the block")},
+		Result:  ""},
+	{Method: http.MethodGet, Path: "/blocks/height/:height/transactions",
+		RPC:     "getblocktransactionsbyheight",
+		Handler: servers.GetTransactionsByHeight, Tag: "blocks",
+		Summary: "Returns the transaction hashes of the block of the height.",
+		Params:  []apiParam{pathParam("height", "height of the block")}},
+	{Method: http.MethodGet, Path: "/blocks/height/:height/arbiters",
+		RPC:     "getarbitratorgroupbyheight",
+		Handler: servers.GetArbitratorGroupByHeight, Tag: "blocks",
+		Summary: "Returns the arbiters of the block of the height.",
+		Params:  []apiParam{pathParam("height", "height of the block")},
+		Result:  servers.ArbitratorGroupInfo{}},
+	{Method: http.MethodGet, Path: "/blocks/hash/:blockhash", RPC: "getblock",
+		Handler: servers.GetBlockByHash, Tag: "blocks",
+		Summary: "Returns the block of the hash.",
+		Params: []apiParam{pathParam("blockhash", "hash of the block"),
+			verbosityParam}},
+	{Method: http.MethodGet, Path: "/confirms/height/:height",
+		RPC: "getconfirmbyheight", Handler: servers.GetConfirmByHeight,
+		Tag: "blocks", Summary: "Returns the confirm of the block of the height.",
+		Params: []apiParam{pathParam("height", "height of the block"),
+			queryParam("verbosity", typeInteger, "0 for raw data, 1 for details")}},
+	{Method: http.MethodGet, Path: "/confirms/hash/:blockhash",
+		RPC: "getconfirmbyhash", Handler: servers.GetConfirmByHash,
+		Tag: "blocks", Summary: "Returns the confirm of the block of the hash.",
+		Params: []apiParam{pathParam("blockhash", "hash of the block"),
+			queryParam("verbosity", typeInteger, "0 for raw data, 1 for details")}},
+
+	// transactions
+	{Method: http.MethodPost, Path: "/transactions", RPC: "sendrawtransaction",
+		Handler: servers.SendRawTransaction, Tag: "transactions",
+		Summary: "Broadcasts the raw transaction and returns its hash.",
+		Params:  []apiParam{bodyParam("data", "raw transaction in hex")},
+		Result:  ""},
+	{Method: http.MethodPost, Path: "/transactions/decode",
+		RPC: "decoderawtransaction", Handler: servers.DecodeRawTransaction,
+		Tag: "transactions", Summary: "Decodes the raw transaction.",
+		Params: []apiParam{bodyParam("data", "raw transaction in hex")},
+		Result: servers.TransactionInfo{}},
+	{Method: http.MethodPost, Path: "/transactions/test",
+		RPC: "testmempoolaccept", Handler: servers.TestMempoolAccept,
+		Tag: "transactions", Summary: "Runs every check of the raw " +
+			"transaction without adding it to the mempool.",
+		Params: []apiParam{bodyParam("data", "raw transaction in hex")},
+		Result: servers.TestMempoolAcceptInfo{}},
+	{Method: http.MethodGet, Path: "/transactions/:txid",
+		RPC: "getrawtransaction", Handler: servers.GetRawTransaction,
+		Tag: "transactions", Summary: "Returns the transaction of the hash.",
+		Params: []apiParam{pathParam("txid", "hash of the transaction"),
+			queryParam("verbose", typeBoolean,
+				"return the details instead of raw data")}},
+	{Method: http.MethodGet, Path: "/mempool", RPC: "getrawmempool",
+		Handler: servers.GetTransactionPool, Tag: "transactions",
+		Summary: "Returns the transactions in the transaction pool.",
+		Params: []apiParam{queryParam("state", typeString,
+			`"all" to return details of transactions instead of hashes`)}},
+	{Method: http.MethodGet, Path: "/fees/estimate", RPC: "estimatesmartfee",
+		Handler: servers.EstimateSmartFee, Tag: "transactions",
+		Summary: "Estimates the fee rate to be confirmed within the blocks.",
+		Params: []apiParam{queryParam("confirmations", typeInteger,
+			"count of blocks to be confirmed within"),
+			queryParam("verbose", typeBoolean,
+				"return the details instead of the fee rate")}},
+
+	// addresses
+	{Method: http.MethodGet, Path: "/addresses/:address/balance",
+		RPC: "getreceivedbyaddress", Handler: servers.GetReceivedByAddress,
+		Tag: "addresses", Summary: "Returns the balance of the address.",
+		Params: []apiParam{pathParam("address", "address")}, Result: ""},
+	{Method: http.MethodGet, Path: "/addresses/:address/history",
+		RPC: "getaddresshistory", Handler: servers.GetAddressHistory,
+		Tag: "addresses", Summary: "Returns the transactions of the address.",
+		Params: []apiParam{pathParam("address", "address"),
+			queryParam("skip", typeInteger, "count of transactions to skip"),
+			queryParam("count", typeInteger, "maximum count of transactions"),
+			queryParam("reverse", typeBoolean, "return the newest first")},
+		Result: servers.AddressHistoryResult{}},
+	{Method: http.MethodGet, Path: "/addresses/:address/txcount",
+		RPC: "getaddresstxcount", Handler: servers.GetAddressTxCount,
+		Tag:     "addresses",
+		Summary: "Returns the count of transactions of the address.",
+		Params:  []apiParam{pathParam("address", "address")}, Result: 0},
+	{Method: http.MethodGet, Path: "/addresses/:address/votestatus",
+		RPC: "votestatus", Handler: servers.VoteStatus, Tag: "addresses",
+		Summary: "Returns the vote status of the address.",
+		Params:  []apiParam{pathParam("address", "address")}},
+	{Method: http.MethodGet, Path: "/addresses/:address/dposv2reward",
+		RPC: "dposv2rewardinfo", Handler: servers.DposV2RewardInfo,
+		Tag: "addresses", Summary: "Returns the DPoS 2.0 reward of the address.",
+		Params: []apiParam{pathParam("address", "address")},
+		Result: servers.RPCDposV2RewardInfo{}},
+	{Method: http.MethodGet, Path: "/utxos", RPC: "listunspent",
+		Handler: servers.ListUnspent, Tag: "addresses",
+		Summary: "Returns the unspent outputs of the addresses.",
+		Params: []apiParam{{Name: "addresses", In: inQuery, Type: typeArray,
+			Required: true, Description: "comma separated addresses"},
+			queryParam("utxotype", typeString,
+				`"mixed", "vote" or "normal", "mixed" by default`)},
+		Result: []servers.UTXOInfo{}},
+	{Method: http.MethodGet, Path: "/utxos/:txid/:vout/lock",
+		RPC: "getutxolock", Handler: servers.GetUTXOLock, Tag: "addresses",
+		Summary: "Returns how to spend the unspent output and when it becomes spendable.",
+		Params: []apiParam{pathParam("txid", "hash of the transaction"),
+			pathParam("vout", "index of the output")},
+		Result: servers.UTXOLockInfo{}},
+
+	// dpos
+	{Method: http.MethodGet, Path: "/producers", RPC: "listproducers",
+		Handler: servers.ListProducers, Tag: "dpos",
+		Summary: "Returns the producers.",
+		Params: []apiParam{startParam, limitParam,
+			queryParam("state", typeString, "state of producers, all by default")},
+		Result: servers.RPCProducersInfo{}},
+	{Method: http.MethodGet, Path: "/producers/:publickey",
+		RPC: "getproducerinfo", Handler: servers.GetProducerInfo, Tag: "dpos",
+		Summary: "Returns the producer of the owner or node public key.",
+		Params: []apiParam{pathParam("publickey",
+			"owner or node public key of the producer")},
+		Result: servers.RPCProducerInfo{}},
+	{Method: http.MethodGet, Path: "/producers/:publickey/status",
+		RPC: "producerstatus", Handler: servers.ProducerStatus, Tag: "dpos",
+		Summary: "Returns the state of the producer.",
+		Params: []apiParam{pathParam("publickey",
+			"owner public key of the producer")}, Result: ""},
+	{Method: http.MethodGet, Path: "/producers/:ownerpublickey/depositcoin",
+		RPC: "getdepositcoin", Handler: servers.GetDepositCoin, Tag: "dpos",
+		Summary: "Returns the deposit coin of the producer.",
+		Params: []apiParam{pathParam("ownerpublickey",
+			"owner public key of the producer")}},
+	{Method: http.MethodGet, Path: "/arbiters", RPC: "getarbitersinfo",
+		Handler: servers.GetArbitersInfo, Tag: "dpos",
+		Summary: "Returns the current and next arbiters."},
+	{Method: http.MethodGet, Path: "/dposv2/info", RPC: "getdposv2info",
+		Handler: servers.GetDPosV2Info, Tag: "dpos",
+		Summary: "Returns the information of DPoS 2.0.",
+		Result:  servers.RPCDPosV2Info{}},
+	{Method: http.MethodGet, Path: "/dposv2/votes",
+		RPC:     "getalldetaileddposv2votes",
+		Handler: servers.GetAllDetailedDPoSV2Votes, Tag: "dpos",
+		Summary: "Returns the DPoS 2.0 votes.",
+		Params: []apiParam{startParam, limitParam,
+			queryParam("stakeaddress", typeString,
+				"return votes of the stake address only")}},
+	{Method: http.MethodGet, Path: "/dposv2/voterights", RPC: "getvoterights",
+		Handler: servers.GetVoteRights, Tag: "dpos",
+		Summary: "Returns the vote rights of the stake addresses.",
+		Params: []apiParam{{Name: "stakeaddresses", In: inQuery,
+			Type: typeArray, Required: true,
+			Description: "comma separated stake addresses"}}},
+
+	// cr
+	{Method: http.MethodGet, Path: "/cr/candidates", RPC: "listcrcandidates",
+		Handler: servers.ListCRCandidates, Tag: "cr",
+		Summary: "Returns the CR candidates.",
+		Params: []apiParam{startParam, limitParam,
+			queryParam("state", typeString, "state of candidates, all by default")},
+		Result: servers.RPCCRCandidatesInfo{}},
+	{Method: http.MethodGet, Path: "/cr/members", RPC: "listcurrentcrs",
+		Handler: servers.ListCurrentCRs, Tag: "cr",
+		Summary: "Returns the current CR members.",
+		Result:  servers.RPCCRMembersInfo{}},
+	{Method: http.MethodGet, Path: "/cr/nextmembers", RPC: "listnextcrs",
+		Handler: servers.ListNextCRs, Tag: "cr",
+		Summary: "Returns the CR members of next term.",
+		Result:  servers.RPCCRMembersInfo{}},
+	{Method: http.MethodGet, Path: "/cr/stage", RPC: "getcrrelatedstage",
+		Handler: servers.GetCRRelatedStage, Tag: "cr",
+		Summary: "Returns the current stage of CR.",
+		Result:  servers.RPCCRRelatedStage{}},
+	{Method: http.MethodGet, Path: "/cr/secretarygeneral",
+		RPC: "getsecretarygeneral", Handler: servers.GetSecretaryGeneral,
+		Tag: "cr", Summary: "Returns the secretary general of CR.",
+		Result: servers.RPCSecretaryGeneralInfo{}},
+	{Method: http.MethodGet, Path: "/cr/committeecanuseamount",
+		RPC:     "getcommitteecanuseamount",
+		Handler: servers.GetCommitteeCanUseAmount, Tag: "cr",
+		Summary: "Returns the amount the CR committee can use.",
+		Result:  servers.RPCCommitteeCanUseAmount{}},
+	{Method: http.MethodGet, Path: "/cr/depositcoin", RPC: "getcrdepositcoin",
+		Handler: servers.GetCRDepositCoin, Tag: "cr",
+		Summary: "Returns the deposit coin of the CR candidate or member.",
+		Params: []apiParam{
+			queryParam("id", typeString, "CID or DID of the CR"),
+			queryParam("publickey", typeString, "public key of the CR")}},
+	{Method: http.MethodGet, Path: "/cr/proposals",
+		RPC: "listcrproposalbasestate", Handler: servers.ListCRProposalBaseState,
+		Tag: "cr", Summary: "Returns the CR proposals.",
+		Params: []apiParam{startParam, limitParam,
+			queryParam("state", typeString, "state of proposals, all by default")},
+		Result: servers.RPCCRProposalBaseStateInfo{}},
+	{Method: http.MethodGet, Path: "/cr/proposals/:proposalhash",
+		RPC: "getcrproposalstate", Handler: servers.GetCRProposalState,
+		Tag: "cr", Summary: "Returns the state of the CR proposal.",
+		Params: []apiParam{pathParam("proposalhash", "hash of the proposal")},
+		Result: servers.RPCCRProposalStateInfo{}},
+	{Method: http.MethodGet, Path: "/cr/drafts/:drafthash",
+		RPC: "getproposaldraftdata", Handler: servers.GetProposalDraftData,
+		Tag: "cr", Summary: "Returns the draft data of the CR proposal.",
+		Params: []apiParam{pathParam("drafthash", "hash of the draft")},
+		Result: ""},
+
+	// nft
+	{Method: http.MethodGet, Path: "/nfts/destroyable",
+		RPC: "getcandestroynftids", Handler: servers.GetCanDestroynftIDs,
+		Tag: "nft", Summary: "Returns the NFT IDs which can be destroyed.",
+		Params: []apiParam{{Name: "ids", In: inQuery, Type: typeArray,
+			Required: true, Description: "comma separated NFT IDs"},
+			{Name: "genesisblockhash", In: inQuery, Type: typeString,
+				Required:    true,
+				Description: "genesis block hash of the side chain"}},
+		Result: []string{}},
+	{Method: http.MethodGet, Path: "/nfts/:id", RPC: "getnftinfo",
+		Handler: servers.GetNFTInfo, Tag: "nft",
+		Summary: "Returns the information of the NFT.",
+		Params:  []apiParam{pathParam("id", "ID of the NFT")}},
+}
+
+func (rt *restServer) initV2Handler() {
+	for i := range apiV2Routes {
+		route := &apiV2Routes[i]
+		rt.router.add(route.Method, ApiV2Prefix+route.Path,
+			func(w http.ResponseWriter, r *http.Request) {
+				req, err := apiV2Params(route, r)
+				if err != nil {
+					rt.response(w, servers.ResponsePack(InvalidParams,
+						err.Error()))
+					return
+				}
+				rt.response(w, route.Handler(req))
+			})
+		if route.Method == http.MethodPost {
+			rt.router.Options(ApiV2Prefix+route.Path,
+				func(w http.ResponseWriter, r *http.Request) {
+					rt.write(w, []byte{})
+				})
+		}
+	}
+	rt.router.Get(ApiV2OpenAPI, func(w http.ResponseWriter, r *http.Request) {
+		data, err := json.Marshal(openAPIDocument(apiV2Routes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		rt.write(w, data)
+	})
+}
+
+// apiV2Params collects the parameters of the request in the types of JSON-RPC
+// named parameters.
+func apiV2Params(route *apiRoute, r *http.Request) (servers.Params, error) {
+	req := make(servers.Params)
+	for _, p := range route.Params {
+		switch p.In {
+		case inPath:
+			req[p.Name] = getParam(r, p.Name)
+		case inQuery:
+			value := r.URL.Query().Get(p.Name)
+			if value == "" {
+				if p.Required {
+					return nil, errMissingParam(p.Name)
+				}
+				continue
+			}
+			converted, err := convertParam(p, value)
+			if err != nil {
+				return nil, err
+			}
+			req[p.Name] = converted
+		}
+	}
+	if route.Method != http.MethodPost {
+		return req, nil
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var bodyParams map[string]interface{}
+	if err := json.Unmarshal(body, &bodyParams); err != nil {
+		return nil, errInvalidParam("body", "a JSON object")
+	}
+	for _, p := range route.Params {
+		if p.In != inBody {
+			continue
+		}
+		value, ok := bodyParams[p.Name]
+		if !ok {
+			if p.Required {
+				return nil, errMissingParam(p.Name)
+			}
+			continue
+		}
+		req[p.Name] = value
+	}
+	return req, nil
+}
+
+// convertParam converts the string value of query parameter to the type of
+// JSON-RPC parameter.
+func convertParam(p apiParam, value string) (interface{}, error) {
+	switch p.Type {
+	case typeInteger:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, errInvalidParam(p.Name, "an integer")
+		}
+		return float64(v), nil
+	case typeBoolean:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errInvalidParam(p.Name, "a boolean")
+		}
+		return v, nil
+	case typeArray:
+		values := strings.Split(value, ",")
+		array := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			array = append(array, v)
+		}
+		return array, nil
+	}
+	return value, nil
+}
+
+func errMissingParam(name string) error {
+	return errors.New("missing parameter " + name)
+}
+
+func errInvalidParam(name, expected string) error {
+	return errors.New("parameter " + name + " should be " + expected)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httprestful
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/servers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApiV2_Routes(t *testing.T) {
+	var got servers.Params
+	var gotRPC string
+	router := &Router{}
+	for i := range apiV2Routes {
+		route := &apiV2Routes[i]
+		router.add(route.Method, ApiV2Prefix+route.Path,
+			func(w http.ResponseWriter, r *http.Request) {
+				params, err := apiV2Params(route, r)
+				assert.NoError(t, err)
+				got, gotRPC = params, route.RPC
+			})
+	}
+
+	serve := func(method, url, body string) {
+		got, gotRPC = nil, ""
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	serve(http.MethodGet, "/api/v2/blocks/height/100/hash", "")
+	assert.Equal(t, "getblockhash", gotRPC)
+	assert.Equal(t, servers.Params{"height": "100"}, got)
+
+	serve(http.MethodGet, "/api/v2/nfts/destroyable?ids=a,b&genesisblockhash=c", "")
+	assert.Equal(t, "getcandestroynftids", gotRPC)
+	assert.Equal(t, servers.Params{"ids": []interface{}{"a", "b"},
+		"genesisblockhash": "c"}, got)
+
+	serve(http.MethodGet, "/api/v2/nfts/abc", "")
+	assert.Equal(t, "getnftinfo", gotRPC)
+	assert.Equal(t, servers.Params{"id": "abc"}, got)
+
+	serve(http.MethodGet, "/api/v2/producers?start=1&limit=10&state=active", "")
+	assert.Equal(t, "listproducers", gotRPC)
+	assert.Equal(t, servers.Params{"start": float64(1), "limit": float64(10),
+		"state": "active"}, got)
+
+	serve(http.MethodGet, "/api/v2/transactions/abcd?verbose=true", "")
+	assert.Equal(t, "getrawtransaction", gotRPC)
+	assert.Equal(t, servers.Params{"txid": "abcd", "verbose": true}, got)
+
+	serve(http.MethodPost, "/api/v2/transactions", `{"data":"0102"}`)
+	assert.Equal(t, "sendrawtransaction", gotRPC)
+	assert.Equal(t, servers.Params{"data": "0102"}, got)
+
+	serve(http.MethodGet, "/api/v2/transactions", "")
+	assert.Equal(t, "", gotRPC)
+}
+
+func TestApiV2_Params(t *testing.T) {
+	route := &apiRoute{Method: http.MethodGet, Path: "/producers",
+		Params: []apiParam{startParam, {Name: "ids", In: inQuery,
+			Type: typeArray, Required: true}}}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/producers?ids=a", nil)
+	params, err := apiV2Params(route, req)
+	assert.NoError(t, err)
+	assert.Equal(t, servers.Params{"ids": []interface{}{"a"}}, params)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/producers", nil)
+	_, err = apiV2Params(route, req)
+	assert.EqualError(t, err, "missing parameter ids")
+
+	req = httptest.NewRequest(http.MethodGet,
+		"/api/v2/producers?ids=a&start=x", nil)
+	_, err = apiV2Params(route, req)
+	assert.EqualError(t, err, "parameter start should be an integer")
+
+	route = &apiRoute{Method: http.MethodPost, Path: "/transactions",
+		Params: []apiParam{bodyParam("data", "")}}
+	req = httptest.NewRequest(http.MethodPost, "/api/v2/transactions",
+		bytes.NewBufferString(`{}`))
+	_, err = apiV2Params(route, req)
+	assert.EqualError(t, err, "missing parameter data")
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	doc := openAPIDocument(apiV2Routes)
+	data, err := json.Marshal(doc)
+	assert.NoError(t, err)
+
+	var decoded struct {
+		Paths map[string]map[string]struct {
+			OperationID string `json:"operationId"`
+			Parameters  []struct {
+				Name string `json:"name"`
+				In   string `json:"in"`
+			} `json:"parameters"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "getproducerinfo",
+		decoded.Paths["/producers/{publickey}"]["get"].OperationID)
+
+	// All path parameters are declared.
+	pathParams := regexp.MustCompile(`{(\w+)}`)
+	for path, item := range decoded.Paths {
+		for _, op := range item {
+			declared := make(map[string]bool)
+			for _, p := range op.Parameters {
+				if p.In == inPath {
+					declared[p.Name] = true
+				}
+			}
+			for _, m := range pathParams.FindAllStringSubmatch(path, -1) {
+				assert.True(t, declared[m[1]], "%s %s", path, m[1])
+			}
+		}
+	}
+
+	// All references are resolved.
+	refs := regexp.MustCompile(`"#/components/schemas/([^"]+)"`)
+	for _, m := range refs.FindAllStringSubmatch(string(data), -1) {
+		assert.Contains(t, decoded.Components.Schemas, m[1])
+	}
+	assert.Contains(t, decoded.Components.Schemas, "RPCProducersInfo")
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httprestful
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/elastos/Elastos.ELA/servers"
+)
+
+// OpenAPIVersion is the version of OpenAPI specification of the document.
+const OpenAPIVersion = "3.0.3"
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// openAPIDocument returns the OpenAPI document describing the routes.
+func openAPIDocument(routes []apiRoute) map[string]interface{} {
+	schemas := newSchemaGenerator()
+	paths := make(map[string]interface{})
+	for _, route := range routes {
+		path := openAPIPath(route.Path)
+		item, ok := paths[path].(map[string]interface{})
+		if !ok {
+			item = make(map[string]interface{})
+			paths[path] = item
+		}
+		item[strings.ToLower(route.Method)] = openAPIOperation(route, schemas)
+	}
+
+	version := servers.Compile
+	if version == "" {
+		version = "unknown"
+	}
+	return map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info": map[string]interface{}{
+			"title": "Elastos.ELA REST API",
+			"description": "The REST API mirroring the JSON-RPC handlers of " +
+				"the node, the operationId is the name of the JSON-RPC method.",
+			"version": version,
+		},
+		"servers": []interface{}{
+			map[string]interface{}{"url": ApiV2Prefix},
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas.schemas,
+		},
+	}
+}
+
+// openAPIPath converts the ":param" of path to "{param}".
+func openAPIPath(path string) string {
+	return paramsRegexp.ReplaceAllString(path, "{$1}")
+}
+
+func openAPIOperation(route apiRoute,
+	schemas *schemaGenerator) map[string]interface{} {
+	var parameters []interface{}
+	bodyProperties := make(map[string]interface{})
+	var bodyRequired []string
+	for _, p := range route.Params {
+		if p.In == inBody {
+			bodyProperties[p.Name] = map[string]interface{}{
+				"type":        p.Type,
+				"description": p.Description,
+			}
+			if p.Required {
+				bodyRequired = append(bodyRequired, p.Name)
+			}
+			continue
+		}
+		schema := map[string]interface{}{"type": p.Type}
+		param := map[string]interface{}{
+			"name":        p.Name,
+			"in":          p.In,
+			"required":    p.Required,
+			"description": p.Description,
+			"schema":      schema,
+		}
+		if p.Type == typeArray {
+			schema["items"] = map[string]interface{}{"type": typeString}
+			param["style"] = "form"
+			param["explode"] = false
+		}
+		parameters = append(parameters, param)
+	}
+
+	operation := map[string]interface{}{
+		"operationId": route.RPC,
+		"summary":     route.Summary,
+		"tags":        []string{route.Tag},
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "The result is valid if Error is 0, otherwise " +
+					"Result is the error message.",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": responseSchema(schemas.schema(route.Result)),
+					},
+				},
+			},
+		},
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+	if len(bodyProperties) > 0 {
+		body := map[string]interface{}{
+			"type":       "object",
+			"properties": bodyProperties,
+		}
+		if len(bodyRequired) > 0 {
+			body["required"] = bodyRequired
+		}
+		operation["requestBody"] = map[string]interface{}{
+			"required": true,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{"schema": body},
+			},
+		}
+	}
+	return operation
+}
+
+// responseSchema returns the schema of the response wrapping the result.
+func responseSchema(result map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"Error", "Desc", "Result"},
+		"properties": map[string]interface{}{
+			"Error":  map[string]interface{}{"type": "integer"},
+			"Desc":   map[string]interface{}{"type": "string"},
+			"Result": result,
+		},
+	}
+}
+
+// schemaGenerator generates the JSON schemas of Go types, named struct types
+// are put into the components of the document and referenced.
+type schemaGenerator struct {
+	schemas map[string]interface{}
+	types   map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: make(map[string]interface{}),
+		types:   make(map[reflect.Type]string),
+	}
+}
+
+// schema returns the schema of the type of v, nil means any type.
+func (g *schemaGenerator) schema(v interface{}) map[string]interface{} {
+	if v == nil {
+		return map[string]interface{}{}
+	}
+	return g.typeSchema(reflect.TypeOf(v))
+}
+
+func (g *schemaGenerator) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Implements(jsonMarshalerType) ||
+		reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return map[string]interface{}{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string", "format": "byte"}
+		}
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.typeSchema(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": g.typeSchema(t.Elem()),
+		}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name, ok := g.types[t]
+		if !ok {
+			name = g.schemaName(t)
+			g.types[t] = name
+			// Put a placeholder first to handle recursive types.
+			g.schemas[name] = map[string]interface{}{}
+			g.schemas[name] = g.structSchema(t)
+		}
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}
+	}
+	return map[string]interface{}{}
+}
+
+// schemaName returns an unique name of the named type in components.
+func (g *schemaGenerator) schemaName(t reflect.Type) string {
+	name := t.Name()
+	if _, ok := g.schemas[name]; !ok {
+		return name
+	}
+	pkg := t.PkgPath()
+	if i := strings.LastIndex(pkg, "/"); i >= 0 {
+		pkg = pkg[i+1:]
+	}
+	return pkg + "." + name
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]interface{} {
+	properties := make(map[string]interface{})
+	g.addProperties(t, properties)
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+}
+
+// addProperties adds the properties of struct fields in the way of
+// encoding/json, the fields of embedded structs are promoted.
+func (g *schemaGenerator) addProperties(t reflect.Type,
+	properties map[string]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			g.addProperties(fieldType, properties)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.typeSchema(field.Type)
+	}
+}
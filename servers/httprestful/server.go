@@ -13,11 +13,9 @@ import (
 	"net"
 	"net/http"
 	"strconv"
-	"strings"
 	"sync"
 
 	"github.com/elastos/Elastos.ELA/common/config"
-	"github.com/elastos/Elastos.ELA/common/log"
 	"github.com/elastos/Elastos.ELA/servers"
 	. "github.com/elastos/Elastos.ELA/servers/errors"
 )
@@ -38,6 +36,8 @@ const (
 	ApiGetBalanceByAsset   = "/api/v1/asset/balance/:addr/:assetid"
 	ApiGetUTXOByAsset      = "/api/v1/asset/utxo/:addr/:assetid"
 	ApiGetUTXOByAddr       = "/api/v1/asset/utxos/:addr"
+	ApiGetAddressHistory   = "/api/v1/address/history/:addr"
+	ApiGetAddressTxCount   = "/api/v1/address/txcount/:addr"
 	ApiSendRawTransaction  = "/api/v1/transaction"
 	ApiGetTransactionPool  = "/api/v1/transactionpool"
 	ApiRestart             = "/api/v1/restart"
@@ -73,6 +73,7 @@ func InitRestServer() ApiServer {
 	rt.initializeMethod()
 	rt.initGetHandler()
 	rt.initPostHandler()
+	rt.initV2Handler()
 	return rt
 }
 
@@ -121,6 +122,8 @@ func (rt *restServer) initializeMethod() {
 		ApiGetUTXOByAsset:      {name: "getutxobyasset", handler: servers.GetUnspendOutput},
 		ApiGetBalanceByAddr:    {name: "getbalancebyaddr", handler: servers.GetBalanceByAddr},
 		ApiGetBalanceByAsset:   {name: "getbalancebyasset", handler: servers.GetBalanceByAsset},
+		ApiGetAddressHistory:   {name: "getaddresshistory", handler: servers.GetAddressHistory},
+		ApiGetAddressTxCount:   {name: "getaddresstxcount", handler: servers.GetAddressTxCount},
 		ApiRestart:             {name: "restart", handler: rt.Restart},
 	}
 
@@ -131,36 +134,6 @@ func (rt *restServer) initializeMethod() {
 	rt.getMap = getMethodMap
 }
 
-func (rt *restServer) getPath(url string) string {
-
-	if strings.Contains(url, strings.TrimRight(ApiGetBlockTxsByHeight, ":height")) {
-		return ApiGetBlockTxsByHeight
-	} else if strings.Contains(url, strings.TrimRight(ApiGetBlockByHeight, ":height")) {
-		return ApiGetBlockByHeight
-	} else if strings.Contains(url, strings.TrimRight(ApiGetBlockByHash, ":hash")) {
-		return ApiGetBlockByHash
-	} else if strings.Contains(url, strings.TrimRight(ApiGetConfirmByHeight, ":height")) {
-		return ApiGetConfirmByHeight
-	} else if strings.Contains(url, strings.TrimRight(ApiGetConfirmByHash, ":hash")) {
-		return ApiGetConfirmByHash
-	} else if strings.Contains(url, strings.TrimRight(ApiGetBlockHash, ":height")) {
-		return ApiGetBlockHash
-	} else if strings.Contains(url, strings.TrimRight(ApiGetTransaction, ":hash")) {
-		return ApiGetTransaction
-	} else if strings.Contains(url, strings.TrimRight(ApiGetBalanceByAddr, ":addr")) {
-		return ApiGetBalanceByAddr
-	} else if strings.Contains(url, strings.TrimRight(ApiGetBalanceByAsset, ":addr/:assetid")) {
-		return ApiGetBalanceByAsset
-	} else if strings.Contains(url, strings.TrimRight(ApiGetUTXOByAddr, ":addr")) {
-		return ApiGetUTXOByAddr
-	} else if strings.Contains(url, strings.TrimRight(ApiGetUTXOByAsset, ":addr/:assetid")) {
-		return ApiGetUTXOByAsset
-	} else if strings.Contains(url, strings.TrimRight(ApiGetAsset, ":hash")) {
-		return ApiGetAsset
-	}
-	return url
-}
-
 func (rt *restServer) getParams(r *http.Request, url string, req map[string]interface{}) map[string]interface{} {
 	switch url {
 	case ApiGetConnectionCount:
@@ -209,6 +182,21 @@ func (rt *restServer) getParams(r *http.Request, url string, req map[string]inte
 		req["addr"] = getParam(r, "addr")
 		req["assetid"] = getParam(r, "assetid")
 
+	case ApiGetAddressHistory:
+		req["address"] = getParam(r, "addr")
+		query := r.URL.Query()
+		for _, key := range []string{"skip", "count"} {
+			if value := query.Get(key); value != "" {
+				req[key] = value
+			}
+		}
+		if query.Get("reverse") == "true" {
+			req["reverse"] = true
+		}
+
+	case ApiGetAddressTxCount:
+		req["address"] = getParam(r, "addr")
+
 	case ApiRestart:
 
 	case ApiSendRawTransaction:
@@ -220,13 +208,12 @@ func (rt *restServer) getParams(r *http.Request, url string, req map[string]inte
 func (rt *restServer) initGetHandler() {
 
 	for k, _ := range rt.getMap {
-		rt.router.Get(k, func(w http.ResponseWriter, r *http.Request) {
+		url := k
+		rt.router.Get(url, func(w http.ResponseWriter, r *http.Request) {
 
 			var req = make(map[string]interface{})
 			var resp map[string]interface{}
 
-			url := rt.getPath(r.URL.Path)
-
 			if h, ok := rt.getMap[url]; ok {
 				req = rt.getParams(r, url, req)
 				resp = h.handler(req)
@@ -240,7 +227,8 @@ func (rt *restServer) initGetHandler() {
 
 func (rt *restServer) initPostHandler() {
 	for k, _ := range rt.postMap {
-		rt.router.Post(k, func(w http.ResponseWriter, r *http.Request) {
+		url := k
+		rt.router.Post(url, func(w http.ResponseWriter, r *http.Request) {
 
 			body, _ := ioutil.ReadAll(r.Body)
 			defer r.Body.Close()
@@ -248,7 +236,6 @@ func (rt *restServer) initPostHandler() {
 			var req = make(map[string]interface{})
 			var resp map[string]interface{}
 
-			url := rt.getPath(r.URL.Path)
 			if h, ok := rt.postMap[url]; ok {
 				if err := json.Unmarshal(body, &req); err == nil {
 					req = rt.getParams(r, url, req)
@@ -0,0 +1,359 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httpwebsocket
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/servers"
+)
+
+// txTypeNames maps the lower case names of transaction types to types.
+var txTypeNames = func() map[string]common2.TxType {
+	names := make(map[string]common2.TxType)
+	for i := 0; i <= 0xff; i++ {
+		txType := common2.TxType(i)
+		if name := txType.Name(); name != "Unknown" {
+			names[strings.ToLower(name)] = txType
+		}
+	}
+	return names
+}()
+
+// subscription is the typed subscriptions of a session.  A session without
+// any subscription receives all notifications, otherwise it receives only
+// the transactions matching any of the subscriptions, and blocks if it has
+// subscribed them.
+type subscription struct {
+	mtx           sync.RWMutex
+	blocks        bool
+	programHashes map[common.Uint168]struct{}
+	txTypes       map[common2.TxType]struct{}
+	payloadTypes  map[string]struct{}
+	producers     map[string]struct{}
+}
+
+// SubscriptionInfo is the subscriptions of a session returned to client.
+type SubscriptionInfo struct {
+	Blocks       bool     `json:"blocks"`
+	Addresses    []string `json:"addresses"`
+	TxTypes      []string `json:"txtypes"`
+	PayloadTypes []string `json:"payloadtypes"`
+	Producers    []string `json:"producers"`
+}
+
+func newSubscription() *subscription {
+	return &subscription{
+		programHashes: make(map[common.Uint168]struct{}),
+		txTypes:       make(map[common2.TxType]struct{}),
+		payloadTypes:  make(map[string]struct{}),
+		producers:     make(map[string]struct{}),
+	}
+}
+
+// IsEmpty returns whether the session has not subscribed anything.
+func (s *subscription) IsEmpty() bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return !s.blocks && len(s.programHashes) == 0 && len(s.txTypes) == 0 &&
+		len(s.payloadTypes) == 0 && len(s.producers) == 0
+}
+
+// Blocks returns whether the session has subscribed connected blocks.
+func (s *subscription) Blocks() bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.blocks
+}
+
+// Update adds the subscriptions in params if add is true, or removes them.
+// The params are validated before any change is made.
+func (s *subscription) Update(params servers.Params, add bool) error {
+	addresses, err := arrayParam(params, "addresses")
+	if err != nil {
+		return err
+	}
+	hashes, err := arrayParam(params, "programhashes")
+	if err != nil {
+		return err
+	}
+	types, err := arrayParam(params, "txtypes")
+	if err != nil {
+		return err
+	}
+	payloadTypes, err := arrayParam(params, "payloadtypes")
+	if err != nil {
+		return err
+	}
+	producers, err := arrayParam(params, "producers")
+	if err != nil {
+		return err
+	}
+
+	var programHashes []common.Uint168
+	for _, address := range addresses {
+		programHash, err := common.Uint168FromAddress(address)
+		if err != nil {
+			return errors.New("invalid address " + address)
+		}
+		programHashes = append(programHashes, *programHash)
+	}
+	for _, hash := range hashes {
+		bytes, err := common.HexStringToBytes(hash)
+		if err != nil {
+			return errors.New("invalid program hash " + hash)
+		}
+		programHash, err := common.Uint168FromBytes(bytes)
+		if err != nil {
+			return errors.New("invalid program hash " + hash)
+		}
+		programHashes = append(programHashes, *programHash)
+	}
+
+	var txTypes []common2.TxType
+	for _, t := range types {
+		txType, err := parseTxType(t)
+		if err != nil {
+			return err
+		}
+		txTypes = append(txTypes, txType)
+	}
+
+	for _, producer := range producers {
+		if _, err := common.HexStringToBytes(producer); err != nil {
+			return errors.New("invalid producer public key " + producer)
+		}
+	}
+	blocks, hasBlocks := params.Bool("blocks")
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if hasBlocks {
+		s.blocks = add && blocks
+	}
+	for _, h := range programHashes {
+		if add {
+			s.programHashes[h] = struct{}{}
+		} else {
+			delete(s.programHashes, h)
+		}
+	}
+	for _, t := range txTypes {
+		if add {
+			s.txTypes[t] = struct{}{}
+		} else {
+			delete(s.txTypes, t)
+		}
+	}
+	for _, t := range payloadTypes {
+		if add {
+			s.payloadTypes[strings.ToLower(t)] = struct{}{}
+		} else {
+			delete(s.payloadTypes, strings.ToLower(t))
+		}
+	}
+	for _, p := range producers {
+		if add {
+			s.producers[strings.ToLower(p)] = struct{}{}
+		} else {
+			delete(s.producers, strings.ToLower(p))
+		}
+	}
+	return nil
+}
+
+// subscriptionParams is the parameters accepted by subscribe and unsubscribe.
+var subscriptionParams = []string{"blocks", "addresses", "programhashes",
+	"txtypes", "payloadtypes", "producers"}
+
+// hasSubscriptionParams returns whether any subscription is given in params.
+func hasSubscriptionParams(params servers.Params) bool {
+	for _, key := range subscriptionParams {
+		if _, ok := params[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// arrayParam returns the string array parameter, a missing parameter is
+// returned as empty.
+func arrayParam(params servers.Params, key string) ([]string, error) {
+	if _, ok := params[key]; !ok {
+		return nil, nil
+	}
+	values, ok := params.ArrayString(key)
+	if !ok {
+		return nil, errors.New(key + " should be an array of strings")
+	}
+	return values, nil
+}
+
+// Clear removes all subscriptions.
+func (s *subscription) Clear() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.blocks = false
+	s.programHashes = make(map[common.Uint168]struct{})
+	s.txTypes = make(map[common2.TxType]struct{})
+	s.payloadTypes = make(map[string]struct{})
+	s.producers = make(map[string]struct{})
+}
+
+// Info returns the subscriptions to be sent to client.
+func (s *subscription) Info() SubscriptionInfo {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	info := SubscriptionInfo{
+		Blocks:       s.blocks,
+		Addresses:    make([]string, 0, len(s.programHashes)),
+		TxTypes:      make([]string, 0, len(s.txTypes)),
+		PayloadTypes: make([]string, 0, len(s.payloadTypes)),
+		Producers:    make([]string, 0, len(s.producers)),
+	}
+	for h := range s.programHashes {
+		address, err := h.ToAddress()
+		if err != nil {
+			address = common.BytesToHexString(h.Bytes())
+		}
+		info.Addresses = append(info.Addresses, address)
+	}
+	for t := range s.txTypes {
+		info.TxTypes = append(info.TxTypes, t.Name())
+	}
+	for t := range s.payloadTypes {
+		info.PayloadTypes = append(info.PayloadTypes, t)
+	}
+	for p := range s.producers {
+		info.Producers = append(info.Producers, p)
+	}
+	return info
+}
+
+// MatchTx returns whether the transaction matches any of the subscriptions.
+func (s *subscription) MatchTx(tx interfaces.Transaction) bool {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	if _, ok := s.txTypes[tx.TxType()]; ok {
+		return true
+	}
+	if len(s.payloadTypes) > 0 && tx.Payload() != nil {
+		if _, ok := s.payloadTypes[payloadTypeName(tx.Payload())]; ok {
+			return true
+		}
+	}
+	if len(s.programHashes) > 0 {
+		for _, output := range tx.Outputs() {
+			if _, ok := s.programHashes[output.ProgramHash]; ok {
+				return true
+			}
+		}
+		for _, program := range tx.Programs() {
+			programHash := signerProgramHash(program.Code)
+			if programHash == nil {
+				continue
+			}
+			if _, ok := s.programHashes[*programHash]; ok {
+				return true
+			}
+		}
+	}
+	if len(s.producers) > 0 {
+		for _, key := range producerKeys(tx) {
+			if _, ok := s.producers[common.BytesToHexString(key)]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseTxType parses the transaction type from its name or number.
+func parseTxType(s string) (common2.TxType, error) {
+	if txType, ok := txTypeNames[strings.ToLower(s)]; ok {
+		return txType, nil
+	}
+	value, err := strconv.ParseUint(s, 0, 8)
+	if err != nil {
+		return 0, errors.New("invalid transaction type " + s)
+	}
+	return common2.TxType(value), nil
+}
+
+// payloadTypeName returns the lower case name of the payload struct, such as
+// "producerinfo" of register and update producer transactions.
+func payloadTypeName(p interfaces.Payload) string {
+	t := reflect.TypeOf(p)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return strings.ToLower(t.Name())
+}
+
+// signerProgramHash returns the program hash of a standard or multi-sign
+// program code, which is the address that signed the transaction.
+func signerProgramHash(code []byte) *common.Uint168 {
+	switch {
+	case contract.IsStandard(code):
+		return common.ToProgramHash(byte(contract.PrefixStandard), code)
+	case contract.IsMultiSig(code):
+		return common.ToProgramHash(byte(contract.PrefixMultiSig), code)
+	}
+	return nil
+}
+
+// producerKeys returns the producer public keys registered, updated,
+// activated or voted by the transaction.
+func producerKeys(tx interfaces.Transaction) [][]byte {
+	var keys [][]byte
+	switch p := tx.Payload().(type) {
+	case *payload.ProducerInfo:
+		keys = append(keys, p.OwnerKey, p.NodePublicKey)
+	case *payload.ProcessProducer:
+		keys = append(keys, p.OwnerKey)
+	case *payload.ActivateProducer:
+		keys = append(keys, p.NodePublicKey)
+	case *payload.Voting:
+		for _, content := range p.Contents {
+			if content.VoteType != outputpayload.Delegate &&
+				content.VoteType != outputpayload.DposV2 {
+				continue
+			}
+			for _, v := range content.VotesInfo {
+				keys = append(keys, v.Candidate)
+			}
+		}
+		for _, content := range p.RenewalContents {
+			keys = append(keys, content.VotesInfo.Candidate)
+		}
+	}
+	for _, output := range tx.Outputs() {
+		vote, ok := output.Payload.(*outputpayload.VoteOutput)
+		if !ok {
+			continue
+		}
+		for _, content := range vote.Contents {
+			if content.VoteType != outputpayload.Delegate {
+				continue
+			}
+			for _, cv := range content.CandidateVotes {
+				keys = append(keys, cv.Candidate)
+			}
+		}
+	}
+	return keys
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package httpwebsocket
+
+import (
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/transaction"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/servers"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFilterTestTx(txType common2.TxType, p interfaces.Payload,
+	outputs []*common2.Output) interfaces.Transaction {
+	return transaction.CreateTransaction(
+		common2.TxVersion09,
+		txType,
+		0,
+		p,
+		[]*common2.Attribute{},
+		[]*common2.Input{},
+		outputs,
+		0,
+		[]*program.Program{},
+	)
+}
+
+func TestSubscription_Update(t *testing.T) {
+	sub := newSubscription()
+	assert.True(t, sub.IsEmpty())
+
+	// Invalid params should not change the subscription.
+	err := sub.Update(servers.Params{
+		"txtypes":   []interface{}{"TransferAsset"},
+		"addresses": []interface{}{"invalid"},
+	}, true)
+	assert.Error(t, err)
+	assert.True(t, sub.IsEmpty())
+
+	err = sub.Update(servers.Params{"txtypes": "TransferAsset"}, true)
+	assert.Error(t, err)
+	assert.True(t, sub.IsEmpty())
+
+	err = sub.Update(servers.Params{
+		"blocks":       true,
+		"txtypes":      []interface{}{"transferasset", "0x09"},
+		"payloadtypes": []interface{}{"ProducerInfo"},
+	}, true)
+	assert.NoError(t, err)
+	assert.False(t, sub.IsEmpty())
+	assert.True(t, sub.Blocks())
+	info := sub.Info()
+	assert.ElementsMatch(t, []string{"TransferAsset", "RegisterProducer"},
+		info.TxTypes)
+	assert.Equal(t, []string{"producerinfo"}, info.PayloadTypes)
+
+	err = sub.Update(servers.Params{
+		"blocks":  false,
+		"txtypes": []interface{}{"TransferAsset"},
+	}, false)
+	assert.NoError(t, err)
+	assert.False(t, sub.Blocks())
+	assert.Equal(t, []string{"RegisterProducer"}, sub.Info().TxTypes)
+
+	sub.Clear()
+	assert.True(t, sub.IsEmpty())
+}
+
+func TestSubscription_MatchTx(t *testing.T) {
+	programHash := common.Uint168{0x21, 1, 2, 3}
+	address, err := programHash.ToAddress()
+	assert.NoError(t, err)
+	ownerKey := []byte{0x02, 0xaa, 0xbb}
+
+	transfer := newFilterTestTx(common2.TransferAsset, &payload.TransferAsset{},
+		[]*common2.Output{{ProgramHash: programHash}})
+	register := newFilterTestTx(common2.RegisterProducer,
+		&payload.ProducerInfo{OwnerKey: ownerKey}, []*common2.Output{})
+
+	sub := newSubscription()
+	assert.False(t, sub.MatchTx(transfer))
+	assert.False(t, sub.MatchTx(register))
+
+	assert.NoError(t, sub.Update(servers.Params{
+		"addresses": []interface{}{address},
+	}, true))
+	assert.True(t, sub.MatchTx(transfer))
+	assert.False(t, sub.MatchTx(register))
+	sub.Clear()
+
+	assert.NoError(t, sub.Update(servers.Params{
+		"producers": []interface{}{common.BytesToHexString(ownerKey)},
+	}, true))
+	assert.False(t, sub.MatchTx(transfer))
+	assert.True(t, sub.MatchTx(register))
+	sub.Clear()
+
+	assert.NoError(t, sub.Update(servers.Params{
+		"payloadtypes": []interface{}{"transferasset"},
+	}, true))
+	assert.True(t, sub.MatchTx(transfer))
+	assert.False(t, sub.MatchTx(register))
+}
@@ -17,7 +17,6 @@ import (
 	"time"
 
 	"github.com/elastos/Elastos.ELA/common/config"
-	"github.com/elastos/Elastos.ELA/common/log"
 	"github.com/elastos/Elastos.ELA/core/types"
 	"github.com/elastos/Elastos.ELA/core/types/interfaces"
 	"github.com/elastos/Elastos.ELA/events"
@@ -43,15 +42,19 @@ var (
 
 type Handler func(servers.Params) map[string]interface{}
 
+// SessionHandler handles requests which are related to the session.
+type SessionHandler func(*session, servers.Params) map[string]interface{}
+
 type Server struct {
 	sync.RWMutex
 	*http.Server
 	net.Listener
 	websocket.Upgrader
 
-	connCount int64
-	sessions  *sessions
-	handlers  map[string]Handler
+	connCount       int64
+	sessions        *sessions
+	handlers        map[string]Handler
+	sessionHandlers map[string]SessionHandler
 }
 
 func Start() {
@@ -65,6 +68,9 @@ func Start() {
 		case events.ETBlockConnected:
 			SendBlock2WSclient(e.Data)
 
+		case events.ETBlockDisconnected:
+			SendBlockDisconnected2WSclient(e.Data)
+
 		case events.ETTransactionAccepted:
 			SendTx2Client(e.Data)
 		}
@@ -115,6 +121,11 @@ func (s *Server) initMethods() {
 		"heartbeat":          s.heartBeat,
 		"getsessioncount":    s.getSessionCount,
 	}
+	s.sessionHandlers = map[string]SessionHandler{
+		"subscribe":        s.subscribe,
+		"unsubscribe":      s.unsubscribe,
+		"getsubscriptions": s.getSubscriptions,
+	}
 }
 
 func (s *Server) heartBeat(cmd servers.Params) map[string]interface{} {
@@ -125,6 +136,30 @@ func (s *Server) getSessionCount(cmd servers.Params) map[string]interface{} {
 	return servers.ResponsePack(errors.Success, s.sessions.Count())
 }
 
+// subscribe adds typed subscriptions to the session, then only matching
+// notifications will be pushed to the session.
+func (s *Server) subscribe(ss *session, cmd servers.Params) map[string]interface{} {
+	if err := ss.subscription.Update(cmd, true); err != nil {
+		return servers.ResponsePack(errors.InvalidParams, err.Error())
+	}
+	return servers.ResponsePack(errors.Success, ss.subscription.Info())
+}
+
+// unsubscribe removes the given subscriptions from the session, or all of
+// them if nothing is given.
+func (s *Server) unsubscribe(ss *session, cmd servers.Params) map[string]interface{} {
+	if !hasSubscriptionParams(cmd) {
+		ss.subscription.Clear()
+	} else if err := ss.subscription.Update(cmd, false); err != nil {
+		return servers.ResponsePack(errors.InvalidParams, err.Error())
+	}
+	return servers.ResponsePack(errors.Success, ss.subscription.Info())
+}
+
+func (s *Server) getSubscriptions(ss *session, cmd servers.Params) map[string]interface{} {
+	return servers.ResponsePack(errors.Success, ss.subscription.Info())
+}
+
 func (s *Server) Stop() {
 	s.Shutdown(context.Background())
 	log.Info("Close websocket ")
@@ -162,9 +197,10 @@ func (s *Server) Handler(w http.ResponseWriter, r *http.Request) {
 	defer conn.Close()
 
 	ss := &session{
-		id:         atomic.AddInt64(&s.connCount, 1),
-		conn:       conn,
-		lastActive: time.Now(),
+		id:           atomic.AddInt64(&s.connCount, 1),
+		conn:         conn,
+		lastActive:   time.Now(),
+		subscription: newSubscription(),
 	}
 	s.sessions.Store(ss.id, ss)
 
@@ -228,6 +264,12 @@ func (s *Server) handle(ss *session, bysMsg []byte, r *http.Request) bool {
 		s.response(ss, resp)
 		return false
 	}
+	if handler, ok := s.sessionHandlers[action]; ok {
+		resp := handler(ss, req)
+		resp["Action"] = action
+		s.response(ss, resp)
+		return true
+	}
 	handler, ok := s.handlers[action]
 	if !ok {
 		resp := servers.ResponsePack(errors.InvalidMethod, "")
@@ -284,6 +326,12 @@ func SendBlock2WSclient(v interface{}) {
 	}
 }
 
+func SendBlockDisconnected2WSclient(v interface{}) {
+	go func() {
+		instance.PushResult("sendblockdisconnected", v)
+	}()
+}
+
 func (s *Server) PushResult(action string, v interface{}) {
 	var result interface{}
 	switch action {
@@ -305,6 +353,10 @@ func (s *Server) PushResult(action string, v interface{}) {
 		if tx, ok := v.(interfaces.Transaction); ok {
 			result = servers.GetTransactionContextInfo(nil, tx)
 		}
+	case "sendblockdisconnected":
+		if block, ok := v.(*types.Block); ok {
+			result = servers.GetBlockTransactions(block)
+		}
 	default:
 		log.Error("httpwebsocket/server.go in pushresult function: unknown action")
 	}
@@ -318,12 +370,64 @@ func (s *Server) PushResult(action string, v interface{}) {
 		return
 	}
 
-	// Broadcast message to all connected clients.
-	s.sessions.Foreach(func(v *session) {
-		v.Send(data)
+	// Broadcast message to all connected clients, sessions with typed
+	// subscriptions receive only the matching part.
+	s.sessions.Foreach(func(ss *session) {
+		if ss.subscription.IsEmpty() {
+			ss.Send(data)
+			return
+		}
+		if filtered := s.filterResult(ss, action, v, data); filtered != nil {
+			ss.Send(filtered)
+		}
 	})
 }
 
+// filterResult returns the message to push to a session with subscriptions,
+// or nil if nothing matches.  Block disconnections are always pushed so that
+// clients can handle reorganizations.
+func (s *Server) filterResult(ss *session, action string, v interface{},
+	data []byte) []byte {
+	switch action {
+	case "sendblock", "sendrawblock":
+		if ss.subscription.Blocks() {
+			return data
+		}
+	case "sendblockdisconnected":
+		return data
+	case "sendnewtransaction":
+		if tx, ok := v.(interfaces.Transaction); ok && ss.subscription.MatchTx(tx) {
+			return data
+		}
+	case "sendblocktransactions":
+		block, ok := v.(*types.Block)
+		if !ok {
+			return nil
+		}
+		var txs []interfaces.Transaction
+		for _, tx := range block.Transactions {
+			if ss.subscription.MatchTx(tx) {
+				txs = append(txs, tx)
+			}
+		}
+		if len(txs) == 0 {
+			return nil
+		}
+		filtered := &types.Block{Header: block.Header, Transactions: txs}
+		resp := servers.ResponsePack(errors.Success,
+			servers.GetBlockTransactions(filtered))
+		resp["Action"] = action
+		resp["Desc"] = errors.ErrMap[resp["Error"].(errors.ServerErrCode)]
+		filteredData, err := json.Marshal(resp)
+		if err != nil {
+			log.Error("Websocket filterResult:", err)
+			return nil
+		}
+		return filteredData
+	}
+	return nil
+}
+
 func (s *Server) initTlsListen() (net.Listener, error) {
 
 	CertPath := config.Parameters.RestCertPath
@@ -14,10 +14,11 @@ import (
 )
 
 type session struct {
-	mtx        sync.Mutex
-	id         int64
-	conn       *websocket.Conn
-	lastActive time.Time
+	mtx          sync.Mutex
+	id           int64
+	conn         *websocket.Conn
+	lastActive   time.Time
+	subscription *subscription
 }
 
 func (s *session) Send(data []byte) error {
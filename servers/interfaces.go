@@ -6,11 +6,14 @@
 package servers
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"math"
+	"os"
 	"sort"
 	"strings"
 
@@ -29,7 +32,9 @@ import (
 	"github.com/elastos/Elastos.ELA/core/types/interfaces"
 	"github.com/elastos/Elastos.ELA/core/types/outputpayload"
 	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/core/types/psbt"
 	crstate "github.com/elastos/Elastos.ELA/cr/state"
+	"github.com/elastos/Elastos.ELA/devnet"
 	"github.com/elastos/Elastos.ELA/dpos"
 	"github.com/elastos/Elastos.ELA/dpos/state"
 	"github.com/elastos/Elastos.ELA/elanet"
@@ -38,11 +43,22 @@ import (
 	"github.com/elastos/Elastos.ELA/p2p/msg"
 	"github.com/elastos/Elastos.ELA/pow"
 	. "github.com/elastos/Elastos.ELA/servers/errors"
+	"github.com/elastos/Elastos.ELA/utils/elalog"
 	"github.com/elastos/Elastos.ELA/wallet"
 
 	"github.com/tidwall/gjson"
 )
 
+const (
+	// DefaultAddressHistoryCount is the count of address history entries
+	// returned when the caller does not specify one.
+	DefaultAddressHistoryCount = 100
+
+	// MaxAddressHistoryCount is the maximum count of address history
+	// entries can be returned in one request.
+	MaxAddressHistoryCount = 1000
+)
+
 var (
 	Compile     string
 	ChainParams *config.Configuration
@@ -54,6 +70,7 @@ var (
 	Arbiter     *dpos.Arbitrator
 	Arbiters    state.Arbitrators
 	Wallet      *wallet.Wallet
+	DevNet      *devnet.DevNet
 	emptyHash   = common.Uint168{}
 )
 
@@ -151,6 +168,10 @@ func GetRawTransaction(param Params) map[string]interface{} {
 
 	var header *common2.Header
 	tx, height, err := Store.GetTransaction(hash)
+	if blockchain.IsPrunedError(err) {
+		return ResponsePack(BlockPruned,
+			"transaction data has been pruned, node is running in prune mode")
+	}
 	if err != nil {
 		//try to find transaction in transaction pool.
 		tx = TxMemPool.GetTransaction(hash)
@@ -238,8 +259,16 @@ func SetLogLevel(param Params) map[string]interface{} {
 		return ResponsePack(InvalidParams, "level must be an integer in 0-6")
 	}
 
-	log.SetPrintLevel(uint8(level))
-	return ResponsePack(Success, fmt.Sprint("log level has been set to ", level))
+	subsystem, ok := param.String("subsystem")
+	if !ok {
+		log.SetPrintLevel(uint8(level))
+		return ResponsePack(Success, fmt.Sprint("log level has been set to ", level))
+	}
+	if err := log.SetLevel(subsystem, elalog.Level(level)); err != nil {
+		return ResponsePack(InvalidParams, err.Error())
+	}
+	return ResponsePack(Success, fmt.Sprint("log level of ", subsystem,
+		" has been set to ", level))
 }
 
 func CreateAuxBlock(param Params) map[string]interface{} {
@@ -1035,6 +1064,116 @@ func DiscreteMining(param Params) map[string]interface{} {
 	return ResponsePack(Success, ret)
 }
 
+func GenerateBlocks(param Params) map[string]interface{} {
+	if rtn := checkRPCServiceLevel(config.MiningPermitted); rtn != nil {
+		return rtn
+	}
+
+	if DevNet == nil {
+		return ResponsePack(DevNetNotEnabled, "")
+	}
+	count, ok := param.Uint("count")
+	if !ok || count == 0 {
+		return ResponsePack(InvalidParams, "count is required")
+	}
+
+	hashes, err := DevNet.Generate(count)
+	if err != nil {
+		return ResponsePack(Error, err.Error())
+	}
+	ret := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		ret = append(ret, common.ToReversedString(hash))
+	}
+
+	return ResponsePack(Success, ret)
+}
+
+func GenerateToHeight(param Params) map[string]interface{} {
+	if rtn := checkRPCServiceLevel(config.MiningPermitted); rtn != nil {
+		return rtn
+	}
+
+	if DevNet == nil {
+		return ResponsePack(DevNetNotEnabled, "")
+	}
+	height, ok := param.Uint("height")
+	if fork, isFork := param.String("fork"); isFork {
+		height, ok = ChainParams.DeploymentHeight(
+			config.Deployment(strings.ToLower(fork)))
+		if !ok {
+			return ResponsePack(InvalidParams, "unknown fork "+fork)
+		}
+	}
+	if !ok {
+		return ResponsePack(InvalidParams, "height or fork is required")
+	}
+
+	generated, err := DevNet.GenerateToHeight(height)
+	if err != nil {
+		return ResponsePack(Error, err.Error())
+	}
+
+	return ResponsePack(Success, GenerateToHeightInfo{
+		Height:    Chain.GetHeight(),
+		Hash:      common.ToReversedString(*Chain.GetBestBlockHash()),
+		Generated: generated,
+	})
+}
+
+func FundAddress(param Params) map[string]interface{} {
+	if rtn := checkRPCServiceLevel(config.TransactionPermitted); rtn != nil {
+		return rtn
+	}
+
+	if DevNet == nil {
+		return ResponsePack(DevNetNotEnabled, "")
+	}
+	address, ok := param.String("address")
+	if !ok {
+		return ResponsePack(InvalidParams, "address is required")
+	}
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid address, "+err.Error())
+	}
+	amountStr, ok := param.String("amount")
+	if !ok {
+		return ResponsePack(InvalidParams, "amount is required")
+	}
+	amount, err := common.StringToFixed64(amountStr)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid amount, "+err.Error())
+	}
+
+	tx, blockHash, err := DevNet.Fund(*programHash, *amount)
+	if err != nil {
+		return ResponsePack(Error, err.Error())
+	}
+
+	return ResponsePack(Success, FundAddressInfo{
+		TxID:      common.ToReversedString(tx.Hash()),
+		BlockHash: common.ToReversedString(blockHash),
+	})
+}
+
+func GetDevNetInfo(param Params) map[string]interface{} {
+	if DevNet == nil {
+		return ResponsePack(DevNetNotEnabled, "")
+	}
+
+	keys := DevNet.Keys()
+	arbiters, err := keys.ArbiterPublicKeys()
+	if err != nil {
+		return ResponsePack(InternalError, err.Error())
+	}
+
+	return ResponsePack(Success, DevNetInfo{
+		Faucet:   keys.Faucet.Address,
+		Arbiters: arbiters,
+	})
+}
+
 func GetConnectionCount(param Params) map[string]interface{} {
 	return ResponsePack(Success, Server.ConnectedCount())
 }
@@ -1125,6 +1264,10 @@ func GetConfirmInfo(confirm *payload.Confirm) ConfirmInfo {
 
 func getBlock(hash common.Uint256, verbose uint32) (interface{}, ServerErrCode) {
 	block, err := Chain.GetBlockByHash(hash)
+	if blockchain.IsPrunedError(err) {
+		return "block data has been pruned, node is running in prune mode",
+			BlockPruned
+	}
 	if err != nil {
 		return "", UnknownBlock
 	}
@@ -1140,7 +1283,11 @@ func getBlock(hash common.Uint256, verbose uint32) (interface{}, ServerErrCode)
 }
 
 func getConfirm(hash common.Uint256, verbose uint32) (interface{}, ServerErrCode) {
-	block, _ := Store.GetFFLDB().GetBlock(hash)
+	block, err := Store.GetFFLDB().GetBlock(hash)
+	if blockchain.IsPrunedError(err) {
+		return "block data has been pruned, node is running in prune mode",
+			BlockPruned
+	}
 	if block == nil {
 		return "", UnknownBlock
 	} else if !block.HaveConfirm {
@@ -1254,6 +1401,75 @@ func SendRawTransaction(param Params) map[string]interface{} {
 	return ResponsePack(Success, common.ToReversedString(txn.Hash()))
 }
 
+// TestMempoolAccept runs every check of SendRawTransaction against the
+// transaction without adding it to the transaction pool, and reports the
+// result of each check.
+func TestMempoolAccept(param Params) map[string]interface{} {
+	if rtn := checkRPCServiceLevel(config.TransactionPermitted); rtn != nil {
+		return rtn
+	}
+
+	str, ok := param.String("data")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a string parameter named data")
+	}
+	bys, err := common.HexStringToBytes(str)
+	if err != nil {
+		return ResponsePack(InvalidParams, "hex string to bytes error")
+	}
+
+	r := bytes.NewReader(bys)
+	txn, err := functions.GetTransactionByBytes(r)
+	if err != nil {
+		return ResponsePack(InvalidTransaction, "invalid transaction")
+	}
+	if err := txn.Deserialize(r); err != nil {
+		return ResponsePack(InvalidTransaction, err.Error())
+	}
+
+	trace := TxMemPool.TestAccept(txn)
+	info := TestMempoolAcceptInfo{
+		TxID:    common.ToReversedString(txn.Hash()),
+		Allowed: true,
+		Size:    txn.GetSize(),
+		Fee:     txn.Fee().String(),
+		Checks:  make([]CheckResultInfo, 0, len(trace.Checks)),
+		Lookups: make([]CheckLookupInfo, 0, len(trace.Lookups)),
+	}
+	if err := trace.Err(); err != nil {
+		info.Allowed = false
+		info.Code = int(err.Code())
+		info.Reason = err.Error()
+	}
+	for _, c := range trace.Checks {
+		check := CheckResultInfo{Name: c.Name}
+		switch {
+		case c.Skipped:
+			check.Result = "skipped"
+		case c.Ignored:
+			check.Result = "ignored"
+			check.Code = int(c.Error.Code())
+			check.Reason = c.Error.Error()
+		case c.Error != nil:
+			check.Result = "failed"
+			check.Code = int(c.Error.Code())
+			check.Reason = c.Error.Error()
+		default:
+			check.Result = "passed"
+		}
+		info.Checks = append(info.Checks, check)
+	}
+	for _, l := range trace.Lookups {
+		info.Lookups = append(info.Lookups, CheckLookupInfo{
+			Name:   l.Name,
+			Key:    l.Key,
+			Result: l.Result,
+		})
+	}
+
+	return ResponsePack(Success, info)
+}
+
 func GetBlockHeight(param Params) map[string]interface{} {
 	return ResponsePack(Success, Chain.GetHeight())
 }
@@ -1270,6 +1486,27 @@ func GetBlockCount(param Params) map[string]interface{} {
 	return ResponsePack(Success, Chain.GetHeight()+1)
 }
 
+func GetDeploymentInfo(param Params) map[string]interface{} {
+	height, ok := param.Uint("height")
+	if !ok {
+		height = Chain.GetHeight()
+	}
+
+	deployments := make([]DeploymentInfo, 0)
+	for _, d := range ChainParams.ListDeployments() {
+		deployments = append(deployments, DeploymentInfo{
+			Name:        string(d.Name),
+			Description: d.Description,
+			Height:      d.Height,
+			Active:      ChainParams.IsActive(d.Name, height),
+		})
+	}
+	return ResponsePack(Success, DeploymentsInfo{
+		Height:      height,
+		Deployments: deployments,
+	})
+}
+
 func GetBlockHash(param Params) map[string]interface{} {
 	height, ok := param.Uint("height")
 	if !ok {
@@ -1313,6 +1550,10 @@ func GetTransactionsByHeight(param Params) map[string]interface{} {
 
 	}
 	block, err := Chain.GetBlockByHash(hash)
+	if blockchain.IsPrunedError(err) {
+		return ResponsePack(BlockPruned,
+			"block data has been pruned, node is running in prune mode")
+	}
 	if err != nil {
 		return ResponsePack(UnknownBlock, "")
 	}
@@ -1352,7 +1593,7 @@ func GetArbitratorGroupByHeight(param Params) map[string]interface{} {
 	}
 
 	result := ArbitratorGroupInfo{}
-	if height < ChainParams.DPoSConfiguration.DPOSNodeCrossChainHeight {
+	if !ChainParams.IsActive(config.DeploymentDPOSNodeCrossChain, height) {
 		crcArbiters := Arbiters.GetCRCArbiters()
 		sort.Slice(crcArbiters, func(i, j int) bool {
 			return bytes.Compare(crcArbiters[i].NodePublicKey, crcArbiters[j].NodePublicKey) < 0
@@ -1514,6 +1755,77 @@ func GetReceivedByAddress(param Params) map[string]interface{} {
 	return ResponsePack(Success, balance.String())
 }
 
+func GetAddressHistory(param Params) map[string]interface{} {
+	address, ok := param.String("address")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a parameter named address")
+	}
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid address, "+err.Error())
+	}
+	skip, ok := param.Uint("skip")
+	if !ok {
+		skip = 0
+	}
+	count, ok := param.Uint("count")
+	if !ok || count == 0 {
+		count = DefaultAddressHistoryCount
+	}
+	if count > MaxAddressHistoryCount {
+		return ResponsePack(InvalidParams, fmt.Sprintf("count can not be "+
+			"greater than %d", MaxAddressHistoryCount))
+	}
+	reverse, _ := param.Bool("reverse")
+
+	total, err := Store.GetFFLDB().GetAddressHistoryCount(programHash)
+	if err != nil {
+		return ResponsePack(InternalError, "get address history failed, "+
+			err.Error())
+	}
+	histories, err := Store.GetFFLDB().GetAddressHistory(programHash, skip,
+		count, reverse)
+	if err != nil {
+		return ResponsePack(InternalError, "get address history failed, "+
+			err.Error())
+	}
+
+	bestHeight := Chain.GetHeight()
+	result := AddressHistoryResult{
+		Total:   total,
+		History: make([]AddressHistoryInfo, 0, len(histories)),
+	}
+	for _, h := range histories {
+		result.History = append(result.History, AddressHistoryInfo{
+			TxID:          common.ToReversedString(h.TxID),
+			Height:        h.Height,
+			Direction:     h.Direction.String(),
+			Amount:        h.Amount.String(),
+			Confirmations: bestHeight - h.Height + 1,
+		})
+	}
+
+	return ResponsePack(Success, result)
+}
+
+func GetAddressTxCount(param Params) map[string]interface{} {
+	address, ok := param.String("address")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a parameter named address")
+	}
+	programHash, err := common.Uint168FromAddress(address)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid address, "+err.Error())
+	}
+	count, err := Store.GetFFLDB().GetAddressTxCount(programHash)
+	if err != nil {
+		return ResponsePack(InternalError, "get address tx count failed, "+
+			err.Error())
+	}
+
+	return ResponsePack(Success, count)
+}
+
 func GetUTXOsByAmount(param Params) map[string]interface{} {
 	if rtn := checkRPCServiceLevel(config.WalletPermitted); rtn != nil {
 		return rtn
@@ -1701,6 +2013,86 @@ func ListUnspent(param Params) map[string]interface{} {
 	return ResponsePack(Success, result)
 }
 
+// GetUTXOLock returns how to spend an unspent output and when it becomes
+// spendable.
+func GetUTXOLock(param Params) map[string]interface{} {
+	txID, ok := param.String("txid")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a parameter named txid")
+	}
+	hash, err := common.Uint256FromReversedHexString(txID)
+	if err != nil {
+		return ResponsePack(InvalidParams, "invalid txid")
+	}
+	vout, ok := param.Uint("vout")
+	if !ok {
+		return ResponsePack(InvalidParams, "need a parameter named vout")
+	}
+
+	tx, _, err := Store.GetTransaction(*hash)
+	if err != nil {
+		return ResponsePack(UnknownTransaction, "cannot find transaction in blockchain")
+	}
+	if int(vout) >= len(tx.Outputs()) {
+		return ResponsePack(InvalidParams, "vout out of range")
+	}
+	output := tx.Outputs()[vout]
+	utxos, err := Store.GetFFLDB().GetUTXO(&output.ProgramHash)
+	if err != nil {
+		return ResponsePack(InternalError, "get utxo failed, "+err.Error())
+	}
+	var unspent bool
+	for _, utxo := range utxos {
+		if utxo.TxID.IsEqual(*hash) && uint32(utxo.Index) == vout {
+			unspent = true
+			break
+		}
+	}
+	if !unspent {
+		return ResponsePack(InvalidParams, "output has been spent")
+	}
+
+	nextHeight := Chain.GetHeight() + 1
+	outPoint := common2.OutPoint{TxID: *hash, Index: uint16(vout)}
+	info := UTXOLockInfo{
+		TxID:       txID,
+		VOut:       uint16(vout),
+		OutputLock: output.OutputLock,
+		LockType:   "none",
+		Sequence:   math.MaxUint32,
+	}
+	timeLock := ChainParams.IsActive(config.DeploymentTimeLock, nextHeight)
+	switch {
+	case output.OutputLock == 0:
+	case timeLock && output.IsRelativeLock():
+		info.LockType = "relativeheight"
+		info.Sequence = output.RelativeLockSequence()
+		if info.Sequence&common2.SequenceLockTimeIsSeconds != 0 {
+			info.LockType = "relativetime"
+		}
+	case timeLock && output.IsTimeLock():
+		info.LockType = "time"
+		info.Sequence = math.MaxUint32 - 1
+		info.LockTime = output.OutputLock
+	default:
+		info.LockType = "height"
+		info.Sequence = math.MaxUint32 - 1
+		info.LockTime = output.OutputLock
+		info.SpendableHeight = output.OutputLock + 1
+	}
+	if timeLock {
+		info.SpendableHeight, info.SpendableTime, err = Chain.CalcUTXOLock(
+			outPoint, output)
+		if err != nil {
+			return ResponsePack(InternalError, err.Error())
+		}
+	}
+	info.Spendable = nextHeight >= info.SpendableHeight &&
+		Chain.PastMedianTime().Unix() >= info.SpendableTime
+
+	return ResponsePack(Success, info)
+}
+
 func CreateRawTransaction(param Params) map[string]interface{} {
 	if rtn := checkRPCServiceLevel(config.WalletPermitted); rtn != nil {
 		return rtn
@@ -2015,6 +2407,10 @@ func GetTransactionByHash(param Params) map[string]interface{} {
 		return ResponsePack(InvalidTransaction, "")
 	}
 	txn, height, err := Store.GetTransaction(hash)
+	if blockchain.IsPrunedError(err) {
+		return ResponsePack(BlockPruned,
+			"transaction data has been pruned, node is running in prune mode")
+	}
 	if err != nil {
 		return ResponsePack(UnknownTransaction, "")
 	}
@@ -3165,24 +3561,96 @@ func EstimateSmartFee(param Params) map[string]interface{} {
 	if !ok {
 		return ResponsePack(InvalidParams, "need a param called confirmations")
 	}
-	if confirm > 25 {
-		return ResponsePack(InvalidParams, "support only 25 confirmations at most")
+	if confirm < 1 || confirm > mempool.MaxConfirmTarget {
+		return ResponsePack(InvalidParams, fmt.Sprintf("support only "+
+			"1 to %d confirmations", mempool.MaxConfirmTarget))
 	}
-	var FeeRate = 10000 //basic fee rate 10000 sela per KB
-	var count = 0
 
-	// TODO just return fixed transaction fee for now, we didn't have that much
-	// transactions in a block yet.
+	estimation, err := TxMemPool.FeeEstimator.EstimateFee(uint32(confirm))
+	if err != nil {
+		return ResponsePack(InvalidParams, err.Error())
+	}
 
-	return ResponsePack(Success, GetFeeRate(count, int(confirm))*FeeRate)
+	verbose, _ := param.Bool("verbose")
+	if !verbose {
+		return ResponsePack(Success, int64(estimation.FeeRate))
+	}
+	return ResponsePack(Success, EstimateFeeInfo{
+		FeeRate:    int64(estimation.FeeRate),
+		Confidence: estimation.Confidence,
+		Blocks:     uint32(confirm),
+	})
 }
 
-func GetFeeRate(count int, confirm int) int {
-	gap := count - confirm
-	if gap < 0 {
-		gap = -1
+func SaveMempool(param Params) map[string]interface{} {
+	if rtn := checkRPCServiceLevel(config.TransactionPermitted); rtn != nil {
+		return rtn
+	}
+
+	count, err := TxMemPool.SaveMempool()
+	if err != nil {
+		return ResponsePack(InternalError, "save mempool failed, "+err.Error())
 	}
-	return gap + 2
+
+	return ResponsePack(Success, SaveMempoolInfo{
+		Saved: count,
+		File:  TxMemPool.MempoolFilePath(),
+	})
+}
+
+func DumpSnapshot(param Params) map[string]interface{} {
+	if rtn := checkRPCServiceLevel(config.ConfigurationPermitted); rtn != nil {
+		return rtn
+	}
+
+	path, ok := param.String("path")
+	if !ok || path == "" {
+		return ResponsePack(InvalidParams, "path is required")
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return ResponsePack(InvalidParams, "create snapshot file failed, "+
+			err.Error())
+	}
+
+	w := bufio.NewWriter(file)
+	info, err := Chain.DumpSnapshot(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	file.Close()
+	if err != nil {
+		os.Remove(path)
+		return ResponsePack(InternalError, "dump snapshot failed, "+err.Error())
+	}
+	stat, err := os.Stat(path)
+	if err != nil {
+		return ResponsePack(InternalError, err.Error())
+	}
+
+	return ResponsePack(Success, DumpSnapshotInfo{
+		Height:      info.Height,
+		Hash:        common.ToReversedString(info.Hash),
+		ContentHash: hex.EncodeToString(info.ContentHash[:]),
+		Size:        stat.Size(),
+		Path:        path,
+	})
+}
+
+func LoadMempool(param Params) map[string]interface{} {
+	if rtn := checkRPCServiceLevel(config.TransactionPermitted); rtn != nil {
+		return rtn
+	}
+
+	accepted, rejected, err := TxMemPool.LoadMempool()
+	if err != nil {
+		return ResponsePack(InternalError, "load mempool failed, "+err.Error())
+	}
+
+	return ResponsePack(Success, LoadMempoolInfo{
+		Accepted: accepted,
+		Rejected: rejected,
+	})
 }
 
 func DecodeRawTransaction(param Params) map[string]interface{} {
@@ -3199,6 +3667,14 @@ func DecodeRawTransaction(param Params) map[string]interface{} {
 		return ResponsePack(InvalidParams, "invalid raw tx data, "+err.Error())
 	}
 	r := bytes.NewReader(txBytes)
+	if psbt.IsPacket(txBytes) {
+		var packet psbt.Packet
+		if err := packet.Deserialize(r); err != nil {
+			return ResponsePack(InvalidParams,
+				"invalid partially signed tx data, "+err.Error())
+		}
+		return ResponsePack(Success, GetPartiallySignedTransactionInfo(&packet))
+	}
 	txn, err := functions.GetTransactionByBytes(r)
 	if err != nil {
 		return ResponsePack(InvalidTransaction, "invalid transaction")
@@ -3210,6 +3686,77 @@ func DecodeRawTransaction(param Params) map[string]interface{} {
 	return ResponsePack(Success, GetTransactionInfo(txn))
 }
 
+func GetPartiallySignedTransactionInfo(
+	p *psbt.Packet) *PartiallySignedTransactionInfo {
+	inputs := make([]PSBTInputInfo, len(p.Inputs))
+	for i, input := range p.Inputs {
+		previous := p.Transaction.Inputs()[i].Previous
+		inputs[i].TxID = common.ToReversedString(previous.TxID)
+		inputs[i].VOut = previous.Index
+		if input.PreviousOutput != nil {
+			inputs[i].Address, _ = input.PreviousOutput.ProgramHash.ToAddress()
+			inputs[i].Value = input.PreviousOutput.Value.String()
+		}
+		inputs[i].RedeemScript = common.BytesToHexString(input.RedeemScript)
+	}
+
+	outputs := make([]PSBTOutputInfo, len(p.Outputs))
+	for i, output := range p.Outputs {
+		txOutput := p.Transaction.Outputs()[i]
+		outputs[i].Index = uint32(i)
+		outputs[i].Address, _ = txOutput.ProgramHash.ToAddress()
+		outputs[i].Value = txOutput.Value.String()
+		outputs[i].RedeemScript = common.BytesToHexString(output.RedeemScript)
+	}
+
+	complete := len(p.Programs) > 0
+	programs := make([]PSBTProgramInfo, len(p.Programs))
+	for i, program := range p.Programs {
+		switch contract.GetCodeType(program.Code) {
+		case contract.Signature:
+			programs[i].Type = "standard"
+		case contract.MultiSig:
+			programs[i].Type = "multisig"
+		case contract.Schnorr:
+			programs[i].Type = "schnorr"
+		}
+		programs[i].Address, _ = program.Address()
+		programs[i].Code = common.BytesToHexString(program.Code)
+		programs[i].Signed = program.Signed()
+		programs[i].Required, _ = program.Required()
+		for _, pk := range program.PublicKeys {
+			programs[i].PublicKeys = append(programs[i].PublicKeys,
+				common.BytesToHexString(pk))
+		}
+		programs[i].Missing = make([]string, 0)
+		for _, pk := range program.Missing() {
+			programs[i].Missing = append(programs[i].Missing,
+				common.BytesToHexString(pk))
+		}
+		for _, n := range program.Nonces {
+			programs[i].Nonces = append(programs[i].Nonces,
+				common.BytesToHexString(n.PublicKey))
+		}
+		if programs[i].Required == 0 ||
+			programs[i].Signed < programs[i].Required {
+			complete = false
+		}
+	}
+
+	info := &PartiallySignedTransactionInfo{
+		Version:     psbt.Version,
+		Transaction: GetTransactionInfo(p.Transaction),
+		Inputs:      inputs,
+		Outputs:     outputs,
+		Programs:    programs,
+		Complete:    complete,
+	}
+	if fee, err := p.Fee(); err == nil {
+		info.Fee = fee.String()
+	}
+	return info
+}
+
 func getPayloadInfo(tx interfaces.Transaction, payloadVersion byte) PayloadInfo {
 	p := tx.Payload()
 	switch object := p.(type) {
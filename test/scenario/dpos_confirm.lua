@@ -0,0 +1,40 @@
+-- Copyright (c) 2017-2020 The Elastos Foundation
+-- Use of this source code is governed by an MIT
+-- license that can be found in the LICENSE file.
+--
+
+-- Blocks are confirmed by the CRC arbiters once the height reaches
+-- CRCOnlyDPOSHeight (20 on the devnet), and the funded coins are spendable
+-- after being packed.
+
+local c = chain.new(5)
+local addr = "EQ4QhsYRwuBbNBXc8BPW972xA9ANByKt6U"
+
+scenario.case("mine pow blocks", function()
+    local hashes = c:mine(19)
+    scenario.assert_equal(19, #hashes)
+    scenario.assert_equal(19, c:height())
+    scenario.assert_equal(hashes[19], c:best_hash())
+end)
+
+scenario.case("mine confirmed blocks", function()
+    c:mine(6)
+    scenario.assert_equal(25, c:height())
+    scenario.assert_equal(5, #c:arbiters(), "arbiters of crc only dpos")
+end)
+
+scenario.case("fund address", function()
+    scenario.assert_equal(0, c:balance(addr))
+    c:fund(addr, 12.5)
+    c:mine(1)
+    scenario.assert_equal(12.5, c:balance(addr))
+
+    local utxos = c:utxos(addr)
+    scenario.assert_equal(1, #utxos)
+    scenario.assert_equal(12.5, utxos[1].amount)
+end)
+
+scenario.case("producer not registered", function()
+    scenario.assert_nil(c:producer(c:arbiters()[1]))
+    scenario.assert_error(function() c:fund("invalid", 1) end)
+end)
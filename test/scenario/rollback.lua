@@ -0,0 +1,34 @@
+-- Copyright (c) 2017-2020 The Elastos Foundation
+-- Use of this source code is governed by an MIT
+-- license that can be found in the LICENSE file.
+--
+
+-- Rolling back the chain reverts the UTXOs of the disconnected blocks, and
+-- new blocks can be mined on the rolled back chain.
+
+local c = chain.new(5)
+local addr = "EQ4QhsYRwuBbNBXc8BPW972xA9ANByKt6U"
+
+scenario.case("rollback funded block", function()
+    c:mine(22)
+    local hash = c:best_hash()
+    c:fund(addr, 3)
+    c:mine(2)
+    scenario.assert_equal(3, c:balance(addr))
+
+    c:rollback(22)
+    scenario.assert_equal(22, c:height())
+    scenario.assert_equal(hash, c:best_hash())
+    scenario.assert_equal(0, c:balance(addr))
+end)
+
+scenario.case("mine after rollback", function()
+    c:fund(addr, 5)
+    c:mine(3)
+    scenario.assert_equal(25, c:height())
+    scenario.assert_equal(5, c:balance(addr))
+end)
+
+scenario.case("rollback to best height", function()
+    scenario.assert_error(function() c:rollback(c:height()) end)
+end)
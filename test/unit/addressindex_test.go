@@ -0,0 +1,365 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package unit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/elastos/Elastos.ELA/blockchain/indexers"
+	"github.com/elastos/Elastos.ELA/common"
+	"github.com/elastos/Elastos.ELA/common/config"
+	"github.com/elastos/Elastos.ELA/common/log"
+	"github.com/elastos/Elastos.ELA/core/contract/program"
+	"github.com/elastos/Elastos.ELA/core/types"
+	common2 "github.com/elastos/Elastos.ELA/core/types/common"
+	"github.com/elastos/Elastos.ELA/core/types/functions"
+	"github.com/elastos/Elastos.ELA/core/types/interfaces"
+	"github.com/elastos/Elastos.ELA/core/types/payload"
+	"github.com/elastos/Elastos.ELA/database"
+	"github.com/elastos/Elastos.ELA/utils/test"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testAddressIndex *indexers.AddressIndex
+	addressIndexDB   database.DB
+	addressIndexPath = filepath.Join(test.DataPath, "addressindex")
+
+	// addressIndexers are the indexers the address index is connected with,
+	// the transactions referenced by inputs are read from the blocks stored
+	// in the database like a running node.
+	addressIndexers []indexers.Indexer
+)
+
+// connectAddressIndexBlock stores the block and connects it to the indexers
+// in the order of the index manager.
+func connectAddressIndexBlock(t *testing.T, block *types.Block,
+	idxs []indexers.Indexer) {
+	err := addressIndexDB.Update(func(dbTx database.Tx) error {
+		buf := new(bytes.Buffer)
+		if err := (&types.DposBlock{Block: block}).Serialize(buf); err != nil {
+			return err
+		}
+		if err := dbTx.StoreBlock(block.Hash(), buf.Bytes()); err != nil {
+			return err
+		}
+		for _, idx := range idxs {
+			if err := idx.ConnectBlock(dbTx, block); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+// disconnectAddressIndexBlock disconnects the block from the indexers in the
+// reverse order of connecting.
+func disconnectAddressIndexBlock(t *testing.T, block *types.Block) {
+	err := addressIndexDB.Update(func(dbTx database.Tx) error {
+		for i := len(addressIndexers) - 1; i >= 0; i-- {
+			err := addressIndexers[i].DisconnectBlock(dbTx, block)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestAddressIndexInit(t *testing.T) {
+	log.NewDefault(test.NodeLogPath, 0, 0, 0)
+
+	var err error
+	os.RemoveAll(addressIndexPath)
+	addressIndexDB, err = LoadBlockDB(addressIndexPath)
+	assert.NoError(t, err)
+
+	txIndex := indexers.NewTxIndex(addressIndexDB)
+	unspentIndex := indexers.NewUnspentIndex(addressIndexDB,
+		config.GetDefaultParams())
+	testAddressIndex = indexers.NewAddressIndex(addressIndexDB)
+	addressIndexers = []indexers.Indexer{txIndex, unspentIndex,
+		testAddressIndex}
+	assert.NotEqual(t, nil, testAddressIndex)
+	assert.Equal(t, []byte("addrhistoryidx"), testAddressIndex.Key())
+	assert.Equal(t, "address history index", testAddressIndex.Name())
+	_ = addressIndexDB.Update(func(dbTx database.Tx) error {
+		for _, idx := range addressIndexers {
+			assert.NoError(t, idx.Create(dbTx))
+		}
+		return nil
+	})
+
+	// The refer transaction spends nothing existing, so it is only added
+	// to the transaction index, and its unspent outputs and history are
+	// initialized directly.
+	connectAddressIndexBlock(t, &types.Block{
+		Header:       common2.Header{Height: referHeight},
+		Transactions: []interfaces.Transaction{testUtxoIndexReferTx},
+	}, addressIndexers[:1])
+	_ = addressIndexDB.Update(func(dbTx database.Tx) error {
+		referTxID := testUtxoIndexReferTx.Hash()
+		indexes := make([]uint16, 0, len(testUtxoIndexReferTx.Outputs()))
+		for i := range testUtxoIndexReferTx.Outputs() {
+			indexes = append(indexes, uint16(i))
+		}
+		assert.NoError(t, indexers.DBPutUnspentIndexEntry(dbTx, &referTxID,
+			indexes))
+
+		// initialize the history of the refer transaction
+		err = indexers.DBPutAddressHistoryEntry(dbTx, referRecipient1,
+			referHeight, 1, &referTxID, indexers.AddressReceived, 100)
+		assert.NoError(t, err)
+
+		histories, err := indexers.DBFetchAddressHistory(dbTx,
+			referRecipient1, 0, 0, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []*indexers.AddressHistory{
+			{
+				TxID:      referTxID,
+				Height:    referHeight,
+				Direction: indexers.AddressReceived,
+				Amount:    100,
+			},
+		}, histories)
+
+		histories, err = indexers.DBFetchAddressHistory(dbTx, recipient1,
+			0, 0, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(histories))
+		return nil
+	})
+}
+
+func TestAddressIndex_ConnectBlock(t *testing.T) {
+	connectAddressIndexBlock(t, testUtxoIndexBlock, addressIndexers)
+
+	_ = addressIndexDB.View(func(dbTx database.Tx) error {
+		// spent value should be recorded after the received history
+		referTxID := testUtxoIndexReferTx.Hash()
+		histories, err := indexers.DBFetchAddressHistory(dbTx,
+			referRecipient1, 0, 0, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []*indexers.AddressHistory{
+			{
+				TxID:      referTxID,
+				Height:    referHeight,
+				Direction: indexers.AddressReceived,
+				Amount:    100,
+			},
+			{
+				TxID:      testUtxoIndexTx2.Hash(),
+				Height:    testUtxoIndexBlock.Height,
+				Direction: indexers.AddressSent,
+				Amount:    100,
+			},
+		}, histories)
+
+		// reverse and paginate
+		histories, err = indexers.DBFetchAddressHistory(dbTx,
+			referRecipient1, 1, 1, true)
+		assert.NoError(t, err)
+		assert.Equal(t, []*indexers.AddressHistory{
+			{
+				TxID:      referTxID,
+				Height:    referHeight,
+				Direction: indexers.AddressReceived,
+				Amount:    100,
+			},
+		}, histories)
+
+		histories, err = indexers.DBFetchAddressHistory(dbTx,
+			referRecipient2, 0, 0, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []*indexers.AddressHistory{
+			{
+				TxID:      testUtxoIndexTx2.Hash(),
+				Height:    testUtxoIndexBlock.Height,
+				Direction: indexers.AddressSent,
+				Amount:    200,
+			},
+		}, histories)
+
+		// outputs to the same address should be merged per transaction
+		histories, err = indexers.DBFetchAddressHistory(dbTx, recipient1,
+			0, 0, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []*indexers.AddressHistory{
+			{
+				TxID:      testUtxoIndexTx1.Hash(),
+				Height:    testUtxoIndexBlock.Height,
+				Direction: indexers.AddressReceived,
+				Amount:    30,
+			},
+			{
+				TxID:      testUtxoIndexTx2.Hash(),
+				Height:    testUtxoIndexBlock.Height,
+				Direction: indexers.AddressReceived,
+				Amount:    30,
+			},
+		}, histories)
+
+		for _, c := range []struct {
+			programHash *common.Uint168
+			count       uint32
+		}{
+			{referRecipient1, 2},
+			{referRecipient2, 1},
+			{recipient1, 2},
+			{recipient2, 1},
+		} {
+			count, err := indexers.DBFetchAddressTxCount(dbTx, c.programHash)
+			assert.NoError(t, err)
+			assert.Equal(t, c.count, count)
+		}
+
+		return nil
+	})
+}
+
+func TestAddressIndex_SpendInBlock(t *testing.T) {
+	// The parent spends an output of the previous block, and the child
+	// spends an output of the parent in the same block, so the parent can
+	// only be found in the block being connected.
+	parent := functions.CreateTransaction(
+		0,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{},
+		[]*common2.Input{{Previous: common2.OutPoint{
+			TxID:  testUtxoIndexTx2.Hash(),
+			Index: 1,
+		}}},
+		[]*common2.Output{
+			{Value: 40, ProgramHash: *recipient1},
+			{Value: 10, ProgramHash: *recipient1},
+		},
+		0,
+		[]*program.Program{},
+	)
+	child := functions.CreateTransaction(
+		0,
+		common2.TransferAsset,
+		0,
+		&payload.TransferAsset{},
+		[]*common2.Attribute{},
+		[]*common2.Input{{Previous: common2.OutPoint{
+			TxID:  parent.Hash(),
+			Index: 0,
+		}}},
+		[]*common2.Output{{Value: 40, ProgramHash: *recipient2}},
+		0,
+		[]*program.Program{},
+	)
+	block := &types.Block{
+		Header: common2.Header{
+			Height:   testUtxoIndexBlock.Height + 1,
+			Previous: testUtxoIndexBlock.Hash(),
+		},
+		Transactions: []interfaces.Transaction{parent, child},
+	}
+	connectAddressIndexBlock(t, block, addressIndexers)
+
+	_ = addressIndexDB.View(func(dbTx database.Tx) error {
+		histories, err := indexers.DBFetchAddressHistory(dbTx, recipient1,
+			2, 0, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []*indexers.AddressHistory{
+			{
+				TxID:      parent.Hash(),
+				Height:    block.Height,
+				Direction: indexers.AddressReceived,
+				Amount:    50,
+			},
+			{
+				TxID:      child.Hash(),
+				Height:    block.Height,
+				Direction: indexers.AddressSent,
+				Amount:    40,
+			},
+		}, histories)
+
+		histories, err = indexers.DBFetchAddressHistory(dbTx, recipient2,
+			0, 0, true)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(histories))
+		assert.Equal(t, child.Hash(), histories[0].TxID)
+		assert.Equal(t, indexers.AddressReceived, histories[0].Direction)
+		assert.Equal(t, parent.Hash(), histories[1].TxID)
+		assert.Equal(t, indexers.AddressSent, histories[1].Direction)
+
+		// The history count is the unit of skip and count, which differs
+		// from the count of transactions.
+		count, err := indexers.DBFetchAddressHistoryCount(dbTx, recipient1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(4), count)
+		count, err = indexers.DBFetchAddressTxCount(dbTx, recipient1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(4), count)
+		count, err = indexers.DBFetchAddressHistoryCount(dbTx, recipient2)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(3), count)
+		count, err = indexers.DBFetchAddressTxCount(dbTx, recipient2)
+		assert.NoError(t, err)
+		assert.Equal(t, uint32(3), count)
+		return nil
+	})
+
+	disconnectAddressIndexBlock(t, block)
+	_ = addressIndexDB.View(func(dbTx database.Tx) error {
+		for _, c := range []struct {
+			programHash *common.Uint168
+			count       uint32
+		}{
+			{recipient1, 2},
+			{recipient2, 1},
+		} {
+			count, err := indexers.DBFetchAddressHistoryCount(dbTx,
+				c.programHash)
+			assert.NoError(t, err)
+			assert.Equal(t, c.count, count)
+		}
+		return nil
+	})
+}
+
+func TestAddressIndex_DisconnectBlock(t *testing.T) {
+	disconnectAddressIndexBlock(t, testUtxoIndexBlock)
+	_ = addressIndexDB.Update(func(dbTx database.Tx) error {
+		histories, err := indexers.DBFetchAddressHistory(dbTx,
+			referRecipient1, 0, 0, false)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(histories))
+		assert.Equal(t, referHeight, histories[0].Height)
+
+		for _, programHash := range []*common.Uint168{
+			referRecipient2, recipient1, recipient2} {
+			count, err := indexers.DBFetchAddressTxCount(dbTx, programHash)
+			assert.NoError(t, err)
+			assert.Equal(t, uint32(0), count)
+		}
+
+		return nil
+	})
+}
+
+func TestAddressIndexEnd(t *testing.T) {
+	_ = addressIndexDB.Update(func(dbTx database.Tx) error {
+		meta := dbTx.Metadata()
+		err := meta.DeleteBucket(indexers.AddressIndexKey)
+		assert.NoError(t, err)
+		return nil
+	})
+	addressIndexDB.Close()
+	os.RemoveAll(addressIndexPath)
+}
@@ -8,6 +8,7 @@ package unit
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"math/big"
@@ -217,6 +218,56 @@ func TestCheckMultiSigSignature(t *testing.T) {
 	assert.Error(t, err, "[CheckMultisigSignature] invalid signature fake signature")
 }
 
+func TestCheckHTLCSignature(t *testing.T) {
+	var tx interfaces.Transaction
+
+	tx = buildTx()
+	data := getData(tx)
+	recipient := newAccount(t)
+	refund := newAccount(t)
+
+	preimage := make([]byte, contract.HTLCPreimageLength)
+	rand.Read(preimage)
+	code, err := contract.CreateHTLCRedeemScript(sha256.Sum256(preimage),
+		recipient.public, refund.public, 1000)
+	assert.NoError(t, err)
+	assert.True(t, contract.IsHTLC(code))
+	assert.Equal(t, contract.HTLC, contract.GetCodeType(code))
+
+	// redeem by recipient with preimage
+	signature, err := recipient.Sign(data)
+	assert.NoError(t, err)
+	redeem := append(append(signature, byte(len(preimage))), preimage...)
+	err = blockchain.CheckHTLCSignature(program.Program{Code: code, Parameter: redeem}, data)
+	assert.NoError(t, err, "[CheckHTLCSignature] redeem failed, %v", err)
+
+	// redeem with a wrong preimage
+	wrong := append(append(signature, byte(len(preimage))), make([]byte, len(preimage))...)
+	err = blockchain.CheckHTLCSignature(program.Program{Code: code, Parameter: wrong}, data)
+	assert.EqualError(t, err, "htlc preimage does not match the hash lock")
+
+	// redeem with the signature of refund key
+	signature, err = refund.Sign(data)
+	assert.NoError(t, err)
+	redeem = append(append(signature, byte(len(preimage))), preimage...)
+	err = blockchain.CheckHTLCSignature(program.Program{Code: code, Parameter: redeem}, data)
+	assert.Error(t, err, "[CheckHTLCSignature] redeem by refund key")
+
+	// refund by refund key
+	err = blockchain.CheckHTLCSignature(program.Program{Code: code, Parameter: signature}, data)
+	assert.NoError(t, err, "[CheckHTLCSignature] refund failed, %v", err)
+
+	// refund with the signature of recipient
+	signature, err = recipient.Sign(data)
+	assert.NoError(t, err)
+	err = blockchain.CheckHTLCSignature(program.Program{Code: code, Parameter: signature}, data)
+	assert.Error(t, err, "[CheckHTLCSignature] refund by recipient")
+
+	// invalid parameter length
+	err = blockchain.CheckHTLCSignature(program.Program{Code: code, Parameter: signature[1:]}, data)
+	assert.EqualError(t, err, "invalid htlc parameter length")
+}
+
 func TestSchnorrRunProgramsOrigin(t *testing.T) {
 	var testCases = []struct {
 		d           string //private key
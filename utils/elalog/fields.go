@@ -0,0 +1,92 @@
+// Copyright (c) 2017-2020 The Elastos Foundation
+// Use of this source code is governed by an MIT
+// license that can be found in the LICENSE file.
+//
+
+package elalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Fields are the structured fields of a log message, such as the height and
+// hash of a block or the address of a peer.  They are passed as the last
+// argument of a logging call, and written as key=value pairs after a text
+// message or as the fields object of a JSON message.
+type Fields map[string]interface{}
+
+// levelNames defines the names of logging levels in JSON messages.
+var levelNames = [...]string{"debug", "info", "warn", "error", "fatal", "off"}
+
+// jsonMessage is the layout of a log message in JSON format.
+type jsonMessage struct {
+	Time      string `json:"time"`
+	Level     string `json:"level"`
+	Subsystem string `json:"subsystem,omitempty"`
+	Caller    string `json:"caller,omitempty"`
+	Message   string `json:"msg"`
+	Fields    Fields `json:"fields,omitempty"`
+}
+
+// SplitFields returns the arguments of a logging call without the structured
+// fields, and the fields if the last argument is.
+func SplitFields(args []interface{}) ([]interface{}, Fields) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	fields, ok := args[len(args)-1].(Fields)
+	if !ok {
+		return args, nil
+	}
+	return args[:len(args)-1], fields
+}
+
+// AppendFields appends the fields to a text message as space separated
+// key=value pairs ordered by key.
+func AppendFields(buf []byte, fields Fields) []byte {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		buf = append(buf, ' ')
+		buf = append(buf, k...)
+		buf = append(buf, '=')
+		buf = append(buf, fmt.Sprint(fields[k])...)
+	}
+	return buf
+}
+
+// AppendJSON appends a log message in JSON format followed by a newline.  The
+// caller is the function and line of the logging call, it is omitted if
+// empty.
+func AppendJSON(buf []byte, t time.Time, level Level, tag, caller,
+	msg string, fields Fields) []byte {
+	if level > LevelOff {
+		level = LevelOff
+	}
+	m := jsonMessage{
+		Time:      t.Format("2006-01-02T15:04:05.000000Z07:00"),
+		Level:     levelNames[level],
+		Subsystem: tag,
+		Caller:    caller,
+		Message:   msg,
+		Fields:    fields,
+	}
+	data, err := json.Marshal(&m)
+	if err != nil {
+		// Fall back to the text form of the values which can not be
+		// encoded, such as channels and functions.
+		m.Fields = make(Fields, len(fields))
+		for k, v := range fields {
+			m.Fields[k] = fmt.Sprint(v)
+		}
+		data, _ = json.Marshal(&m)
+	}
+	buf = append(buf, data...)
+	return append(buf, '\n')
+}
@@ -12,6 +12,7 @@ import (
 	"io/ioutil"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -27,6 +28,10 @@ const (
 	// Lshortfile modifies the logger output to include filename and line number
 	// of the logging callsite, e.g. main.go:123.  Overrides Llongfile.
 	Lshortfile
+
+	// LJSON modifies the logger output to write a JSON object per line,
+	// which contains the time, level, tag, message and structured fields.
+	LJSON
 )
 
 // Level is the level at which a logger is configured.  All messages sent
@@ -213,10 +218,21 @@ func (b *Backend) print(lvl, tag string, args ...interface{}) {
 		file, line = callsite(b.flag)
 	}
 
-	formatHeader(bytebuf, t, lvl, tag, file, line)
-	buf := bytes.NewBuffer(*bytebuf)
-	fmt.Fprintln(buf, args...)
-	*bytebuf = buf.Bytes()
+	args, fields := SplitFields(args)
+	if b.flag&LJSON != 0 {
+		msg := fmt.Sprintln(args...)
+		*bytebuf = b.appendJSON(*bytebuf, t, lvl, tag, file, line,
+			msg[:len(msg)-1], fields)
+	} else {
+		formatHeader(bytebuf, t, lvl, tag, file, line)
+		buf := bytes.NewBuffer(*bytebuf)
+		fmt.Fprintln(buf, args...)
+		*bytebuf = buf.Bytes()
+		if len(fields) > 0 {
+			*bytebuf = append(AppendFields((*bytebuf)[:len(*bytebuf)-1],
+				fields), '\n')
+		}
+	}
 
 	b.mu.Lock()
 	b.w.Write(*bytebuf)
@@ -240,10 +256,16 @@ func (b *Backend) printf(lvl, tag string, format string, args ...interface{}) {
 		file, line = callsite(b.flag)
 	}
 
-	formatHeader(bytebuf, t, lvl, tag, file, line)
-	buf := bytes.NewBuffer(*bytebuf)
-	fmt.Fprintf(buf, format, args...)
-	*bytebuf = append(buf.Bytes(), '\n')
+	args, fields := SplitFields(args)
+	if b.flag&LJSON != 0 {
+		*bytebuf = b.appendJSON(*bytebuf, t, lvl, tag, file, line,
+			fmt.Sprintf(format, args...), fields)
+	} else {
+		formatHeader(bytebuf, t, lvl, tag, file, line)
+		buf := bytes.NewBuffer(*bytebuf)
+		fmt.Fprintf(buf, format, args...)
+		*bytebuf = append(AppendFields(buf.Bytes(), fields), '\n')
+	}
 
 	b.mu.Lock()
 	b.w.Write(*bytebuf)
@@ -252,6 +274,18 @@ func (b *Backend) printf(lvl, tag string, format string, args ...interface{}) {
 	recycleBuffer(bytebuf)
 }
 
+// appendJSON appends the log message in JSON format, the level is one of the
+// level tags used in text messages.
+func (b *Backend) appendJSON(buf []byte, t time.Time, lvl, tag string,
+	file string, line int, msg string, fields Fields) []byte {
+	level, _ := LevelFromString(lvl)
+	var caller string
+	if file != "" {
+		caller = file + ":" + strconv.Itoa(line)
+	}
+	return AppendJSON(buf, t, level, tag, caller, msg, fields)
+}
+
 // Logger returns a new logger for a particular subsystem that writes to the
 // Backend b.  A tag describes the subsystem and is included in all log
 // messages.  The logger uses the info verbosity level by default.
@@ -126,4 +126,7 @@ const (
 
 	// For schnorr
 	SCHNORR = 0xD0
+
+	// For hash time locked contract
+	HTLC = 0xD1
 )